@@ -30,9 +30,9 @@ type Account struct {
 	Contact   []string   `json:"contact"`
 	CreatedAt timeString `json:"createdAt,omitempty"` // non-standard field
 	Location  *string    `json:"-"`                   // omit because it is in the header
+	Orders    string     `json:"orders,omitempty"`    // optional; not all servers provide it
 	// -- also available but not in use
 	// JsonWebKey jsonWebKey `json:"key"`
-	// Orders     string     `json:"orders"`
 	// InitialIP  string     `json:"initialIp"`
 }
 
@@ -68,11 +68,11 @@ func (response *Account) Email() string {
 }
 
 // NewAccount posts a secure message to the NewAccount URL of the directory
-func (service *Service) NewAccount(payload NewAccountPayload, privateKey crypto.PrivateKey) (acct Account, err error) {
+func (service *Service) NewAccount(payload NewAccountPayload, signer crypto.Signer) (acct Account, err error) {
 	// Create ACME accountKey
 	// Register account should never use kid, it must always use JWK
 	accountKey := AccountKey{
-		Key: privateKey,
+		Key: signer,
 	}
 
 	// url to post to
@@ -174,7 +174,7 @@ func (service *Service) DeactivateAccount(accountKey AccountKey) (acct Account,
 
 // RolloverAccountKey rolls over the specified account's key to the newKey. This essentially
 // retires the old key from the account and substitutes the new key in its place.
-func (service *Service) RolloverAccountKey(newKey crypto.PrivateKey, oldAccountKey AccountKey) (err error) {
+func (service *Service) RolloverAccountKey(newKey crypto.Signer, oldAccountKey AccountKey) (err error) {
 	// build payload
 	payload := acmeSignedMessage{}
 
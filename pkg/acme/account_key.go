@@ -5,9 +5,11 @@ import (
 	"strings"
 )
 
-// AccountKey is the necessary account / key information for signed message generation
+// AccountKey is the necessary account / key information for signed message generation.
+// Key may hold its private key in memory or it may be an external signer (in which
+// case the private key never leaves the signer's secure module).
 type AccountKey struct {
-	Key crypto.PrivateKey
+	Key crypto.Signer
 	Kid string
 }
 
@@ -0,0 +1,68 @@
+package acme
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/webpackager/resource/httplink"
+)
+
+// maxOrdersListPages caps how many pages of an account's orders list are fetched
+const maxOrdersListPages = 100
+
+var errOrdersListUnsupported = errors.New("acme server did not provide an orders url for the account")
+
+// ordersList is the ACME orders list object (see: RFC8555 7.1.2.1)
+type ordersList struct {
+	Orders []string `json:"orders"`
+}
+
+// GetAccountOrders fetches the account (to get its orders url) and then does a
+// POST-as-GET of the account's orders list, following any pagination (Link
+// rel="next"). It returns the urls of all of the orders the server listed.
+func (service *Service) GetAccountOrders(accountKey AccountKey) (orderUrls []string, err error) {
+	acct, err := service.GetAccount(accountKey)
+	if err != nil {
+		return nil, err
+	}
+	if acct.Orders == "" {
+		return nil, errOrdersListUnsupported
+	}
+
+	orderUrls = []string{}
+	nextUrl := acct.Orders
+	for page := 0; nextUrl != "" && page < maxOrdersListPages; page++ {
+		// POST-as-GET
+		jsonResp, headers, err := service.postAsGet(nextUrl, accountKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// unmarshal response
+		var list ordersList
+		err = json.Unmarshal(jsonResp, &list)
+		if err != nil {
+			return nil, err
+		}
+		orderUrls = append(orderUrls, list.Orders...)
+
+		// find the next page, if there is one
+		nextUrl = ""
+		for _, headerLink := range headers.Values("Link") {
+			httpLinks, err := httplink.Parse(headerLink)
+			if err != nil {
+				service.logger.Warnf("acme: %s sent bad Link header in orders list response (%s)", service.dirUri, err)
+				continue
+			}
+
+			for _, httpLink := range httpLinks {
+				if strings.EqualFold(httpLink.Params.Get("rel"), "next") {
+					nextUrl = httpLink.URL.String()
+				}
+			}
+		}
+	}
+
+	return orderUrls, nil
+}
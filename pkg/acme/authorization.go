@@ -2,8 +2,11 @@ package acme
 
 import (
 	"encoding/json"
+	"errors"
 )
 
+var errNewAuthzUnsupported = errors.New("acme server does not support pre-authorization (newAuthz)")
+
 // ACME authorization response
 type Authorization struct {
 	Identifier Identifier  `json:"identifier"` // see orders
@@ -11,6 +14,12 @@ type Authorization struct {
 	Expires    timeString  `json:"expires"`
 	Challenges []Challenge `json:"challenges"`
 	Wildcard   bool        `json:"wildcard,omitempty"`
+	Location   string      `json:"-"` // omit because it is in the header
+}
+
+// NewAuthzPayload is the payload to post to ACME newAuthz
+type NewAuthzPayload struct {
+	Identifier Identifier `json:"identifier"`
 }
 
 // Account response decoder
@@ -37,6 +46,62 @@ func (service *Service) GetAuth(authUrl string, accountKey AccountKey) (auth Aut
 	if err != nil {
 		return Authorization{}, err
 	}
+	auth.Location = authUrl
+
+	return auth, nil
+}
+
+// SupportsNewAuthz returns if the acme server advertises the newAuthz resource
+// (pre-authorization, see: RFC8555 7.4.1)
+func (service *Service) SupportsNewAuthz() bool {
+	return service.dir.NewAuthz != ""
+}
+
+// NewAuthz posts a secure message to the NewAuthz URL of the directory to
+// pre-authorize an identifier
+func (service *Service) NewAuthz(payload NewAuthzPayload, accountKey AccountKey) (auth Authorization, err error) {
+	if !service.SupportsNewAuthz() {
+		return Authorization{}, errNewAuthzUnsupported
+	}
+
+	// post new-authz
+	jsonResp, headers, err := service.postToUrlSigned(payload, service.dir.NewAuthz, accountKey)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	// unmarshal response
+	auth, err = unmarshalAuthorization(jsonResp)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	// authorization location (url) isn't part of the JSON response, add it from the header.
+	auth.Location = headers.Get("Location")
+
+	return auth, nil
+}
+
+// deactivateAuthPayload is the payload to deactivate an authorization
+type deactivateAuthPayload struct {
+	Status string `json:"status"`
+}
+
+// DeactivateAuth posts a secure message to the authorization URL to deactivate the
+// authorization (see: RFC8555 7.5.2)
+func (service *Service) DeactivateAuth(authUrl string, accountKey AccountKey) (auth Authorization, err error) {
+	// post deactivation
+	jsonResp, _, err := service.postToUrlSigned(deactivateAuthPayload{Status: "deactivated"}, authUrl, accountKey)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	// unmarshal response
+	auth, err = unmarshalAuthorization(jsonResp)
+	if err != nil {
+		return Authorization{}, err
+	}
+	auth.Location = authUrl
 
 	return auth, nil
 }
@@ -2,11 +2,13 @@ package acme
 
 import (
 	"certwarden-backend/pkg/httpclient"
+	"certwarden-backend/pkg/metrics"
 	"certwarden-backend/pkg/randomness"
 	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
 	"reflect"
 	"sync"
 	"time"
@@ -31,6 +33,9 @@ type directory struct {
 		Website                 string   `json:"website"`
 		CaaIdentities           []string `json:"caaIdentities"`
 		ExternalAccountRequired bool     `json:"externalAccountRequired"`
+		// Profiles are the certificate profiles the server offers, keyed by profile name
+		// with a human readable description (draft-aaron-acme-profiles)
+		Profiles map[string]string `json:"profiles,omitempty"`
 	} `json:"meta"`
 }
 
@@ -38,10 +43,13 @@ type directory struct {
 // dirUri and return a directory object. If the directory fails to fetch or what
 // is fetched is invalid, an error is returned.
 func FetchAcmeDirectory(httpClient *httpclient.Client, dirUri string) (directory, error) {
+	startTime := time.Now()
 	response, err := httpClient.Get(dirUri)
 	if err != nil {
+		metrics.ObserveAcmeRequest(http.MethodGet, dirUri, startTime, 0, err)
 		return directory{}, err
 	}
+	metrics.ObserveAcmeRequest(http.MethodGet, dirUri, startTime, response.StatusCode, nil)
 	defer response.Body.Close()
 
 	// No nonce to save. ACME spec provides nonce on new-nonce requests
@@ -184,3 +192,25 @@ func (service *Service) TosUrl() string {
 func (service *Service) RequiresEAB() bool {
 	return service.dir.Meta.ExternalAccountRequired
 }
+
+// Profiles returns the certificate profiles advertised by the acme server (name
+// and description). If the server does not support profiles, the map is empty.
+func (service *Service) Profiles() map[string]string {
+	profiles := make(map[string]string, len(service.dir.Meta.Profiles))
+	for name, desc := range service.dir.Meta.Profiles {
+		profiles[name] = desc
+	}
+
+	return profiles
+}
+
+// ProfileValid returns true if the specified profile is advertised by the acme
+// server. A blank profile is always valid (it means the server's default).
+func (service *Service) ProfileValid(profile string) bool {
+	if profile == "" {
+		return true
+	}
+
+	_, exists := service.dir.Meta.Profiles[profile]
+	return exists
+}
@@ -3,6 +3,7 @@ package acme
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // ACME error
@@ -10,6 +11,8 @@ type Error struct {
 	Status int    `json:"status"`
 	Type   string `json:"type"`
 	Detail string `json:"detail"`
+	// RetryAfter is the time from the response's Retry-After header, if there was one
+	RetryAfter *time.Time `json:"-"`
 }
 
 // Error() implements the error interface
@@ -22,8 +25,10 @@ func (e *Error) Error() string {
 func unmarshalErrorResponse(bodyBytes []byte) (errResponse *Error) {
 	errResponse = new(Error)
 	err := json.Unmarshal(bodyBytes, errResponse)
-	// if error decoding was not succesful, not an error
-	if err != nil {
+	// if error decoding was not succesful, not an error; also not an error if there is
+	// no problem type (e.g. a successful response that has no conflicting fields, such
+	// as an orders list)
+	if err != nil || errResponse.Type == "" {
 		return nil
 	}
 
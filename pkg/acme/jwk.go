@@ -23,27 +23,31 @@ type jsonWebKey struct {
 func (accountKey *AccountKey) jwk() (jwk *jsonWebKey, err error) {
 	jwk = new(jsonWebKey)
 
-	switch privateKey := accountKey.Key.(type) {
-	case *rsa.PrivateKey:
+	if accountKey.Key == nil {
+		return nil, errors.New("acme: jwk: missing key")
+	}
+
+	switch publicKey := accountKey.Key.Public().(type) {
+	case *rsa.PublicKey:
 		jwk.KeyType = "RSA"
 
-		jwk.PublicExponent, err = encodeInt(privateKey.E)
+		jwk.PublicExponent, err = encodeInt(publicKey.E)
 		if err != nil {
 			return nil, err
 		}
-		keyBitSize := privateKey.N.BitLen()
-		jwk.Modulus = encodeBigInt(privateKey.N, keyBitSize)
+		keyBitSize := publicKey.N.BitLen()
+		jwk.Modulus = encodeBigInt(publicKey.N, keyBitSize)
 
 		return jwk, nil
 
-	case *ecdsa.PrivateKey:
+	case *ecdsa.PublicKey:
 		jwk.KeyType = "EC"
 
-		jwk.CurveName = privateKey.Curve.Params().Name
+		jwk.CurveName = publicKey.Curve.Params().Name
 
-		keyBitSize := privateKey.Curve.Params().BitSize
-		jwk.CurvePointX = encodeBigInt(privateKey.X, keyBitSize)
-		jwk.CurvePointY = encodeBigInt(privateKey.Y, keyBitSize)
+		keyBitSize := publicKey.Curve.Params().BitSize
+		jwk.CurvePointX = encodeBigInt(publicKey.X, keyBitSize)
+		jwk.CurvePointY = encodeBigInt(publicKey.Y, keyBitSize)
 
 		return jwk, nil
 
@@ -3,8 +3,11 @@ package nonces
 import (
 	"certwarden-backend/pkg/datatypes/ringbuffer"
 	"certwarden-backend/pkg/httpclient"
+	"certwarden-backend/pkg/metrics"
 	"errors"
 	"io"
+	"net/http"
+	"time"
 )
 
 // Manager is the NonceManager
@@ -32,10 +35,13 @@ func NewManager(client *httpclient.Client, nonceUrl *string) *Manager {
 // if fetching fails or the header does not contain a nonce,
 // an error is returned
 func (manager *Manager) fetchNonce() (string, error) {
+	startTime := time.Now()
 	response, err := manager.httpClient.Head(*manager.newNonceUrl)
 	if err != nil {
+		metrics.ObserveAcmeRequest(http.MethodHead, *manager.newNonceUrl, startTime, 0, err)
 		return "", err
 	}
+	metrics.ObserveAcmeRequest(http.MethodHead, *manager.newNonceUrl, startTime, response.StatusCode, nil)
 	defer response.Body.Close()
 
 	// read entire body (to keep single tls connection open and avoid redundant cert
@@ -4,14 +4,41 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 )
 
 // NewOrderPayload is the payload to post to ACME newOrder
 type NewOrderPayload struct {
-	// notBefore and notAfter are optional and not implemented
 	Identifiers IdentifierSlice `json:"identifiers"`
+	// NotBefore and NotAfter are optional; many CAs do not support them
+	NotBefore *timeString `json:"notBefore,omitempty"`
+	NotAfter  *timeString `json:"notAfter,omitempty"`
+	// Profile is optional and only sent if the server advertises profiles
+	Profile string `json:"profile,omitempty"`
+}
+
+// SetValidity sets notBefore to now and notAfter to now plus the specified validity. If
+// validity is not positive, notBefore and notAfter are removed from the payload.
+func (payload *NewOrderPayload) SetValidity(validity time.Duration) {
+	if validity <= 0 {
+		payload.NotBefore = nil
+		payload.NotAfter = nil
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	notBefore := timeString(now.Format(time.RFC3339))
+	notAfter := timeString(now.Add(validity).Format(time.RFC3339))
+
+	payload.NotBefore = &notBefore
+	payload.NotAfter = &notAfter
+}
+
+// HasValidity returns true if the payload requests a specific validity period
+func (payload *NewOrderPayload) HasValidity() bool {
+	return payload.NotBefore != nil || payload.NotAfter != nil
 }
 
 // LE response with order information
@@ -26,6 +53,8 @@ type Order struct {
 	NotBefore      *timeString     `json:"notBefore,omitempty"`
 	NotAfter       *timeString     `json:"notAfter,omitempty"`
 	Location       string          `json:"-"` // omit because it is in the header
+	// RetryAfter is the time from the Retry-After header (e.g. when the order is processing)
+	RetryAfter *time.Time `json:"-"`
 }
 
 // Account response decoder
@@ -37,6 +66,7 @@ func unmarshalOrder(jsonResp []byte, headers http.Header) (order Order, err erro
 
 	// order location (url) isn't part of the JSON response, add it from the header.
 	order.Location = headers.Get("Location")
+	order.RetryAfter = parseRetryAfter(headers.Get("Retry-After"), time.Now())
 
 	return order, nil
 }
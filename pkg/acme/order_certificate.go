@@ -1,8 +1,10 @@
 package acme
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -21,12 +23,71 @@ type Certificate struct {
 	notBefore   time.Time
 	notAfter    time.Time
 	chainRootCN string
+	issuers     []ChainIssuer
+	url         string
 }
 
-func (c *Certificate) PEM() string          { return c.pem }
-func (c *Certificate) NotBefore() time.Time { return c.notBefore }
-func (c *Certificate) NotAfter() time.Time  { return c.notAfter }
-func (c *Certificate) ChainRootCN() string  { return c.chainRootCN }
+func (c *Certificate) PEM() string            { return c.pem }
+func (c *Certificate) NotBefore() time.Time   { return c.notBefore }
+func (c *Certificate) NotAfter() time.Time    { return c.notAfter }
+func (c *Certificate) ChainRootCN() string    { return c.chainRootCN }
+func (c *Certificate) Issuers() []ChainIssuer { return c.issuers }
+func (c *Certificate) URL() string            { return c.url }
+
+// ChainIssuer is one of the issuing (non-leaf) certificates in a certificate chain
+type ChainIssuer struct {
+	CommonName       string `json:"common_name"`
+	IssuerCommonName string `json:"issuer_common_name"`
+	// SPKISHA256 is the hex encoded SHA-256 hash of the certificate's Subject Public Key Info
+	SPKISHA256 string `json:"spki_sha256"`
+}
+
+// MatchesRoot returns true if the chain's topmost certificate was issued by the
+// specified Subject Common Name
+func (c *Certificate) MatchesRoot(rootCN string) bool {
+	return strings.EqualFold(strings.TrimSpace(rootCN), c.chainRootCN)
+}
+
+// MatchesIssuer returns true if any issuing certificate in the chain has the specified
+// Subject Common Name or Subject Public Key Info SHA-256 hash (hex, optionally prefixed
+// with 'sha256:')
+func (c *Certificate) MatchesIssuer(nameOrHash string) bool {
+	nameOrHash = strings.TrimSpace(nameOrHash)
+	hash := strings.TrimPrefix(strings.ToLower(nameOrHash), "sha256:")
+
+	for _, issuer := range c.issuers {
+		if strings.EqualFold(nameOrHash, issuer.CommonName) || hash == issuer.SPKISHA256 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SelectChain returns the preferred chain from the list of chains. Chains whose root CN
+// matches preferred are chosen first, then chains with an issuer matching preferred by CN
+// or SPKI hash. If preferred is blank or nothing matches, the first chain (the server's
+// default, if it was valid) is returned. nil is returned if chains is empty.
+func SelectChain(chains []*Certificate, preferred string) *Certificate {
+	if len(chains) == 0 {
+		return nil
+	}
+
+	if strings.TrimSpace(preferred) != "" {
+		for _, chain := range chains {
+			if chain.MatchesRoot(preferred) {
+				return chain
+			}
+		}
+		for _, chain := range chains {
+			if chain.MatchesIssuer(preferred) {
+				return chain
+			}
+		}
+	}
+
+	return chains[0]
+}
 
 // responseToCertificate checks that the ACME server returned a valid cert/chain response
 // when this client downloaded a certificate. If valid, the response is parsed into the
@@ -38,8 +99,17 @@ func responseToCertificate(bodyBytes []byte, headers http.Header) (*Certificate,
 		return nil, errors.New("certificate content type is not application/pem-certificate-chain")
 	}
 
+	return ParseCertificateChain(string(bodyBytes))
+}
+
+// ParseCertificateChain validates and parses a pem certificate chain (e.g. one that was
+// previously downloaded and stored) into the Certificate struct. If not valid, an error
+// is returned.
+func ParseCertificateChain(pemChain string) (*Certificate, error) {
+	bodyBytes := []byte(pemChain)
+
 	// validate ACME server didn't return malicious pem (see: RFC8555 s 11.4)
-	pemCheck := string(bodyBytes)
+	pemCheck := pemChain
 	beginString := "-----BEGIN"
 	mustBeFollowedBy := " CERTIFICATE"
 
@@ -60,7 +130,7 @@ func responseToCertificate(bodyBytes []byte, headers http.Header) (*Certificate,
 
 	// make return struct
 	cert := &Certificate{
-		pem: string(bodyBytes),
+		pem: pemChain,
 	}
 
 	// parse chain and do more extensive validation
@@ -109,19 +179,50 @@ func responseToCertificate(bodyBytes []byte, headers http.Header) (*Certificate,
 	cert.notBefore = leafCert.NotBefore
 	cert.notAfter = leafCert.NotAfter
 
+	// parse the issuing certs to record the issuer path
+	cert.issuers = []ChainIssuer{}
+	for _, derIssuerCert := range tlsCert.Certificate[1:] {
+		issuerCert, err := x509.ParseCertificate(derIssuerCert)
+		if err != nil {
+			return nil, errors.New("failed to parse issuer cert in chain")
+		}
+
+		spkiHash := sha256.Sum256(issuerCert.RawSubjectPublicKeyInfo)
+		cert.issuers = append(cert.issuers, ChainIssuer{
+			CommonName:       issuerCert.Subject.CommonName,
+			IssuerCommonName: issuerCert.Issuer.CommonName,
+			SPKISHA256:       hex.EncodeToString(spkiHash[:]),
+		})
+	}
+
 	return cert, nil
 }
 
 // DownloadCertificate uses POST-as-GET to download a valid certificate from the specified
-// url. If this first download passes basic sanity checks, it becomes the default cert/chain
-// that is returned by this function. If the sanity check fails, alternate chain options are
-// tried until one passes the sanity check. The first to pass the sanity check becomes the
-// default cert/chain returned.
-// If a preferredChain is specified, prefer the chain whose topmost certificate was issued
-// from this Subject Common Name (assuming the basic sanity checks pass). If no match or the
-// sanity check fails, the default chain is returned instead.
+// url. All of the chains the server offers are downloaded and the chain to return is
+// chosen by SelectChain using preferredChain (which may be a root CN, an intermediate CN,
+// or an issuer SPKI hash). If nothing matches, the default chain is returned (or the first
+// alternate that passes the sanity check, if the default did not).
 func (service *Service) DownloadCertificate(certificateUrl string, accountKey AccountKey, preferredChain string) (*Certificate, error) {
-	var defaultChainCert *Certificate
+	chains, err := service.DownloadCertificateChains(certificateUrl, accountKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := SelectChain(chains, preferredChain)
+	if preferredChain != "" && !cert.MatchesRoot(preferredChain) && !cert.MatchesIssuer(preferredChain) {
+		service.logger.Warnf("acme: went through all alt chains of %s without preferred chain match, returning default chain", certificateUrl)
+	}
+
+	return cert, nil
+}
+
+// DownloadCertificateChains uses POST-as-GET to download the certificate from the specified
+// url as well as all alternate chains (Link rel="alternate", see: RFC8555 7.4.2). Chains
+// that fail the sanity check are skipped. The server's default chain is first (if it passed
+// the sanity check). An error is returned if no valid chain was found.
+func (service *Service) DownloadCertificateChains(certificateUrl string, accountKey AccountKey) ([]*Certificate, error) {
+	chains := []*Certificate{}
 
 	// POST-as-GET
 	bodyBytes, defaultHeaders, err := service.postAsGet(certificateUrl, accountKey)
@@ -134,20 +235,12 @@ func (service *Service) DownloadCertificate(certificateUrl string, accountKey Ac
 	// if default chain didn't validate, log issue and continue to alts
 	if err != nil {
 		service.logger.Warnf("acme: %s default cert chain failed validation (see: rfc8555 s 11.4) (%s); will try others if available", certificateUrl, err)
-		// don't return, instead try any alt chains first
+		// don't return, instead try any alt chains
 	} else {
-		// return now if no preferred chain specified, OR if this chain is the preferred chain
-		if preferredChain == "" || strings.EqualFold(preferredChain, cert.ChainRootCN()) {
-			return cert, nil
-		}
-
-		// validated but not the preferred chain we're seeking -- set this as default
-		defaultChainCert = cert
+		cert.url = certificateUrl
+		chains = append(chains, cert)
 	}
 
-	// at this point, either the default chain didn't validate, or it wasn't preferred, so
-	// proceed to checking the alt options
-
 	// make slice of the URLs for alt chains
 	altChainUrls := []*url.URL{}
 	// check each Link header
@@ -156,7 +249,7 @@ func (service *Service) DownloadCertificate(certificateUrl string, accountKey Ac
 		httpLinks, err := httplink.Parse(headerLink)
 		if err != nil {
 			// if failed to parse, discard this Link header and continue
-			service.logger.Warnf("acme: %s sent bad Link header in certificate download response (%s)", err)
+			service.logger.Warnf("acme: %s sent bad Link header in certificate download response (%s)", certificateUrl, err)
 			continue
 		}
 
@@ -168,7 +261,7 @@ func (service *Service) DownloadCertificate(certificateUrl string, accountKey Ac
 		}
 	}
 
-	// check alt chain URLs that are available
+	// fetch alt chain URLs that are available
 	for _, altChainURL := range altChainUrls {
 
 		// POST-as-GET the alt chain
@@ -181,36 +274,20 @@ func (service *Service) DownloadCertificate(certificateUrl string, accountKey Ac
 
 		// validate alt chain
 		cert, err = responseToCertificate(bodyBytes, headers)
-		// if default chain didn't validate, log issue
 		if err != nil {
 			service.logger.Warnf("acme: %s alt cert chain failed validation (see: rfc8555 s 11.4) (%s); will try others if available", altChainURL.String(), err)
 			// don't return, continue to next alt to keep trying
 			continue
 		}
 
-		// this chain is now confirmed valid
-
-		// return this chain if no preferred chain specified, OR if this chain is the preferred chain
-		if preferredChain == "" || strings.EqualFold(preferredChain, cert.ChainRootCN()) {
-			return cert, nil
-		}
-
-		// set defaultChain if there isn't one yet
-		if defaultChainCert == nil {
-			defaultChainCert = cert
-		}
-
-		// preferred chain is set and this chain did not match, continue loop to keep trying alts
+		cert.url = altChainURL.String()
+		chains = append(chains, cert)
 	}
 
-	// made it through all of the alt chains without success (either no valid chain at all and/or no chain match preferred chain
-
-	// check if defaultChain was set by any valid chain; if not, error as no valid chains
-	if defaultChainCert == nil {
+	// error if no valid chains
+	if len(chains) == 0 {
 		return nil, fmt.Errorf("acme: no valid cert chains found for %s", certificateUrl)
 	}
 
-	// at least one valid chain was found (even though preferred didn't match), return that one
-	service.logger.Warnf("acme: went through all alt chains of %s without preferred chain match, returning default chain", certificateUrl)
-	return defaultChainCert, nil
+	return chains, nil
 }
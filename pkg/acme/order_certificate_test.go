@@ -0,0 +1,42 @@
+package acme
+
+import "testing"
+
+func TestOrderCertificate_SelectChain(t *testing.T) {
+	defaultChain := &Certificate{
+		chainRootCN: "Root A",
+		issuers:     []ChainIssuer{{CommonName: "Intermediate A", IssuerCommonName: "Root A", SPKISHA256: "aaaa"}},
+	}
+	altChain := &Certificate{
+		chainRootCN: "Root B",
+		issuers: []ChainIssuer{
+			{CommonName: "Intermediate A", IssuerCommonName: "Root A", SPKISHA256: "aaaa"},
+			{CommonName: "Root A", IssuerCommonName: "Root B", SPKISHA256: "bbbb"},
+		},
+	}
+	chains := []*Certificate{defaultChain, altChain}
+
+	tests := []struct {
+		preferred string
+		expected  *Certificate
+	}{
+		{"", defaultChain},
+		{"root b", altChain},
+		// root CN match is preferred over intermediate match
+		{"Root A", defaultChain},
+		{"Intermediate A", defaultChain},
+		{"sha256:BBBB", altChain},
+		{"bbbb", altChain},
+		{"no match", defaultChain},
+	}
+
+	for _, test := range tests {
+		if SelectChain(chains, test.preferred) != test.expected {
+			t.Errorf("SelectChain(%q): unexpected chain selected", test.preferred)
+		}
+	}
+
+	if SelectChain(nil, "Root A") != nil {
+		t.Error("SelectChain(nil): expected nil")
+	}
+}
@@ -2,11 +2,13 @@ package acme
 
 import (
 	"bytes"
+	"certwarden-backend/pkg/metrics"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 )
@@ -123,10 +125,13 @@ func (service *Service) postToUrlSigned(payload any, url string, accountKey Acco
 		//service.logger.Debugf("acme post signed body: %s", string(messageBodyJson))
 
 		// post to ACME
+		startTime := time.Now()
 		response, err = service.httpClient.Post(url, "application/jose+json", bytes.NewBuffer(messageBodyJson))
 		if err != nil {
+			metrics.ObserveAcmeRequest(http.MethodPost, url, startTime, 0, err)
 			return nil, nil, err
 		}
+		metrics.ObserveAcmeRequest(http.MethodPost, url, startTime, response.StatusCode, nil)
 		defer response.Body.Close()
 
 		// read body of response
@@ -154,6 +159,10 @@ func (service *Service) postToUrlSigned(payload any, url string, accountKey Acco
 			// set err to check after loop ends
 			err = acmeError
 
+			// record any rate limiting
+			acmeError.RetryAfter = parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+			service.recordRateLimit(accountKey, response.StatusCode, acmeError, acmeError.RetryAfter)
+
 			// if acme error and it is specifically bad nonce, set header nonce and continue
 			// to next loop iteration
 			if acmeError.Type == "urn:ietf:params:acme:error:badNonce" {
@@ -0,0 +1,141 @@
+package acme
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errTypeRateLimited is the ACME error type for rate limiting
+const errTypeRateLimited = "urn:ietf:params:acme:error:rateLimited"
+
+// defaultRateLimitDuration is how long to consider an account or server rate limited
+// if the server sends a rateLimited error without a usable Retry-After header
+const defaultRateLimitDuration = 1 * time.Hour
+
+// maxRateLimitDuration caps how long a Retry-After can pause the client, in case a
+// server sends a nonsensical value
+const maxRateLimitDuration = 7 * 24 * time.Hour
+
+// IsRateLimited returns true if the error is the ACME rateLimited error
+func (e *Error) IsRateLimited() bool {
+	return e != nil && e.Type == errTypeRateLimited
+}
+
+// parseRetryAfter parses the value of a Retry-After header (RFC 9110 10.2.3), which can
+// be either a number of seconds or an HTTP-date. nil is returned if the value is blank or
+// invalid.
+func parseRetryAfter(value string, now time.Time) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	var retryAfter time.Time
+
+	// delay-seconds
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return nil
+		}
+		retryAfter = now.Add(time.Duration(seconds) * time.Second)
+	} else {
+		// HTTP-date
+		retryAfter, err = http.ParseTime(value)
+		if err != nil {
+			return nil
+		}
+	}
+
+	// cap
+	if retryAfter.After(now.Add(maxRateLimitDuration)) {
+		retryAfter = now.Add(maxRateLimitDuration)
+	}
+
+	return &retryAfter
+}
+
+// rateLimits tracks when the ACME server has said the client may retry. Limits are
+// recorded per account (by kid) and for the server as a whole (e.g. for requests
+// that are not tied to an account or when the server is unavailable).
+type rateLimits struct {
+	mu       sync.RWMutex
+	server   time.Time
+	accounts map[string]time.Time // [kid]
+}
+
+// record saves a rate limit that lasts until the specified time. If kid is blank,
+// the limit applies to the whole server.
+func (rl *rateLimits) record(kid string, until time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if kid == "" {
+		if until.After(rl.server) {
+			rl.server = until
+		}
+		return
+	}
+
+	if rl.accounts == nil {
+		rl.accounts = make(map[string]time.Time)
+	}
+	if until.After(rl.accounts[kid]) {
+		rl.accounts[kid] = until
+	}
+}
+
+// until returns the time the specified account (or the server, if kid is blank) can
+// retry. If it is not currently rate limited, nil is returned.
+func (rl *rateLimits) until(kid string, now time.Time) *time.Time {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	limit := rl.server
+	if kid != "" && rl.accounts[kid].After(limit) {
+		limit = rl.accounts[kid]
+	}
+
+	if !limit.After(now) {
+		return nil
+	}
+
+	return &limit
+}
+
+// recordRateLimit records rate limit state from an ACME response. rateLimited errors
+// apply to the account that sent the request (or the server, if the request was not
+// associated with an account). A 503 response with Retry-After applies to the server.
+func (service *Service) recordRateLimit(accountKey AccountKey, statusCode int, acmeErr *Error, retryAfter *time.Time) {
+	now := time.Now()
+
+	switch {
+	case acmeErr.IsRateLimited():
+		until := now.Add(defaultRateLimitDuration)
+		if retryAfter != nil {
+			until = *retryAfter
+		}
+
+		service.rateLimits.record(accountKey.Kid, until)
+		service.logger.Warnf("acme: rate limited by %s until %s (%s)", service.dirUri, until.Format(time.RFC1123), acmeErr.Detail)
+
+	case statusCode == http.StatusServiceUnavailable && retryAfter != nil:
+		service.rateLimits.record("", *retryAfter)
+		service.logger.Warnf("acme: %s unavailable, paused until %s", service.dirUri, retryAfter.Format(time.RFC1123))
+	}
+}
+
+// RateLimitedUntil returns the time the account with kid can retry requests to the
+// ACME server. The server wide limit is also considered. If the account is not
+// currently rate limited, nil is returned.
+func (service *Service) RateLimitedUntil(kid string) *time.Time {
+	return service.rateLimits.until(kid, time.Now())
+}
+
+// ServerRateLimitedUntil returns the time the client can retry requests to the ACME
+// server (that are not tied to an account that is rate limited). If the server is
+// not currently rate limited, nil is returned.
+func (service *Service) ServerRateLimitedUntil() *time.Time {
+	return service.rateLimits.until("", time.Now())
+}
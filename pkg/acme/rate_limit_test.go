@@ -0,0 +1,70 @@
+package acme
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimit_ParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected *time.Time
+	}{
+		{"", nil},
+		{"abc", nil},
+		{"-5", nil},
+		{"0", &now},
+		{"120", ptr(now.Add(2 * time.Minute))},
+		{"Tue, 02 Jan 2024 04:04:05 GMT", ptr(now.Add(time.Hour))},
+		// capped
+		{"99999999", ptr(now.Add(maxRateLimitDuration))},
+	}
+
+	for _, test := range tests {
+		result := parseRetryAfter(test.value, now)
+		if (result == nil) != (test.expected == nil) || (result != nil && !result.Equal(*test.expected)) {
+			t.Errorf("parseRetryAfter(%q): expected %v, got %v", test.value, test.expected, result)
+		}
+	}
+}
+
+func TestRateLimit_Until(t *testing.T) {
+	now := time.Now()
+	rl := rateLimits{}
+
+	if rl.until("acct1", now) != nil {
+		t.Error("expected no rate limit")
+	}
+
+	// account limit
+	rl.record("acct1", now.Add(time.Hour))
+	if u := rl.until("acct1", now); u == nil || !u.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected acct1 limited for 1 hour, got %v", u)
+	}
+	if rl.until("acct2", now) != nil || rl.until("", now) != nil {
+		t.Error("expected acct1 limit to not apply to others")
+	}
+
+	// earlier limit doesn't shorten existing
+	rl.record("acct1", now.Add(time.Minute))
+	if u := rl.until("acct1", now); u == nil || !u.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected acct1 limit to remain 1 hour, got %v", u)
+	}
+
+	// server limit applies to all accounts
+	rl.record("", now.Add(2*time.Hour))
+	if u := rl.until("acct2", now); u == nil || !u.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("expected acct2 limited by server for 2 hours, got %v", u)
+	}
+
+	// expired
+	if rl.until("acct1", now.Add(3*time.Hour)) != nil {
+		t.Error("expected limit to be expired")
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
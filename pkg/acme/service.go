@@ -25,10 +25,12 @@ type Service struct {
 	dirUri       string
 	dir          *directory
 	nonceManager *nonces.Manager
+	rateLimits   rateLimits
 }
 
-// NewService creates a new service
-func NewService(app App, dirUri string) (*Service, error) {
+// NewService creates a new service. If httpClient is nil, the app's http client is
+// used.
+func NewService(app App, dirUri string, httpClient *httpclient.Client) (*Service, error) {
 	service := new(Service)
 
 	// logger
@@ -38,7 +40,10 @@ func NewService(app App, dirUri string) (*Service, error) {
 	}
 
 	// http client
-	service.httpClient = app.GetHttpClient()
+	service.httpClient = httpClient
+	if service.httpClient == nil {
+		service.httpClient = app.GetHttpClient()
+	}
 
 	// acme directory
 	service.dirUri = dirUri
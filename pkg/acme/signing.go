@@ -8,20 +8,26 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/asn1"
 	"encoding/base64"
 	"errors"
+	"math/big"
 )
 
-// signingAlg returns the proper signature algorithm based on the private key
+// signingAlg returns the proper signature algorithm based on the signer's public key
 // within an AccountKey
 func (accountKey *AccountKey) signingAlg() (signatureAlgorithm string, err error) {
-	switch privateKey := accountKey.Key.(type) {
-	case *rsa.PrivateKey:
+	if accountKey.Key == nil {
+		return "", errors.New("acme: signature algorithm: missing key")
+	}
+
+	switch publicKey := accountKey.Key.Public().(type) {
+	case *rsa.PublicKey:
 		// all rsa use RS256
 		return "RS256", nil
 
-	case *ecdsa.PrivateKey:
-		switch privateKey.Curve.Params().Name {
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve.Params().Name {
 		case "P-256":
 			return "ES256", nil
 		case "P-384":
@@ -46,16 +52,19 @@ func (asm *acmeSignedMessage) Sign(accountKey AccountKey) error {
 	// create the data to sign
 	toSign := asm.dataToSign()
 
+	if accountKey.Key == nil {
+		return errors.New("acme: failed to sign (missing key)")
+	}
+
 	// sign appropriately based on key type
-	switch privateKey := accountKey.Key.(type) {
-	case *rsa.PrivateKey:
+	switch publicKey := accountKey.Key.Public().(type) {
+	case *rsa.PublicKey:
 		// all rsa use RS256
-		hash := crypto.SHA256
 		hashed256 := sha256.Sum256(toSign)
 		hashed := hashed256[:]
 
-		// sign using the key
-		signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, hash, hashed)
+		// sign using the key (PKCS #1 v1.5)
+		signature, err := accountKey.Key.Sign(rand.Reader, hashed, crypto.SHA256)
 		if err != nil {
 			return err
 		}
@@ -64,28 +73,42 @@ func (asm *acmeSignedMessage) Sign(accountKey AccountKey) error {
 		// for RSA.
 		encodedSignature = encodeString(signature)
 
-	case *ecdsa.PrivateKey:
+	case *ecdsa.PublicKey:
 		// hash has to be generated based on the header.Algorithm or will error
 		var hashed []byte
-		bitSize := privateKey.PublicKey.Params().BitSize
+		var hash crypto.Hash
+		bitSize := publicKey.Params().BitSize
 		switch bitSize {
 		case 256:
 			hashed256 := sha256.Sum256(toSign)
 			hashed = hashed256[:]
+			hash = crypto.SHA256
 
 		case 384:
 			hashed384 := sha512.Sum384(toSign)
 			hashed = hashed384[:]
+			hash = crypto.SHA384
 
 		default:
 			return errors.New("acme: failed to sign (unsupported ec bit size)")
 		}
 
-		// sign using the key
-		r, s, err := ecdsa.Sign(rand.Reader, privateKey, hashed)
+		// sign using the key (signers return ASN.1 DER, JWS needs the raw r and s)
+		derSignature, err := accountKey.Key.Sign(rand.Reader, hashed, hash)
+		if err != nil {
+			return err
+		}
+
+		var ecSig struct {
+			R, S *big.Int
+		}
+		rest, err := asn1.Unmarshal(derSignature, &ecSig)
 		if err != nil {
 			return err
+		} else if len(rest) != 0 || ecSig.R == nil || ecSig.S == nil {
+			return errors.New("acme: failed to sign (malformed ecdsa signature)")
 		}
+		r, s := ecSig.R, ecSig.S
 
 		// ACME expects these values to be zero padded
 		rPadded := padBytes(r.Bytes(), bitSize)
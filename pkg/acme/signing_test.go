@@ -0,0 +1,88 @@
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"testing"
+)
+
+// opaqueSigner hides the concrete key type, like an external signer does
+type opaqueSigner struct {
+	signer crypto.Signer
+}
+
+func (os opaqueSigner) Public() crypto.PublicKey { return os.signer.Public() }
+func (os opaqueSigner) Sign(r io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return os.signer.Sign(r, digest, opts)
+}
+
+func TestSigning_Sign(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, signer := range []crypto.Signer{rsaKey, p256Key, p384Key, opaqueSigner{rsaKey}, opaqueSigner{p384Key}} {
+		asm := acmeSignedMessage{
+			Payload:         "payload",
+			ProtectedHeader: "header",
+		}
+
+		err = asm.Sign(AccountKey{Key: signer})
+		if err != nil {
+			t.Fatalf("sign with %T: %s", signer, err)
+		}
+
+		signature, err := base64.RawURLEncoding.DecodeString(asm.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		valid := false
+		switch publicKey := signer.Public().(type) {
+		case *rsa.PublicKey:
+			hashed := sha256.Sum256(asm.dataToSign())
+			valid = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signature) == nil
+
+		case *ecdsa.PublicKey:
+			var hashed []byte
+			if publicKey.Params().BitSize == 256 {
+				hashed256 := sha256.Sum256(asm.dataToSign())
+				hashed = hashed256[:]
+			} else {
+				hashed384 := sha512.Sum384(asm.dataToSign())
+				hashed = hashed384[:]
+			}
+
+			// JWS ecdsa signatures are fixed length r || s
+			octetLength := (publicKey.Params().BitSize + 7) >> 3
+			if len(signature) != 2*octetLength {
+				t.Errorf("sign with %T: bad ecdsa signature length %d", signer, len(signature))
+				continue
+			}
+			r := new(big.Int).SetBytes(signature[:octetLength])
+			s := new(big.Int).SetBytes(signature[octetLength:])
+			valid = ecdsa.Verify(publicKey, hashed, r, s)
+		}
+
+		if !valid {
+			t.Errorf("sign with %T: signature did not verify", signer)
+		}
+	}
+}
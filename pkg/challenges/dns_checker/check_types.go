@@ -1,6 +1,7 @@
 package dns_checker
 
 import (
+	"context"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -19,7 +20,8 @@ const (
 
 // CheckTXTWithRetry checks for the specified record. If the check fails, use exponential
 // backoff until that times out and then return false if propagation still hasn't occurred.
-func (service *Service) CheckTXTWithRetry(fqdn string, recordValue string) (propagated bool) {
+// Checking also stops (returning false) if ctx is canceled.
+func (service *Service) CheckTXTWithRetry(ctx context.Context, fqdn string, recordValue string) (propagated bool) {
 	// func to try with exponential backoff
 	checkAllServicesFunc := func() error {
 		// check for propagation
@@ -34,7 +36,7 @@ func (service *Service) CheckTXTWithRetry(fqdn string, recordValue string) (prop
 	bo.MaxInterval = 2 * time.Minute
 	bo.MaxElapsedTime = 30 * time.Minute
 
-	boWithContext := backoff.WithContext(bo, service.shutdownContext)
+	boWithContext := backoff.WithContext(bo, ctx)
 
 	// log failures / delays
 	notifyFunc := func(err error, dur time.Duration) {
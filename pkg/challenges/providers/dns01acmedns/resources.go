@@ -2,6 +2,7 @@ package dns01acmedns
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -81,7 +82,12 @@ func (service *Service) getAcmeDnsResource(domain string) (*acmeDnsResource, err
 
 // Provision updates the acme-dns resource corresponding to domain with
 // the new value calculated from keyAuth
-func (service *Service) Provision(domain string, _ string, keyAuth acme.KeyAuth) error {
+func (service *Service) Provision(ctx context.Context, domain string, _ string, keyAuth acme.KeyAuth) error {
+	// don't start if already canceled
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	// get acme-dns resource
 	adr, err := service.getAcmeDnsResource(domain)
 	if err != nil {
@@ -1,22 +1,29 @@
 package dns01acmesh
 
 import (
+	"context"
 	"os"
 	"os/exec"
+	"time"
 )
 
+// scriptWaitDelay is how long to wait for a killed script's output to close (child
+// processes of the script, e.g. `sleep`, may otherwise hold it open until they exit)
+const scriptWaitDelay = 5 * time.Second
+
 // makeCreateCommand creates the command to make a dns record
-func (service *Service) makeCreateCommand(dnsRecordName, dnsRecordValue string) *exec.Cmd {
-	return service.makeCommand(dnsRecordName, dnsRecordValue, false)
+// (the command is killed if ctx is canceled before it completes)
+func (service *Service) makeCreateCommand(ctx context.Context, dnsRecordName, dnsRecordValue string) *exec.Cmd {
+	return service.makeCommand(ctx, dnsRecordName, dnsRecordValue, false)
 }
 
 // makeDeleteCommand creates the command to delete a dns record
 func (service *Service) makeDeleteCommand(dnsRecordName, dnsRecordValue string) *exec.Cmd {
-	return service.makeCommand(dnsRecordName, dnsRecordValue, true)
+	return service.makeCommand(context.Background(), dnsRecordName, dnsRecordValue, true)
 }
 
 // makeCommand makes a command to create or delete a dns record
-func (service *Service) makeCommand(dnsRecordName, dnsRecordValue string, delete bool) *exec.Cmd {
+func (service *Service) makeCommand(ctx context.Context, dnsRecordName, dnsRecordValue string, delete bool) *exec.Cmd {
 	// func name
 	funcName := service.dnsHook + "_add"
 	if delete {
@@ -31,10 +38,14 @@ func (service *Service) makeCommand(dnsRecordName, dnsRecordValue string, delete
 	args = append(args, "source "+service.shellScriptPath+" ; "+funcName+" "+dnsRecordName+" "+dnsRecordValue)
 
 	// make command
-	cmd := exec.Command(service.shellPath, args...)
+	cmd := exec.CommandContext(ctx, service.shellPath, args...)
 
 	// set command environment
 	cmd.Env = append(os.Environ(), service.environmentParams.StringSlice()...)
 
+	// if the command is killed, don't wait indefinitely for any child processes that
+	// still hold the output open
+	cmd.WaitDelay = scriptWaitDelay
+
 	return cmd
 }
@@ -2,18 +2,19 @@ package dns01acmesh
 
 import (
 	"certwarden-backend/pkg/acme"
+	"context"
 	"errors"
 	"os/exec"
 )
 
 // Provision adds the requested DNS record.
-func (service *Service) Provision(domain string, _ string, keyAuth acme.KeyAuth) error {
+func (service *Service) Provision(ctx context.Context, domain string, _ string, keyAuth acme.KeyAuth) error {
 	// get dns record
 	dnsRecordName, dnsRecordValue := acme.ValidationResourceDns01(domain, keyAuth)
 
 	// run create script
 	// script command
-	cmd := service.makeCreateCommand(dnsRecordName, dnsRecordValue)
+	cmd := service.makeCreateCommand(ctx, dnsRecordName, dnsRecordValue)
 
 	// run script command
 	result, err := cmd.Output()
@@ -9,7 +9,7 @@ import (
 )
 
 // Provision adds the corresponding DNS record on Cloudflare.
-func (service *Service) Provision(domain string, _ string, keyAuth acme.KeyAuth) error {
+func (service *Service) Provision(ctx context.Context, domain string, _ string, keyAuth acme.KeyAuth) error {
 	// get dns record
 	dnsRecordName, dnsRecordValue := acme.ValidationResourceDns01(domain, keyAuth)
 
@@ -20,7 +20,7 @@ func (service *Service) Provision(domain string, _ string, keyAuth acme.KeyAuth)
 	}
 
 	// create DNS record on cloudflare for the ACME resource
-	ctx, cancel := context.WithTimeout(context.Background(), apiCallTimeout)
+	ctx, cancel := context.WithTimeout(ctx, apiCallTimeout)
 	defer cancel()
 
 	_, err = service.cloudflareApi.CreateDNSRecord(ctx, cfResource, cloudflareCreateDNSParams(dnsRecordName, dnsRecordValue))
@@ -1,10 +1,17 @@
 package dns01goacme
 
-import "certwarden-backend/pkg/acme"
+import (
+	"certwarden-backend/pkg/acme"
+	"context"
+)
 
 // Provision adds the corresponding DNS record. It essentially just calls go-acme's
-// provider "Present" function
-func (service *Service) Provision(domain string, token string, keyAuth acme.KeyAuth) error {
+// provider "Present" function (which can't be canceled once started)
+func (service *Service) Provision(ctx context.Context, domain string, token string, keyAuth acme.KeyAuth) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
 	return service.goacmeProvider.Present(domain, token, string(keyAuth))
 }
 
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"fmt"
@@ -84,6 +85,7 @@ func (mgr *Manager) DeleteProvider(w http.ResponseWriter, r *http.Request) *outp
 		mgr.logger.Errorf("failed to save config file after providers update (%s)", err)
 		return output.ErrInternal
 	}
+	audit.SetChanges(r, p, nil)
 
 	// write response
 	response := &output.JsonResponse{
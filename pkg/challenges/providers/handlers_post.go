@@ -7,6 +7,7 @@ import (
 	"certwarden-backend/pkg/challenges/providers/dns01goacme"
 	"certwarden-backend/pkg/challenges/providers/dns01manual"
 	"certwarden-backend/pkg/challenges/providers/http01internal"
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"net/http"
@@ -100,6 +101,7 @@ func (mgr *Manager) CreateProvider(w http.ResponseWriter, r *http.Request) *outp
 		mgr.logger.Errorf("failed to save config file after providers update (%s)", err)
 		return output.ErrInternal
 	}
+	audit.SetChanges(r, nil, p)
 
 	// write response
 	response := &providerResponse{}
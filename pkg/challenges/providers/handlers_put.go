@@ -7,9 +7,11 @@ import (
 	"certwarden-backend/pkg/challenges/providers/dns01goacme"
 	"certwarden-backend/pkg/challenges/providers/dns01manual"
 	"certwarden-backend/pkg/challenges/providers/http01internal"
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/julienschmidt/httprouter"
@@ -77,6 +79,10 @@ func (mgr *Manager) ModifyProvider(w http.ResponseWriter, r *http.Request) *outp
 		return output.ErrValidationFailed
 	}
 
+	// copy of provider before modification (for audit)
+	oldP := *p
+	oldP.Domains = slices.Clone(p.Domains)
+
 	// if domains included, validate domains
 	if payload.Domains != nil {
 		err = mgr.unsafeValidateDomains(payload.Domains, p)
@@ -188,6 +194,7 @@ func (mgr *Manager) ModifyProvider(w http.ResponseWriter, r *http.Request) *outp
 		mgr.logger.Errorf("failed to save config file after providers update (%s)", err)
 		return output.ErrInternal
 	}
+	audit.SetChanges(r, &oldP, p)
 
 	// write response
 	response := &providerResponse{}
@@ -2,11 +2,12 @@ package http01internal
 
 import (
 	"certwarden-backend/pkg/acme"
+	"context"
 	"fmt"
 )
 
 // Provision adds a resource to host
-func (service *Service) Provision(_ string, token string, keyAuth acme.KeyAuth) error {
+func (service *Service) Provision(_ context.Context, _ string, token string, keyAuth acme.KeyAuth) error {
 	// add new entry
 	exists, _ := service.provisionedResources.Add(token, keyAuth)
 
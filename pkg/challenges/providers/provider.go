@@ -2,6 +2,7 @@ package providers
 
 import (
 	"certwarden-backend/pkg/acme"
+	"context"
 )
 
 // providerConfig is the interface provider configs must satisfy
@@ -10,7 +11,7 @@ type providerConfig interface{}
 // service is an interface for a child provider service
 type Service interface {
 	AcmeChallengeType() acme.ChallengeType
-	Provision(domain string, token string, keyAuth acme.KeyAuth) (err error)
+	Provision(ctx context.Context, domain string, token string, keyAuth acme.KeyAuth) (err error)
 	Deprovision(domain string, token string, keyAuth acme.KeyAuth) (err error)
 	Stop() error
 }
@@ -3,6 +3,8 @@ package challenges
 import (
 	"certwarden-backend/pkg/acme"
 	"certwarden-backend/pkg/challenges/providers"
+	"certwarden-backend/pkg/metrics"
+	"context"
 	"errors"
 	"time"
 )
@@ -14,8 +16,10 @@ var (
 
 // Provision adds the specified ACME Challenge resource name to the in use tracker and then calls the provider
 // to provision the actual resource. If the resource name is already in use, it waits until the name is free
-// and then proceeds.
-func (service *Service) provision(domain string, token string, keyAuth acme.KeyAuth, provider providers.Service) (err error) {
+// and then proceeds. providerLabels are the provider's type and tag (for metrics). Waiting and provisioning
+// stop if ctx is canceled. claimed is true once the resource name was added to the tracker, in which case
+// deprovision must be called (even if an error is returned).
+func (service *Service) provision(ctx context.Context, domain string, token string, keyAuth acme.KeyAuth, provider providers.Service, providerLabels []string) (claimed bool, err error) {
 	// loop to add domain to those currently provisioned and wait if not available
 	// if multiple callers are in the waiting state, it is random which will execute next
 	for {
@@ -49,26 +53,38 @@ func (service *Service) provision(domain string, token string, keyAuth acme.KeyA
 				<-timeoutTimer.C
 			}
 
-			return errShutdown
+			return false, errShutdown
+
+		// canceled - return error
+		case <-ctx.Done():
+			// ensure timer releases resources
+			if !timeoutTimer.Stop() {
+				<-timeoutTimer.C
+			}
+
+			return false, ctx.Err()
 
 		// timeout - return error if blocked too long (should never happen, but just in case to prevent hang)
 		case <-timeoutTimer.C:
-			return errNameUnavailable
+			return false, errNameUnavailable
 		}
 	}
 
 	// Provision with the appropriate provider
-	err = provider.Provision(domain, token, keyAuth)
+	startTime := time.Now()
+	err = provider.Provision(ctx, domain, token, keyAuth)
+	metrics.ChallengeProvisionDuration.Observe(time.Since(startTime).Seconds(), append(providerLabels, metrics.Result(err))...)
 	if err != nil {
-		return err
+		return true, err
 	}
 
-	return nil
+	return true, nil
 }
 
 // Deprovision calls the provider to deprovision the actual resource. It then removes the resource name from
-// the in use (work) tracker to indicate the name is once again available for use.
-func (service *Service) deprovision(domain string, token string, keyAuth acme.KeyAuth, provider providers.Service) (err error) {
+// the in use (work) tracker to indicate the name is once again available for use. providerLabels are the
+// provider's type and tag (for metrics).
+func (service *Service) deprovision(domain string, token string, keyAuth acme.KeyAuth, provider providers.Service, providerLabels []string) (err error) {
 	// delete resource name from tracker (after the rest of the deprovisioning steps are done or failed)
 	defer func() {
 		// delete func closes the signal channel before returning true
@@ -89,7 +105,9 @@ func (service *Service) deprovision(domain string, token string, keyAuth acme.Ke
 	}()
 
 	// Deprovision with the appropriate provider
+	startTime := time.Now()
 	err = provider.Deprovision(domain, token, keyAuth)
+	metrics.ChallengeDeprovisionDuration.Observe(time.Since(startTime).Seconds(), append(providerLabels, metrics.Result(err))...)
 	if err != nil {
 		return err
 	}
@@ -2,7 +2,9 @@ package challenges
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/datatypes/order_events"
 	"certwarden-backend/pkg/randomness"
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -17,8 +19,17 @@ var (
 )
 
 // Solve accepts an ACME identifier and a slice of challenges and then solves the challenge using a provider
-// for the specific domain. If no provider exists or solving otherwise fails, an error is returned.
-func (service *Service) Solve(identifier acme.Identifier, challenges []acme.Challenge, key acme.AccountKey, acmeService *acme.Service) (err error) {
+// for the specific domain. If no provider exists or solving otherwise fails, an error is returned. The
+// solving steps are sent to recordEvent (which may be nil). Solving stops if ctx is canceled, but any
+// provisioned resource is always deprovisioned.
+func (service *Service) Solve(ctx context.Context, identifier acme.Identifier, challenges []acme.Challenge, key acme.AccountKey, acmeService *acme.Service, recordEvent order_events.Recorder) (err error) {
+	// record a failed challenge
+	defer func() {
+		if err != nil {
+			recordEvent.Record(order_events.TypeChallengeFailed, identifier.Value, err.Error())
+		}
+	}()
+
 	// get provider for identifier
 	provider, err := service.Providers.ProviderFor(identifier)
 	if err != nil {
@@ -42,6 +53,7 @@ func (service *Service) Solve(identifier acme.Identifier, challenges []acme.Chal
 	}
 
 	// vars for provision/deprovision
+	providerLabels := []string{provider.Type, provider.Tag}
 	domain := identifier.Value
 	token := challenge.Token
 	keyAuth, err := key.KeyAuthorization(token)
@@ -52,15 +64,20 @@ func (service *Service) Solve(identifier acme.Identifier, challenges []acme.Chal
 	// provision the needed resource for validation and defer deprovisioning
 	// add to wg to ensure deprovision completes during shutdown
 	service.shutdownWaitgroup.Add(1)
-	err = service.provision(domain, token, keyAuth, provider)
+	claimed, err := service.provision(ctx, domain, token, keyAuth, provider, providerLabels)
 	// do error check after Deprovision to ensure any records that were created
-	// get cleaned up, even if Provision errored.
+	// get cleaned up, even if Provision errored (or was canceled). If the resource
+	// name was never claimed, there is nothing to deprovision.
 
 	defer func() {
 		// wg done do shutdown can proceed after deprovision
 		defer service.shutdownWaitgroup.Done()
 
-		err := service.deprovision(domain, token, keyAuth, provider)
+		if !claimed {
+			return
+		}
+
+		err := service.deprovision(domain, token, keyAuth, provider, providerLabels)
 		if err != nil {
 			service.logger.Errorf("challenges: deprovision failed (%s)", err)
 		}
@@ -70,6 +87,7 @@ func (service *Service) Solve(identifier acme.Identifier, challenges []acme.Chal
 	if err != nil {
 		return err
 	}
+	recordEvent.Record(order_events.TypeChallengeProvisioned, domain, fmt.Sprintf("%s provisioned by %s provider", challengeType, provider.Type))
 
 	// if using dns-01 provider, utilize dnsChecker
 	if challengeType == acme.ChallengeTypeDns01 {
@@ -78,11 +96,15 @@ func (service *Service) Solve(identifier acme.Identifier, challenges []acme.Chal
 			dnsRecordName, dnsRecordValue := acme.ValidationResourceDns01(domain, keyAuth)
 
 			// check for propagation
-			propagated := service.dnsChecker.CheckTXTWithRetry(dnsRecordName, dnsRecordValue)
+			propagated := service.dnsChecker.CheckTXTWithRetry(ctx, dnsRecordName, dnsRecordValue)
 			// if failed to propagate
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			if !propagated {
 				return errDnsDidntPropagate
 			}
+			recordEvent.Record(order_events.TypeDnsPropagated, domain, dnsRecordName)
 		} else {
 			// dnschecker is needed but not configured, shouldn't happen but deal with it just in case
 			sleepWait := 240
@@ -132,12 +154,23 @@ func (service *Service) Solve(identifier acme.Identifier, challenges []acme.Chal
 		service.logger.Infof("challenges: %s, will check again in %s", funcErr, dur.Round(100*time.Millisecond))
 	}
 
-	bo := randomness.BackoffACME(service.shutdownContext)
+	bo := randomness.BackoffACME(ctx)
 	err = backoff.RetryNotify(challCheckFunc, bo, notifyFunc)
 	// if err returned, retry was exhausted
 	if err != nil {
 		return errors.Join(errChallengeRetriesExhausted, err)
 	}
 
+	// record final status
+	if challenge.Status == "valid" {
+		recordEvent.Record(order_events.TypeChallengeValid, domain, challenge.Url)
+	} else {
+		detail := challenge.Url
+		if challenge.Error != nil {
+			detail = challenge.Error.Error()
+		}
+		recordEvent.Record(order_events.TypeChallengeInvalid, domain, detail)
+	}
+
 	return nil
 }
@@ -2,6 +2,7 @@ package job_manager
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -31,13 +32,82 @@ func (mgr *Manager[V]) AddJob(job V) error {
 	// add to work queue
 	mgr.waitingJobs = append(mgr.waitingJobs, job)
 
-	// send ID to the appropriate channel
-	// async required as this will block until other end of channel reads the job
+	// send to the appropriate channel
+	mgr.sendJob(job)
+
+	return nil
+}
+
+// AddJobAfter adds the specified job to the manager's queue once runAt is reached (e.g.
+// to retry a job after a rate limit expires). Until then, the job is delayed. If an Equal
+// job is already waiting or delayed, the job is not added again. Unlike AddJob, a job that
+// is currently being worked is not considered a duplicate so that a job may reschedule
+// itself.
+func (mgr *Manager[V]) AddJobAfter(job V, runAt time.Time) error {
+	// fail if zeroVal
+	var zeroVal V
+	if job.Equal(zeroVal) {
+		return ErrAddZeroValueJob
+	}
+
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	// check for equivelant job that is waiting or delayed
+	for _, mgrJ := range mgr.waitingJobs {
+		if job.Equal(mgrJ) {
+			return ErrAddDuplicateJob
+		}
+	}
+	for _, mgrDJ := range mgr.delayedJobs {
+		if job.Equal(mgrDJ.Job) {
+			return ErrAddDuplicateJob
+		}
+	}
+
+	// add to delayed
+	mgr.delayedJobs = append(mgr.delayedJobs, DelayedJob[V]{Job: job, RunAt: runAt})
+
+	// wait for runAt, then move to the queue
+	mgr.shutdownWg.Add(1)
 	go func() {
-		if job.IsHighPriority() {
-			mgr.highJobsChan <- job
-		} else {
-			mgr.lowJobsChan <- job
+		defer mgr.shutdownWg.Done()
+
+		delayTimer := time.NewTimer(time.Until(runAt))
+
+		select {
+		case <-mgr.shutdownCtx.Done():
+			// ensure timer releases resources
+			if !delayTimer.Stop() {
+				<-delayTimer.C
+			}
+			return
+
+		case <-delayTimer.C:
+			// continue
+		}
+
+		// remove from delayed
+		found := false
+		mgr.Lock()
+		for i := range mgr.delayedJobs {
+			if any(mgr.delayedJobs[i].Job) == any(job) {
+				mgr.delayedJobs = append(mgr.delayedJobs[:i], mgr.delayedJobs[i+1:]...)
+				found = true
+				break
+			}
+		}
+		mgr.Unlock()
+
+		// job was removed while delayed
+		if !found {
+			return
+		}
+
+		// add to queue
+		err := mgr.AddJob(job)
+		if err != nil {
+			mgr.logger.Errorf("%s: failed to queue delayed job (%s): %s", mgr.workLabel, job.Description(), err)
 		}
 	}()
 
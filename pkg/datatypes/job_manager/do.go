@@ -1,20 +1,50 @@
 package job_manager
 
 // do updates the job to assign it to a worker and then executes the internal 'real'
-// job
+// job. If the job is no longer waiting (i.e. it was removed), it is not executed. If
+// the job's concurrency group is at its limit, the job remains waiting and is sent to
+// a worker again once one of the group's jobs is done.
 func (mgr *Manager[V]) doJob(job V, workerID int) {
+	// get group (before locking, as the job may need to look up its limit)
+	group, limit := "", 0
+	if groupedJob, ok := any(job).(GroupedJob); ok {
+		group, limit = groupedJob.ConcurrencyGroup()
+	}
+
 	// move job from waiting to working
 	mgr.Lock()
+	waitingIndex := -1
 	for i, waitingJ := range mgr.waitingJobs {
-		if waitingJ.Equal(job) {
-			// remove from waiting
-			mgr.waitingJobs[i] = mgr.waitingJobs[len(mgr.waitingJobs)-1]
-			mgr.waitingJobs = mgr.waitingJobs[:len(mgr.waitingJobs)-1]
-
-			// add to worker
-			mgr.workingJobs[workerID] = job
+		// match the exact job, as an Equal job may have been added after this one was removed
+		if any(waitingJ) == any(job) {
+			waitingIndex = i
+			break
 		}
+	}
+
+	// job was removed while waiting
+	if waitingIndex < 0 {
+		mgr.Unlock()
+		mgr.logger.Debugf("%s worker %d: skipping removed job (%s)", mgr.workLabel, workerID, job.Description())
+		return
+	}
 
+	// group is at its limit, hold the job until one of the group's jobs is done
+	if limit > 0 && mgr.groupWorking[group] >= limit {
+		mgr.parkedJobs[group] = append(mgr.parkedJobs[group], job)
+		mgr.Unlock()
+		mgr.logger.Debugf("%s worker %d: holding job (%s), %s is at its limit of %d", mgr.workLabel, workerID, job.Description(), group, limit)
+		return
+	}
+
+	// remove from waiting
+	mgr.waitingJobs[waitingIndex] = mgr.waitingJobs[len(mgr.waitingJobs)-1]
+	mgr.waitingJobs = mgr.waitingJobs[:len(mgr.waitingJobs)-1]
+
+	// add to worker
+	mgr.workingJobs[workerID] = job
+	if group != "" {
+		mgr.groupWorking[group]++
 	}
 	mgr.Unlock()
 
@@ -25,5 +55,41 @@ func (mgr *Manager[V]) doJob(job V, workerID int) {
 	mgr.Lock()
 	var zeroVal V
 	mgr.workingJobs[workerID] = zeroVal
+
+	// release the group and resend any held jobs (they'll be held again if the group
+	// is still at its limit)
+	if group != "" {
+		mgr.groupWorking[group]--
+		if mgr.groupWorking[group] <= 0 {
+			delete(mgr.groupWorking, group)
+		}
+
+		for _, parkedJob := range mgr.parkedJobs[group] {
+			mgr.sendJob(parkedJob)
+		}
+		delete(mgr.parkedJobs, group)
+	}
 	mgr.Unlock()
 }
+
+// RedispatchParkedJobs resends all jobs that are being held because their group was
+// at its limit. This should be called when group limits change so held jobs don't
+// wait for another job of their group to finish. Jobs are held again if their group
+// is still at its limit.
+func (mgr *Manager[V]) RedispatchParkedJobs() {
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	mgr.unsafeRedispatchParkedJobs()
+}
+
+// unsafeRedispatchParkedJobs resends all held jobs.
+// Manager MUST be Locked before calling this func.
+func (mgr *Manager[V]) unsafeRedispatchParkedJobs() {
+	for group, parkedJobs := range mgr.parkedJobs {
+		for _, parkedJob := range parkedJobs {
+			mgr.sendJob(parkedJob)
+		}
+		delete(mgr.parkedJobs, group)
+	}
+}
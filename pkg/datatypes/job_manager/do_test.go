@@ -0,0 +1,145 @@
+package job_manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type testGroupedJob struct {
+	id      int
+	started chan struct{}
+	release chan struct{}
+	limit   *atomic.Int32 // nil is a limit of 1
+}
+
+func (j *testGroupedJob) Description() string  { return "test grouped job" }
+func (j *testGroupedJob) IsHighPriority() bool { return false }
+func (j *testGroupedJob) Equal(j2 *testGroupedJob) bool {
+	return j != nil && j2 != nil && j.id == j2.id
+}
+func (j *testGroupedJob) ConcurrencyGroup() (string, int) {
+	if j.limit == nil {
+		return "group", 1
+	}
+	return "group", int(j.limit.Load())
+}
+func (j *testGroupedJob) Do(workerID int) {
+	close(j.started)
+	<-j.release
+}
+
+func TestGroupLimit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	mgr := NewManager[*testGroupedJob](2, "test", ctx, wg, zap.NewNop().Sugar())
+
+	job1 := &testGroupedJob{id: 1, started: make(chan struct{}), release: make(chan struct{})}
+	job2 := &testGroupedJob{id: 2, started: make(chan struct{}), release: make(chan struct{})}
+	defer close(job2.release)
+
+	if err := mgr.AddJob(job1); err != nil {
+		t.Fatal(err)
+	}
+	<-job1.started
+
+	if err := mgr.AddJob(job2); err != nil {
+		t.Fatal(err)
+	}
+
+	// second job must wait even though a worker is free
+	select {
+	case <-job2.started:
+		t.Fatalf("job started while its group was at its limit")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// finishing the first job lets the second run
+	close(job1.release)
+	select {
+	case <-job2.started:
+	case <-time.After(time.Second):
+		t.Fatalf("held job did not start after its group had room")
+	}
+}
+
+func TestGroupLimitChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	mgr := NewManager[*testGroupedJob](2, "test", ctx, wg, zap.NewNop().Sugar())
+
+	limit := new(atomic.Int32)
+	limit.Store(1)
+	job1 := &testGroupedJob{id: 1, started: make(chan struct{}), release: make(chan struct{}), limit: limit}
+	job2 := &testGroupedJob{id: 2, started: make(chan struct{}), release: make(chan struct{}), limit: limit}
+	defer close(job1.release)
+	defer close(job2.release)
+
+	if err := mgr.AddJob(job1); err != nil {
+		t.Fatal(err)
+	}
+	<-job1.started
+
+	if err := mgr.AddJob(job2); err != nil {
+		t.Fatal(err)
+	}
+
+	// second job is held
+	select {
+	case <-job2.started:
+		t.Fatalf("job started while its group was at its limit")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// raising the limit and redispatching lets the second run without waiting for the first
+	limit.Store(2)
+	mgr.RedispatchParkedJobs()
+	select {
+	case <-job2.started:
+	case <-time.After(time.Second):
+		t.Fatalf("held job did not start after its group limit was raised")
+	}
+}
+
+func TestSetWorkerCount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	mgr := NewManager[*testJob](3, "test", ctx, wg, zap.NewNop().Sugar())
+
+	if err := mgr.SetWorkerCount(0); err == nil {
+		t.Fatalf("expected error for 0 workers")
+	}
+
+	if err := mgr.SetWorkerCount(1); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.WorkerCount() != 1 {
+		t.Fatalf("expected 1 worker, got %d", mgr.WorkerCount())
+	}
+
+	if err := mgr.SetWorkerCount(4); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.WorkerCount() != 4 {
+		t.Fatalf("expected 4 workers, got %d", mgr.WorkerCount())
+	}
+}
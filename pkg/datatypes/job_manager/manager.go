@@ -2,12 +2,17 @@ package job_manager
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
 
-// Job is the interface that the external job struct will need to satisfy
+var ErrWorkerCountBad = errors.New("job manager: worker count must be at least 1")
+
+// Job is the interface that the external job struct will need to satisfy. Jobs must be
+// comparable (e.g. a pointer to a struct) so the manager can track a specific job.
 type Job[V any] interface {
 	// Description should return information to help identify a specific job in the logs
 	// (e.g. a certificate's CN)
@@ -24,16 +29,45 @@ type Job[V any] interface {
 	Do(workerID int)
 }
 
+// GroupedJob may optionally be implemented by a job to limit how many jobs of the same
+// group (e.g. orders for the same ACME server) are worked at the same time
+type GroupedJob interface {
+	// ConcurrencyGroup returns the job's group and the maximum number of the group's jobs
+	// that may be worked at the same time (0 or less is unlimited). It is called each
+	// time the job is about to be worked, so the limit may change at runtime.
+	ConcurrencyGroup() (group string, limit int)
+}
+
+// DelayedJob is a job that will be added to the queue once RunAt is reached
+type DelayedJob[V Job[V]] struct {
+	Job   V
+	RunAt time.Time
+}
+
 // Manager manages jobs and their interaction with the workers
 type Manager[V Job[V]] struct {
 	// readable list of all jobs in the manager
 	workingJobs map[int]V // workerID:job
 	waitingJobs []V
+	delayedJobs []DelayedJob[V]
+
+	// waiting jobs that were held back because their group was at its limit
+	parkedJobs   map[string][]V
+	groupWorking map[string]int
 
 	// channels to send work to workers
 	highJobsChan chan V
 	lowJobsChan  chan V
 
+	// channels to stop individual workers (when the worker count is reduced)
+	workerStops map[int]chan struct{}
+
+	// for delayed jobs and workers
+	workLabel   string
+	shutdownCtx context.Context
+	shutdownWg  *sync.WaitGroup
+	logger      *zap.SugaredLogger
+
 	sync.RWMutex
 }
 
@@ -48,63 +82,155 @@ func NewManager[V Job[V]](workerCount int, workLabel string, shutdownCtx context
 	mgr := &Manager[V]{
 		workingJobs: make(map[int]V),
 
+		parkedJobs:   make(map[string][]V),
+		groupWorking: make(map[string]int),
+
 		highJobsChan: make(chan V),
 		lowJobsChan:  make(chan V),
+
+		workerStops: make(map[int]chan struct{}),
+
+		workLabel:   workLabel,
+		shutdownCtx: shutdownCtx,
+		shutdownWg:  shutdownWg,
+		logger:      logger,
 	}
 
 	// make workers
-	for i := 0; i < workerCount; i++ {
-		// make entry on map for worker tracking
-		var zeroVal V
-		mgr.workingJobs[i] = zeroVal
-
-		// start worker func w/ id
-		shutdownWg.Add(1)
-		go func(workerId int) {
-			// spawn worker
-			defer shutdownWg.Done()
-			logger.Debugf("%s worker %d: started", workLabel, workerId)
-
-		doingWork:
-			for {
-				select {
-				case <-shutdownCtx.Done():
-					// break to shutdown
-					break doingWork
-
-				case highJob := <-mgr.highJobsChan:
-					logger.Debugf("%s worker %d: start high priority job (%s)", workLabel, workerId, highJob.Description())
-					mgr.doJob(highJob, workerId)
-					logger.Debugf("%s worker %d: end high priority job (%s)", workLabel, workerId, highJob.Description())
-
-				case lowJob := <-mgr.lowJobsChan:
-				lower:
-					for {
-						select {
-						case <-shutdownCtx.Done():
-							// break to shutdown
-							break doingWork
-
-						case highJob := <-mgr.highJobsChan:
-							logger.Debugf("%s worker %d: start high priority job (%s)", workLabel, workerId, highJob.Description())
-							mgr.doJob(highJob, workerId)
-							logger.Debugf("%s worker %d: end high priority job (%s)", workLabel, workerId, highJob.Description())
-
-						default:
-							break lower
-						}
-					}
+	err := mgr.SetWorkerCount(workerCount)
+	if err != nil {
+		return nil
+	}
 
-					logger.Debugf("%s worker %d: start low priority job (%s)", workLabel, workerId, lowJob.Description())
-					mgr.doJob(lowJob, workerId)
-					logger.Debugf("%s worker %d: end low priority job (%s)", workLabel, workerId, lowJob.Description())
-				}
+	return mgr
+}
+
+// SetWorkerCount changes the number of workers. If the count is reduced, workers that
+// are working a job stop once their job is done.
+func (mgr *Manager[V]) SetWorkerCount(workerCount int) error {
+	if workerCount <= 0 {
+		return ErrWorkerCountBad
+	}
+
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	// resend held jobs if workers are added
+	if len(mgr.workerStops) < workerCount {
+		defer mgr.unsafeRedispatchParkedJobs()
+	}
+
+	// add workers (using the lowest free worker ids)
+	for len(mgr.workerStops) < workerCount {
+		workerId := 0
+		for {
+			if _, exists := mgr.workingJobs[workerId]; !exists {
+				break
 			}
+			workerId++
+		}
+
+		mgr.unsafeStartWorker(workerId)
+	}
 
-			logger.Debugf("%s worker %d: shutdown complete", workLabel, workerId)
-		}(i)
+	// remove workers (highest worker ids first)
+	for len(mgr.workerStops) > workerCount {
+		highestId := -1
+		for workerId := range mgr.workerStops {
+			if workerId > highestId {
+				highestId = workerId
+			}
+		}
 
+		close(mgr.workerStops[highestId])
+		delete(mgr.workerStops, highestId)
 	}
 
-	return mgr
+	return nil
+}
+
+// WorkerCount returns the number of workers (excluding any that are stopping)
+func (mgr *Manager[V]) WorkerCount() int {
+	mgr.RLock()
+	defer mgr.RUnlock()
+
+	return len(mgr.workerStops)
+}
+
+// unsafeStartWorker starts a worker with the specified id.
+// Manager MUST be Locked before calling this func.
+func (mgr *Manager[V]) unsafeStartWorker(workerId int) {
+	// make entry on map for worker tracking
+	var zeroVal V
+	mgr.workingJobs[workerId] = zeroVal
+
+	stop := make(chan struct{})
+	mgr.workerStops[workerId] = stop
+
+	// start worker func w/ id
+	mgr.shutdownWg.Add(1)
+	go func() {
+		// spawn worker
+		defer mgr.shutdownWg.Done()
+		mgr.logger.Debugf("%s worker %d: started", mgr.workLabel, workerId)
+
+	doingWork:
+		for {
+			select {
+			case <-mgr.shutdownCtx.Done():
+				// break to shutdown
+				break doingWork
+
+			case <-stop:
+				// break to stop (worker count reduced)
+				break doingWork
+
+			case highJob := <-mgr.highJobsChan:
+				mgr.logger.Debugf("%s worker %d: start high priority job (%s)", mgr.workLabel, workerId, highJob.Description())
+				mgr.doJob(highJob, workerId)
+				mgr.logger.Debugf("%s worker %d: end high priority job (%s)", mgr.workLabel, workerId, highJob.Description())
+
+			case lowJob := <-mgr.lowJobsChan:
+			lower:
+				for {
+					select {
+					case <-mgr.shutdownCtx.Done():
+						// break to shutdown
+						break doingWork
+
+					case highJob := <-mgr.highJobsChan:
+						mgr.logger.Debugf("%s worker %d: start high priority job (%s)", mgr.workLabel, workerId, highJob.Description())
+						mgr.doJob(highJob, workerId)
+						mgr.logger.Debugf("%s worker %d: end high priority job (%s)", mgr.workLabel, workerId, highJob.Description())
+
+					default:
+						break lower
+					}
+				}
+
+				mgr.logger.Debugf("%s worker %d: start low priority job (%s)", mgr.workLabel, workerId, lowJob.Description())
+				mgr.doJob(lowJob, workerId)
+				mgr.logger.Debugf("%s worker %d: end low priority job (%s)", mgr.workLabel, workerId, lowJob.Description())
+			}
+		}
+
+		// remove worker tracking
+		mgr.Lock()
+		delete(mgr.workingJobs, workerId)
+		mgr.Unlock()
+
+		mgr.logger.Debugf("%s worker %d: shutdown complete", mgr.workLabel, workerId)
+	}()
+}
+
+// sendJob sends the job to the appropriate channel to be received by a worker.
+// This is async as sending blocks until a worker reads the job.
+func (mgr *Manager[V]) sendJob(job V) {
+	go func() {
+		if job.IsHighPriority() {
+			mgr.highJobsChan <- job
+		} else {
+			mgr.lowJobsChan <- job
+		}
+	}()
 }
@@ -1,5 +1,7 @@
 package job_manager
 
+import "sort"
+
 // unsafeJobExists searches for an Equal job in manager. If one is found,
 // the worker number it is associated with is returned. If the job is in
 // queue without a worker, a negative number is returned. If the job is not
@@ -30,6 +32,14 @@ func (mgr *Manager[V]) unsafeJobExists(job V) *int {
 		}
 	}
 
+	// check delayed (also considered in queue)
+	for i, mgrDJ := range mgr.delayedJobs {
+		if !mgrDJ.Job.Equal(zeroVal) && job.Equal(mgrDJ.Job) {
+			i = -1 * (len(mgr.waitingJobs) + i)
+			return &i
+		}
+	}
+
 	return nil
 }
 
@@ -54,10 +64,11 @@ func (mgr *Manager[V]) JobExists(job V) *int {
 type AllManagerJobs[V Job[V]] struct {
 	WorkingJobs map[int]V // workerID:job
 	WaitingJobs []V
+	DelayedJobs []DelayedJob[V]
 }
 
 // AllCurrentJobs returns all of the jobs in manager. Jobs are separated by those
-// currently being worked on, and those waiting in the queue.
+// currently being worked on, those waiting in the queue, and those delayed.
 func (mgr *Manager[V]) AllCurrentJobs() *AllManagerJobs[V] {
 	mgr.RLock()
 	defer mgr.RUnlock()
@@ -73,9 +84,45 @@ func (mgr *Manager[V]) AllCurrentJobs() *AllManagerJobs[V] {
 	waitingJobs := make([]V, len(mgr.waitingJobs))
 	_ = copy(waitingJobs, mgr.waitingJobs)
 
+	// delayed jobs
+	delayedJobs := make([]DelayedJob[V], len(mgr.delayedJobs))
+	_ = copy(delayedJobs, mgr.delayedJobs)
+
 	// return result
 	return &AllManagerJobs[V]{
 		WorkingJobs: workingJobs,
 		WaitingJobs: waitingJobs,
+		DelayedJobs: delayedJobs,
+	}
+}
+
+// Stats returns the number of jobs waiting in the queue, the number of workers that
+// are currently busy, and the total number of workers.
+func (mgr *Manager[V]) Stats() (waiting int, busy int, workers int) {
+	mgr.RLock()
+	defer mgr.RUnlock()
+
+	var zeroVal V
+	for _, mgrJob := range mgr.workingJobs {
+		if !mgrJob.Equal(zeroVal) {
+			busy++
+		}
 	}
+
+	return len(mgr.waitingJobs), busy, len(mgr.workingJobs)
+}
+
+// WorkerIDs returns the ids of all of the manager's workers (including any that are
+// stopping once their current job is done), in ascending order
+func (mgr *Manager[V]) WorkerIDs() []int {
+	mgr.RLock()
+	defer mgr.RUnlock()
+
+	workerIDs := []int{}
+	for workerID := range mgr.workingJobs {
+		workerIDs = append(workerIDs, workerID)
+	}
+	sort.Ints(workerIDs)
+
+	return workerIDs
 }
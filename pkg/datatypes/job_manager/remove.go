@@ -0,0 +1,64 @@
+package job_manager
+
+// RemoveJob removes an Equal job that is waiting in the queue or delayed so that it
+// will not be worked. The removed job is returned. If no Equal job is waiting or
+// delayed, false is returned (a job currently being worked can't be removed).
+func (mgr *Manager[V]) RemoveJob(job V) (removed V, ok bool) {
+	// zero value job will never be in manager
+	var zeroVal V
+	if job.Equal(zeroVal) {
+		return zeroVal, false
+	}
+
+	mgr.Lock()
+	defer mgr.Unlock()
+
+	// check waiting (the worker that receives the job from the channel will skip it)
+	for i, mgrJ := range mgr.waitingJobs {
+		if job.Equal(mgrJ) {
+			mgr.waitingJobs = append(mgr.waitingJobs[:i], mgr.waitingJobs[i+1:]...)
+
+			// also drop it if it is being held for its group
+			for group, parked := range mgr.parkedJobs {
+				for p := range parked {
+					if any(parked[p]) == any(mgrJ) {
+						mgr.parkedJobs[group] = append(parked[:p], parked[p+1:]...)
+						break
+					}
+				}
+			}
+
+			return mgrJ, true
+		}
+	}
+
+	// check delayed (the delay timer will not queue it)
+	for i, mgrDJ := range mgr.delayedJobs {
+		if job.Equal(mgrDJ.Job) {
+			mgr.delayedJobs = append(mgr.delayedJobs[:i], mgr.delayedJobs[i+1:]...)
+			return mgrDJ.Job, true
+		}
+	}
+
+	return zeroVal, false
+}
+
+// WorkingJob returns the Equal job that is currently being worked, if there is one
+func (mgr *Manager[V]) WorkingJob(job V) (working V, ok bool) {
+	// zero value job will never be in manager
+	var zeroVal V
+	if job.Equal(zeroVal) {
+		return zeroVal, false
+	}
+
+	mgr.RLock()
+	defer mgr.RUnlock()
+
+	for _, mgrJ := range mgr.workingJobs {
+		if !mgrJ.Equal(zeroVal) && job.Equal(mgrJ) {
+			return mgrJ, true
+		}
+	}
+
+	return zeroVal, false
+}
@@ -0,0 +1,60 @@
+package job_manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type testJob struct {
+	id  int
+	ran chan struct{}
+}
+
+func (j *testJob) Description() string    { return "test job" }
+func (j *testJob) IsHighPriority() bool   { return false }
+func (j *testJob) Equal(j2 *testJob) bool { return j != nil && j2 != nil && j.id == j2.id }
+func (j *testJob) Do(workerID int)        { close(j.ran) }
+
+func TestRemoveJob(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	mgr := NewManager[*testJob](1, "test", ctx, wg, zap.NewNop().Sugar())
+
+	// delayed job can be removed and never runs
+	delayed := &testJob{id: 1, ran: make(chan struct{})}
+	err := mgr.AddJobAfter(delayed, time.Now().Add(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, ok := mgr.RemoveJob(&testJob{id: 1})
+	if !ok || removed != delayed {
+		t.Fatalf("expected delayed job to be removed")
+	}
+	if mgr.JobExists(delayed) != nil {
+		t.Fatalf("removed job still exists in manager")
+	}
+
+	select {
+	case <-delayed.ran:
+		t.Fatalf("removed job ran")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	// job not in manager can't be removed or found working
+	if _, ok := mgr.RemoveJob(&testJob{id: 2}); ok {
+		t.Fatalf("removed job that was never added")
+	}
+	if _, ok := mgr.WorkingJob(&testJob{id: 2}); ok {
+		t.Fatalf("found working job that was never added")
+	}
+}
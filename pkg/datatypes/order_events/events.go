@@ -0,0 +1,61 @@
+package order_events
+
+// Type is the type of step in an order's event history
+type Type string
+
+const (
+	TypeCreated              Type = "created"
+	TypeAuthorizationFetched Type = "authorization_fetched"
+	TypeAuthorizationFailed  Type = "authorization_failed"
+	TypeChallengeProvisioned Type = "challenge_provisioned"
+	TypeChallengeFailed      Type = "challenge_failed"
+	TypeDnsPropagated        Type = "dns_propagated"
+	TypeChallengeValid       Type = "challenge_valid"
+	TypeChallengeInvalid     Type = "challenge_invalid"
+	TypeFinalized            Type = "finalized"
+	TypeDownloaded           Type = "downloaded"
+	TypeFulfillFailed        Type = "fulfill_failed"
+	TypePostProcessed        Type = "post_processed"
+	TypePostProcessFailed    Type = "post_process_failed"
+	TypeRevoked              Type = "revoked"
+)
+
+// Event is a single step in the history of an order. Identifier is the ACME identifier
+// (or post processing method) the step applies to, if any, and ExitCode is only set for
+// post processing commands.
+type Event struct {
+	Type       Type
+	Identifier string
+	Detail     string
+	ExitCode   *int
+}
+
+// Recorder records an order's events (e.g. to storage). A nil Recorder discards
+// events, so callers that aren't working on behalf of an order can pass nil.
+type Recorder func(event Event)
+
+// Record sends the event to the Recorder, if there is one
+func (r Recorder) Record(eventType Type, identifier string, detail string) {
+	if r == nil {
+		return
+	}
+	r(Event{
+		Type:       eventType,
+		Identifier: identifier,
+		Detail:     detail,
+	})
+}
+
+// RecordExitCode sends the event, including an exit code, to the Recorder, if there
+// is one
+func (r Recorder) RecordExitCode(eventType Type, identifier string, detail string, exitCode int) {
+	if r == nil {
+		return
+	}
+	r(Event{
+		Type:       eventType,
+		Identifier: identifier,
+		Detail:     detail,
+		ExitCode:   &exitCode,
+	})
+}
@@ -15,23 +15,49 @@ import (
 	"golang.org/x/crypto/ocsp"
 )
 
+// maxOCSPResponseSize is the maximum size of an OCSP response that is read
+const maxOCSPResponseSize = 1024 * 1024
+
 var (
-	errOCSPStaplingNotAvailable = errors.New("safecert: ocsp stapling not available for leaf certificate")
-	errOCSPValidTooLong         = errors.New("safecert: received ocsp response that expires after leaf (which is invalid)")
-	errOCSPStatusNotGood        = errors.New("safecert: ocsp response was not Good (0)")
+	ErrOCSPNotAvailable  = errors.New("safecert: ocsp not available for leaf certificate")
+	errOCSPValidTooLong  = errors.New("safecert: received ocsp response that expires after leaf (which is invalid)")
+	errOCSPStatusNotGood = errors.New("safecert: ocsp response was not Good (0)")
 )
 
 // getOCSPResponse fetches the OCSP response from the OCSP server for the specified
-// leaf certificate
+// leaf certificate. An error is returned if the response isn't suitable for stapling.
 func getOCSPResponse(leafCert, issuerCert *x509.Certificate, httpClient *httpclient.Client) (*ocsp.Response, error) {
+	ocspResp, err := FetchOCSPResponse(context.Background(), leafCert, issuerCert, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// if OCSP Response is valid longer than the leaf cert, it is no bueno
+	// and should be discarded
+	if ocspResp.NextUpdate.After(leafCert.NotAfter.Truncate(time.Second).Add(1 * time.Second)) {
+		return nil, errOCSPValidTooLong
+	}
+
+	// only return the response if it is good
+	if ocspResp.Status != ocsp.Good {
+		return nil, errOCSPStatusNotGood
+	}
+
+	return ocspResp, nil
+}
+
+// FetchOCSPResponse fetches and verifies the OCSP response from the OCSP server(s) of
+// the specified leaf certificate. The response is returned regardless of the status it
+// contains (good, revoked, or unknown).
+func FetchOCSPResponse(ctx context.Context, leafCert, issuerCert *x509.Certificate, httpClient *httpclient.Client) (*ocsp.Response, error) {
 	// if no leaf or issuer, ocsp isn't supported
 	if leafCert == nil || issuerCert == nil {
-		return nil, errOCSPStaplingNotAvailable
+		return nil, ErrOCSPNotAvailable
 	}
 
 	// make sure there is at least one OCSPServer for leaf
 	if len(leafCert.OCSPServer) <= 0 {
-		return nil, errOCSPStaplingNotAvailable
+		return nil, ErrOCSPNotAvailable
 	}
 
 	// make request
@@ -52,45 +78,35 @@ func getOCSPResponse(leafCert, issuerCert *x509.Certificate, httpClient *httpcli
 
 	// fetch response (try each server until valid response, or run out of servers)
 	var ocspResp *ocsp.Response
-	for i := 0; i < len(leafCert.IssuingCertificateURL); i++ {
+	for i := 0; i < len(leafCert.OCSPServer); i++ {
 		reqURL := leafCert.OCSPServer[(serverIndex+i)%len(leafCert.OCSPServer)] + "/" + ocspReqBase64
 
 		var resp *http.Response
-		resp, err = httpClient.GetWithHeader(reqURL, headers)
+		resp, err = httpClient.DoContext(ctx, http.MethodGet, reqURL, nil, headers)
 		if err != nil {
 			// this loop iteration failed
 			continue
 		}
-		defer resp.Body.Close()
 
 		// read and parse
 		var respBody []byte
-		respBody, err = io.ReadAll(resp.Body)
+		respBody, err = io.ReadAll(io.LimitReader(resp.Body, maxOCSPResponseSize))
+		_ = resp.Body.Close()
 		if err != nil {
 			// this loop iteration failed
 			continue
 		}
 
-		ocspResp, err = ocsp.ParseResponse(respBody, issuerCert)
+		// parse for leafCert (verifies the response is for leafCert's serial, not just
+		// signed by the issuer)
+		ocspResp, err = ocsp.ParseResponseForCert(respBody, leafCert, issuerCert)
 		if err != nil {
 			// this loop iteration failed
 			continue
 		}
 
-		// if OCSP Response is valid longer than the leaf cert, it is no bueno
-		// and should be discarded
-		if ocspResp.NextUpdate.After(leafCert.NotAfter.Truncate(time.Second).Add(1 * time.Second)) {
-			// this loop iteration failed
-			err = errOCSPValidTooLong
-			continue
-		}
-
-		// only return the response if it is good
-		if ocspResp.Status != ocsp.Good {
-			// this loop iteration failed
-			err = errOCSPStatusNotGood
-			continue
-		}
+		// got a response
+		break
 	}
 
 	// check last err from loop
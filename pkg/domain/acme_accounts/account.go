@@ -17,6 +17,7 @@ type Account struct {
 	Status      string
 	Email       string
 	AcceptedTos bool
+	KeyRotation KeyRotationPolicy
 	CreatedAt   int
 	UpdatedAt   int
 	Kid         string
@@ -73,11 +74,12 @@ func (acct Account) SummaryResponse() AccountSummaryResponse {
 // fields that can be returned as JSON
 type accountDetailedResponse struct {
 	AccountSummaryResponse
-	AcmeServer accountServerDetailedResponse `json:"acme_server"`
-	AccountKey accountKeyDetailedResponse    `json:"private_key"`
-	CreatedAt  int                           `json:"created_at"`
-	UpdatedAt  int                           `json:"updated_at"`
-	Kid        string                        `json:"kid"`
+	AcmeServer  accountServerDetailedResponse `json:"acme_server"`
+	AccountKey  accountKeyDetailedResponse    `json:"private_key"`
+	KeyRotation keyRotationPolicyResponse     `json:"key_rotation"`
+	CreatedAt   int                           `json:"created_at"`
+	UpdatedAt   int                           `json:"updated_at"`
+	Kid         string                        `json:"kid"`
 }
 
 type accountServerDetailedResponse struct {
@@ -117,17 +119,18 @@ func (acct Account) detailedResponse(service *Service) (accountDetailedResponse,
 			},
 			Algorithm: acct.AccountKey.Algorithm,
 		},
-		CreatedAt: acct.CreatedAt,
-		UpdatedAt: acct.UpdatedAt,
-		Kid:       acct.Kid,
+		KeyRotation: acct.KeyRotation.response(),
+		CreatedAt:   acct.CreatedAt,
+		UpdatedAt:   acct.UpdatedAt,
+		Kid:         acct.Kid,
 	}, nil
 }
 
 // AcmeAccountKey() provides a method to create an ACME AccountKey
 // for the Account
 func (account *Account) AcmeAccountKey() (acmeAcctKey acme.AccountKey, err error) {
-	// get crypto key from the account's key
-	acmeAcctKey.Key, err = account.AccountKey.CryptoPrivateKey()
+	// get signer from the account's key
+	acmeAcctKey.Key, err = account.AccountKey.Signer()
 	if err != nil {
 		return acme.AccountKey{}, err
 	}
@@ -1,6 +1,7 @@
 package acme_accounts
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"fmt"
 	"net/http"
@@ -21,7 +22,7 @@ func (service *Service) DeleteAccount(w http.ResponseWriter, r *http.Request) *o
 
 	// validation
 	// verify account exists
-	_, outErr := service.getAccount(id)
+	account, outErr := service.getAccount(id)
 	if outErr != nil {
 		return outErr
 	}
@@ -39,6 +40,7 @@ func (service *Service) DeleteAccount(w http.ResponseWriter, r *http.Request) *o
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, account.SummaryResponse(), nil)
 
 	// write response
 	response := &output.JsonResponse{
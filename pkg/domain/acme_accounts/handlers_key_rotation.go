@@ -0,0 +1,197 @@
+package acme_accounts
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// KeyRotationPolicyPayload is used to set an account's automatic key rotation policy.
+// Only fields received in the payload (non-nil) are updated.
+type KeyRotationPolicyPayload struct {
+	ID             int     `json:"-"`
+	IntervalDays   *int    `json:"interval_days"`
+	AlgorithmValue *string `json:"algorithm_value"`
+	GraceDays      *int    `json:"grace_days"`
+	UpdatedAt      int     `json:"-"`
+}
+
+// PutKeyRotationPolicy is a handler that sets an account's automatic key rotation policy
+func (service *Service) PutKeyRotationPolicy(w http.ResponseWriter, r *http.Request) *output.Error {
+	// payload decoding
+	var payload KeyRotationPolicyPayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// get id from param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	payload.ID, err = strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	// id
+	oldAcct, outErr := service.getAccount(payload.ID)
+	if outErr != nil {
+		return outErr
+	}
+	// interval (optional)
+	if payload.IntervalDays != nil && *payload.IntervalDays < 0 {
+		service.logger.Debug(ErrKeyRotationPolicyBad)
+		return output.ErrValidationFailed
+	}
+	// algorithm (optional, blank is the current key's algorithm)
+	if payload.AlgorithmValue != nil && *payload.AlgorithmValue != "" &&
+		key_crypto.AlgorithmByStorageValue(*payload.AlgorithmValue) == key_crypto.UnknownAlgorithm {
+		service.logger.Debug(ErrKeyRotationPolicyBad)
+		return output.ErrValidationFailed
+	}
+	// grace (optional)
+	if payload.GraceDays != nil && (*payload.GraceDays < 0 || *payload.GraceDays > maxKeyRotationGraceDays) {
+		service.logger.Debug(ErrKeyRotationPolicyBad)
+		return output.ErrValidationFailed
+	}
+	// end validation
+
+	// add additional details to the payload before saving
+	payload.UpdatedAt = int(time.Now().Unix())
+
+	updatedAcct, err := service.storage.PutKeyRotationPolicy(payload)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, oldAcct.KeyRotation.response(), updatedAcct.KeyRotation.response())
+
+	detailedResp, err := updatedAcct.detailedResponse(service)
+	if err != nil {
+		service.logger.Errorf("failed to generate account summary response (%s)", err)
+		return output.ErrInternal
+	}
+
+	// write response
+	response := &accountResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "updated account key rotation policy"
+	response.Account = detailedResp
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// keyRotationsResponse is the response for an account's key rotation history
+type keyRotationsResponse struct {
+	output.JsonResponse
+	KeyRotations []keyRotationResponse `json:"key_rotations"`
+}
+
+// GetKeyRotations is a handler that returns the account's key rotation history (newest
+// first)
+func (service *Service) GetKeyRotations(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id from param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	_, outErr := service.getAccount(id)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	rotations, err := service.storage.GetKeyRotations(id)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// write response
+	response := &keyRotationsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.KeyRotations = []keyRotationResponse{}
+	for i := range rotations {
+		response.KeyRotations = append(response.KeyRotations, rotations[i].response())
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// RotateKeyNow is a handler that immediately rotates the account's key to a newly
+// generated key (using the account's key rotation policy algorithm). The old key is
+// retained for the policy's grace period.
+func (service *Service) RotateKeyNow(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id from param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	account, outErr := service.getAccount(id)
+	if outErr != nil {
+		return outErr
+	}
+
+	// must be a valid registered account
+	if account.Status != "valid" || account.Kid == "" {
+		service.logger.Debug("account must be registered and valid to rotate its key")
+		return output.ErrValidationFailed
+	}
+	// end validation
+
+	updatedAcct, err := service.rotateAccountKey(account, true)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+	audit.SetChanges(r, account.SummaryResponse(), updatedAcct.SummaryResponse())
+
+	detailedResp, err := updatedAcct.detailedResponse(service)
+	if err != nil {
+		service.logger.Errorf("failed to generate account summary response (%s)", err)
+		return output.ErrInternal
+	}
+
+	// write response
+	response := &accountResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "rotated account key"
+	response.Account = detailedResp
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
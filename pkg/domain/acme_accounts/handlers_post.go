@@ -1,6 +1,7 @@
 package acme_accounts
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/validation"
 	"encoding/json"
@@ -88,6 +89,7 @@ func (service *Service) PostNewAccount(w http.ResponseWriter, r *http.Request) *
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, nil, newAcct.SummaryResponse())
 
 	detailedResp, err := newAcct.detailedResponse(service)
 	if err != nil {
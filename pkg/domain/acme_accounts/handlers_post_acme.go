@@ -1,6 +1,7 @@
 package acme_accounts
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/domain/private_keys/key_crypto"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
@@ -45,8 +46,8 @@ func (service *Service) NewAcmeAccount(w http.ResponseWriter, r *http.Request) *
 		return output.ErrValidationFailed
 	}
 
-	// get crypto key
-	key, err := key_crypto.PemStringToKey(account.AccountKey.Pem, account.AccountKey.Algorithm)
+	// get signer
+	key, err := key_crypto.PemStringToSigner(account.AccountKey.Pem, account.AccountKey.Algorithm)
 	if err != nil {
 		service.logger.Error(err)
 		return output.ErrInternal
@@ -77,6 +78,8 @@ func (service *Service) NewAcmeAccount(w http.ResponseWriter, r *http.Request) *
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, account.SummaryResponse(), updatedAcct.SummaryResponse())
+	service.notifyAccountStatusChange(account, updatedAcct)
 
 	updatedAcctDetailedResp, err := updatedAcct.detailedResponse(service)
 	if err != nil {
@@ -158,6 +161,8 @@ func (service *Service) RefreshAcmeAccount(w http.ResponseWriter, r *http.Reques
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, account.SummaryResponse(), updatedAcct.SummaryResponse())
+	service.notifyAccountStatusChange(account, updatedAcct)
 
 	updatedAcctDetailedResp, err := updatedAcct.detailedResponse(service)
 	if err != nil {
@@ -246,6 +251,8 @@ func (service *Service) Deactivate(w http.ResponseWriter, r *http.Request) *outp
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, account.SummaryResponse(), updatedAcct.SummaryResponse())
+	service.notifyAccountStatusChange(account, updatedAcct)
 
 	updatedAcctDetailedResp, err := updatedAcct.detailedResponse(service)
 	if err != nil {
@@ -1,6 +1,7 @@
 package acme_accounts
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"net/http"
@@ -39,7 +40,7 @@ func (service *Service) PutNameDescAccount(w http.ResponseWriter, r *http.Reques
 
 	// validation
 	// id
-	_, outErr := service.getAccount(payload.ID)
+	oldAcct, outErr := service.getAccount(payload.ID)
 	if outErr != nil {
 		return outErr
 	}
@@ -61,6 +62,7 @@ func (service *Service) PutNameDescAccount(w http.ResponseWriter, r *http.Reques
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, oldAcct.SummaryResponse(), updatedAcct.SummaryResponse())
 
 	detailedResp, err := updatedAcct.detailedResponse(service)
 	if err != nil {
@@ -2,6 +2,8 @@ package acme_accounts
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/domain/private_keys"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/validation"
 	"encoding/json"
@@ -88,6 +90,7 @@ func (service *Service) ChangeEmail(w http.ResponseWriter, r *http.Request) *out
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, account.SummaryResponse(), updatedAcct.SummaryResponse())
 
 	detailedResp, err := updatedAcct.detailedResponse(service)
 	if err != nil {
@@ -110,11 +113,15 @@ func (service *Service) ChangeEmail(w http.ResponseWriter, r *http.Request) *out
 	return nil
 }
 
-// RolloverKeyPayload is used to change an account's private key
+// RolloverKeyPayload is used to change an account's private key. OldKey, NewKey, and
+// Automatic are used to record the rotation history.
 type RolloverKeyPayload struct {
-	ID           int  `json:"-"`
-	PrivateKeyID *int `json:"private_key_id"`
-	UpdatedAt    int  `json:"-"`
+	ID           int              `json:"-"`
+	PrivateKeyID *int             `json:"private_key_id"`
+	OldKey       private_keys.Key `json:"-"`
+	NewKey       private_keys.Key `json:"-"`
+	Automatic    bool             `json:"-"`
+	UpdatedAt    int              `json:"-"`
 }
 
 // RolloverKey changes the private key used for an account
@@ -149,13 +156,6 @@ func (service *Service) RolloverKey(w http.ResponseWriter, r *http.Request) *out
 	}
 	// end validation
 
-	// get AccountKey
-	oldAcmeAccountKey, err := account.AcmeAccountKey()
-	if err != nil {
-		service.logger.Error(err)
-		return output.ErrInternal
-	}
-
 	// fetch new private key
 	newKey, err := service.storage.GetOneKeyById(*payload.PrivateKeyID)
 	if err != nil {
@@ -163,27 +163,17 @@ func (service *Service) RolloverKey(w http.ResponseWriter, r *http.Request) *out
 		return output.ErrStorageGeneric
 	}
 
-	// get crypto key from the new key
-	newCryptoKey, err := newKey.CryptoPrivateKey()
-	if err != nil {
-		service.logger.Error(err)
-		return output.ErrInternal
-	}
-
 	// send the rollover to ACME
-	acmeService, err := service.acmeServerService.AcmeService(account.AcmeServer.ID)
-	if err != nil {
-		service.logger.Error(err)
-		return output.ErrInternal
-	}
-
-	err = acmeService.RolloverAccountKey(newCryptoKey, oldAcmeAccountKey)
+	err = service.rolloverAcmeAccountKey(account, newKey)
 	if err != nil {
 		service.logger.Error(err)
 		return output.ErrInternal
 	}
 
 	// add additional details to the payload before saving
+	payload.OldKey = account.AccountKey
+	payload.NewKey = newKey
+	payload.Automatic = false
 	payload.UpdatedAt = int(time.Now().Unix())
 
 	// update private key id in db
@@ -192,6 +182,7 @@ func (service *Service) RolloverKey(w http.ResponseWriter, r *http.Request) *out
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, account.SummaryResponse(), updatedAcct.SummaryResponse())
 
 	detailedResp, err := updatedAcct.detailedResponse(service)
 	if err != nil {
@@ -0,0 +1,367 @@
+package acme_accounts
+
+import (
+	"certwarden-backend/pkg/domain/app/notifications"
+	"certwarden-backend/pkg/domain/private_keys"
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
+	"certwarden-backend/pkg/pagination_sort"
+	"certwarden-backend/pkg/randomness"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrKeyRotationPolicyBad = errors.New("account key rotation policy is not valid")
+
+// maxKeyRotationGraceDays is the longest a rotated out key can be retained for
+const maxKeyRotationGraceDays = 3650
+
+// KeyRotationPolicy is an account's automatic key rotation policy
+type KeyRotationPolicy struct {
+	// IntervalDays is how often to rotate the key (0 is disabled)
+	IntervalDays int
+	// AlgorithmValue is the algorithm for new keys (blank for the current key's)
+	AlgorithmValue string
+	// GraceDays is how long the old key is retained before it is deleted
+	GraceDays int
+}
+
+// keyRotationPolicyResponse is the JSON output of a KeyRotationPolicy
+type keyRotationPolicyResponse struct {
+	IntervalDays   int    `json:"interval_days"`
+	AlgorithmValue string `json:"algorithm_value"`
+	GraceDays      int    `json:"grace_days"`
+}
+
+func (policy KeyRotationPolicy) response() keyRotationPolicyResponse {
+	return keyRotationPolicyResponse{
+		IntervalDays:   policy.IntervalDays,
+		AlgorithmValue: policy.AlgorithmValue,
+		GraceDays:      policy.GraceDays,
+	}
+}
+
+// enabled returns true if automatic rotation is enabled
+func (policy KeyRotationPolicy) enabled() bool {
+	return policy.IntervalDays > 0
+}
+
+// due returns true if a key last rotated (or created) at lastRotated is due
+// for rotation
+func (policy KeyRotationPolicy) due(lastRotated int, now time.Time) bool {
+	if !policy.enabled() {
+		return false
+	}
+
+	return !time.Unix(int64(lastRotated), 0).AddDate(0, 0, policy.IntervalDays).After(now)
+}
+
+// delay before retrying after a failed automatic rotation; it doubles with each
+// consecutive failure, up to the max
+const (
+	keyRotationRetryBaseDelay = 6 * time.Hour
+	keyRotationRetryMaxDelay  = 7 * 24 * time.Hour
+)
+
+// dueWithBackoff returns true if an automatic rotation should be attempted now for
+// an account created at createdAt with the specified rotation history (newest first).
+// The interval is measured from the last successful rotation (or account creation).
+// If the most recent automatic attempts failed, the next attempt waits for the retry
+// delay so a persistent failure isn't retried every time rotation is checked.
+func (policy KeyRotationPolicy) dueWithBackoff(createdAt int, history []KeyRotation, now time.Time) bool {
+	lastRotated := createdAt
+	failures := 0
+	lastFailure := 0
+	for _, rotation := range history {
+		if rotation.Success {
+			lastRotated = rotation.CreatedAt
+			break
+		}
+
+		// only automatic failures cause backoff
+		if rotation.Automatic {
+			if failures == 0 {
+				lastFailure = rotation.CreatedAt
+			}
+			failures++
+		}
+	}
+
+	if !policy.due(lastRotated, now) {
+		return false
+	}
+	if failures == 0 {
+		return true
+	}
+
+	retryDelay := keyRotationRetryMaxDelay
+	if failures <= 10 {
+		retryDelay = min(keyRotationRetryBaseDelay<<(failures-1), keyRotationRetryMaxDelay)
+	}
+
+	return !time.Unix(int64(lastFailure), 0).Add(retryDelay).After(now)
+}
+
+// KeyRotation is a record of one rollover of an account's key. Automatic is true if
+// the new key was generated by key rotation (instead of being a user specified key);
+// only the old keys of these rotations are deleted after the grace period.
+type KeyRotation struct {
+	ID              int
+	AccountID       int
+	OldKeyID        int
+	OldKeyName      string
+	NewKeyID        *int
+	NewKeyName      string
+	Automatic       bool
+	Success         bool
+	Error           string
+	OldKeyDeletedAt int
+	CreatedAt       int
+}
+
+// keyRotationResponse is the JSON output of a KeyRotation
+type keyRotationResponse struct {
+	ID              int    `json:"id"`
+	AccountID       int    `json:"acme_account_id"`
+	OldKeyID        int    `json:"old_private_key_id"`
+	OldKeyName      string `json:"old_private_key_name"`
+	NewKeyID        *int   `json:"new_private_key_id"`
+	NewKeyName      string `json:"new_private_key_name"`
+	Automatic       bool   `json:"automatic"`
+	Success         bool   `json:"success"`
+	Error           string `json:"error"`
+	OldKeyDeletedAt int    `json:"old_key_deleted_at"`
+	CreatedAt       int    `json:"created_at"`
+}
+
+func (rotation KeyRotation) response() keyRotationResponse {
+	return keyRotationResponse{
+		ID:              rotation.ID,
+		AccountID:       rotation.AccountID,
+		OldKeyID:        rotation.OldKeyID,
+		OldKeyName:      rotation.OldKeyName,
+		NewKeyID:        rotation.NewKeyID,
+		NewKeyName:      rotation.NewKeyName,
+		Automatic:       rotation.Automatic,
+		Success:         rotation.Success,
+		Error:           rotation.Error,
+		OldKeyDeletedAt: rotation.OldKeyDeletedAt,
+		CreatedAt:       rotation.CreatedAt,
+	}
+}
+
+// rolloverAcmeAccountKey rolls the account's key over to newKey with the ACME server
+func (service *Service) rolloverAcmeAccountKey(account Account, newKey private_keys.Key) error {
+	oldAcmeAccountKey, err := account.AcmeAccountKey()
+	if err != nil {
+		return err
+	}
+
+	newSigner, err := newKey.Signer()
+	if err != nil {
+		return err
+	}
+
+	acmeService, err := service.acmeServerService.AcmeService(account.AcmeServer.ID)
+	if err != nil {
+		return err
+	}
+
+	// don't send if rate limited
+	if until := acmeService.RateLimitedUntil(oldAcmeAccountKey.Kid); until != nil {
+		return fmt.Errorf("acme server rate limited until %s", until.Format(time.RFC3339))
+	}
+
+	return acmeService.RolloverAccountKey(newSigner, oldAcmeAccountKey)
+}
+
+// rotateAccountKey generates a new key for the account, rolls the account over to it
+// with the ACME server, and then updates storage (the account key change and the
+// rotation record are saved together). If the new key can't be made or the rollover
+// fails, the new key is removed and the failure is recorded.
+func (service *Service) rotateAccountKey(account Account, automatic bool) (Account, error) {
+	now := time.Now()
+
+	// new key's algorithm
+	alg := account.AccountKey.Algorithm
+	if account.KeyRotation.AlgorithmValue != "" {
+		alg = key_crypto.AlgorithmByStorageValue(account.KeyRotation.AlgorithmValue)
+	}
+
+	newKeyPem, err := alg.GeneratePrivateKeyPem()
+	if err != nil {
+		service.recordFailedKeyRotation(account, "", automatic, err, now)
+		return Account{}, err
+	}
+
+	apiKey, err := randomness.GenerateApiKey()
+	if err != nil {
+		service.recordFailedKeyRotation(account, "", automatic, err, now)
+		return Account{}, err
+	}
+
+	// save new key (disabled for api download, same as a key that isn't meant to be
+	// fetched by clients)
+	newKeyName := fmt.Sprintf("%s_rotated_%d", account.Name, now.Unix())
+	newKeyDesc := fmt.Sprintf("automatically generated key for account %s", account.Name)
+	newKeyAlgValue := alg.StorageValue()
+	apiKeyDisabled := true
+	newKey, err := service.storage.PostNewKey(private_keys.NewPayload{
+		Name:           &newKeyName,
+		Description:    &newKeyDesc,
+		AlgorithmValue: &newKeyAlgValue,
+		PemContent:     &newKeyPem,
+		ApiKey:         apiKey,
+		ApiKeyDisabled: &apiKeyDisabled,
+		ApiKeyViaUrl:   false,
+		CreatedAt:      int(now.Unix()),
+		UpdatedAt:      int(now.Unix()),
+	})
+	if err != nil {
+		service.recordFailedKeyRotation(account, newKeyName, automatic, err, now)
+		return Account{}, err
+	}
+
+	// rollover with acme
+	err = service.rolloverAcmeAccountKey(account, newKey)
+	if err != nil {
+		// remove unused new key and record failure
+		delErr := service.storage.DeleteKey(newKey.ID)
+		if delErr != nil {
+			service.logger.Errorf("acme_accounts: failed to delete unused key %d after failed rotation (%s)", newKey.ID, delErr)
+		}
+
+		service.recordFailedKeyRotation(account, newKey.Name, automatic, err, now)
+		return Account{}, err
+	}
+
+	// update storage
+	updatedAcct, err := service.storage.PutNewAccountKey(RolloverKeyPayload{
+		ID:           account.ID,
+		PrivateKeyID: &newKey.ID,
+		OldKey:       account.AccountKey,
+		NewKey:       newKey,
+		Automatic:    automatic,
+		UpdatedAt:    int(now.Unix()),
+	})
+	if err != nil {
+		return Account{}, fmt.Errorf("account %d key rolled over with acme server to key %d but failed to save (%w)", account.ID, newKey.ID, err)
+	}
+
+	return updatedAcct, nil
+}
+
+// recordFailedKeyRotation saves a record of a failed rotation of the account's key
+func (service *Service) recordFailedKeyRotation(account Account, newKeyName string, automatic bool, rotateErr error, now time.Time) {
+	err := service.storage.PostKeyRotation(KeyRotation{
+		AccountID:  account.ID,
+		OldKeyID:   account.AccountKey.ID,
+		OldKeyName: account.AccountKey.Name,
+		NewKeyName: newKeyName,
+		Automatic:  automatic,
+		Success:    false,
+		Error:      rotateErr.Error(),
+		CreatedAt:  int(now.Unix()),
+	})
+	if err != nil {
+		service.logger.Errorf("acme_accounts: failed to record failed key rotation for account %d (%s)", account.ID, err)
+	}
+}
+
+// rotateDueAccountKeys rotates the keys of all accounts whose rotation policy says
+// they are due
+func (service *Service) rotateDueAccountKeys() {
+	accounts, err := service.accountsWithKeyRotation()
+	if err != nil {
+		service.logger.Errorf("acme_accounts: failed to get accounts for key rotation (%s)", err)
+		return
+	}
+
+	now := time.Now()
+	for _, account := range accounts {
+		// only valid registered accounts can rollover
+		if account.Status != "valid" || account.Kid == "" {
+			continue
+		}
+
+		history, err := service.storage.GetKeyRotations(account.ID)
+		if err != nil {
+			service.logger.Errorf("acme_accounts: failed to get key rotation history for account %s (%s)", account.Name, err)
+			continue
+		}
+
+		if !account.KeyRotation.dueWithBackoff(account.CreatedAt, history, now) {
+			continue
+		}
+
+		updatedAcct, err := service.rotateAccountKey(account, true)
+		if err != nil {
+			service.logger.Errorf("acme_accounts: automatic key rotation for account %s failed (%s)", account.Name, err)
+			service.notifications.Notify(notifications.EventTypeAccountKeyRotationFailed,
+				fmt.Sprintf("automatic key rotation for acme account %s failed", account.Name),
+				map[string]any{
+					"account_id":   account.ID,
+					"account_name": account.Name,
+					"error":        err.Error(),
+				})
+			continue
+		}
+
+		service.logger.Infof("acme_accounts: automatically rotated key for account %s (new key: %s)", account.Name, updatedAcct.AccountKey.Name)
+		service.notifications.Notify(notifications.EventTypeAccountKeyRotated,
+			fmt.Sprintf("acme account %s key automatically rotated", account.Name),
+			map[string]any{
+				"account_id":           account.ID,
+				"account_name":         account.Name,
+				"old_private_key_id":   account.AccountKey.ID,
+				"old_private_key_name": account.AccountKey.Name,
+				"new_private_key_id":   updatedAcct.AccountKey.ID,
+				"new_private_key_name": updatedAcct.AccountKey.Name,
+			})
+	}
+}
+
+// accountsWithKeyRotation returns all accounts that have automatic key rotation enabled
+func (service *Service) accountsWithKeyRotation() ([]Account, error) {
+	accounts, _, err := service.storage.GetAllAccounts(pagination_sort.Query{})
+	if err != nil {
+		return nil, err
+	}
+
+	rotating := []Account{}
+	for i := range accounts {
+		if accounts[i].KeyRotation.enabled() {
+			rotating = append(rotating, accounts[i])
+		}
+	}
+
+	return rotating, nil
+}
+
+// deleteRetiredRotationKeys deletes old keys from automatic rotations whose grace
+// period has passed. Keys that are still in use elsewhere are retained.
+func (service *Service) deleteRetiredRotationKeys() {
+	now := int(time.Now().Unix())
+
+	rotations, err := service.storage.GetKeyRotationsPastGrace(now)
+	if err != nil {
+		service.logger.Errorf("acme_accounts: failed to get rotated keys past grace period (%s)", err)
+		return
+	}
+
+	for _, rotation := range rotations {
+		err = service.storage.DeleteKey(rotation.OldKeyID)
+		if err != nil {
+			service.logger.Debugf("acme_accounts: retaining rotated out key %d (%s)", rotation.OldKeyID, err)
+			continue
+		}
+
+		err = service.storage.PutKeyRotationOldKeyDeleted(rotation.ID, now)
+		if err != nil {
+			service.logger.Errorf("acme_accounts: failed to record deletion of rotated out key %d (%s)", rotation.OldKeyID, err)
+			continue
+		}
+
+		service.logger.Infof("acme_accounts: deleted rotated out key %s (grace period ended)", rotation.OldKeyName)
+	}
+}
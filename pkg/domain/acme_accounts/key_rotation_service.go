@@ -0,0 +1,40 @@
+package acme_accounts
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// keyRotationCheckInterval is how often accounts are checked for due key rotations
+// and rotated out keys are checked for the end of their grace period
+const keyRotationCheckInterval = time.Hour
+
+// startKeyRotationService starts a go routine that periodically rotates the keys of
+// accounts with a key rotation policy and deletes rotated out keys once their grace
+// period ends
+func (service *Service) startKeyRotationService(ctx context.Context, wg *sync.WaitGroup) {
+	service.logger.Infof("acme_accounts: starting automatic key rotation service; accounts will be checked every %s", keyRotationCheckInterval)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(keyRotationCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				service.logger.Info("acme_accounts: automatic key rotation service shutdown complete")
+				return
+
+			case <-ticker.C:
+				// proceed
+			}
+
+			service.rotateDueAccountKeys()
+			service.deleteRetiredRotationKeys()
+		}
+	}()
+}
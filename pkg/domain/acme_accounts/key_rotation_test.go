@@ -0,0 +1,71 @@
+package acme_accounts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyRotationPolicy_Due(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		policy      KeyRotationPolicy
+		lastRotated time.Time
+		expected    bool
+	}{
+		{"disabled", KeyRotationPolicy{IntervalDays: 0}, now.AddDate(-5, 0, 0), false},
+		{"not yet due", KeyRotationPolicy{IntervalDays: 90}, now.AddDate(0, 0, -89), false},
+		{"exactly due", KeyRotationPolicy{IntervalDays: 90}, now.AddDate(0, 0, -90), true},
+		{"overdue", KeyRotationPolicy{IntervalDays: 30}, now.AddDate(0, -2, 0), true},
+	}
+
+	for _, test := range tests {
+		result := test.policy.due(int(test.lastRotated.Unix()), now)
+		if result != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestKeyRotationPolicy_DueWithBackoff(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := KeyRotationPolicy{IntervalDays: 30}
+	createdAt := int(now.AddDate(-1, 0, 0).Unix())
+
+	failedAt := func(ago time.Duration) KeyRotation {
+		return KeyRotation{Automatic: true, Success: false, CreatedAt: int(now.Add(-ago).Unix())}
+	}
+
+	tests := []struct {
+		name     string
+		history  []KeyRotation
+		expected bool
+	}{
+		{"no history, due", nil, true},
+		{"recent success", []KeyRotation{{Success: true, CreatedAt: int(now.AddDate(0, 0, -10).Unix())}}, false},
+		{"one failure, within delay", []KeyRotation{failedAt(time.Hour)}, false},
+		{"one failure, after delay", []KeyRotation{failedAt(keyRotationRetryBaseDelay)}, true},
+		{"two failures, within doubled delay", []KeyRotation{failedAt(keyRotationRetryBaseDelay), failedAt(2 * keyRotationRetryBaseDelay)}, false},
+		{"two failures, after doubled delay", []KeyRotation{failedAt(2 * keyRotationRetryBaseDelay), failedAt(3 * keyRotationRetryBaseDelay)}, true},
+		{"manual failure doesn't delay", []KeyRotation{{Automatic: false, Success: false, CreatedAt: int(now.Add(-time.Minute).Unix())}}, true},
+	}
+
+	// many failures are capped at the max delay
+	manyFailures := []KeyRotation{}
+	for i := 0; i < 40; i++ {
+		manyFailures = append(manyFailures, failedAt(keyRotationRetryMaxDelay+time.Duration(i)*time.Hour))
+	}
+	tests = append(tests, struct {
+		name     string
+		history  []KeyRotation
+		expected bool
+	}{"many failures, capped delay", manyFailures, true})
+
+	for _, test := range tests {
+		result := policy.dueWithBackoff(createdAt, test.history, now)
+		if result != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.name, test.expected, result)
+		}
+	}
+}
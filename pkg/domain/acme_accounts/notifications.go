@@ -0,0 +1,24 @@
+package acme_accounts
+
+import (
+	"certwarden-backend/pkg/domain/app/notifications"
+	"fmt"
+)
+
+// notifyAccountStatusChange sends a notification if the account's status changed
+// to deactivated (or to revoked by the ACME server)
+func (service *Service) notifyAccountStatusChange(before, after Account) {
+	if before.Status == after.Status || (after.Status != "deactivated" && after.Status != "revoked") {
+		return
+	}
+
+	service.notifications.Notify(notifications.EventTypeAccountDeactivated,
+		fmt.Sprintf("acme account %s is now %s", after.Name, after.Status),
+		map[string]any{
+			"account_id":      after.ID,
+			"account_name":    after.Name,
+			"acme_server_id":  after.AcmeServer.ID,
+			"previous_status": before.Status,
+			"status":          after.Status,
+		})
+}
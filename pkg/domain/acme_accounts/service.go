@@ -2,10 +2,13 @@ package acme_accounts
 
 import (
 	"certwarden-backend/pkg/domain/acme_servers"
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/domain/private_keys"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/pagination_sort"
+	"context"
 	"errors"
+	"sync"
 
 	"go.uber.org/zap"
 )
@@ -19,6 +22,9 @@ type App interface {
 	GetAccountStorage() Storage
 	GetKeysService() *private_keys.Service
 	GetAcmeServerService() *acme_servers.Service
+	GetNotificationsService() *notifications.Service
+	GetShutdownContext() context.Context
+	GetShutdownWaitGroup() *sync.WaitGroup
 }
 
 // Storage interface for storage functions
@@ -32,12 +38,20 @@ type Storage interface {
 	PutNameDescAccount(NameDescPayload) (updatedAcct Account, err error)
 	PutAcmeAccountResponse(response AcmeAccount) (updatedAcct Account, err error)
 	PutNewAccountKey(payload RolloverKeyPayload) (updatedAcct Account, err error)
+	PutKeyRotationPolicy(payload KeyRotationPolicyPayload) (updatedAcct Account, err error)
 
 	DeleteAccount(int) error
 
 	AccountHasCerts(accountId int) (inUse bool)
 
 	GetOneKeyById(id int) (private_keys.Key, error)
+	PostNewKey(private_keys.NewPayload) (private_keys.Key, error)
+	DeleteKey(id int) error
+
+	GetKeyRotations(accountId int) ([]KeyRotation, error)
+	GetKeyRotationsPastGrace(now int) ([]KeyRotation, error)
+	PostKeyRotation(KeyRotation) error
+	PutKeyRotationOldKeyDeleted(rotationId int, deletedAt int) error
 }
 
 // Accounts service struct
@@ -47,6 +61,7 @@ type Service struct {
 	storage           Storage
 	keys              *private_keys.Service
 	acmeServerService *acme_servers.Service
+	notifications     *notifications.Service
 }
 
 // NewService creates a new acme_accounts service
@@ -83,5 +98,14 @@ func NewService(app App) (*Service, error) {
 		return nil, errServiceComponent
 	}
 
+	// notifications
+	service.notifications = app.GetNotificationsService()
+	if service.notifications == nil {
+		return nil, errServiceComponent
+	}
+
+	// start automatic key rotation
+	service.startKeyRotationService(app.GetShutdownContext(), app.GetShutdownWaitGroup())
+
 	return service, nil
 }
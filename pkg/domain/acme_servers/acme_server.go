@@ -4,6 +4,7 @@ import (
 	"certwarden-backend/pkg/acme"
 	"certwarden-backend/pkg/pagination_sort"
 	"fmt"
+	"sort"
 )
 
 // Server is the struct for an ACME Server
@@ -13,8 +14,29 @@ type Server struct {
 	Description  string
 	DirectoryURL string
 	IsStaging    bool
-	CreatedAt    int
-	UpdatedAt    int
+	ClientSettings
+	MaxConcurrentOrders int // 0 is unlimited
+	CreatedAt           int
+	UpdatedAt           int
+}
+
+// auditSummary returns the static fields of the Server for recording in
+// the audit trail
+func (serv Server) auditSummary() map[string]any {
+	return map[string]any{
+		"id":            serv.ID,
+		"name":          serv.Name,
+		"description":   serv.Description,
+		"directory_url": serv.DirectoryURL,
+		"is_staging":    serv.IsStaging,
+
+		"tls_root_cas_set":  serv.TlsRootCAsPem != "",
+		"tls_client_key_id": serv.TlsClientKeyID,
+		"timeout_seconds":   serv.TimeoutSeconds,
+		"proxy_url":         serv.ProxyURL,
+
+		"max_concurrent_orders": serv.MaxConcurrentOrders,
+	}
 }
 
 // AcmeService returns the service for a specific ACME Server specified
@@ -33,6 +55,43 @@ func (service *Service) AcmeService(id int) (*acme.Service, error) {
 	return acmeService, nil
 }
 
+// MaxConcurrentOrders returns the maximum number of the Server's orders that should be
+// fulfilled at the same time (0 is unlimited). If the Server can't be found, 0 is
+// returned.
+func (service *Service) MaxConcurrentOrders(acmeServerId int) int {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	return service.maxConcurrentOrders[acmeServerId]
+}
+
+// OnMaxConcurrentOrdersChange registers f to be called whenever a Server's max
+// concurrent orders is changed
+func (service *Service) OnMaxConcurrentOrdersChange(f func()) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.maxConcurrentOrdersOnEdit = append(service.maxConcurrentOrdersOnEdit, f)
+}
+
+// setMaxConcurrentOrders updates the cached max concurrent orders for the Server and
+// calls the registered change funcs if the value changed
+func (service *Service) setMaxConcurrentOrders(acmeServerId int, limit int) {
+	service.mu.Lock()
+	oldLimit, exists := service.maxConcurrentOrders[acmeServerId]
+	service.maxConcurrentOrders[acmeServerId] = limit
+	onEdit := service.maxConcurrentOrdersOnEdit
+	service.mu.Unlock()
+
+	if exists && oldLimit == limit {
+		return
+	}
+
+	for _, f := range onEdit {
+		f()
+	}
+}
+
 // serverSummaryResponse contains abbreviated details about an ACME server
 type ServerSummaryResponse struct {
 	// static
@@ -42,8 +101,17 @@ type ServerSummaryResponse struct {
 	DirectoryURL string `json:"directory_url"`
 	IsStaging    bool   `json:"is_staging"`
 	// from remote server
-	ExternalAccountRequired bool   `json:"external_account_required"`
-	TermsOfService          string `json:"terms_of_service"`
+	ExternalAccountRequired bool                    `json:"external_account_required"`
+	TermsOfService          string                  `json:"terms_of_service"`
+	Profiles                []serverProfileResponse `json:"profiles"`
+	// local state
+	RateLimitedUntil *int `json:"rate_limited_until"`
+}
+
+// serverProfileResponse is a certificate profile advertised by an ACME server
+type serverProfileResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 func (serv Server) summaryResponse(service *Service) (ServerSummaryResponse, error) {
@@ -52,6 +120,20 @@ func (serv Server) summaryResponse(service *Service) (ServerSummaryResponse, err
 		return ServerSummaryResponse{}, err
 	}
 
+	var rateLimitedUntil *int
+	until := acmeService.ServerRateLimitedUntil()
+	if until != nil {
+		untilUnix := int(until.Unix())
+		rateLimitedUntil = &untilUnix
+	}
+
+	// profiles (sorted by name for consistent output)
+	profiles := []serverProfileResponse{}
+	for name, desc := range acmeService.Profiles() {
+		profiles = append(profiles, serverProfileResponse{Name: name, Description: desc})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
 	return ServerSummaryResponse{
 		ID:                      serv.ID,
 		Name:                    serv.Name,
@@ -60,14 +142,18 @@ func (serv Server) summaryResponse(service *Service) (ServerSummaryResponse, err
 		IsStaging:               serv.IsStaging,
 		ExternalAccountRequired: acmeService.RequiresEAB(),
 		TermsOfService:          acmeService.TosUrl(),
+		Profiles:                profiles,
+		RateLimitedUntil:        rateLimitedUntil,
 	}, nil
 }
 
 // serverDetailedResponse contains full details about an ACME server
 type serverDetailedResponse struct {
 	ServerSummaryResponse
-	CreatedAt int `json:"created_at"`
-	UpdatedAt int `json:"updated_at"`
+	ClientSettings      clientSettingsResponse `json:"client_settings"`
+	MaxConcurrentOrders int                    `json:"max_concurrent_orders"`
+	CreatedAt           int                    `json:"created_at"`
+	UpdatedAt           int                    `json:"updated_at"`
 }
 
 func (serv Server) detailedResponse(service *Service) (serverDetailedResponse, error) {
@@ -79,6 +165,8 @@ func (serv Server) detailedResponse(service *Service) (serverDetailedResponse, e
 
 	return serverDetailedResponse{
 		ServerSummaryResponse: summaryResp,
+		ClientSettings:        serv.ClientSettings.response(),
+		MaxConcurrentOrders:   serv.MaxConcurrentOrders,
 		CreatedAt:             serv.CreatedAt,
 		UpdatedAt:             serv.UpdatedAt,
 	}, nil
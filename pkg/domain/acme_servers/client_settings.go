@@ -0,0 +1,144 @@
+package acme_servers
+
+import (
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
+	"certwarden-backend/pkg/httpclient"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrClientSettingsBad = errors.New("server http client settings are not valid")
+
+// maxTimeoutSeconds is the maximum http client timeout that can be set for a Server
+const maxTimeoutSeconds = 600
+
+// ClientSettings are a Server's http client settings for CAs that require a private
+// root CA, mutual TLS, a longer timeout, or a specific proxy. Zero values use the
+// app's http client settings.
+type ClientSettings struct {
+	TlsRootCAsPem    string
+	TlsClientCertPem string
+	TlsClientKeyID   *int
+	TimeoutSeconds   int
+	ProxyURL         string
+}
+
+// isDefault returns true if none of the settings are specified
+func (cs ClientSettings) isDefault() bool {
+	return cs.TlsRootCAsPem == "" && cs.TlsClientCertPem == "" && cs.TlsClientKeyID == nil &&
+		cs.TimeoutSeconds == 0 && cs.ProxyURL == ""
+}
+
+// clientSettingsResponse is the output of the ClientSettings
+type clientSettingsResponse struct {
+	TlsRootCAsPem    string `json:"tls_root_cas_pem"`
+	TlsClientCertPem string `json:"tls_client_cert_pem"`
+	TlsClientKeyID   *int   `json:"tls_client_key_id"`
+	TimeoutSeconds   int    `json:"timeout_seconds"`
+	ProxyURL         string `json:"proxy_url"`
+}
+
+func (cs ClientSettings) response() clientSettingsResponse {
+	return clientSettingsResponse{
+		TlsRootCAsPem:    cs.TlsRootCAsPem,
+		TlsClientCertPem: cs.TlsClientCertPem,
+		TlsClientKeyID:   cs.TlsClientKeyID,
+		TimeoutSeconds:   cs.TimeoutSeconds,
+		ProxyURL:         cs.ProxyURL,
+	}
+}
+
+// ClientSettingsPayload contains the optional http client settings in a new or
+// update payload. Only non-nil fields are set. A TlsClientKeyID of 0 removes the key.
+type ClientSettingsPayload struct {
+	TlsRootCAsPem    *string `json:"tls_root_cas_pem"`
+	TlsClientCertPem *string `json:"tls_client_cert_pem"`
+	TlsClientKeyID   *int    `json:"tls_client_key_id"`
+	TimeoutSeconds   *int    `json:"timeout_seconds"`
+	ProxyURL         *string `json:"proxy_url"`
+}
+
+// changed returns true if the payload changes any of the settings
+func (payload ClientSettingsPayload) changed() bool {
+	return payload.TlsRootCAsPem != nil || payload.TlsClientCertPem != nil || payload.TlsClientKeyID != nil ||
+		payload.TimeoutSeconds != nil || payload.ProxyURL != nil
+}
+
+// merged returns the ClientSettings with the payload's changes applied
+func (cs ClientSettings) merged(payload ClientSettingsPayload) ClientSettings {
+	if payload.TlsRootCAsPem != nil {
+		cs.TlsRootCAsPem = *payload.TlsRootCAsPem
+	}
+	if payload.TlsClientCertPem != nil {
+		cs.TlsClientCertPem = *payload.TlsClientCertPem
+	}
+	if payload.TlsClientKeyID != nil {
+		cs.TlsClientKeyID = payload.TlsClientKeyID
+		if *payload.TlsClientKeyID <= 0 {
+			cs.TlsClientKeyID = nil
+		}
+	}
+	if payload.TimeoutSeconds != nil {
+		cs.TimeoutSeconds = *payload.TimeoutSeconds
+	}
+	if payload.ProxyURL != nil {
+		cs.ProxyURL = *payload.ProxyURL
+	}
+
+	return cs
+}
+
+// payload returns a payload that sets all of the ClientSettings
+func (cs ClientSettings) payload() ClientSettingsPayload {
+	return ClientSettingsPayload{
+		TlsRootCAsPem:    &cs.TlsRootCAsPem,
+		TlsClientCertPem: &cs.TlsClientCertPem,
+		TlsClientKeyID:   cs.TlsClientKeyID,
+		TimeoutSeconds:   &cs.TimeoutSeconds,
+		ProxyURL:         &cs.ProxyURL,
+	}
+}
+
+// httpClientFor validates the ClientSettings and returns an http client that uses
+// them. If the settings are all default, the app's http client is returned.
+func (service *Service) httpClientFor(cs ClientSettings) (*httpclient.Client, error) {
+	if cs.isDefault() {
+		return service.httpClient, nil
+	}
+
+	// timeout
+	if cs.TimeoutSeconds < 0 || cs.TimeoutSeconds > maxTimeoutSeconds {
+		return nil, fmt.Errorf("%w (timeout must be 0 to %d seconds)", ErrClientSettingsBad, maxTimeoutSeconds)
+	}
+
+	opts := httpclient.Options{
+		RootCAsPem: cs.TlsRootCAsPem,
+		Timeout:    time.Duration(cs.TimeoutSeconds) * time.Second,
+		ProxyURL:   cs.ProxyURL,
+	}
+
+	// client cert (mutual TLS)
+	if (cs.TlsClientCertPem == "") != (cs.TlsClientKeyID == nil) {
+		return nil, fmt.Errorf("%w (tls client cert and key must be specified together)", ErrClientSettingsBad)
+	}
+	if cs.TlsClientKeyID != nil {
+		key, err := service.storage.GetOneKeyById(*cs.TlsClientKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("%w (failed to get tls client key %d: %s)", ErrClientSettingsBad, *cs.TlsClientKeyID, err)
+		}
+
+		tlsCert, err := key_crypto.X509KeyPair(cs.TlsClientCertPem, key.Pem)
+		if err != nil {
+			return nil, fmt.Errorf("%w (bad tls client cert and key: %s)", ErrClientSettingsBad, err)
+		}
+		opts.ClientCertificate = &tlsCert
+	}
+
+	httpClient, err := service.httpClient.WithOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", ErrClientSettingsBad, err)
+	}
+
+	return httpClient, nil
+}
@@ -1,6 +1,7 @@
 package acme_servers
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"fmt"
 	"net/http"
@@ -22,7 +23,7 @@ func (service *Service) DeleteServer(w http.ResponseWriter, r *http.Request) *ou
 
 	// validation
 	// verify server exists
-	_, outErr := service.getServer(id)
+	server, outErr := service.getServer(id)
 	if outErr != nil {
 		return outErr
 	}
@@ -40,11 +41,13 @@ func (service *Service) DeleteServer(w http.ResponseWriter, r *http.Request) *ou
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, server.auditSummary(), nil)
 
 	// delete acme Service
 	service.mu.Lock()
 	defer service.mu.Unlock()
 	delete(service.acmeServers, id)
+	delete(service.maxConcurrentOrders, id)
 
 	// write response
 	response := &output.JsonResponse{
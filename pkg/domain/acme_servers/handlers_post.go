@@ -2,6 +2,7 @@ package acme_servers
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"net/http"
@@ -14,8 +15,10 @@ type NewPayload struct {
 	Description  *string `json:"description"`
 	DirectoryURL *string `json:"directory_url"`
 	IsStaging    *bool   `json:"is_staging"`
-	CreatedAt    int     `json:"-"`
-	UpdatedAt    int     `json:"-"`
+	ClientSettingsPayload
+	MaxConcurrentOrders *int `json:"max_concurrent_orders"`
+	CreatedAt           int  `json:"-"`
+	UpdatedAt           int  `json:"-"`
 }
 
 // PostNewServer creates a new server, saves it to storage, and starts an *acme.Service
@@ -39,11 +42,19 @@ func (service *Service) PostNewServer(w http.ResponseWriter, r *http.Request) *o
 	if payload.Description == nil {
 		payload.Description = new(string)
 	}
+	// client settings (optional, unspecified use the app's settings)
+	clientSettings := ClientSettings{}.merged(payload.ClientSettingsPayload)
+	httpClient, err := service.httpClientFor(clientSettings)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+	payload.ClientSettingsPayload = clientSettings.payload()
 	// directory url (required - confirm it actually fetches and decodes properly)
 	if payload.DirectoryURL == nil {
 		service.logger.Debug("cant post: directory url is missing")
 		return output.ErrValidationFailed
-	} else if !service.directoryUrlValid(*payload.DirectoryURL) {
+	} else if !service.directoryUrlValid(*payload.DirectoryURL, httpClient) {
 		// if not nil, and validation fails, return error
 		return output.ErrBadDirectoryURL
 	}
@@ -52,6 +63,13 @@ func (service *Service) PostNewServer(w http.ResponseWriter, r *http.Request) *o
 		service.logger.Debug("cant post: is_staging is missing")
 		return output.ErrValidationFailed
 	}
+	// max concurrent orders (optional, default unlimited)
+	if payload.MaxConcurrentOrders == nil {
+		payload.MaxConcurrentOrders = new(int)
+	} else if *payload.MaxConcurrentOrders < 0 {
+		service.logger.Debug(ErrMaxConcurrentOrdersBad)
+		return output.ErrValidationFailed
+	}
 	// end validation
 
 	// add additional details to the payload before saving
@@ -64,12 +82,16 @@ func (service *Service) PostNewServer(w http.ResponseWriter, r *http.Request) *o
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, nil, newServer.auditSummary())
+
+	// cache limit
+	service.setMaxConcurrentOrders(newServer.ID, newServer.MaxConcurrentOrders)
 
 	// spin up new acme.Service
 	service.mu.Lock()
 	defer service.mu.Unlock()
 
-	service.acmeServers[newServer.ID], err = acme.NewService(service, *payload.DirectoryURL)
+	service.acmeServers[newServer.ID], err = acme.NewService(service, *payload.DirectoryURL, httpClient)
 	if err != nil {
 		service.logger.Error(err)
 		return output.ErrInternal
@@ -2,6 +2,8 @@ package acme_servers
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/httpclient"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"net/http"
@@ -19,7 +21,9 @@ type UpdatePayload struct {
 	Description  *string `json:"description"`
 	DirectoryURL *string `json:"directory_url"`
 	IsStaging    *bool   `json:"is_staging"`
-	UpdatedAt    int     `json:"-"`
+	ClientSettingsPayload
+	MaxConcurrentOrders *int `json:"max_concurrent_orders"`
+	UpdatedAt           int  `json:"-"`
 }
 
 // PutServerUpdate updates a Server that already exists in storage.
@@ -43,7 +47,7 @@ func (service *Service) PutServerUpdate(w http.ResponseWriter, r *http.Request)
 
 	// validation
 	// id
-	_, outErr := service.getServer(payload.ID)
+	oldServer, outErr := service.getServer(payload.ID)
 	if outErr != nil {
 		return outErr
 	}
@@ -52,9 +56,28 @@ func (service *Service) PutServerUpdate(w http.ResponseWriter, r *http.Request)
 		service.logger.Debug(ErrNameBad)
 		return output.ErrValidationFailed
 	}
-	// directory_url (optional - check if not nil)
-	if payload.DirectoryURL != nil && !service.directoryUrlValid(*payload.DirectoryURL) {
-		return output.ErrBadDirectoryURL
+	// client settings (optional) and directory_url (optional) - if either changes, the
+	// directory must fetch with the new settings
+	var httpClient *httpclient.Client
+	if payload.DirectoryURL != nil || payload.ClientSettingsPayload.changed() {
+		httpClient, err = service.httpClientFor(oldServer.ClientSettings.merged(payload.ClientSettingsPayload))
+		if err != nil {
+			service.logger.Debug(err)
+			return output.ErrValidationFailed
+		}
+
+		dirUrl := oldServer.DirectoryURL
+		if payload.DirectoryURL != nil {
+			dirUrl = *payload.DirectoryURL
+		}
+		if !service.directoryUrlValid(dirUrl, httpClient) {
+			return output.ErrBadDirectoryURL
+		}
+	}
+	// max concurrent orders (optional)
+	if payload.MaxConcurrentOrders != nil && *payload.MaxConcurrentOrders < 0 {
+		service.logger.Debug(ErrMaxConcurrentOrdersBad)
+		return output.ErrValidationFailed
 	}
 	// Description, and IsStaging do not need validation
 	// end validation
@@ -68,13 +91,17 @@ func (service *Service) PutServerUpdate(w http.ResponseWriter, r *http.Request)
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, oldServer.auditSummary(), updatedServer.auditSummary())
+
+	// update cached limit (re-dispatches held orders if changed)
+	service.setMaxConcurrentOrders(updatedServer.ID, updatedServer.MaxConcurrentOrders)
 
-	// if directory url changed, create new acme.Service
-	if payload.DirectoryURL != nil {
+	// if directory url or client settings changed, create new acme.Service
+	if httpClient != nil {
 		service.mu.Lock()
 		defer service.mu.Unlock()
 
-		service.acmeServers[payload.ID], err = acme.NewService(service, *payload.DirectoryURL)
+		service.acmeServers[payload.ID], err = acme.NewService(service, updatedServer.DirectoryURL, httpClient)
 		if err != nil {
 			service.logger.Error(err)
 			return output.ErrInternal
@@ -2,6 +2,7 @@ package acme_servers
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/private_keys"
 	"certwarden-backend/pkg/httpclient"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/pagination_sort"
@@ -35,6 +36,8 @@ type Storage interface {
 	DeleteServer(acmeServerId int) error
 
 	ServerHasAccounts(accountId int) (inUse bool)
+
+	GetOneKeyById(id int) (private_keys.Key, error)
 }
 
 // Acme service struct
@@ -47,6 +50,11 @@ type Service struct {
 	shutdownWaitgroup *sync.WaitGroup
 	acmeServers       map[int]*acme.Service // [id]acmeServer
 	mu                sync.Mutex
+
+	// cached max concurrent orders (so the limit isn't read from storage each time an
+	// order is dispatched) and funcs to call when any limit changes
+	maxConcurrentOrders       map[int]int // [id]limit
+	maxConcurrentOrdersOnEdit []func()
 }
 
 // NewService creates a new service
@@ -98,6 +106,12 @@ func NewService(app App) (*Service, error) {
 		return nil, err
 	}
 
+	// max concurrent orders cache
+	service.maxConcurrentOrders = make(map[int]int)
+	for i := range servers {
+		service.maxConcurrentOrders[servers[i].ID] = servers[i].MaxConcurrentOrders
+	}
+
 	// populate all of the acme servers services
 	// use waitgroup to expedite directory fetching
 	var wg sync.WaitGroup
@@ -112,8 +126,16 @@ func NewService(app App) (*Service, error) {
 			// done after func
 			defer wg.Done()
 
+			// http client for the server (if misconfigured, log and fall back to the
+			// app's client)
+			httpClient, err := service.httpClientFor(serv.ClientSettings)
+			if err != nil {
+				service.logger.Errorf("acme server %s: http client settings invalid, using default client (%s)", serv.Name, err)
+				httpClient = nil
+			}
+
 			// make service
-			acmeService, err := acme.NewService(app, serv.DirectoryURL)
+			acmeService, err := acme.NewService(app, serv.DirectoryURL, httpClient)
 			wgErrors <- err
 
 			// don't directly assign to map so dir fetching can occur simultaneously
@@ -2,6 +2,7 @@ package acme_servers
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/httpclient"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/storage"
 	"certwarden-backend/pkg/validation"
@@ -12,6 +13,8 @@ import (
 var (
 	ErrIdBad   = errors.New("server id is invalid")
 	ErrNameBad = errors.New("server name is not valid")
+
+	ErrMaxConcurrentOrdersBad = errors.New("server max concurrent orders must not be negative")
 )
 
 // getAcmeServer returns the Server for the specified id or an error.
@@ -85,14 +88,15 @@ func (service *Service) nameValid(serverName string, serverId *int) bool {
 
 // directoryUrlValid returns true if the specified acme directory url
 // starts with https and actually returns a valid json ACME directory object
-func (service *Service) directoryUrlValid(dirUrl string) bool {
+// when fetched with the specified http client
+func (service *Service) directoryUrlValid(dirUrl string, httpClient *httpclient.Client) bool {
 	// require directory be specified as https://
 	if !strings.HasPrefix(dirUrl, "https://") {
 		return false
 	}
 
 	// check that dir actually fetches correctly
-	_, err := acme.FetchAcmeDirectory(service.httpClient, dirUrl)
+	_, err := acme.FetchAcmeDirectory(httpClient, dirUrl)
 	if err != nil {
 		service.logger.Debug(err)
 		return false
@@ -5,11 +5,14 @@ import (
 	"certwarden-backend/pkg/datatypes/safecert"
 	"certwarden-backend/pkg/domain/acme_accounts"
 	"certwarden-backend/pkg/domain/acme_servers"
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/domain/app/auth"
 	"certwarden-backend/pkg/domain/app/backup"
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/domain/app/updater"
 	"certwarden-backend/pkg/domain/authorizations"
 	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/domain/deploy_targets"
 	"certwarden-backend/pkg/domain/download"
 	"certwarden-backend/pkg/domain/orders"
 	"certwarden-backend/pkg/domain/private_keys"
@@ -70,11 +73,14 @@ type Application struct {
 	challenges        *challenges.Service
 	updater           *updater.Service
 	auth              *auth.Service
+	audit             *audit.Service
+	notifications     *notifications.Service
 	keys              *private_keys.Service
 	accounts          *acme_accounts.Service
 	authorizations    *authorizations.Service
 	orders            *orders.Service
 	certificates      *certificates.Service
+	deployTargets     *deploy_targets.Service
 	download          *download.Service
 }
 
@@ -143,6 +149,15 @@ func (app *Application) GetChallengesService() *challenges.Service {
 func (app *Application) GetAuthStorage() auth.Storage {
 	return app.storage
 }
+func (app *Application) GetAuditStorage() audit.Storage {
+	return app.storage
+}
+func (app *Application) GetNotificationsStorage() notifications.Storage {
+	return app.storage
+}
+func (app *Application) GetAuthorizationsStorage() authorizations.Storage {
+	return app.storage
+}
 func (app *Application) GetKeyStorage() private_keys.Storage {
 	return app.storage
 }
@@ -155,6 +170,9 @@ func (app *Application) GetAccountStorage() acme_accounts.Storage {
 func (app *Application) GetCertificatesStorage() certificates.Storage {
 	return app.storage
 }
+func (app *Application) GetDeployTargetsStorage() deploy_targets.Storage {
+	return app.storage
+}
 func (app *Application) GetOrderStorage() orders.Storage {
 	return app.storage
 }
@@ -164,6 +182,10 @@ func (app *Application) GetDownloadStorage() download.Storage {
 
 //
 
+func (app *Application) GetNotificationsService() *notifications.Service {
+	return app.notifications
+}
+
 func (app *Application) GetKeysService() *private_keys.Service {
 	return app.keys
 }
@@ -184,6 +206,10 @@ func (app *Application) GetCertificatesService() *certificates.Service {
 	return app.certificates
 }
 
+func (app *Application) GetDeployTargetsService() *deploy_targets.Service {
+	return app.deployTargets
+}
+
 // shutdown related
 func (app *Application) GetShutdownContext() context.Context {
 	return app.shutdownContext
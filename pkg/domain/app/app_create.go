@@ -5,14 +5,18 @@ import (
 	"certwarden-backend/pkg/datatypes/safecert"
 	"certwarden-backend/pkg/domain/acme_accounts"
 	"certwarden-backend/pkg/domain/acme_servers"
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/domain/app/auth"
 	"certwarden-backend/pkg/domain/app/backup"
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/domain/app/updater"
 	"certwarden-backend/pkg/domain/authorizations"
 	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/domain/deploy_targets"
 	"certwarden-backend/pkg/domain/download"
 	"certwarden-backend/pkg/domain/orders"
 	"certwarden-backend/pkg/domain/private_keys"
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
 	"certwarden-backend/pkg/httpclient"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/storage/sqlite"
@@ -128,7 +132,16 @@ func create() (*Application, error) {
 
 	// create http client
 	userAgent := fmt.Sprintf("CertWarden/%s (%s; %s)", appVersion, runtime.GOOS, runtime.GOARCH)
-	app.httpClient = httpclient.New(userAgent)
+	app.httpClient, err = httpclient.NewWithOptions(userAgent, httpclient.Options{
+		ProxyURL: *app.config.OutboundProxy,
+	})
+	if err != nil {
+		app.logger.Errorf("failed to configure app http client (%s)", err)
+		return app, err
+	}
+
+	// external signer plugins (for keys that are kept in a secure module)
+	key_crypto.SetExternalSignerPlugins(app.config.ExternalSignerPlugins)
 
 	// start automatic backup service
 	app.backup.StartAutoBackupService(app, &app.config.Backup)
@@ -187,12 +200,27 @@ func create() (*Application, error) {
 	}
 
 	// users service
-	app.auth, err = auth.NewService(app)
+	app.auth, err = auth.NewService(app, &app.config.Auth)
 	if err != nil {
 		app.logger.Errorf("failed to configure app authentication (%s)", err)
 		return app, err
 	}
 
+	// audit service
+	app.audit, err = audit.NewService(app)
+	if err != nil {
+		app.logger.Errorf("failed to configure app audit (%s)", err)
+		return app, err
+	}
+
+	// notifications service
+	app.notifications, err = notifications.NewService(app, &app.config.Notifications)
+	if err != nil {
+		app.logger.Errorf("failed to configure app notifications (%s)", err)
+		return app, err
+	}
+	app.backup.SetNotificationsService(app.notifications)
+
 	// keys service
 	app.keys, err = private_keys.NewService(app)
 	if err != nil {
@@ -221,12 +249,20 @@ func create() (*Application, error) {
 		return app, err
 	}
 
+	// deploy targets service
+	app.deployTargets, err = deploy_targets.NewService(app)
+	if err != nil {
+		app.logger.Errorf("failed to configure app deploy targets (%s)", err)
+		return app, err
+	}
+
 	// orders service
 	app.orders, err = orders.NewService(app, &app.config.Orders)
 	if err != nil {
 		app.logger.Errorf("failed to configure app orders (%s)", err)
 		return app, err
 	}
+	app.notifications.StartEmailDigestService(app.orders.ExpiringCerts)
 
 	// download service
 	app.download, err = download.NewService(app)
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Change is the before and after value of a single field
+type Change struct {
+	Before any `json:"before,omitempty"`
+	After  any `json:"after,omitempty"`
+}
+
+// Changes is a map of field names to the Change for that field
+type Changes map[string]Change
+
+// sensitiveFieldSubstrings are substrings that, if contained in a field name, cause
+// the field's value to be redacted
+var sensitiveFieldSubstrings = []string{
+	"api_key",
+	"client_key",
+	"environment",
+	"password",
+	"pem",
+	"secret",
+	"token",
+}
+
+// isSensitiveField returns true if the field name indicates the value should be redacted
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveFieldSubstrings {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactValue redacts all strings contained in v
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return output.RedactString(val)
+	case []any:
+		for i := range val {
+			val[i] = redactValue(val[i])
+		}
+		return val
+	case map[string]any:
+		for k := range val {
+			val[k] = redactValue(val[k])
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// redactFields redacts the values of any sensitive fields (at any depth) in v
+func redactFields(v any) any {
+	switch val := v.(type) {
+	case []any:
+		for i := range val {
+			val[i] = redactFields(val[i])
+		}
+		return val
+	case map[string]any:
+		for k := range val {
+			if isSensitiveField(k) {
+				val[k] = redactValue(val[k])
+			} else {
+				val[k] = redactFields(val[k])
+			}
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// toFieldMap converts obj to a map of its JSON fields. If obj is nil or not a JSON object,
+// an empty map is returned.
+func toFieldMap(obj any) map[string]any {
+	fields := make(map[string]any)
+	if obj == nil {
+		return fields
+	}
+
+	jsonBytes, err := json.Marshal(obj)
+	if err != nil {
+		return fields
+	}
+
+	// if not an object, return empty
+	_ = json.Unmarshal(jsonBytes, &fields)
+
+	return fields
+}
+
+// diff returns the Changes between the JSON representations of before and after, with
+// sensitive values redacted
+func diff(before, after any) Changes {
+	beforeFields := toFieldMap(before)
+	afterFields := toFieldMap(after)
+
+	changes := make(Changes)
+
+	// fields that were removed or changed
+	for k, beforeVal := range beforeFields {
+		afterVal, exists := afterFields[k]
+		if exists && reflect.DeepEqual(beforeVal, afterVal) {
+			continue
+		}
+
+		change := Change{Before: beforeVal}
+		if exists {
+			change.After = afterVal
+		}
+
+		changes[k] = change
+	}
+
+	// fields that were added
+	for k, afterVal := range afterFields {
+		if _, exists := beforeFields[k]; !exists {
+			changes[k] = Change{After: afterVal}
+		}
+	}
+
+	// redact
+	for k, change := range changes {
+		if isSensitiveField(k) {
+			change.Before = redactValue(change.Before)
+			change.After = redactValue(change.After)
+		} else {
+			change.Before = redactFields(change.Before)
+			change.After = redactFields(change.After)
+		}
+		changes[k] = change
+	}
+
+	return changes
+}
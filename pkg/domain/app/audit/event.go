@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Event is a single audit event
+type Event struct {
+	ID         int
+	CreatedAt  int
+	Actor      string
+	SourceIP   string
+	Action     string
+	ObjectType string
+	ObjectID   string
+	Success    bool
+	Message    string
+	Changes    Changes
+}
+
+// eventResponse is the JSON response for an Event
+type eventResponse struct {
+	ID         int     `json:"id"`
+	CreatedAt  int     `json:"created_at"`
+	Actor      string  `json:"actor"`
+	SourceIP   string  `json:"source_ip"`
+	Action     string  `json:"action"`
+	ObjectType string  `json:"object_type"`
+	ObjectID   string  `json:"object_id"`
+	Success    bool    `json:"success"`
+	Message    string  `json:"message"`
+	Changes    Changes `json:"changes"`
+}
+
+func (e Event) response() eventResponse {
+	return eventResponse{
+		ID:         e.ID,
+		CreatedAt:  e.CreatedAt,
+		Actor:      e.Actor,
+		SourceIP:   e.SourceIP,
+		Action:     e.Action,
+		ObjectType: e.ObjectType,
+		ObjectID:   e.ObjectID,
+		Success:    e.Success,
+		Message:    e.Message,
+		Changes:    e.Changes,
+	}
+}
+
+// eventContextKey is the key used to store the in progress Event in a request's context
+type eventContextKey struct{}
+
+// WithEvent returns a shallow copy of r with event attached to its context. Handlers
+// can then add details to the event using SetChanges.
+func WithEvent(r *http.Request, event *Event) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), eventContextKey{}, event))
+}
+
+// eventFromRequest returns the Event attached to r, or nil if there is none
+func eventFromRequest(r *http.Request) *Event {
+	event, _ := r.Context().Value(eventContextKey{}).(*Event)
+	return event
+}
+
+// SetChanges records the before and after state of the object being modified by the
+// request r. Either before or after may be nil (e.g. for a create or delete). Sensitive
+// fields are redacted. If r is not being audited, this is a no-op.
+func SetChanges(r *http.Request, before, after any) {
+	event := eventFromRequest(r)
+	if event == nil {
+		return
+	}
+
+	event.Changes = diff(before, after)
+
+	// if object id isn't known yet (e.g. a create), use the id of the new object
+	if event.ObjectID == "" {
+		id, exists := toFieldMap(after)["id"]
+		if exists {
+			event.ObjectID = fmt.Sprint(id)
+		}
+	}
+}
+
+// Record saves the event to storage. Errors are logged but not returned as a failure
+// to write an audit event should not fail the action that was audited.
+func (service *Service) Record(event Event) {
+	err := service.storage.PostNewAuditEvent(event)
+	if err != nil {
+		service.logger.Errorf("audit: failed to save event (%s %s by %s) (%s)", event.Action, event.ObjectID, event.Actor, err)
+	}
+}
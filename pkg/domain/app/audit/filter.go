@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Filter contains the optional criteria audit events must match
+type Filter struct {
+	Actor      string
+	Action     string
+	ObjectType string
+	ObjectID   string
+	// unix times (0 = not specified)
+	Since int
+	Until int
+}
+
+// parseRequestToFilter returns the Filter specified by the request's query params. Times
+// may be specified as unix seconds or RFC3339.
+func parseRequestToFilter(r *http.Request) Filter {
+	v := r.URL.Query()
+
+	return Filter{
+		Actor:      v.Get("actor"),
+		Action:     v.Get("action"),
+		ObjectType: v.Get("object_type"),
+		ObjectID:   v.Get("object_id"),
+		Since:      parseFilterTime(v.Get("since")),
+		Until:      parseFilterTime(v.Get("until")),
+	}
+}
+
+// parseFilterTime parses a unix or RFC3339 time string; if the string is not valid 0 is
+// returned
+func parseFilterTime(s string) int {
+	if s == "" {
+		return 0
+	}
+
+	unix, err := strconv.Atoi(s)
+	if err == nil {
+		return unix
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return int(t.Unix())
+	}
+
+	return 0
+}
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"bytes"
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/pagination_sort"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// auditEventsResponse provides the json response struct
+// to answer a query for a portion of the audit events
+type auditEventsResponse struct {
+	output.JsonResponse
+	TotalEvents int             `json:"total_records"`
+	Events      []eventResponse `json:"audit_events"`
+}
+
+// GetAuditEvents returns the audit events matching the request's filter as JSON
+func (service *Service) GetAuditEvents(w http.ResponseWriter, r *http.Request) *output.Error {
+	// parse pagination, sorting, and filter
+	query := pagination_sort.ParseRequestToQuery(r)
+	filter := parseRequestToFilter(r)
+
+	// get events from storage
+	events, totalRows, err := service.storage.GetAuditEvents(query, filter)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// populate events for output
+	outputEvents := []eventResponse{}
+	for i := range events {
+		outputEvents = append(outputEvents, events[i].response())
+	}
+
+	// write response
+	response := &auditEventsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.TotalEvents = totalRows
+	response.Events = outputEvents
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// ExportAuditEvents sends all audit events matching the request's filter (pagination is
+// ignored) to the client as a JSON Lines file
+func (service *Service) ExportAuditEvents(w http.ResponseWriter, r *http.Request) *output.Error {
+	filter := parseRequestToFilter(r)
+
+	// get events from storage (blank query = all)
+	events, _, err := service.storage.GetAuditEvents(pagination_sort.Query{}, filter)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// encode each event as a line
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	for i := range events {
+		err = encoder.Encode(events[i].response())
+		if err != nil {
+			service.logger.Errorf("audit: failed to encode event %d for export (%s)", events[i].ID, err)
+			return output.ErrInternal
+		}
+	}
+
+	// return file to client
+	filename := fmt.Sprintf("certwarden-audit-%s.jsonl", time.Now().Format("20060102-150405"))
+	service.output.WriteJSONLinesNoStoreCache(w, r, filename, buf.Bytes())
+
+	return nil
+}
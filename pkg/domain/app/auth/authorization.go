@@ -13,13 +13,14 @@ type authorization struct {
 	sessionCookie      *sessionCookie `json:"-"`
 }
 
-// createAuth creates all of the necessary pieces of information for an auth response
-func (service *Service) newAuthorization(username string) (auth authorization, err error) {
+// createAuth creates all of the necessary pieces of information for an auth response.
+// totpVerified indicates the user completed the TOTP step for this session.
+func (service *Service) newAuthorization(username string, totpVerified bool) (auth authorization, err error) {
 	// generate UUID
 	uuid := uuid.New()
 
 	// make access token claims
-	auth.AccessTokenClaims = newTokenClaims(username, uuid, accessTokenExpiration)
+	auth.AccessTokenClaims = newTokenClaims(username, uuid, totpVerified, accessTokenExpiration)
 
 	// create token and then signed token string
 	token := jwt.NewWithClaims(tokenSignatureMethod, auth.AccessTokenClaims)
@@ -30,7 +31,7 @@ func (service *Service) newAuthorization(username string) (auth authorization, e
 	auth.AccessToken = accessToken(tokenString)
 
 	// make session token claims
-	auth.SessionTokenClaims = newTokenClaims(username, uuid, sessionTokenExpiration)
+	auth.SessionTokenClaims = newTokenClaims(username, uuid, totpVerified, sessionTokenExpiration)
 
 	// create token and then signed token string
 	token = jwt.NewWithClaims(tokenSignatureMethod, auth.SessionTokenClaims)
@@ -55,38 +55,14 @@ func (service *Service) LoginUsingUserPwPayload(w http.ResponseWriter, r *http.R
 			return output.ErrUnauthorized
 		}
 
-		// user and password now verified, make auth
-		auth, err := service.newAuthorization(user.Username)
-		if err != nil {
-			service.logger.Errorf("client %s: login failed (internal error: %s)", r.RemoteAddr, err)
-			return output.ErrInternal
-		}
-
-		// save auth's session in manager
-		err = service.sessionManager.new(auth.SessionTokenClaims)
-		if err != nil {
-			service.logger.Errorf("client %s: login failed (internal error: %s)", r.RemoteAddr, err)
-			return output.ErrUnauthorized
-		}
-
-		// return response to client
-		response := &authResponse{}
-		response.StatusCode = http.StatusOK
-		response.Message = fmt.Sprintf("user '%s' logged in", auth.SessionTokenClaims.Subject)
-		response.Authorization = auth
-
-		// write response
-		auth.writeSessionCookie(w)
-		err = service.output.WriteJSON(w, response)
-		if err != nil {
-			service.logger.Errorf("failed to write json (%s)", err)
-			return output.ErrWriteJsonError
+		// user and password now verified; if user has totp enabled, the second step
+		// is required before an authorization is issued
+		if user.TotpEnabled {
+			return service.writeTotpRequired(w, r, user.Username)
 		}
 
-		// log success
-		service.logger.Infof("client %s: user '%s' logged in", r.RemoteAddr, auth.SessionTokenClaims.Subject)
-
-		return nil
+		// no totp, make auth
+		return service.writeNewAuthorization(w, r, user.Username, false)
 	}()
 
 	// if err, delete session cookie and return err
@@ -98,6 +74,43 @@ func (service *Service) LoginUsingUserPwPayload(w http.ResponseWriter, r *http.R
 	return nil
 }
 
+// writeNewAuthorization makes a new authorization for username, saves its session, and
+// then writes the auth response (access token and session cookie) to the client
+func (service *Service) writeNewAuthorization(w http.ResponseWriter, r *http.Request, username string, totpVerified bool) *output.Error {
+	// make auth
+	auth, err := service.newAuthorization(username, totpVerified)
+	if err != nil {
+		service.logger.Errorf("client %s: login failed (internal error: %s)", r.RemoteAddr, err)
+		return output.ErrInternal
+	}
+
+	// save auth's session in manager
+	err = service.sessionManager.new(auth.SessionTokenClaims)
+	if err != nil {
+		service.logger.Errorf("client %s: login failed (internal error: %s)", r.RemoteAddr, err)
+		return output.ErrUnauthorized
+	}
+
+	// return response to client
+	response := &authResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("user '%s' logged in", auth.SessionTokenClaims.Subject)
+	response.Authorization = auth
+
+	// write response
+	auth.writeSessionCookie(w)
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	// log success
+	service.logger.Infof("client %s: user '%s' logged in", r.RemoteAddr, auth.SessionTokenClaims.Subject)
+
+	return nil
+}
+
 // RefreshUsingCookie validates the SessionToken cookie and confirms its UUID is for a valid
 // session. If so, it generates a new AccessToken and new SessionToken cookie and then sends both
 // to the client.
@@ -115,7 +128,7 @@ func (service *Service) RefreshUsingCookie(w http.ResponseWriter, r *http.Reques
 		}
 
 		// cookie & session verified, make new auth
-		auth, err := service.newAuthorization(oldClaims.Subject)
+		auth, err := service.newAuthorization(oldClaims.Subject, oldClaims.TotpVerified)
 		if err != nil {
 			service.logger.Errorf("client %s: access token refresh failed (internal error: %s)", r.RemoteAddr, err)
 			return output.ErrInternal
@@ -0,0 +1,524 @@
+package auth
+
+import (
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/randomness"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// number of recovery codes generated for a user
+const totpRecoveryCodeCount = 10
+
+// totpRequiredResponse is sent to the client after a successful username and password
+// login when the user also has totp enabled
+type totpRequiredResponse struct {
+	output.JsonResponse
+	TotpRequired   bool   `json:"totp_required"`
+	TotpLoginToken string `json:"totp_login_token"`
+}
+
+// writeTotpRequired creates a pending totp login for username and writes the response
+// telling the client to complete the totp login step
+func (service *Service) writeTotpRequired(w http.ResponseWriter, r *http.Request, username string) *output.Error {
+	token, err := service.newPendingTotpLogin(username)
+	if err != nil {
+		service.logger.Errorf("client %s: login failed (internal error: %s)", r.RemoteAddr, err)
+		return output.ErrInternal
+	}
+
+	response := &totpRequiredResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("user '%s' password accepted, totp required", username)
+	response.TotpRequired = true
+	response.TotpLoginToken = token
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	service.logger.Infof("client %s: user '%s' password accepted, awaiting totp", r.RemoteAddr, username)
+
+	return nil
+}
+
+// totpLoginPayload is the payload client's send to complete a login that requires totp.
+// Either Code or RecoveryCode should be specified.
+type totpLoginPayload struct {
+	TotpLoginToken string `json:"totp_login_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// LoginUsingTotpPayload completes the second step of a login for a user with totp enabled.
+// If the totp (or recovery) code is valid, an Access Token is returned in JSON and a refresh
+// token is sent in a cookie.
+func (service *Service) LoginUsingTotpPayload(w http.ResponseWriter, r *http.Request) *output.Error {
+	// wrap handler to easily check err and delete cookies
+	outErr := func() *output.Error {
+		var payload totpLoginPayload
+
+		// log attempt
+		service.logger.Infof("client %s: attempting totp login", r.RemoteAddr)
+
+		// decode body into payload
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			service.logger.Infof("client %s: totp login failed (payload error: %s)", r.RemoteAddr, err)
+			return output.ErrUnauthorized
+		}
+
+		// get pending login
+		pending, err := service.readPendingTotpLogin(payload.TotpLoginToken)
+		if err != nil {
+			service.logger.Infof("client %s: totp login failed (%s)", r.RemoteAddr, err)
+			return output.ErrUnauthorized
+		}
+
+		// fetch user from storage
+		user, err := service.storage.GetOneUserByName(pending.username)
+		if err != nil || !user.TotpEnabled {
+			service.logger.Errorf("client %s: totp login failed (user '%s' no longer valid for totp: %s)", r.RemoteAddr, pending.username, err)
+			service.closePendingTotpLogin(payload.TotpLoginToken)
+			return output.ErrUnauthorized
+		}
+
+		// too many recent failures for this user (regardless of pending login)
+		err = service.checkTotpUserLocked(user.Username)
+		if err != nil {
+			service.logger.Warnf("client %s: totp login for user '%s' failed (%s)", r.RemoteAddr, user.Username, err)
+			service.closePendingTotpLogin(payload.TotpLoginToken)
+			return output.ErrUnauthorized
+		}
+
+		// check recovery code, if specified
+		if payload.RecoveryCode != "" {
+			valid, remainingCodes := useRecoveryCode(payload.RecoveryCode, user.TotpRecoveryCodes)
+			if !valid {
+				service.logger.Infof("client %s: totp login for user '%s' failed (bad recovery code)", r.RemoteAddr, user.Username)
+				service.failPendingTotpLogin(payload.TotpLoginToken, pending)
+				return output.ErrUnauthorized
+			}
+
+			// remove used code from storage (only if codes haven't changed since they were
+			// read, otherwise a concurrent request already used the code)
+			replaced, err := service.storage.ReplaceUserTotpRecoveryCodes(user.Username, user.TotpRecoveryCodes, remainingCodes)
+			if err != nil {
+				service.logger.Errorf("client %s: totp login for user '%s' failed (internal error: %s)", r.RemoteAddr, user.Username, err)
+				return output.ErrStorageGeneric
+			}
+			if !replaced {
+				service.logger.Infof("client %s: totp login for user '%s' failed (recovery codes changed concurrently)", r.RemoteAddr, user.Username)
+				service.failPendingTotpLogin(payload.TotpLoginToken, pending)
+				return output.ErrUnauthorized
+			}
+
+			service.logger.Warnf("client %s: user '%s' used a totp recovery code (%d remaining)", r.RemoteAddr, user.Username, len(remainingCodes))
+		} else {
+			// else check totp code
+			valid, step := validateTotpCode(user.TotpSecret, payload.Code, time.Now())
+			if !valid {
+				service.logger.Infof("client %s: totp login for user '%s' failed (bad code)", r.RemoteAddr, user.Username)
+				service.failPendingTotpLogin(payload.TotpLoginToken, pending)
+				return output.ErrUnauthorized
+			}
+
+			err = service.markTotpStepUsed(user.Username, totpPurposeLogin, step)
+			if err != nil {
+				service.logger.Infof("client %s: totp login for user '%s' failed (%s)", r.RemoteAddr, user.Username, err)
+				service.failPendingTotpLogin(payload.TotpLoginToken, pending)
+				return output.ErrUnauthorized
+			}
+		}
+
+		// second factor verified, pending login is done
+		service.closePendingTotpLogin(payload.TotpLoginToken)
+		service.clearTotpUserFailures(user.Username)
+
+		return service.writeNewAuthorization(w, r, user.Username, true)
+	}()
+
+	// if err, delete session cookie and return err
+	if outErr != nil {
+		service.deleteSessionCookie(w)
+		return outErr
+	}
+
+	return nil
+}
+
+// totpStatusResponse contains the current user's totp status
+type totpStatusResponse struct {
+	output.JsonResponse
+	Totp struct {
+		Enabled                bool `json:"enabled"`
+		RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+		SessionTotpVerified    bool `json:"session_totp_verified"`
+	} `json:"totp"`
+}
+
+// GetTotpStatus returns the totp status of the logged in user
+func (service *Service) GetTotpStatus(w http.ResponseWriter, r *http.Request) *output.Error {
+	claims, err := service.ValidateAuthHeader(r, w, "totp status")
+	if err != nil {
+		return output.ErrUnauthorized
+	}
+
+	user, err := service.storage.GetOneUserByName(claims.Subject)
+	if err != nil {
+		service.logger.Errorf("client %s: totp status for user '%s' failed (%s)", r.RemoteAddr, claims.Subject, err)
+		return output.ErrStorageGeneric
+	}
+
+	response := &totpStatusResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Totp.Enabled = user.TotpEnabled
+	response.Totp.RecoveryCodesRemaining = len(user.TotpRecoveryCodes)
+	response.Totp.SessionTotpVerified = claims.TotpVerified
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// totpEnrollPayload is the payload to start totp enrollment
+type totpEnrollPayload struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+// totpEnrollResponse contains the information needed to add the secret to an
+// authenticator app
+type totpEnrollResponse struct {
+	output.JsonResponse
+	Totp struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	} `json:"totp"`
+}
+
+// BeginTotpEnrollment generates a new totp secret for the logged in user. The secret is not
+// enforced until it is confirmed using ConfirmTotpEnrollment.
+func (service *Service) BeginTotpEnrollment(w http.ResponseWriter, r *http.Request) *output.Error {
+	// log attempt
+	service.logger.Infof("client %s: attempting totp enrollment", r.RemoteAddr)
+
+	claims, err := service.ValidateAuthHeader(r, w, "totp enrollment")
+	if err != nil {
+		return output.ErrUnauthorized
+	}
+	username := claims.Subject
+
+	// decode body into payload
+	var payload totpEnrollPayload
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Infof("client %s: totp enrollment for user '%s' failed (payload error: %s)", r.RemoteAddr, username, err)
+		return output.ErrValidationFailed
+	}
+
+	user, err := service.storage.GetOneUserByName(username)
+	if err != nil {
+		service.logger.Errorf("client %s: totp enrollment for user '%s' failed (bad username: %s)", r.RemoteAddr, username, err)
+		return output.ErrUnauthorized
+	}
+
+	// confirm current password is correct
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(payload.CurrentPassword))
+	if err != nil {
+		service.logger.Infof("client %s: totp enrollment for user '%s' failed (bad password: %s)", r.RemoteAddr, username, err)
+		return output.ErrUnauthorized
+	}
+
+	// can't re-enroll without disabling first
+	if user.TotpEnabled {
+		service.logger.Infof("client %s: totp enrollment for user '%s' failed (totp already enabled)", r.RemoteAddr, username)
+		return output.ErrValidationFailed
+	}
+
+	// make and save new secret (not enabled yet)
+	secret, err := randomness.GenerateTotpSecret()
+	if err != nil {
+		service.logger.Errorf("client %s: totp enrollment for user '%s' failed (internal error: %s)", r.RemoteAddr, username, err)
+		return output.ErrInternal
+	}
+
+	_, err = service.storage.UpdateUserTotp(username, secret, false, []string{})
+	if err != nil {
+		service.logger.Errorf("client %s: totp enrollment for user '%s' failed (internal error: %s)", r.RemoteAddr, username, err)
+		return output.ErrStorageGeneric
+	}
+
+	// log success
+	service.logger.Infof("client %s: totp enrollment for user '%s' started", r.RemoteAddr, username)
+
+	response := &totpEnrollResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("totp secret generated for user '%s', confirm with a code to enable", username)
+	response.Totp.Secret = secret
+	response.Totp.ProvisioningURI = totpProvisioningURI(username, secret)
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// totpCodePayload is a payload containing only a totp code
+type totpCodePayload struct {
+	Code string `json:"code"`
+}
+
+// totpRecoveryCodesResponse contains newly generated recovery codes. These are only
+// ever returned once.
+type totpRecoveryCodesResponse struct {
+	output.JsonResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// newRecoveryCodes generates a new set of recovery codes and their hashes
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < totpRecoveryCodeCount; i++ {
+		code, err := randomness.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// confirmTotpCode decodes a totpCodePayload from r and verifies it against user's secret
+func (service *Service) confirmTotpCode(r *http.Request, user User, purpose totpPurpose, logTaskName string) *output.Error {
+	var payload totpCodePayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Infof("client %s: %s for user '%s' failed (payload error: %s)", r.RemoteAddr, logTaskName, user.Username, err)
+		return output.ErrValidationFailed
+	}
+
+	// too many recent failures for this user
+	err = service.checkTotpUserLocked(user.Username)
+	if err != nil {
+		service.logger.Warnf("client %s: %s for user '%s' failed (%s)", r.RemoteAddr, logTaskName, user.Username, err)
+		return output.ErrTotpRequired
+	}
+
+	valid, step := validateTotpCode(user.TotpSecret, payload.Code, time.Now())
+	if user.TotpSecret == "" || !valid {
+		service.logger.Infof("client %s: %s for user '%s' failed (bad code)", r.RemoteAddr, logTaskName, user.Username)
+		service.failTotpUser(user.Username)
+		return output.ErrTotpRequired
+	}
+
+	err = service.markTotpStepUsed(user.Username, purpose, step)
+	if err != nil {
+		service.logger.Infof("client %s: %s for user '%s' failed (%s)", r.RemoteAddr, logTaskName, user.Username, err)
+		service.failTotpUser(user.Username)
+		return output.ErrTotpRequired
+	}
+	service.clearTotpUserFailures(user.Username)
+
+	return nil
+}
+
+// writeRecoveryCodes generates and saves new recovery codes for user (also setting totp
+// enabled) and writes the codes to the client
+func (service *Service) writeRecoveryCodes(w http.ResponseWriter, r *http.Request, user User, logTaskName string) *output.Error {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		service.logger.Errorf("client %s: %s for user '%s' failed (internal error: %s)", r.RemoteAddr, logTaskName, user.Username, err)
+		return output.ErrInternal
+	}
+
+	_, err = service.storage.UpdateUserTotp(user.Username, user.TotpSecret, true, hashes)
+	if err != nil {
+		service.logger.Errorf("client %s: %s for user '%s' failed (internal error: %s)", r.RemoteAddr, logTaskName, user.Username, err)
+		return output.ErrStorageGeneric
+	}
+
+	// log success (before response since already saved)
+	service.logger.Infof("client %s: %s for user '%s' succeeded", r.RemoteAddr, logTaskName, user.Username)
+
+	response := &totpRecoveryCodesResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("%s for user '%s' succeeded, store the recovery codes safely as they will not be shown again", logTaskName, user.Username)
+	response.RecoveryCodes = codes
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// ConfirmTotpEnrollment verifies a code generated from the secret created by
+// BeginTotpEnrollment. If valid, totp is enabled for the user and recovery codes
+// are returned.
+func (service *Service) ConfirmTotpEnrollment(w http.ResponseWriter, r *http.Request) *output.Error {
+	logTaskName := "totp enrollment confirmation"
+
+	// log attempt
+	service.logger.Infof("client %s: attempting %s", r.RemoteAddr, logTaskName)
+
+	claims, err := service.ValidateAuthHeader(r, w, logTaskName)
+	if err != nil {
+		return output.ErrUnauthorized
+	}
+
+	user, err := service.storage.GetOneUserByName(claims.Subject)
+	if err != nil {
+		service.logger.Errorf("client %s: %s for user '%s' failed (bad username: %s)", r.RemoteAddr, logTaskName, claims.Subject, err)
+		return output.ErrUnauthorized
+	}
+
+	// must be pending (secret created but not enabled)
+	if user.TotpEnabled || user.TotpSecret == "" {
+		service.logger.Infof("client %s: %s for user '%s' failed (no pending enrollment)", r.RemoteAddr, logTaskName, user.Username)
+		return output.ErrValidationFailed
+	}
+
+	outErr := service.confirmTotpCode(r, user, totpPurposeEnroll, logTaskName)
+	if outErr != nil {
+		return outErr
+	}
+
+	return service.writeRecoveryCodes(w, r, user, logTaskName)
+}
+
+// RegenerateTotpRecoveryCodes replaces the logged in user's recovery codes with a new
+// set. A valid totp code is required.
+func (service *Service) RegenerateTotpRecoveryCodes(w http.ResponseWriter, r *http.Request) *output.Error {
+	logTaskName := "totp recovery code regeneration"
+
+	// log attempt
+	service.logger.Infof("client %s: attempting %s", r.RemoteAddr, logTaskName)
+
+	claims, err := service.ValidateAuthHeader(r, w, logTaskName)
+	if err != nil {
+		return output.ErrUnauthorized
+	}
+
+	user, err := service.storage.GetOneUserByName(claims.Subject)
+	if err != nil {
+		service.logger.Errorf("client %s: %s for user '%s' failed (bad username: %s)", r.RemoteAddr, logTaskName, claims.Subject, err)
+		return output.ErrUnauthorized
+	}
+
+	if !user.TotpEnabled {
+		service.logger.Infof("client %s: %s for user '%s' failed (totp not enabled)", r.RemoteAddr, logTaskName, user.Username)
+		return output.ErrValidationFailed
+	}
+
+	outErr := service.confirmTotpCode(r, user, totpPurposeRecovery, logTaskName)
+	if outErr != nil {
+		return outErr
+	}
+
+	return service.writeRecoveryCodes(w, r, user, logTaskName)
+}
+
+// totpDisablePayload is the payload to disable totp
+type totpDisablePayload struct {
+	CurrentPassword string `json:"current_password"`
+	Code            string `json:"code"`
+}
+
+// DisableTotp removes totp from the logged in user. The current password and a valid
+// totp code are required.
+func (service *Service) DisableTotp(w http.ResponseWriter, r *http.Request) *output.Error {
+	// log attempt
+	service.logger.Infof("client %s: attempting totp disable", r.RemoteAddr)
+
+	claims, err := service.ValidateAuthHeader(r, w, "totp disable")
+	if err != nil {
+		return output.ErrUnauthorized
+	}
+	username := claims.Subject
+
+	// decode body into payload
+	var payload totpDisablePayload
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Infof("client %s: totp disable for user '%s' failed (payload error: %s)", r.RemoteAddr, username, err)
+		return output.ErrValidationFailed
+	}
+
+	user, err := service.storage.GetOneUserByName(username)
+	if err != nil {
+		service.logger.Errorf("client %s: totp disable for user '%s' failed (bad username: %s)", r.RemoteAddr, username, err)
+		return output.ErrUnauthorized
+	}
+
+	// confirm current password is correct
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(payload.CurrentPassword))
+	if err != nil {
+		service.logger.Infof("client %s: totp disable for user '%s' failed (bad password: %s)", r.RemoteAddr, username, err)
+		return output.ErrUnauthorized
+	}
+
+	// if enabled, confirm code
+	if user.TotpEnabled {
+		// too many recent failures for this user
+		err = service.checkTotpUserLocked(username)
+		if err != nil {
+			service.logger.Warnf("client %s: totp disable for user '%s' failed (%s)", r.RemoteAddr, username, err)
+			return output.ErrTotpRequired
+		}
+
+		valid, step := validateTotpCode(user.TotpSecret, payload.Code, time.Now())
+		if !valid {
+			service.logger.Infof("client %s: totp disable for user '%s' failed (bad code)", r.RemoteAddr, username)
+			service.failTotpUser(username)
+			return output.ErrTotpRequired
+		}
+
+		err = service.markTotpStepUsed(username, totpPurposeDisable, step)
+		if err != nil {
+			service.logger.Infof("client %s: totp disable for user '%s' failed (%s)", r.RemoteAddr, username, err)
+			service.failTotpUser(username)
+			return output.ErrTotpRequired
+		}
+		service.clearTotpUserFailures(username)
+	}
+
+	// clear totp
+	userId, err := service.storage.UpdateUserTotp(username, "", false, []string{})
+	if err != nil {
+		service.logger.Errorf("client %s: totp disable for user '%s' failed (internal error: %s)", r.RemoteAddr, username, err)
+		return output.ErrStorageGeneric
+	}
+
+	// log success
+	service.logger.Infof("client %s: totp disable for user '%s' succeeded", r.RemoteAddr, username)
+
+	response := &output.JsonResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("totp disabled for user '%s' (id: %d)", username, userId)
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -1,10 +1,12 @@
 package auth
 
 import (
+	"certwarden-backend/pkg/datatypes/safemap"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/randomness"
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
 	"go.uber.org/zap"
@@ -27,16 +29,31 @@ type App interface {
 }
 
 type User struct {
-	ID           int
-	Username     string
-	PasswordHash string
-	CreatedAt    int
-	UpdatedAt    int
+	ID                int
+	Username          string
+	PasswordHash      string
+	TotpSecret        string
+	TotpEnabled       bool
+	TotpRecoveryCodes []string
+	CreatedAt         int
+	UpdatedAt         int
 }
 
 type Storage interface {
 	GetOneUserByName(username string) (User, error)
 	UpdateUserPassword(username string, newPasswordHash string) (userId int, err error)
+	UpdateUserTotp(username string, totpSecret string, totpEnabled bool, recoveryCodeHashes []string) (userId int, err error)
+	ReplaceUserTotpRecoveryCodes(username string, oldRecoveryCodeHashes []string, newRecoveryCodeHashes []string) (replaced bool, err error)
+}
+
+// Config holds the auth config
+type Config struct {
+	Totp TotpConfig `yaml:"totp"`
+}
+
+// TotpConfig holds the config options for TOTP (two-factor) authentication
+type TotpConfig struct {
+	SensitiveRouteEnforcement *SensitiveRouteEnforcement `yaml:"sensitive_route_enforcement"`
 }
 
 // Keys service struct
@@ -49,10 +66,14 @@ type Service struct {
 	accessJwtSecret  []byte
 	sessionJwtSecret []byte
 	sessionManager   *sessionManager
+	totpEnforcement  SensitiveRouteEnforcement
+	totpPending      *safemap.SafeMap[*pendingTotpLogin]
+	totpUsedSteps    *safemap.SafeMap[int64]
+	totpUserFailures *safemap.SafeMap[*totpUserFailures]
 }
 
 // NewService creates a new users service
-func NewService(app App) (*Service, error) {
+func NewService(app App, cfg *Config) (*Service, error) {
 	service := new(Service)
 	var err error
 
@@ -92,6 +113,15 @@ func NewService(app App) (*Service, error) {
 		return nil, errServiceComponent
 	}
 
+	// totp
+	service.totpEnforcement = *cfg.Totp.SensitiveRouteEnforcement
+	if !service.totpEnforcement.valid() {
+		return nil, fmt.Errorf("auth: invalid totp sensitive_route_enforcement value '%s'", service.totpEnforcement)
+	}
+	service.totpPending = safemap.NewSafeMap[*pendingTotpLogin]()
+	service.totpUsedSteps = safemap.NewSafeMap[int64]()
+	service.totpUserFailures = safemap.NewSafeMap[*totpUserFailures]()
+
 	// create session manager
 	service.sessionManager = newSessionManager()
 	// start cleaner
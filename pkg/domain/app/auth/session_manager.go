@@ -153,6 +153,9 @@ func (service *Service) startCleanerService(ctx context.Context, wg *sync.WaitGr
 
 			// run delete func against sessions map
 			_ = service.sessionManager.sessions.DeleteFunc(deleteFunc)
+
+			// also clean up expired totp login state
+			service.cleanTotpState()
 		}
 	}()
 }
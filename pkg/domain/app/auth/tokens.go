@@ -28,11 +28,12 @@ type sessionToken string
 // custom claims for tokens
 type tokenClaims struct {
 	jwt.RegisteredClaims
-	SessionID uuid.UUID `json:"session_id"`
+	SessionID    uuid.UUID `json:"session_id"`
+	TotpVerified bool      `json:"totp_verified"`
 }
 
 // newTokenClaims creates tokenClaims
-func newTokenClaims(username string, uuid uuid.UUID, expirationDuration time.Duration) tokenClaims {
+func newTokenClaims(username string, uuid uuid.UUID, totpVerified bool, expirationDuration time.Duration) tokenClaims {
 	return tokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   username,
@@ -41,7 +42,8 @@ func newTokenClaims(username string, uuid uuid.UUID, expirationDuration time.Dur
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			// TODO: Issuer / Audiences domains ?
 		},
-		SessionID: uuid,
+		SessionID:    uuid,
+		TotpVerified: totpVerified,
 	}
 }
 
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238 defaults; these are the only values widely supported
+// by authenticator apps)
+const (
+	totpIssuer = "CertWarden"
+	totpDigits = 6
+	totpPeriod = 30 * time.Second
+	// number of periods before and after the current period that are also
+	// accepted (to allow for some clock drift)
+	totpSkew = 1
+)
+
+// totpSecretEncoding is the encoding used for TOTP secrets (RFC 4648 base32 without
+// padding, as expected by authenticator apps)
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpStep returns the TOTP time step (counter) for the specified time
+func totpStep(t time.Time) int64 {
+	return t.Unix() / int64(totpPeriod.Seconds())
+}
+
+// totpCodeForStep calculates the TOTP code for the specified base32 secret and time
+// step. See RFC 4226 s 5.3 and RFC 6238 s 4.
+func totpCodeForStep(secret string, step int64) (string, error) {
+	key, err := totpSecretEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("auth: failed to decode totp secret (%s)", err)
+	}
+
+	// HMAC-SHA-1 of the counter
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	binCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	// modulo to get the desired number of digits
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, binCode%mod), nil
+}
+
+// validateTotpCode checks code against the secret for the specified time, allowing
+// for the configured skew. If the code is valid, true and the time step the code
+// matched are returned.
+func validateTotpCode(secret string, code string, t time.Time) (valid bool, step int64) {
+	// remove any formatting the user may have included (e.g. "123 456")
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totpDigits {
+		return false, 0
+	}
+
+	currentStep := totpStep(t)
+	for i := -totpSkew; i <= totpSkew; i++ {
+		expected, err := totpCodeForStep(secret, currentStep+int64(i))
+		if err != nil {
+			return false, 0
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true, currentStep + int64(i)
+		}
+	}
+
+	return false, 0
+}
+
+// totpProvisioningURI returns the otpauth URI for the specified user and secret. This
+// is the value that is encoded into a QR code for authenticator apps to scan.
+// See: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func totpProvisioningURI(username string, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", totpIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", int(totpPeriod.Seconds())))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + totpIssuer + ":" + username,
+		RawQuery: params.Encode(),
+	}
+
+	return u.String()
+}
+
+// hashRecoveryCode returns the hex encoded sha256 hash of a recovery code. Recovery
+// codes are long random values, so a slow hash (e.g. bcrypt) isn't needed.
+func hashRecoveryCode(code string) string {
+	// normalize
+	code = strings.ToLower(strings.ReplaceAll(code, "-", ""))
+
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+// useRecoveryCode checks if code matches any of the hashes. If it does, true is
+// returned along with the remaining hashes (with the used one removed).
+func useRecoveryCode(code string, hashes []string) (valid bool, remainingHashes []string) {
+	codeHash := hashRecoveryCode(code)
+
+	remainingHashes = []string{}
+	for i := range hashes {
+		if !valid && subtle.ConstantTimeCompare([]byte(codeHash), []byte(hashes[i])) == 1 {
+			valid = true
+			continue
+		}
+		remainingHashes = append(remainingHashes, hashes[i])
+	}
+
+	return valid, remainingHashes
+}
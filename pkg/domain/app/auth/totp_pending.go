@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// pending login expiration and max failed code attempts
+const totpPendingExpiration = 5 * time.Minute
+const totpPendingMaxAttempts = 5
+
+// max failed totp code attempts for a user (across all of their pending logins and
+// any other totp checks) before totp is blocked for that user, and how long the
+// block lasts
+const totpUserMaxFailures = 10
+const totpUserLockout = 15 * time.Minute
+
+var errTotpPendingInvalid = errors.New("totp login token is invalid or expired")
+var errTotpCodeReused = errors.New("totp code was already used")
+var errTotpUserLocked = errors.New("too many failed totp attempts for user, try again later")
+
+// totpPurpose is what a totp code is being used for. Replay protection is tracked
+// separately for each purpose.
+type totpPurpose string
+
+const (
+	totpPurposeLogin     totpPurpose = "login"
+	totpPurposeEnroll    totpPurpose = "enroll"
+	totpPurposeRecovery  totpPurpose = "recovery_codes"
+	totpPurposeDisable   totpPurpose = "disable"
+	totpPurposeSensitive totpPurpose = "sensitive_route"
+)
+
+// totpUserFailures tracks failed totp login attempts for one user
+type totpUserFailures struct {
+	count       atomic.Int32
+	windowStart time.Time
+}
+
+// pendingTotpLogin is a login that passed the username/password check but
+// still needs to complete the TOTP step
+type pendingTotpLogin struct {
+	username       string
+	expiresAt      time.Time
+	failedAttempts atomic.Int32
+}
+
+// newPendingTotpLogin creates a pending login for username and returns the token
+// the client must send back along with their TOTP (or recovery) code
+func (service *Service) newPendingTotpLogin(username string) (token string, err error) {
+	token = uuid.New().String()
+
+	pending := &pendingTotpLogin{
+		username:  username,
+		expiresAt: time.Now().Add(totpPendingExpiration),
+	}
+
+	exists, _ := service.totpPending.Add(token, pending)
+	if exists {
+		// should never happen
+		return "", errors.New("auth: duplicate totp login token")
+	}
+
+	return token, nil
+}
+
+// readPendingTotpLogin returns the pending login for token, if it exists and has
+// not expired
+func (service *Service) readPendingTotpLogin(token string) (*pendingTotpLogin, error) {
+	pending, err := service.totpPending.Read(token)
+	if err != nil || time.Now().After(pending.expiresAt) {
+		return nil, errTotpPendingInvalid
+	}
+
+	return pending, nil
+}
+
+// closePendingTotpLogin removes the pending login for token
+func (service *Service) closePendingTotpLogin(token string) {
+	_ = service.totpPending.DeleteFunc(func(k string, _ *pendingTotpLogin) bool {
+		return k == token
+	})
+}
+
+// failPendingTotpLogin increments the failed attempt count for the pending login
+// and removes it if the maximum number of attempts is reached
+func (service *Service) failPendingTotpLogin(token string, pending *pendingTotpLogin) {
+	if pending.failedAttempts.Add(1) >= totpPendingMaxAttempts {
+		service.closePendingTotpLogin(token)
+	}
+
+	// also count against the user, since anyone with the password can create more
+	// pending logins
+	service.failTotpUser(pending.username)
+}
+
+// failTotpUser increments the failed totp code count for username (starting a new
+// window if the last one is over). All totp code checks count towards the same limit.
+func (service *Service) failTotpUser(username string) {
+	now := time.Now()
+	_ = service.totpUserFailures.DeleteFunc(func(k string, v *totpUserFailures) bool {
+		return k == username && now.Sub(v.windowStart) > totpUserLockout
+	})
+	_, failures := service.totpUserFailures.Add(username, &totpUserFailures{windowStart: now})
+	failures.count.Add(1)
+}
+
+// checkTotpUserLocked returns an error if username has reached the max failed totp
+// code attempts within the lockout window
+func (service *Service) checkTotpUserLocked(username string) error {
+	failures, err := service.totpUserFailures.Read(username)
+	if err != nil || time.Since(failures.windowStart) > totpUserLockout {
+		return nil
+	}
+
+	if failures.count.Load() >= totpUserMaxFailures {
+		return errTotpUserLocked
+	}
+
+	return nil
+}
+
+// clearTotpUserFailures resets the failed totp code attempts for username
+func (service *Service) clearTotpUserFailures(username string) {
+	_ = service.totpUserFailures.DeleteFunc(func(k string, _ *totpUserFailures) bool {
+		return k == username
+	})
+}
+
+// markTotpStepUsed records that username used the code for step for the specified
+// purpose. If the step was already used for that purpose, an error is returned (i.e.
+// the code is being replayed).
+func (service *Service) markTotpStepUsed(username string, purpose totpPurpose, step int64) error {
+	exists, _ := service.totpUsedSteps.Add(username+":"+string(purpose)+":"+strconv.FormatInt(step, 10), step)
+	if exists {
+		return errTotpCodeReused
+	}
+
+	return nil
+}
+
+// cleanTotpState removes expired pending logins and used code steps that are
+// too old to be accepted anymore
+func (service *Service) cleanTotpState() {
+	now := time.Now()
+
+	_ = service.totpPending.DeleteFunc(func(_ string, v *pendingTotpLogin) bool {
+		return now.After(v.expiresAt)
+	})
+
+	_ = service.totpUserFailures.DeleteFunc(func(_ string, v *totpUserFailures) bool {
+		return now.Sub(v.windowStart) > totpUserLockout
+	})
+
+	oldestValidStep := totpStep(now) - totpSkew
+	_ = service.totpUsedSteps.DeleteFunc(func(_ string, step int64) bool {
+		return step < oldestValidStep
+	})
+}
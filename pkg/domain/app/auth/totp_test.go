@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"certwarden-backend/pkg/datatypes/safemap"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B test secret ("12345678901234567890") base32 encoded
+const testTotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 Appendix B SHA1 test vectors (truncated to 6 digits)
+var totpTestVectors = []struct {
+	unixTime int64
+	code     string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTotp_CodeForStep(t *testing.T) {
+	for _, vector := range totpTestVectors {
+		code, err := totpCodeForStep(testTotpSecret, totpStep(time.Unix(vector.unixTime, 0)))
+		if err != nil {
+			t.Fatalf("unexpected error for time %d: %s", vector.unixTime, err)
+		}
+
+		if code != vector.code {
+			t.Errorf("time %d returned code '%s' (expected '%s')", vector.unixTime, code, vector.code)
+		}
+	}
+}
+
+func TestTotp_Validate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	// current, previous, and next period should be accepted
+	for _, offset := range []time.Duration{0, -totpPeriod, totpPeriod} {
+		code, _ := totpCodeForStep(testTotpSecret, totpStep(now.Add(offset)))
+		valid, step := validateTotpCode(testTotpSecret, code, now)
+		if !valid {
+			t.Errorf("code for offset %s returned invalid", offset)
+		}
+		if step != totpStep(now.Add(offset)) {
+			t.Errorf("code for offset %s returned wrong step %d", offset, step)
+		}
+	}
+
+	// outside of skew should not be accepted
+	code, _ := totpCodeForStep(testTotpSecret, totpStep(now.Add(2*totpPeriod)))
+	if valid, _ := validateTotpCode(testTotpSecret, code, now); valid {
+		t.Error("code outside of skew returned valid")
+	}
+
+	// garbage should not be accepted
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if valid, _ := validateTotpCode(testTotpSecret, code, now); valid {
+			t.Errorf("invalid code '%s' returned valid", code)
+		}
+	}
+}
+
+func TestTotp_RecoveryCode(t *testing.T) {
+	hashes := []string{hashRecoveryCode("abcde-fghjk"), hashRecoveryCode("mnpqr-stuvw")}
+
+	// formatting differences are ignored
+	valid, remaining := useRecoveryCode("ABCDEFGHJK", hashes)
+	if !valid || len(remaining) != 1 || remaining[0] != hashes[1] {
+		t.Errorf("valid recovery code was not accepted and removed")
+	}
+
+	// used code can't be reused
+	valid, remaining = useRecoveryCode("abcde-fghjk", remaining)
+	if valid || len(remaining) != 1 {
+		t.Errorf("used recovery code was accepted")
+	}
+}
+
+func TestTotp_StepReplayAndUserLockout(t *testing.T) {
+	service := &Service{
+		totpPending:      safemap.NewSafeMap[*pendingTotpLogin](),
+		totpUsedSteps:    safemap.NewSafeMap[int64](),
+		totpUserFailures: safemap.NewSafeMap[*totpUserFailures](),
+	}
+
+	// a step can only be used once per purpose
+	if err := service.markTotpStepUsed("admin", totpPurposeLogin, 100); err != nil {
+		t.Fatalf("first use of step returned error: %s", err)
+	}
+	if err := service.markTotpStepUsed("admin", totpPurposeLogin, 100); err != errTotpCodeReused {
+		t.Errorf("replayed step returned %v (expected %v)", err, errTotpCodeReused)
+	}
+	if err := service.markTotpStepUsed("admin", totpPurposeSensitive, 100); err != nil {
+		t.Errorf("step used for a different purpose returned error: %s", err)
+	}
+
+	// failures across many pending logins lock the user
+	for i := 0; i < totpUserMaxFailures; i++ {
+		if err := service.checkTotpUserLocked("admin"); err != nil {
+			t.Fatalf("user locked after only %d failures", i)
+		}
+
+		token, err := service.newPendingTotpLogin("admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pending, _ := service.readPendingTotpLogin(token)
+		service.failPendingTotpLogin(token, pending)
+	}
+	if err := service.checkTotpUserLocked("admin"); err != errTotpUserLocked {
+		t.Errorf("user not locked after max failures (got %v)", err)
+	}
+	if err := service.checkTotpUserLocked("other"); err != nil {
+		t.Errorf("different user was locked")
+	}
+
+	service.clearTotpUserFailures("admin")
+	if err := service.checkTotpUserLocked("admin"); err != nil {
+		t.Errorf("user still locked after clearing failures")
+	}
+
+	// failures outside of login (e.g. sensitive routes) count towards the same limit
+	for i := 0; i < totpUserMaxFailures-1; i++ {
+		service.failTotpUser("admin")
+	}
+	token, _ := service.newPendingTotpLogin("admin")
+	pending, _ := service.readPendingTotpLogin(token)
+	service.failPendingTotpLogin(token, pending)
+	if err := service.checkTotpUserLocked("admin"); err != errTotpUserLocked {
+		t.Errorf("user not locked after mixed failures (got %v)", err)
+	}
+}
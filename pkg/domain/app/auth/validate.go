@@ -3,6 +3,7 @@ package auth
 import (
 	"certwarden-backend/pkg/output"
 	"net/http"
+	"time"
 )
 
 const authHeader = "Authorization"
@@ -93,3 +94,81 @@ func (service *Service) validateSessionCookie(r *http.Request, w http.ResponseWr
 
 	return claims, nil
 }
+
+// SensitiveRouteEnforcement is the level of TOTP enforcement applied to sensitive routes
+type SensitiveRouteEnforcement string
+
+const (
+	// no additional requirements beyond a valid access token
+	SensitiveRouteEnforcementNone SensitiveRouteEnforcement = "none"
+	// the session must have been established using TOTP
+	SensitiveRouteEnforcementSession SensitiveRouteEnforcement = "session"
+	// the session must have been established using TOTP and each request must also
+	// include a current TOTP code in the totpHeader
+	SensitiveRouteEnforcementCode SensitiveRouteEnforcement = "code"
+)
+
+// valid returns true if the enforcement is a known value
+func (e SensitiveRouteEnforcement) valid() bool {
+	return e == SensitiveRouteEnforcementNone || e == SensitiveRouteEnforcementSession || e == SensitiveRouteEnforcementCode
+}
+
+const totpHeader = "X-TOTP-Code"
+
+// ValidateAuthHeaderSensitive does the same validation as ValidateAuthHeader and then
+// additionally applies the configured TOTP enforcement for sensitive routes.
+func (service *Service) ValidateAuthHeaderSensitive(r *http.Request, w http.ResponseWriter, logTaskName string) (*tokenClaims, error) {
+	claims, err := service.ValidateAuthHeader(r, w, logTaskName)
+	if err != nil {
+		return nil, err
+	}
+
+	// no enforcement
+	if service.totpEnforcement == SensitiveRouteEnforcementNone {
+		return claims, nil
+	}
+
+	// session must have used totp
+	if !claims.TotpVerified {
+		service.logger.Infof("client %s: %s failed (session for user '%s' did not use totp)", r.RemoteAddr, logTaskName, claims.Subject)
+		return nil, output.ErrTotpRequired
+	}
+
+	// done, unless a code is also required
+	if service.totpEnforcement != SensitiveRouteEnforcementCode {
+		return claims, nil
+	}
+
+	// indicate totp header influenced the response
+	w.Header().Add("Vary", totpHeader)
+
+	user, err := service.storage.GetOneUserByName(claims.Subject)
+	if err != nil {
+		service.logger.Errorf("client %s: %s failed (failed to get user '%s': %s)", r.RemoteAddr, logTaskName, claims.Subject, err)
+		return nil, output.ErrUnauthorized
+	}
+
+	// too many recent failures for this user
+	err = service.checkTotpUserLocked(user.Username)
+	if err != nil {
+		service.logger.Warnf("client %s: %s failed (user '%s': %s)", r.RemoteAddr, logTaskName, claims.Subject, err)
+		return nil, output.ErrTotpRequired
+	}
+
+	valid, step := validateTotpCode(user.TotpSecret, r.Header.Get(totpHeader), time.Now())
+	if !user.TotpEnabled || !valid {
+		service.logger.Infof("client %s: %s failed (bad or missing totp code for user '%s')", r.RemoteAddr, logTaskName, claims.Subject)
+		service.failTotpUser(user.Username)
+		return nil, output.ErrTotpRequired
+	}
+
+	err = service.markTotpStepUsed(user.Username, totpPurposeSensitive, step)
+	if err != nil {
+		service.logger.Infof("client %s: %s failed (user '%s': %s)", r.RemoteAddr, logTaskName, claims.Subject, err)
+		service.failTotpUser(user.Username)
+		return nil, output.ErrTotpRequired
+	}
+	service.clearTotpUserFailures(user.Username)
+
+	return claims, nil
+}
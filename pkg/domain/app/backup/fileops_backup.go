@@ -3,6 +3,8 @@ package backup
 import (
 	"archive/zip"
 	"bytes"
+	"certwarden-backend/pkg/domain/app/notifications"
+	"certwarden-backend/pkg/metrics"
 	"crypto/sha1"
 	"fmt"
 	"io"
@@ -10,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const dataStorageBackupDirName = "backup"
@@ -129,8 +132,28 @@ func (service *Service) createDataBackup(withOnDiskBackups bool) (zipFileBytes [
 }
 
 // CreateBackupOnDisk backs up the app state and saves it to the local backup folder. It
-// optionally includes log files but never includes on disk backups.
+// optionally includes log files but never includes on disk backups. A notification is
+// sent with the result.
 func (service *Service) CreateBackupOnDisk() (backupFileDetails, error) {
+	details, err := service.createBackupOnDisk()
+	if err != nil {
+		metrics.BackupLastFailure.Set(float64(time.Now().Unix()))
+		service.notifications.Load().Notify(notifications.EventTypeBackupFailed, fmt.Sprintf("failed to create on disk backup (%s)", err), map[string]any{
+			"error": err.Error(),
+		})
+	} else {
+		metrics.BackupLastSuccess.Set(float64(time.Now().Unix()))
+		service.notifications.Load().Notify(notifications.EventTypeBackupSucceeded, fmt.Sprintf("backup saved to disk (%s)", details.Name), map[string]any{
+			"name": details.Name,
+			"size": details.Size,
+		})
+	}
+
+	return details, err
+}
+
+// createBackupOnDisk does the work of CreateBackupOnDisk
+func (service *Service) createBackupOnDisk() (backupFileDetails, error) {
 	// make backup
 	zipFileData, err := service.createDataBackup(false)
 	if err != nil {
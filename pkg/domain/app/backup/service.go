@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/output"
 	"context"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
@@ -32,6 +34,10 @@ type Service struct {
 	logger                     *zap.SugaredLogger
 	output                     *output.Service
 	config                     *Config
+
+	// notifications is set after the service is created (since backup runs before storage
+	// is available)
+	notifications atomic.Pointer[notifications.Service]
 }
 
 // NewService creates a new service
@@ -70,3 +76,9 @@ func NewService(app App) (*Service, error) {
 
 	return service, nil
 }
+
+// SetNotificationsService sets the notifications service used to send notifications
+// about backup results
+func (service *Service) SetNotificationsService(notificationsService *notifications.Service) {
+	service.notifications.Store(notificationsService)
+}
@@ -5,7 +5,9 @@ import (
 	"certwarden-backend/pkg/challenges/dns_checker"
 	"certwarden-backend/pkg/challenges/providers"
 	"certwarden-backend/pkg/challenges/providers/http01internal"
+	"certwarden-backend/pkg/domain/app/auth"
 	"certwarden-backend/pkg/domain/app/backup"
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/domain/app/updater"
 	"certwarden-backend/pkg/domain/orders"
 	"errors"
@@ -34,24 +36,36 @@ func (app *Application) GetFileBackupFolderMode() fs.FileMode {
 
 // config is the configuration structure for app (and subsequently services)
 type config struct {
-	ConfigVersion             *int              `yaml:"config_version"`
-	BindAddress               *string           `yaml:"bind_address"`
-	HttpsPort                 *int              `yaml:"https_port"`
-	HttpPort                  *int              `yaml:"http_port"`
-	EnableHttpRedirect        *bool             `yaml:"enable_http_redirect"`
-	FrontendServe             *bool             `yaml:"serve_frontend"`
-	FrontendShowDebugInfo     *bool             `yaml:"frontend_show_debug_info"`
-	CORSPermittedCrossOrigins []string          `yaml:"cors_permitted_crossorigins"`
-	CertificateName           *string           `yaml:"certificate_name"`
-	DisableHSTS               *bool             `yaml:"disable_hsts"`
-	LogLevel                  *string           `yaml:"log_level"`
-	EnablePprof               *bool             `yaml:"enable_pprof"`
-	PprofHttpsPort            *int              `yaml:"pprof_https_port"`
-	PprofHttpPort             *int              `yaml:"pprof_http_port"`
-	Backup                    backup.Config     `yaml:"backup"`
-	Updater                   updater.Config    `yaml:"updater"`
-	Orders                    orders.Config     `yaml:"orders"`
-	Challenges                challenges.Config `yaml:"challenges"`
+	ConfigVersion             *int                 `yaml:"config_version"`
+	BindAddress               *string              `yaml:"bind_address"`
+	HttpsPort                 *int                 `yaml:"https_port"`
+	HttpPort                  *int                 `yaml:"http_port"`
+	EnableHttpRedirect        *bool                `yaml:"enable_http_redirect"`
+	FrontendServe             *bool                `yaml:"serve_frontend"`
+	FrontendShowDebugInfo     *bool                `yaml:"frontend_show_debug_info"`
+	CORSPermittedCrossOrigins []string             `yaml:"cors_permitted_crossorigins"`
+	CertificateName           *string              `yaml:"certificate_name"`
+	DisableHSTS               *bool                `yaml:"disable_hsts"`
+	OutboundProxy             *string              `yaml:"outbound_proxy"`
+	LogLevel                  *string              `yaml:"log_level"`
+	EnablePprof               *bool                `yaml:"enable_pprof"`
+	PprofHttpsPort            *int                 `yaml:"pprof_https_port"`
+	PprofHttpPort             *int                 `yaml:"pprof_http_port"`
+	Auth                      auth.Config          `yaml:"auth"`
+	Backup                    backup.Config        `yaml:"backup"`
+	Updater                   updater.Config       `yaml:"updater"`
+	Orders                    orders.Config        `yaml:"orders"`
+	Challenges                challenges.Config    `yaml:"challenges"`
+	Notifications             notifications.Config `yaml:"notifications"`
+	Metrics                   metricsConfig        `yaml:"metrics"`
+	ExternalSignerPlugins     map[string]string    `yaml:"external_signer_plugins"`
+}
+
+// metricsConfig contains the configuration options for the Prometheus metrics endpoint
+type metricsConfig struct {
+	Enable *bool `yaml:"enable"`
+	// BearerToken, if set, must be sent by scrapers (Authorization: Bearer <token>)
+	BearerToken *string `yaml:"bearer_token"`
 }
 
 // httpAddress() returns formatted http server address string
@@ -240,6 +254,10 @@ func (app *Application) setDefaultConfigValues() {
 		app.config.DisableHSTS = new(bool)
 		*app.config.DisableHSTS = false
 	}
+	if app.config.OutboundProxy == nil {
+		app.config.OutboundProxy = new(string)
+		*app.config.OutboundProxy = ""
+	}
 
 	// debug and dev stuff
 	if app.config.LogLevel == nil {
@@ -259,6 +277,12 @@ func (app *Application) setDefaultConfigValues() {
 		*app.config.PprofHttpsPort = 4070
 	}
 
+	// auth
+	if app.config.Auth.Totp.SensitiveRouteEnforcement == nil {
+		app.config.Auth.Totp.SensitiveRouteEnforcement = new(auth.SensitiveRouteEnforcement)
+		*app.config.Auth.Totp.SensitiveRouteEnforcement = auth.SensitiveRouteEnforcementNone
+	}
+
 	// backup
 	if app.config.Backup.Enabled == nil {
 		app.config.Backup.Enabled = new(bool)
@@ -300,6 +324,46 @@ func (app *Application) setDefaultConfigValues() {
 		app.config.Orders.RefreshTimeMinute = new(int)
 		*app.config.Orders.RefreshTimeMinute = 12
 	}
+	if app.config.Orders.FulfillingWorkers == nil {
+		app.config.Orders.FulfillingWorkers = new(int)
+		*app.config.Orders.FulfillingWorkers = 3
+	}
+	if app.config.Orders.PostProcessingWorkers == nil {
+		app.config.Orders.PostProcessingWorkers = new(int)
+		*app.config.Orders.PostProcessingWorkers = 3
+	}
+	if app.config.Orders.RevocationCheckEnable == nil {
+		app.config.Orders.RevocationCheckEnable = new(bool)
+		*app.config.Orders.RevocationCheckEnable = true
+	}
+	if app.config.Orders.RevocationCheckHours == nil || *app.config.Orders.RevocationCheckHours < 1 {
+		app.config.Orders.RevocationCheckHours = new(int)
+		*app.config.Orders.RevocationCheckHours = 6
+	}
+
+	// metrics
+	if app.config.Metrics.Enable == nil {
+		app.config.Metrics.Enable = new(bool)
+		*app.config.Metrics.Enable = false
+	}
+	if app.config.Metrics.BearerToken == nil {
+		app.config.Metrics.BearerToken = new(string)
+		*app.config.Metrics.BearerToken = ""
+	}
+
+	// notifications
+	if app.config.Notifications.Email.DigestTimeHour == nil {
+		app.config.Notifications.Email.DigestTimeHour = new(int)
+		*app.config.Notifications.Email.DigestTimeHour = 7
+	}
+	if app.config.Notifications.Email.DigestTimeMinute == nil {
+		app.config.Notifications.Email.DigestTimeMinute = new(int)
+		*app.config.Notifications.Email.DigestTimeMinute = 0
+	}
+	if app.config.Notifications.Email.DigestLookaheadDays == nil {
+		app.config.Notifications.Email.DigestLookaheadDays = new(int)
+		*app.config.Notifications.Email.DigestLookaheadDays = 0
+	}
 
 	// challenge dns checker services
 	if app.config.Challenges.DnsCheckerConfig.DnsServices == nil || len(app.config.Challenges.DnsCheckerConfig.DnsServices) <= 0 {
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"certwarden-backend/pkg/metrics"
+	"certwarden-backend/pkg/output"
+	"crypto/subtle"
+	"net/http"
+)
+
+// metricsHandler writes the app's metrics in the Prometheus text exposition format. If
+// a bearer token is configured, the request must include it.
+func (app *Application) metricsHandler(w http.ResponseWriter, r *http.Request) *output.Error {
+	// auth (if configured)
+	if *app.config.Metrics.BearerToken != "" {
+		expected := "Bearer " + *app.config.Metrics.BearerToken
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			app.logger.Debug("metrics: missing or invalid bearer token")
+			return output.ErrUnauthorized
+		}
+	}
+
+	// write response
+	w.Header().Set("Content-Type", metrics.TextContentType)
+	err := metrics.WriteText(w)
+	if err != nil {
+		app.logger.Errorf("metrics: failed to write metrics (%s)", err)
+		return output.ErrInternal
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// usernameContextKey is the key used to store the authenticated username in a request's
+// context
+type usernameContextKey struct{}
+
+// withUsername returns a shallow copy of r with username added to its context
+func withUsername(r *http.Request, username string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), usernameContextKey{}, username))
+}
+
+// usernameFromRequest returns the authenticated username in r's context (or blank if
+// there is none)
+func usernameFromRequest(r *http.Request) string {
+	username, _ := r.Context().Value(usernameContextKey{}).(string)
+	return username
+}
+
+// auditObjectFromRoutePath determines the audit object type and the name of the route
+// param that holds the object's id based on the route's path. The object type is the
+// non-param path segments before the last param (or all segments if there are no params)
+// and the id param is the last param.
+// e.g. /v1/certificates/:certid/orders/:orderid/revoke is type `certificates/orders` with
+// id param `orderid`
+func auditObjectFromRoutePath(routePath string) (objectType string, idParamName string) {
+	// remove api and version prefix (and app prefix for app routes)
+	routePath = strings.TrimPrefix(routePath, apiUrlPath)
+	routePath = strings.TrimPrefix(routePath, "/v1")
+	routePath = strings.TrimPrefix(routePath, "/app")
+
+	segments := strings.Split(strings.Trim(routePath, "/"), "/")
+
+	// find last param
+	lastParam := -1
+	for i := range segments {
+		if strings.HasPrefix(segments[i], ":") {
+			lastParam = i
+			idParamName = strings.TrimPrefix(segments[i], ":")
+		}
+	}
+
+	// segments to use for type
+	if lastParam >= 0 {
+		segments = segments[:lastParam]
+	}
+
+	typeSegments := []string{}
+	for i := range segments {
+		if !strings.HasPrefix(segments[i], ":") {
+			typeSegments = append(typeSegments, segments[i])
+		}
+	}
+
+	return strings.Join(typeSegments, "/"), idParamName
+}
+
+// sourceIP returns the IP address of the client (without the port)
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// middlewareApplyAudit applies middleware that records an audit event for every request
+// to the route. Handlers can add details about the change they made using audit.SetChanges.
+// This must be applied inside of the auth middleware so the actor is known.
+func middlewareApplyAudit(next handlerFunc, method string, routePath string, auditService *audit.Service) handlerFunc {
+	// route details are constant for all requests to the route
+	objectType, idParamName := auditObjectFromRoutePath(routePath)
+	action := method + " " + strings.TrimPrefix(routePath, apiUrlPath)
+
+	return func(w http.ResponseWriter, r *http.Request) *output.Error {
+		event := &audit.Event{
+			CreatedAt:  int(time.Now().Unix()),
+			Actor:      usernameFromRequest(r),
+			SourceIP:   sourceIP(r),
+			Action:     action,
+			ObjectType: objectType,
+		}
+		if idParamName != "" {
+			event.ObjectID = httprouter.ParamsFromContext(r.Context()).ByName(idParamName)
+		}
+
+		// do next with the event attached
+		outErr := next(w, audit.WithEvent(r, event))
+
+		// record result
+		event.Success = outErr == nil
+		if outErr != nil {
+			event.Message = outErr.Message
+		}
+		auditService.Record(*event)
+
+		return outErr
+	}
+}
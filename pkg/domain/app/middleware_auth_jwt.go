@@ -15,12 +15,36 @@ func middlewareApplyAuthJWT(next handlerFunc, auth *auth.Service) handlerFunc {
 		// shorten URI for logging
 		trimmedURI := loggableRequestURI(r)
 
-		_, err := auth.ValidateAuthHeader(r, w, fmt.Sprintf("%s %s", r.Method, trimmedURI))
+		claims, err := auth.ValidateAuthHeader(r, w, fmt.Sprintf("%s %s", r.Method, trimmedURI))
 		if err != nil {
 			return output.ErrUnauthorized
 		}
 
-		// if valid, do next
-		return next(w, r)
+		// if valid, do next (with username in context)
+		return next(w, withUsername(r, claims.Subject))
+	}
+}
+
+// middlewareApplyAuthJWTSensitive applies middleware that validates the jwt access token
+// contained in the auth header and also applies any additional auth requirements that
+// are configured for sensitive routes (e.g. TOTP). If it is not valid, an error is
+// returned instead of executing next.
+func middlewareApplyAuthJWTSensitive(next handlerFunc, auth *auth.Service) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) *output.Error {
+		// shorten URI for logging
+		trimmedURI := loggableRequestURI(r)
+
+		claims, err := auth.ValidateAuthHeaderSensitive(r, w, fmt.Sprintf("%s %s", r.Method, trimmedURI))
+		if err != nil {
+			// return specific error if it is an output.Error
+			outErr, ok := err.(*output.Error)
+			if ok {
+				return outErr
+			}
+			return output.ErrUnauthorized
+		}
+
+		// if valid, do next (with username in context)
+		return next(w, withUsername(r, claims.Subject))
 	}
 }
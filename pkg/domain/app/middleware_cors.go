@@ -32,6 +32,9 @@ func middlewareApplyCORS(next handlerFunc, permittedCrossOrigins []string) handl
 			// access token
 			"authorization",
 
+			// totp code for sensitive routes
+			"X-TOTP-Code",
+
 			// pem download authentication
 			"X-API-Key", "apiKey",
 
@@ -0,0 +1,38 @@
+package notifications
+
+// Config contains the configuration options for notifications. Webhooks are
+// configured via the API (and saved in storage), not in the config file.
+type Config struct {
+	Email EmailConfig `yaml:"email"`
+}
+
+// EmailConfig contains the configuration options for email (SMTP) notifications
+type EmailConfig struct {
+	// time of day to send the daily digest of expiring certificates
+	DigestTimeHour   *int `yaml:"digest_time_hour"`
+	DigestTimeMinute *int `yaml:"digest_time_minute"`
+	// in addition to certificates that are already expiring, include certificates that
+	// will become expiring within this many days
+	DigestLookaheadDays *int                 `yaml:"digest_lookahead_days"`
+	Channels            []EmailChannelConfig `yaml:"channels"`
+}
+
+// EmailChannelConfig is the configuration of a single SMTP server and the
+// recipients that should receive emails through it
+type EmailChannelConfig struct {
+	Name string `yaml:"name"`
+	Host string `yaml:"host"`
+	// Port defaults based on Security (25, 587, or 465)
+	Port *int `yaml:"port"`
+	// Security is one of: none, starttls, tls (default starttls)
+	Security   *string  `yaml:"security"`
+	Username   string   `yaml:"username"`
+	Password   string   `yaml:"password"`
+	From       string   `yaml:"from"`
+	Recipients []string `yaml:"recipients"`
+	// Events to immediately send an alert for (default: order_failed and
+	// post_processing_failed)
+	Events []string `yaml:"events"`
+	// Digest enables the daily digest of expiring certificates (default true)
+	Digest *bool `yaml:"digest"`
+}
@@ -0,0 +1,121 @@
+package notifications
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// delivery retry parameters
+const (
+	// deliveryMaxAttempts is the maximum number of attempts before a delivery
+	// is marked failed
+	deliveryMaxAttempts = 6
+	// deliveryRetryBaseDelay is the delay after the first failed attempt; each
+	// subsequent delay is multiplied by deliveryRetryMultiplier
+	deliveryRetryBaseDelay  = 1 * time.Minute
+	deliveryRetryMultiplier = 4
+	// deliveryRetryMaxDelay caps the delay between attempts
+	deliveryRetryMaxDelay = 6 * time.Hour
+)
+
+// Delivery is a single event queued for (or already sent to) a webhook
+type Delivery struct {
+	ID               int
+	WebhookID        int
+	WebhookName      string
+	EventID          string
+	EventType        string
+	Payload          string
+	Status           string
+	Attempts         int
+	LastResponseCode int
+	LastError        string
+	NextAttemptAt    int
+	CreatedAt        int
+	UpdatedAt        int
+}
+
+// NewDeliveryPayload is used to save a new delivery to storage
+type NewDeliveryPayload struct {
+	WebhookID     int
+	EventID       string
+	EventType     string
+	Payload       string
+	Status        string
+	NextAttemptAt int
+	CreatedAt     int
+	UpdatedAt     int
+}
+
+// DeliveryAttemptPayload is used to record the result of a delivery attempt in storage
+type DeliveryAttemptPayload struct {
+	ID               int
+	Status           string
+	Attempts         int
+	LastResponseCode int
+	LastError        string
+	NextAttemptAt    int
+	UpdatedAt        int
+}
+
+// deliveryResponse is the JSON response for a delivery
+type deliveryResponse struct {
+	ID               int             `json:"id"`
+	WebhookID        int             `json:"webhook_id"`
+	WebhookName      string          `json:"webhook_name"`
+	EventID          string          `json:"event_id"`
+	EventType        string          `json:"event_type"`
+	Payload          json.RawMessage `json:"payload"`
+	Status           string          `json:"status"`
+	Attempts         int             `json:"attempts"`
+	LastResponseCode int             `json:"last_response_code"`
+	LastError        string          `json:"last_error"`
+	NextAttemptAt    int             `json:"next_attempt_at"`
+	CreatedAt        int             `json:"created_at"`
+	UpdatedAt        int             `json:"updated_at"`
+}
+
+func (delivery Delivery) response() deliveryResponse {
+	// payload is stored as json; if somehow it isn't valid, return it as a json string
+	payload := json.RawMessage(delivery.Payload)
+	if !json.Valid(payload) {
+		payload, _ = json.Marshal(delivery.Payload)
+	}
+
+	return deliveryResponse{
+		ID:               delivery.ID,
+		WebhookID:        delivery.WebhookID,
+		WebhookName:      delivery.WebhookName,
+		EventID:          delivery.EventID,
+		EventType:        delivery.EventType,
+		Payload:          payload,
+		Status:           delivery.Status,
+		Attempts:         delivery.Attempts,
+		LastResponseCode: delivery.LastResponseCode,
+		LastError:        delivery.LastError,
+		NextAttemptAt:    delivery.NextAttemptAt,
+		CreatedAt:        delivery.CreatedAt,
+		UpdatedAt:        delivery.UpdatedAt,
+	}
+}
+
+// deliveryRetryDelay returns how long to wait before the next attempt, after the
+// specified number of attempts have failed
+func deliveryRetryDelay(failedAttempts int) time.Duration {
+	delay := deliveryRetryBaseDelay
+	for i := 1; i < failedAttempts; i++ {
+		delay *= deliveryRetryMultiplier
+		if delay >= deliveryRetryMaxDelay {
+			return deliveryRetryMaxDelay
+		}
+	}
+
+	return delay
+}
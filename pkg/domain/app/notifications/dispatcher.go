@@ -0,0 +1,179 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/storage"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dispatcher timing
+const (
+	// dispatcherInterval is how often the dispatcher checks for deliveries that are due
+	// (in addition to being woken when new events are queued)
+	dispatcherInterval = 30 * time.Second
+	// dispatcherBatchSize is the max number of deliveries processed in one pass
+	dispatcherBatchSize = 25
+)
+
+// webhook request headers
+const (
+	headerEvent     = "X-CertWarden-Event"
+	headerDelivery  = "X-CertWarden-Delivery"
+	headerTimestamp = "X-CertWarden-Timestamp"
+	headerSignature = "X-CertWarden-Signature"
+)
+
+// wake signals the dispatcher to check for due deliveries now
+func (service *Service) wake() {
+	select {
+	case service.wakeDispatcher <- struct{}{}:
+	default:
+		// already signaled
+	}
+}
+
+// startDispatcher starts the go routine that sends due deliveries to their webhooks
+func (service *Service) startDispatcher(ctx context.Context, wg *sync.WaitGroup) {
+	service.logger.Info("notifications: starting webhook delivery service")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(dispatcherInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				service.logger.Info("notifications: webhook delivery service shutdown complete")
+				return
+
+			case <-ticker.C:
+			case <-service.wakeDispatcher:
+			}
+
+			service.sendDueDeliveries(ctx)
+		}
+	}()
+}
+
+// sendDueDeliveries attempts all deliveries that are due
+func (service *Service) sendDueDeliveries(ctx context.Context) {
+	for {
+		deliveries, err := service.storage.GetDueDeliveries(int(time.Now().Unix()), dispatcherBatchSize)
+		if err != nil {
+			service.logger.Errorf("notifications: failed to get due deliveries (%s)", err)
+			return
+		}
+
+		for i := range deliveries {
+			// stop if shutting down (remaining deliveries will be sent after restart)
+			if ctx.Err() != nil {
+				return
+			}
+
+			service.attemptDelivery(deliveries[i])
+		}
+
+		// if less than a full batch, all due deliveries were done
+		if len(deliveries) < dispatcherBatchSize {
+			return
+		}
+	}
+}
+
+// attemptDelivery sends delivery to its webhook and records the result
+func (service *Service) attemptDelivery(delivery Delivery) {
+	attempt := DeliveryAttemptPayload{
+		ID:        delivery.ID,
+		Attempts:  delivery.Attempts + 1,
+		UpdatedAt: int(time.Now().Unix()),
+	}
+
+	webhook, err := service.storage.GetOneWebhookById(delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRecord) {
+			// webhook was deleted, nothing to do
+			err = errors.New("webhook no longer exists")
+			attempt.Attempts = deliveryMaxAttempts
+		}
+	} else if !webhook.Enabled && delivery.EventType != string(EventTypeTest) {
+		// test events are still sent to disabled webhooks (to allow testing before enabling)
+		err = errors.New("webhook is disabled")
+		attempt.Attempts = deliveryMaxAttempts
+	} else {
+		attempt.LastResponseCode, err = service.send(webhook, delivery)
+	}
+
+	if err == nil {
+		attempt.Status = DeliveryStatusSucceeded
+		service.logger.Debugf("notifications: delivered event %s to webhook %s (attempt %d)", delivery.EventType, webhook.Name, attempt.Attempts)
+	} else {
+		attempt.LastError = err.Error()
+
+		if attempt.Attempts >= deliveryMaxAttempts {
+			attempt.Status = DeliveryStatusFailed
+			service.logger.Errorf("notifications: delivery %d of event %s failed permanently after %d attempt(s) (%s)", delivery.ID, delivery.EventType, attempt.Attempts, err)
+		} else {
+			attempt.Status = DeliveryStatusPending
+			attempt.NextAttemptAt = int(time.Now().Add(deliveryRetryDelay(attempt.Attempts)).Unix())
+			service.logger.Warnf("notifications: delivery %d of event %s failed (%s), will retry at %s", delivery.ID, delivery.EventType, err, time.Unix(int64(attempt.NextAttemptAt), 0))
+		}
+	}
+
+	err = service.storage.PutDeliveryAttempt(attempt)
+	if err != nil {
+		service.logger.Errorf("notifications: failed to save delivery %d attempt result (%s)", delivery.ID, err)
+	}
+}
+
+// send posts the delivery's payload to the webhook. The response status code is
+// returned, along with an error if the webhook did not respond with a 2xx code.
+func (service *Service) send(webhook Webhook, delivery Delivery) (statusCode int, err error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	header := make(http.Header)
+	header.Set(headerEvent, delivery.EventType)
+	header.Set(headerDelivery, strconv.Itoa(delivery.ID))
+	header.Set(headerTimestamp, timestamp)
+	if webhook.Secret != "" {
+		header.Set(headerSignature, "sha256="+signPayload(webhook.Secret, timestamp, []byte(delivery.Payload)))
+	}
+
+	resp, err := service.httpClient.PostWithHeader(webhook.URL, "application/json", strings.NewReader(delivery.Payload), header)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// include the start of the body, which may explain the problem
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d (%s)", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// signPayload returns the hex encoded HMAC-SHA256 of `timestamp.payload` using the
+// webhook secret as the key. Including the timestamp allows receivers to reject
+// replayed requests.
+func signPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignPayload(t *testing.T) {
+	// expected value calculated independently:
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac 'secret1234'
+	got := signPayload("secret1234", "1700000000", []byte(`{"a":1}`))
+	want := "0b0d4a5e65d9dd5da64ce54e37e6d4be77d07d22ef10aec2f3e9d7011de58e5e"
+
+	if got != want {
+		t.Errorf("signPayload() = %s, want %s", got, want)
+	}
+}
+
+func TestDeliveryRetryDelay(t *testing.T) {
+	tests := []struct {
+		failedAttempts int
+		want           time.Duration
+	}{
+		{1, 1 * time.Minute},
+		{2, 4 * time.Minute},
+		{3, 16 * time.Minute},
+		{4, 64 * time.Minute},
+		{5, 256 * time.Minute},
+		{6, deliveryRetryMaxDelay},
+		{20, deliveryRetryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		got := deliveryRetryDelay(tt.failedAttempts)
+		if got != tt.want {
+			t.Errorf("deliveryRetryDelay(%d) = %s, want %s", tt.failedAttempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package notifications
+
+import (
+	"bytes"
+	"certwarden-backend/pkg/validation"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// email security modes
+const (
+	emailSecurityNone     = "none"
+	emailSecurityStartTLS = "starttls"
+	emailSecurityTLS      = "tls"
+)
+
+// email timing
+const (
+	// emailTimeout is the max time for a single SMTP conversation
+	emailTimeout = 30 * time.Second
+	// emailMaxAttempts is the number of times sending an alert is tried before giving up
+	emailMaxAttempts = 3
+	// emailRetryDelay is the delay between alert attempts
+	emailRetryDelay = time.Minute
+)
+
+// emailSubjectPrefix is prepended to the subject of all emails
+const emailSubjectPrefix = "[CertWarden] "
+
+// emailChannel is a validated EmailChannelConfig
+type emailChannel struct {
+	name       string
+	host       string
+	port       int
+	security   string
+	username   string
+	password   string
+	from       string
+	recipients []string
+	events     []EventType
+	digest     bool
+}
+
+// newEmailChannel validates cfg and returns the emailChannel for it
+func newEmailChannel(cfg EmailChannelConfig) (*emailChannel, error) {
+	ch := &emailChannel{
+		name:       cfg.Name,
+		host:       cfg.Host,
+		security:   emailSecurityStartTLS,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		from:       cfg.From,
+		recipients: cfg.Recipients,
+		digest:     true,
+	}
+
+	if ch.name == "" {
+		return nil, errors.New("email channel name must be specified")
+	}
+	if ch.host == "" {
+		return nil, fmt.Errorf("email channel %s: host must be specified", ch.name)
+	}
+
+	// security
+	if cfg.Security != nil {
+		ch.security = strings.ToLower(*cfg.Security)
+	}
+
+	// port (default based on security)
+	switch ch.security {
+	case emailSecurityNone:
+		ch.port = 25
+	case emailSecurityStartTLS:
+		ch.port = 587
+	case emailSecurityTLS:
+		ch.port = 465
+	default:
+		return nil, fmt.Errorf("email channel %s: invalid security %s (must be %s, %s, or %s)", ch.name, ch.security,
+			emailSecurityNone, emailSecurityStartTLS, emailSecurityTLS)
+	}
+	if cfg.Port != nil {
+		ch.port = *cfg.Port
+	}
+	if ch.port < 1 || ch.port > 65535 {
+		return nil, fmt.Errorf("email channel %s: invalid port %d", ch.name, ch.port)
+	}
+
+	// from & recipients
+	if !validation.EmailValid(ch.from) {
+		return nil, fmt.Errorf("email channel %s: invalid from address %s", ch.name, ch.from)
+	}
+	if len(ch.recipients) == 0 {
+		return nil, fmt.Errorf("email channel %s: at least one recipient must be specified", ch.name)
+	}
+	for _, rcpt := range ch.recipients {
+		if !validation.EmailValid(rcpt) {
+			return nil, fmt.Errorf("email channel %s: invalid recipient address %s", ch.name, rcpt)
+		}
+	}
+
+	// events
+	if cfg.Events == nil {
+		ch.events = []EventType{EventTypeOrderFailed, EventTypePostProcessingFailed}
+	} else {
+		for _, e := range cfg.Events {
+			if !isValidEventType(e) {
+				return nil, fmt.Errorf("email channel %s: invalid event type %s", ch.name, e)
+			}
+			ch.events = append(ch.events, EventType(e))
+		}
+	}
+
+	// digest
+	if cfg.Digest != nil {
+		ch.digest = *cfg.Digest
+	}
+
+	return ch, nil
+}
+
+// subscribedTo returns true if the channel should send an alert for eventType
+func (ch *emailChannel) subscribedTo(eventType EventType) bool {
+	for _, e := range ch.events {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// message returns the full message (headers and body) for an email with the
+// specified subject and body
+func (ch *emailChannel) message(subject, body string) []byte {
+	msg := &bytes.Buffer{}
+
+	fmt.Fprintf(msg, "From: %s\r\n", ch.from)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(ch.recipients, ", "))
+	fmt.Fprintf(msg, "Subject: %s\r\n", emailSubject(subject))
+	fmt.Fprintf(msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(msg, "Message-ID: <%s@certwarden>\r\n", uuid.New().String())
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+
+	// normalize line endings
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	return msg.Bytes()
+}
+
+// emailSubject returns the Subject header value for subject. CR and LF are removed
+// (so the subject can't inject headers) and non-ASCII is encoded per RFC 2047.
+func emailSubject(subject string) string {
+	subject = strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, emailSubjectPrefix+subject)
+
+	return mime.QEncoding.Encode("utf-8", subject)
+}
+
+// send sends an email with the specified subject and body to all of the
+// channel's recipients
+func (ch *emailChannel) send(subject, body string) error {
+	addr := net.JoinHostPort(ch.host, strconv.Itoa(ch.port))
+	dialer := &net.Dialer{Timeout: emailTimeout}
+	tlsConfig := &tls.Config{ServerName: ch.host}
+
+	// connect
+	var conn net.Conn
+	var err error
+	if ch.security == emailSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	c, err := smtp.NewClient(conn, ch.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	// upgrade connection
+	if ch.security == emailSecurityStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support starttls")
+		}
+		err = c.StartTLS(tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	// auth
+	if ch.username != "" {
+		err = c.Auth(smtp.PlainAuth("", ch.username, ch.password, ch.host))
+		if err != nil {
+			return err
+		}
+	}
+
+	// envelope
+	err = c.Mail(ch.from)
+	if err != nil {
+		return err
+	}
+	for _, rcpt := range ch.recipients {
+		err = c.Rcpt(rcpt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// message
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(ch.message(subject, body))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// emailEvent sends an alert for event to all email channels subscribed to its type.
+// Sending is asynchronous and failed attempts are retried a limited number of times.
+func (service *Service) emailEvent(event Event) {
+	for _, ch := range service.emailChannels {
+		if !ch.subscribedTo(event.Type) {
+			continue
+		}
+
+		service.shutdownWaitgroup.Add(1)
+		go func(ch *emailChannel) {
+			defer service.shutdownWaitgroup.Done()
+
+			subject := event.Message
+			body := eventEmailBody(event)
+
+			for attempt := 1; ; attempt++ {
+				err := ch.send(subject, body)
+				if err == nil {
+					service.logger.Debugf("notifications: event %s (%s) emailed via channel %s", event.Type, event.ID, ch.name)
+					return
+				}
+
+				if attempt >= emailMaxAttempts {
+					service.logger.Errorf("notifications: failed to email event %s (%s) via channel %s, giving up (%s)", event.Type, event.ID, ch.name, err)
+					return
+				}
+				service.logger.Warnf("notifications: failed to email event %s (%s) via channel %s, will retry (%s)", event.Type, event.ID, ch.name, err)
+
+				select {
+				case <-service.shutdownContext.Done():
+					return
+				case <-time.After(emailRetryDelay):
+				}
+			}
+		}(ch)
+	}
+}
+
+// eventEmailBody returns the plain text email body for event
+func eventEmailBody(event Event) string {
+	body := &strings.Builder{}
+
+	fmt.Fprintf(body, "%s\n\n", event.Message)
+	fmt.Fprintf(body, "Event: %s\n", event.Type)
+	fmt.Fprintf(body, "Event ID: %s\n", event.ID)
+	fmt.Fprintf(body, "Time: %s\n", time.Unix(int64(event.CreatedAt), 0).Format(time.RFC1123))
+
+	if len(event.Data) > 0 {
+		body.WriteString("\nDetails:\n")
+
+		keys := make([]string, 0, len(event.Data))
+		for k := range event.Data {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			fmt.Fprintf(body, "  %s: %v\n", k, event.Data[k])
+		}
+	}
+
+	return body.String()
+}
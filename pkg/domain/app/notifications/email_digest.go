@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExpiringCert is a certificate included in the expiring certificates digest
+type ExpiringCert struct {
+	CertificateID      int
+	CertificateName    string
+	CertificateSubject string
+	OrderID            int
+	ValidTo            time.Time
+	ExpiringAt         time.Time
+}
+
+// ExpiringCertsSource returns the certificates that are expiring or that will become
+// expiring within lookahead
+type ExpiringCertsSource func(lookahead time.Duration) ([]ExpiringCert, error)
+
+// StartEmailDigestService starts a go routine that emails a daily digest of expiring
+// certificates to all email channels that have the digest enabled. It is a no-op if
+// no channels have the digest enabled.
+func (service *Service) StartEmailDigestService(source ExpiringCertsSource) {
+	// channels that want the digest
+	digestChannels := []*emailChannel{}
+	for _, ch := range service.emailChannels {
+		if ch.digest {
+			digestChannels = append(digestChannels, ch)
+		}
+	}
+	if len(digestChannels) == 0 {
+		return
+	}
+
+	digestHour := *service.emailCfg.DigestTimeHour
+	digestMinute := *service.emailCfg.DigestTimeMinute
+	lookahead := time.Duration(*service.emailCfg.DigestLookaheadDays) * 24 * time.Hour
+
+	// log start and update wg
+	service.logger.Infof("notifications: starting expiring certificate email digest service; digest will be sent every day at %02d:%02d "+
+		"to %d channel(s)", digestHour, digestMinute, len(digestChannels))
+	service.shutdownWaitgroup.Add(1)
+
+	// service routine
+	go func() {
+		defer service.shutdownWaitgroup.Done()
+		var nextRunTime time.Time
+
+		// indefinite service loop
+		for {
+			// run time for today
+			nextRunTime = time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(),
+				digestHour, digestMinute, 0, 0, time.Local)
+
+			// if today's run already passed, run tomorrow
+			if !nextRunTime.After(time.Now()) {
+				nextRunTime = nextRunTime.Add(24 * time.Hour)
+			}
+
+			// sleep or wait for shutdown context to be done
+			delayTimer := time.NewTimer(time.Until(nextRunTime))
+
+			select {
+			case <-service.shutdownContext.Done():
+				// ensure timer releases resources
+				if !delayTimer.Stop() {
+					<-delayTimer.C
+				}
+
+				// close routine
+				service.logger.Info("notifications: expiring certificate email digest service shutdown complete")
+				return
+
+			case <-delayTimer.C:
+				// continue and run
+			}
+
+			expiringCerts, err := source(lookahead)
+			if err != nil {
+				service.logger.Errorf("notifications: failed to get expiring certificates for email digest (%s)", err)
+				continue
+			}
+
+			// nothing to report
+			if len(expiringCerts) == 0 {
+				service.logger.Debug("notifications: no expiring certificates, skipping email digest")
+				continue
+			}
+
+			subject, body := expiringCertsDigest(expiringCerts, time.Now())
+			for _, ch := range digestChannels {
+				err = ch.send(subject, body)
+				if err != nil {
+					service.logger.Errorf("notifications: failed to send email digest via channel %s (%s)", ch.name, err)
+					continue
+				}
+				service.logger.Debugf("notifications: email digest sent via channel %s", ch.name)
+			}
+		}
+	}()
+}
+
+// expiringCertsDigest returns the email subject and body for the digest of expiringCerts
+func expiringCertsDigest(expiringCerts []ExpiringCert, now time.Time) (subject string, body string) {
+	subject = fmt.Sprintf("%d certificate(s) expiring", len(expiringCerts))
+
+	b := &strings.Builder{}
+	b.WriteString("The following certificates are expiring (or will be soon):\n")
+
+	for _, cert := range expiringCerts {
+		daysRemaining := int(cert.ValidTo.Sub(now).Hours() / 24)
+
+		fmt.Fprintf(b, "\n%s (%s)\n", cert.CertificateName, cert.CertificateSubject)
+		fmt.Fprintf(b, "  Certificate ID: %d\n", cert.CertificateID)
+		fmt.Fprintf(b, "  Order ID: %d\n", cert.OrderID)
+		fmt.Fprintf(b, "  Valid To: %s (%d day(s) remaining)\n", cert.ValidTo.Format(time.RFC1123), daysRemaining)
+		fmt.Fprintf(b, "  Expiring At: %s\n", cert.ExpiringAt.Format(time.RFC1123))
+	}
+
+	return subject, b.String()
+}
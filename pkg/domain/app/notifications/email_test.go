@@ -0,0 +1,179 @@
+package notifications
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSmtpSession is what a fakeSmtpServer received during a single session
+type fakeSmtpSession struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// fakeSmtpServer starts a minimal plaintext SMTP server on localhost that accepts a
+// single session. It returns the port it is listening on and a channel that receives
+// the session once it is complete.
+func fakeSmtpServer(t *testing.T) (int, <-chan fakeSmtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	sessionChan := make(chan fakeSmtpSession, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		session := fakeSmtpSession{}
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost ESMTP fake")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				session.recipients = append(session.recipients, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				data := &strings.Builder{}
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				session.data = data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				sessionChan <- session
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, sessionChan
+}
+
+func TestEmail_Send(t *testing.T) {
+	port, sessionChan := fakeSmtpServer(t)
+
+	security := emailSecurityNone
+	ch, err := newEmailChannel(EmailChannelConfig{
+		Name:       "test",
+		Host:       "127.0.0.1",
+		Port:       &port,
+		Security:   &security,
+		From:       "certwarden@example.com",
+		Recipients: []string{"admin1@example.com", "admin2@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := newEvent(EventTypeOrderFailed, "order 5 for certificate test failed", map[string]any{"order_id": 5})
+	err = ch.send(event.Message, eventEmailBody(event))
+	if err != nil {
+		t.Fatalf("send failed (%s)", err)
+	}
+
+	session := <-sessionChan
+	if session.from != "certwarden@example.com" {
+		t.Errorf("unexpected from %s", session.from)
+	}
+	if strings.Join(session.recipients, ",") != "admin1@example.com,admin2@example.com" {
+		t.Errorf("unexpected recipients %v", session.recipients)
+	}
+	if !strings.Contains(session.data, "Subject: "+emailSubjectPrefix+event.Message+"\r\n") {
+		t.Errorf("subject missing from message:\n%s", session.data)
+	}
+	if !strings.Contains(session.data, "order_id: 5\r\n") {
+		t.Errorf("event data missing from message:\n%s", session.data)
+	}
+}
+
+func TestEmail_Subject(t *testing.T) {
+	// header injection
+	subject := emailSubject("bad\r\nBcc: victim@example.com")
+	if strings.ContainsAny(subject, "\r\n") {
+		t.Errorf("subject contains CR or LF: %q", subject)
+	}
+
+	// non-ascii is encoded
+	subject = emailSubject("certificate café failed")
+	if !strings.HasPrefix(subject, "=?utf-8?q?") {
+		t.Errorf("non-ascii subject not encoded: %q", subject)
+	}
+}
+
+func TestEmail_NewChannel(t *testing.T) {
+	valid := EmailChannelConfig{
+		Name:       "test",
+		Host:       "smtp.example.com",
+		From:       "certwarden@example.com",
+		Recipients: []string{"admin@example.com"},
+	}
+
+	// defaults
+	ch, err := newEmailChannel(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.security != emailSecurityStartTLS || ch.port != 587 || !ch.digest {
+		t.Errorf("unexpected defaults (security: %s, port: %d, digest: %t)", ch.security, ch.port, ch.digest)
+	}
+	if !ch.subscribedTo(EventTypeOrderFailed) || !ch.subscribedTo(EventTypePostProcessingFailed) || ch.subscribedTo(EventTypeOrderValid) {
+		t.Errorf("unexpected default events %v", ch.events)
+	}
+
+	// invalid configs
+	badSecurity := "ssl"
+	badPort := 70000
+	invalid := map[string]func(cfg *EmailChannelConfig){
+		"no host":        func(cfg *EmailChannelConfig) { cfg.Host = "" },
+		"bad security":   func(cfg *EmailChannelConfig) { cfg.Security = &badSecurity },
+		"bad port":       func(cfg *EmailChannelConfig) { cfg.Port = &badPort },
+		"bad from":       func(cfg *EmailChannelConfig) { cfg.From = "certwarden" },
+		"no recipients":  func(cfg *EmailChannelConfig) { cfg.Recipients = nil },
+		"bad recipient":  func(cfg *EmailChannelConfig) { cfg.Recipients = []string{"admin"} },
+		"bad event type": func(cfg *EmailChannelConfig) { cfg.Events = []string{"order_bogus"} },
+	}
+	for name, modify := range invalid {
+		cfg := valid
+		modify(&cfg)
+		_, err = newEmailChannel(cfg)
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package notifications
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is the type of a notification event
+type EventType string
+
+// event types
+const (
+	EventTypeOrderValid               EventType = "order_valid"
+	EventTypeOrderFailed              EventType = "order_failed"
+	EventTypeCertExpiring             EventType = "certificate_expiring"
+	EventTypePostProcessingFailed     EventType = "post_processing_failed"
+	EventTypeCertRevoked              EventType = "certificate_revoked"
+	EventTypeAccountDeactivated       EventType = "account_deactivated"
+	EventTypeAccountKeyRotated        EventType = "account_key_rotated"
+	EventTypeAccountKeyRotationFailed EventType = "account_key_rotation_failed"
+	EventTypeBackupSucceeded          EventType = "backup_succeeded"
+	EventTypeBackupFailed             EventType = "backup_failed"
+	EventTypeTest                     EventType = "test"
+)
+
+// ListOfEventTypes returns all of the event types that targets can subscribe to
+func ListOfEventTypes() []EventType {
+	return []EventType{
+		EventTypeOrderValid,
+		EventTypeOrderFailed,
+		EventTypeCertExpiring,
+		EventTypePostProcessingFailed,
+		EventTypeCertRevoked,
+		EventTypeAccountDeactivated,
+		EventTypeAccountKeyRotated,
+		EventTypeAccountKeyRotationFailed,
+		EventTypeBackupSucceeded,
+		EventTypeBackupFailed,
+	}
+}
+
+// isValidEventType returns true if eventType is a type that targets can subscribe to
+func isValidEventType(eventType string) bool {
+	for _, t := range ListOfEventTypes() {
+		if string(t) == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is a single notification event; it is the JSON body sent to webhooks
+type Event struct {
+	ID        string         `json:"id"`
+	Type      EventType      `json:"type"`
+	CreatedAt int            `json:"created_at"`
+	Message   string         `json:"message"`
+	Data      map[string]any `json:"data"`
+}
+
+// newEvent creates a new Event of the specified type
+func newEvent(eventType EventType, message string, data map[string]any) Event {
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	return Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: int(time.Now().Unix()),
+		Message:   message,
+		Data:      data,
+	}
+}
+
+// Notify sends an event to all enabled targets (webhooks and email channels) that are
+// subscribed to eventType. Deliveries are queued and sent asynchronously, so this does
+// not block on the targets. It is safe to call on a nil Service (e.g. before the
+// service has been created), in which case it is a no-op.
+func (service *Service) Notify(eventType EventType, message string, data map[string]any) {
+	if service == nil {
+		return
+	}
+
+	event := newEvent(eventType, message, data)
+
+	// email
+	service.emailEvent(event)
+
+	// webhooks
+	webhooks, err := service.storage.GetEnabledWebhooks()
+	if err != nil {
+		service.logger.Errorf("notifications: failed to get webhooks for event %s (%s)", event.Type, err)
+		return
+	}
+
+	queued := 0
+	for i := range webhooks {
+		if !webhooks[i].subscribedTo(eventType) {
+			continue
+		}
+
+		err = service.queueDelivery(webhooks[i], event)
+		if err != nil {
+			service.logger.Errorf("notifications: failed to queue event %s for webhook %s (%s)", event.Type, webhooks[i].Name, err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		service.logger.Debugf("notifications: event %s (%s) queued for %d webhook(s)", event.Type, event.ID, queued)
+		service.wake()
+	}
+}
+
+// queueDelivery saves a new pending delivery of event to webhook
+func (service *Service) queueDelivery(webhook Webhook, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := int(time.Now().Unix())
+	_, err = service.storage.PostNewDelivery(NewDeliveryPayload{
+		WebhookID:     webhook.ID,
+		EventID:       event.ID,
+		EventType:     string(event.Type),
+		Payload:       string(payload),
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+
+	return err
+}
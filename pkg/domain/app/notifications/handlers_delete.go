@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// DeleteWebhook deletes a webhook and its delivery history from storage
+func (service *Service) DeleteWebhook(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	webhook, outErr := service.getWebhook(id)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	// delete from storage
+	err = service.storage.DeleteWebhook(id)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, webhook.detailedResponse(), nil)
+
+	// write response
+	response := &output.JsonResponse{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("deleted webhook (id: %d)", id),
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// emailChannel returns the email channel with the specified name, or nil if there
+// is no such channel
+func (service *Service) emailChannel(name string) *emailChannel {
+	for _, ch := range service.emailChannels {
+		if ch.name == name {
+			return ch
+		}
+	}
+
+	return nil
+}
+
+// testEmailPayload is the payload to send a test email
+type testEmailPayload struct {
+	// Channel is the name of the channel to test; if not specified, all channels
+	// are tested
+	Channel *string `json:"channel"`
+}
+
+// testEmailResult is the result of sending a test email via one channel
+type testEmailResult struct {
+	Channel string `json:"channel"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// testEmailResponse is the response to sending test emails
+type testEmailResponse struct {
+	output.JsonResponse
+	Results []testEmailResult `json:"results"`
+}
+
+// SendTestEmail sends a test email via the specified channel (or all channels if
+// none is specified). Sending is synchronous so the result of each attempt can be
+// returned to the client.
+func (service *Service) SendTestEmail(w http.ResponseWriter, r *http.Request) *output.Error {
+	var payload testEmailPayload
+
+	// decode body into payload (body is optional)
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil && !errors.Is(err, io.EOF) {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	channels := service.emailChannels
+	if payload.Channel != nil {
+		ch := service.emailChannel(*payload.Channel)
+		if ch == nil {
+			service.logger.Debugf("email channel %s does not exist", *payload.Channel)
+			return output.ErrValidationFailed
+		}
+		channels = []*emailChannel{ch}
+	}
+	if len(channels) == 0 {
+		service.logger.Debug("no email channels are configured")
+		return output.ErrValidationFailed
+	}
+	// end validation
+
+	// send
+	results := []testEmailResult{}
+	failed := 0
+	for _, ch := range channels {
+		result := testEmailResult{
+			Channel: ch.name,
+			Success: true,
+		}
+
+		err = ch.send("test email", "This is a test email from CertWarden.\n")
+		if err != nil {
+			service.logger.Errorf("notifications: failed to send test email via channel %s (%s)", ch.name, err)
+			result.Success = false
+			result.Error = err.Error()
+			failed++
+		}
+
+		results = append(results, result)
+	}
+
+	// write response
+	response := &testEmailResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("sent test email via %d of %d channel(s)", len(channels)-failed, len(channels))
+	response.Results = results
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/pagination_sort"
+	"certwarden-backend/pkg/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// allWebhooksResponse provides the json response struct
+// to answer a query for a portion of the webhooks
+type allWebhooksResponse struct {
+	output.JsonResponse
+	TotalWebhooks int                      `json:"total_records"`
+	Webhooks      []webhookSummaryResponse `json:"webhooks"`
+}
+
+// GetAllWebhooks returns all of the webhooks in storage as JSON
+func (service *Service) GetAllWebhooks(w http.ResponseWriter, r *http.Request) *output.Error {
+	// parse pagination and sorting
+	query := pagination_sort.ParseRequestToQuery(r)
+
+	// get webhooks from storage
+	webhooks, totalRows, err := service.storage.GetAllWebhooks(query)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// populate summaries for output
+	outputWebhooks := []webhookSummaryResponse{}
+	for i := range webhooks {
+		outputWebhooks = append(outputWebhooks, webhooks[i].summaryResponse())
+	}
+
+	// write response
+	response := &allWebhooksResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.TotalWebhooks = totalRows
+	response.Webhooks = outputWebhooks
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+type webhookResponse struct {
+	output.JsonResponse
+	Webhook webhookDetailedResponse `json:"webhook"`
+}
+
+// GetOneWebhook returns a single webhook as JSON
+func (service *Service) GetOneWebhook(w http.ResponseWriter, r *http.Request) *output.Error {
+	// params
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// if id is new, provide some info
+	if validation.IsIdNew(id) {
+		return service.GetNewWebhookOptions(w, r)
+	}
+
+	// get the webhook from storage (and validate id)
+	webhook, outErr := service.getWebhook(id)
+	if outErr != nil {
+		return outErr
+	}
+
+	// write response
+	response := &webhookResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Webhook = webhook.detailedResponse()
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// new webhook options
+// used to return info about valid options when making a new webhook
+type newWebhookOptions struct {
+	output.JsonResponse
+	WebhookOptions struct {
+		EventTypes []EventType `json:"event_types"`
+	} `json:"webhook_options"`
+}
+
+// GetNewWebhookOptions returns configuration options for a new webhook as JSON
+func (service *Service) GetNewWebhookOptions(w http.ResponseWriter, r *http.Request) *output.Error {
+	// write response
+	response := &newWebhookOptions{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.WebhookOptions.EventTypes = ListOfEventTypes()
+
+	err := service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// deliveriesResponse provides the json response struct
+// to answer a query for a portion of the deliveries
+type deliveriesResponse struct {
+	output.JsonResponse
+	TotalDeliveries int                `json:"total_records"`
+	Deliveries      []deliveryResponse `json:"deliveries"`
+}
+
+// GetAllDeliveries returns the delivery history of all webhooks as JSON
+func (service *Service) GetAllDeliveries(w http.ResponseWriter, r *http.Request) *output.Error {
+	return service.writeDeliveries(w, r, 0)
+}
+
+// GetWebhookDeliveries returns the delivery history of a single webhook as JSON
+func (service *Service) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) *output.Error {
+	// params
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validate webhook exists
+	_, outErr := service.getWebhook(id)
+	if outErr != nil {
+		return outErr
+	}
+
+	return service.writeDeliveries(w, r, id)
+}
+
+// writeDeliveries writes the deliveries for webhookId (or all webhooks, if 0) as JSON
+func (service *Service) writeDeliveries(w http.ResponseWriter, r *http.Request, webhookId int) *output.Error {
+	// parse pagination and sorting
+	query := pagination_sort.ParseRequestToQuery(r)
+
+	deliveries, totalRows, err := service.storage.GetDeliveries(query, webhookId)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	outputDeliveries := []deliveryResponse{}
+	for i := range deliveries {
+		outputDeliveries = append(outputDeliveries, deliveries[i].response())
+	}
+
+	// write response
+	response := &deliveriesResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.TotalDeliveries = totalRows
+	response.Deliveries = outputDeliveries
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
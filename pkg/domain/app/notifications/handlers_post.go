@@ -0,0 +1,151 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/randomness"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// NewWebhookPayload is a struct for posting a new webhook
+type NewWebhookPayload struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	URL         *string  `json:"url"`
+	Secret      *string  `json:"secret"`
+	Events      []string `json:"events"`
+	Enabled     *bool    `json:"enabled"`
+	CreatedAt   int      `json:"-"`
+	UpdatedAt   int      `json:"-"`
+}
+
+// PostNewWebhook creates a new webhook and saves it to storage
+func (service *Service) PostNewWebhook(w http.ResponseWriter, r *http.Request) *output.Error {
+	var payload NewWebhookPayload
+
+	// decode body into payload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// do validation
+	// name (missing or invalid)
+	if payload.Name == nil || !service.nameValid(*payload.Name, nil) {
+		service.logger.Debug(ErrNameBad)
+		return output.ErrValidationFailed
+	}
+	// description (if none, set to blank)
+	if payload.Description == nil {
+		payload.Description = new(string)
+	}
+	// url
+	if payload.URL == nil || !urlValid(*payload.URL) {
+		service.logger.Debug(ErrUrlBad)
+		return output.ErrValidationFailed
+	}
+	// secret (generate if not specified)
+	if payload.Secret == nil || *payload.Secret == "" {
+		payload.Secret = new(string)
+		*payload.Secret, err = randomness.GenerateApiKey()
+		if err != nil {
+			service.logger.Error(err)
+			return output.ErrInternal
+		}
+	} else if len(*payload.Secret) < 10 {
+		service.logger.Debug(ErrSecretBad)
+		return output.ErrValidationFailed
+	}
+	// events (none = all)
+	if payload.Events == nil {
+		payload.Events = []string{}
+	}
+	err = eventsValid(payload.Events)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+	// enabled (default true)
+	if payload.Enabled == nil {
+		payload.Enabled = new(bool)
+		*payload.Enabled = true
+	}
+	// end validation
+
+	// add additional details to the payload before saving
+	payload.CreatedAt = int(time.Now().Unix())
+	payload.UpdatedAt = payload.CreatedAt
+
+	// save to storage
+	newWebhook, err := service.storage.PostNewWebhook(payload)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, nil, newWebhook.detailedResponse())
+
+	// write response
+	response := &webhookResponse{}
+	response.StatusCode = http.StatusCreated
+	response.Message = "created webhook"
+	response.Webhook = newWebhook.detailedResponse()
+	// full secret is only returned on create so it can be configured on the receiver
+	response.Webhook.Secret = newWebhook.Secret
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// SendTestEvent queues a test event for delivery to the specified webhook. The
+// result of the delivery is available in the webhook's delivery history.
+func (service *Service) SendTestEvent(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	webhook, outErr := service.getWebhook(id)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	// queue test event (regardless of webhook enabled or event filter)
+	event := newEvent(EventTypeTest, "this is a test event from CertWarden", nil)
+	err = service.queueDelivery(webhook, event)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	service.wake()
+
+	// write response
+	response := &output.JsonResponse{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("queued test event %s for webhook (id: %d)", event.ID, webhook.ID),
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
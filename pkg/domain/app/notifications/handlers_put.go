@@ -0,0 +1,105 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// UpdateWebhookPayload is the struct for editing an existing webhook. Only
+// non-nil fields are updated.
+type UpdateWebhookPayload struct {
+	ID          int       `json:"-"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	URL         *string   `json:"url"`
+	Secret      *string   `json:"secret"`
+	Events      *[]string `json:"events"`
+	Enabled     *bool     `json:"enabled"`
+	UpdatedAt   int       `json:"-"`
+}
+
+// PutWebhookUpdate updates a webhook that already exists in storage.
+// Only fields received in the payload (non-nil) are updated.
+func (service *Service) PutWebhookUpdate(w http.ResponseWriter, r *http.Request) *output.Error {
+	// parse payload
+	var payload UpdateWebhookPayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	payload.ID, err = strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	// id
+	oldWebhook, outErr := service.getWebhook(payload.ID)
+	if outErr != nil {
+		return outErr
+	}
+	// name (optional - check if not nil)
+	if payload.Name != nil && !service.nameValid(*payload.Name, &payload.ID) {
+		service.logger.Debug(ErrNameBad)
+		return output.ErrValidationFailed
+	}
+	// url (optional)
+	if payload.URL != nil && !urlValid(*payload.URL) {
+		service.logger.Debug(ErrUrlBad)
+		return output.ErrValidationFailed
+	}
+	// secret (optional; if the redacted secret was sent back, keep the current secret)
+	if payload.Secret != nil && *payload.Secret == output.RedactString(oldWebhook.Secret) {
+		payload.Secret = nil
+	}
+	if payload.Secret != nil && len(*payload.Secret) < 10 {
+		service.logger.Debug(ErrSecretBad)
+		return output.ErrValidationFailed
+	}
+	// events (optional)
+	if payload.Events != nil {
+		err = eventsValid(*payload.Events)
+		if err != nil {
+			service.logger.Debug(err)
+			return output.ErrValidationFailed
+		}
+	}
+	// Description and Enabled do not need validation
+	// end validation
+
+	// add additional details to the payload before saving
+	payload.UpdatedAt = int(time.Now().Unix())
+
+	// save to storage
+	updatedWebhook, err := service.storage.PutWebhookUpdate(payload)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, oldWebhook.detailedResponse(), updatedWebhook.detailedResponse())
+
+	// write response
+	response := &webhookResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "updated webhook"
+	response.Webhook = updatedWebhook.detailedResponse()
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
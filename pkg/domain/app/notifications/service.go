@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/httpclient"
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/pagination_sort"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var errServiceComponent = errors.New("notifications: necessary service component is missing")
+
+// App interface is for connecting to the main app
+type App interface {
+	GetLogger() *zap.SugaredLogger
+	GetOutputter() *output.Service
+	GetNotificationsStorage() Storage
+	GetHttpClient() *httpclient.Client
+	GetShutdownContext() context.Context
+	GetShutdownWaitGroup() *sync.WaitGroup
+}
+
+// Storage interface for storage functions
+type Storage interface {
+	// webhooks
+	GetAllWebhooks(q pagination_sort.Query) (webhooks []Webhook, totalRows int, err error)
+	GetOneWebhookById(id int) (Webhook, error)
+	GetOneWebhookByName(name string) (Webhook, error)
+	GetEnabledWebhooks() ([]Webhook, error)
+
+	PostNewWebhook(NewWebhookPayload) (Webhook, error)
+	PutWebhookUpdate(UpdateWebhookPayload) (Webhook, error)
+	DeleteWebhook(id int) error
+
+	// deliveries
+	GetDeliveries(q pagination_sort.Query, webhookId int) (deliveries []Delivery, totalRows int, err error)
+	GetDueDeliveries(nowUnix int, limit int) ([]Delivery, error)
+
+	PostNewDelivery(NewDeliveryPayload) (newId int, err error)
+	PutDeliveryAttempt(DeliveryAttemptPayload) error
+}
+
+// Service struct
+type Service struct {
+	shutdownContext context.Context
+	logger          *zap.SugaredLogger
+	output          *output.Service
+	storage         Storage
+	httpClient      *httpclient.Client
+
+	shutdownWaitgroup *sync.WaitGroup
+
+	// email
+	emailCfg      EmailConfig
+	emailChannels []*emailChannel
+
+	// wakeDispatcher signals the dispatcher to immediately check for due deliveries
+	wakeDispatcher chan struct{}
+}
+
+// NewService creates a new notifications service and starts the webhook delivery
+// dispatcher
+func NewService(app App, cfg *Config) (*Service, error) {
+	service := new(Service)
+
+	// shutdown context & wg
+	service.shutdownContext = app.GetShutdownContext()
+	service.shutdownWaitgroup = app.GetShutdownWaitGroup()
+
+	// logger
+	service.logger = app.GetLogger()
+	if service.logger == nil {
+		return nil, errServiceComponent
+	}
+
+	// output service
+	service.output = app.GetOutputter()
+	if service.output == nil {
+		return nil, errServiceComponent
+	}
+
+	// storage
+	service.storage = app.GetNotificationsStorage()
+	if service.storage == nil {
+		return nil, errServiceComponent
+	}
+
+	// http client
+	service.httpClient = app.GetHttpClient()
+	if service.httpClient == nil {
+		return nil, errServiceComponent
+	}
+
+	// email channels
+	service.emailCfg = cfg.Email
+	for _, chCfg := range cfg.Email.Channels {
+		ch, err := newEmailChannel(chCfg)
+		if err != nil {
+			return nil, err
+		}
+		if service.emailChannel(ch.name) != nil {
+			return nil, fmt.Errorf("email channel %s: name is not unique", ch.name)
+		}
+		service.emailChannels = append(service.emailChannels, ch)
+	}
+
+	// start webhook delivery
+	service.wakeDispatcher = make(chan struct{}, 1)
+	service.startDispatcher(service.shutdownContext, service.shutdownWaitgroup)
+
+	return service, nil
+}
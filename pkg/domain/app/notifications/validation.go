@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/storage"
+	"certwarden-backend/pkg/validation"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var (
+	ErrIdBad      = errors.New("webhook id is invalid")
+	ErrNameBad    = errors.New("webhook name is not valid")
+	ErrUrlBad     = errors.New("webhook url is not valid (must be an absolute http or https url)")
+	ErrSecretBad  = errors.New("webhook secret is not valid (must be at least 10 chars in length)")
+	errEventBadFn = func(event string) error { return fmt.Errorf("webhook event %s is not a valid event type", event) }
+)
+
+// getWebhook returns the Webhook for the specified id or an error
+func (service *Service) getWebhook(id int) (Webhook, *output.Error) {
+	// basic check
+	if !validation.IsIdExistingValidRange(id) {
+		service.logger.Debug(ErrIdBad)
+		return Webhook{}, output.ErrValidationFailed
+	}
+
+	// get from storage
+	webhook, err := service.storage.GetOneWebhookById(id)
+	if err != nil {
+		// special error case for no record found
+		if errors.Is(err, storage.ErrNoRecord) {
+			service.logger.Debug(err)
+			return Webhook{}, output.ErrNotFound
+		} else {
+			service.logger.Error(err)
+			return Webhook{}, output.ErrStorageGeneric
+		}
+	}
+
+	return webhook, nil
+}
+
+// nameValid returns true if the specified webhook name is acceptable and not already
+// in use by another webhook. If an id is specified, the name will also be accepted if
+// the name is already in use by the specified id.
+func (service *Service) nameValid(name string, webhookId *int) bool {
+	// basic character/length check
+	if !validation.NameValid(name) {
+		return false
+	}
+
+	// make sure the name isn't already in use in storage
+	webhook, err := service.storage.GetOneWebhookByName(name)
+	if errors.Is(err, storage.ErrNoRecord) {
+		// no rows means name is not in use
+		return true
+	} else if err != nil {
+		// any other error
+		return false
+	}
+
+	// if the returned webhook is the webhook being edited, name is ok
+	if webhookId != nil && webhook.ID == *webhookId {
+		return true
+	}
+
+	return false
+}
+
+// urlValid returns true if the url is an absolute http or https url
+func urlValid(webhookUrl string) bool {
+	u, err := url.Parse(webhookUrl)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// eventsValid returns an error if any of the events are not valid event types
+func eventsValid(events []string) error {
+	for i := range events {
+		if !isValidEventType(events[i]) {
+			return errEventBadFn(events[i])
+		}
+	}
+
+	return nil
+}
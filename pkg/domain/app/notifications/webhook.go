@@ -0,0 +1,80 @@
+package notifications
+
+import "certwarden-backend/pkg/output"
+
+// Webhook is a single webhook notification target
+type Webhook struct {
+	ID          int
+	Name        string
+	Description string
+	URL         string
+	Secret      string
+	// Events the webhook is subscribed to; if empty, the webhook receives all events
+	Events    []string
+	Enabled   bool
+	CreatedAt int
+	UpdatedAt int
+}
+
+// subscribedTo returns true if the webhook should receive events of eventType
+func (webhook Webhook) subscribedTo(eventType EventType) bool {
+	// test events are always sent (they're only sent to one specific webhook)
+	if eventType == EventTypeTest {
+		return true
+	}
+
+	// no filter = all events
+	if len(webhook.Events) == 0 {
+		return true
+	}
+
+	for i := range webhook.Events {
+		if webhook.Events[i] == string(eventType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// webhookSummaryResponse is a JSON response containing only fields
+// desired for the summary
+type webhookSummaryResponse struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Enabled     bool     `json:"enabled"`
+}
+
+func (webhook Webhook) summaryResponse() webhookSummaryResponse {
+	return webhookSummaryResponse{
+		ID:          webhook.ID,
+		Name:        webhook.Name,
+		Description: webhook.Description,
+		URL:         webhook.URL,
+		Events:      webhook.Events,
+		Enabled:     webhook.Enabled,
+	}
+}
+
+// webhookDetailedResponse is a JSON response containing all fields
+// that can be returned as JSON
+type webhookDetailedResponse struct {
+	webhookSummaryResponse
+	Secret    string `json:"secret"`
+	CreatedAt int    `json:"created_at"`
+	UpdatedAt int    `json:"updated_at"`
+}
+
+// detailedResponse returns the detailed response with the secret redacted (the full
+// secret is only returned when the webhook is created)
+func (webhook Webhook) detailedResponse() webhookDetailedResponse {
+	return webhookDetailedResponse{
+		webhookSummaryResponse: webhook.summaryResponse(),
+		Secret:                 output.RedactString(webhook.Secret),
+		CreatedAt:              webhook.CreatedAt,
+		UpdatedAt:              webhook.UpdatedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWebhook_DetailedResponseRedactsSecret(t *testing.T) {
+	webhook := Webhook{ID: 1, Name: "test", Secret: "abcdefghijklmnopqrstuvwxyz"}
+
+	resp := webhook.detailedResponse()
+	if resp.Secret == webhook.Secret || strings.Contains(resp.Secret, "ghijklmnop") {
+		t.Errorf("secret was not redacted (got %s)", resp.Secret)
+	}
+}
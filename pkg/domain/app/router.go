@@ -1,6 +1,7 @@
 package app
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/domain/app/auth"
 	"certwarden-backend/pkg/output"
 	"net/http"
@@ -18,6 +19,7 @@ type router struct {
 	logger *zap.SugaredLogger
 	output *output.Service
 	auth   *auth.Service
+	audit  *audit.Service
 	// actual router
 	r *httprouter.Router
 	// config options
@@ -43,6 +45,11 @@ func (router *router) handleAPIRouteInsecure(method string, path string, handler
 
 // handleAPIRouteSecure creates a route on router intended for an authenticated API route
 func (router *router) handleAPIRouteSecure(method string, path string, handlerFunc handlerFunc) {
+	// Audit (any method that may change something)
+	if method != http.MethodGet && method != http.MethodHead {
+		handlerFunc = middlewareApplyAudit(handlerFunc, method, path, router.audit)
+	}
+
 	// JWT Auth
 	handlerFunc = middlewareApplyAuthJWT(handlerFunc, router.auth)
 
@@ -56,11 +63,17 @@ func (router *router) handleAPIRouteSecure(method string, path string, handlerFu
 	router.r.HandlerFunc(method, path, httpHandlerFunc)
 }
 
-// handleAPIRouteSecure creates a route on router intended for an authenticated API route WITH
-// enhanced logging to ensure any time these routes are accessed they are explicitly logged
+// handleAPIRouteSecureSensitive creates a route on router intended for an authenticated API route WITH
+// enhanced logging to ensure any time these routes are accessed they are explicitly logged. These
+// routes are also subject to the configured TOTP enforcement for sensitive routes.
 func (router *router) handleAPIRouteSecureSensitive(method string, path string, handlerFunc handlerFunc) {
-	// JWT Auth
-	handlerFunc = middlewareApplyAuthJWT(handlerFunc, router.auth)
+	// Audit (any method that may change something)
+	if method != http.MethodGet && method != http.MethodHead {
+		handlerFunc = middlewareApplyAudit(handlerFunc, method, path, router.audit)
+	}
+
+	// JWT Auth (+ sensitive route requirements)
+	handlerFunc = middlewareApplyAuthJWTSensitive(handlerFunc, router.auth)
 
 	// CORS
 	handlerFunc = middlewareApplyCORS(handlerFunc, router.permittedCrossOrigins)
@@ -23,6 +23,7 @@ func (app *Application) makeRouterAndRoutes() {
 		logger:                app.logger.SugaredLogger,
 		output:                app.output,
 		auth:                  app.auth,
+		audit:                 app.audit,
 		permittedCrossOrigins: app.config.CORSPermittedCrossOrigins,
 		r:                     httprouter.New(),
 	}
@@ -34,6 +35,8 @@ func (app *Application) makeRouterAndRoutes() {
 	// app auth - insecure as these give clients the access_token to access secure routes
 	// validates with user/password
 	router.handleAPIRouteInsecure(http.MethodPost, apiUrlPath+"/v1/app/auth/login", app.auth.LoginUsingUserPwPayload)
+	// validates with totp login token + totp code (second step of login, if user has totp)
+	router.handleAPIRouteInsecure(http.MethodPost, apiUrlPath+"/v1/app/auth/login/totp", app.auth.LoginUsingTotpPayload)
 	// validates with cookie
 	router.handleAPIRouteInsecure(http.MethodPost, apiUrlPath+"/v1/app/auth/refresh", app.auth.RefreshUsingCookie)
 
@@ -41,13 +44,42 @@ func (app *Application) makeRouterAndRoutes() {
 	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/app/auth/changepassword", app.auth.ChangePassword)
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/auth/logout", app.auth.Logout)
 
+	// app auth - totp (two-factor); these are not sensitive routes since they do their own totp
+	// validation (and a user without totp must be able to enroll)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/auth/totp", app.auth.GetTotpStatus)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/auth/totp", app.auth.BeginTotpEnrollment)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/auth/totp/confirm", app.auth.ConfirmTotpEnrollment)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/auth/totp/recovery-codes", app.auth.RegenerateTotpRecoveryCodes)
+	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/app/auth/totp", app.auth.DisableTotp)
+
 	// status
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/status", app.statusHandler)
 
+	// metrics - insecure for Prometheus scraping (optionally protected by bearer token)
+	if *app.config.Metrics.Enable {
+		router.handleAPIRouteInsecure(http.MethodGet, apiUrlPath+"/metrics", app.metricsHandler)
+	}
+
 	// app
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/log", app.viewCurrentLogHandler)
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/logs", app.downloadLogsHandler)
 
+	// audit
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/audit", app.audit.GetAuditEvents)
+	router.handleAPIRouteSecureDownload(http.MethodGet, apiUrlPath+"/v1/app/audit/export", app.audit.ExportAuditEvents)
+
+	// notifications
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/notifications/webhooks", app.notifications.GetAllWebhooks)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/notifications/webhooks/:id", app.notifications.GetOneWebhook)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/notifications/webhooks/:id/deliveries", app.notifications.GetWebhookDeliveries)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/app/notifications/deliveries", app.notifications.GetAllDeliveries)
+
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/notifications/webhooks", app.notifications.PostNewWebhook)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/notifications/webhooks/:id/test", app.notifications.SendTestEvent)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/notifications/email/test", app.notifications.SendTestEmail)
+	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/app/notifications/webhooks/:id", app.notifications.PutWebhookUpdate)
+	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/app/notifications/webhooks/:id", app.notifications.DeleteWebhook)
+
 	// app control
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/control/shutdown", app.doShutdownHandler)
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/app/control/restart", app.doRestartHandler)
@@ -104,11 +136,20 @@ func (app *Application) makeRouterAndRoutes() {
 	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/acmeaccounts/:id", app.accounts.PutNameDescAccount)
 	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/acmeaccounts/:id/email", app.accounts.ChangeEmail)
 	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/acmeaccounts/:id/key-change", app.accounts.RolloverKey)
+	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/acmeaccounts/:id/key-rotation", app.accounts.PutKeyRotationPolicy)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/acmeaccounts/:id/key-rotations", app.accounts.GetKeyRotations)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/acmeaccounts/:id/key-rotations", app.accounts.RotateKeyNow)
 
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/acmeaccounts/:id/register", app.accounts.NewAcmeAccount)
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/acmeaccounts/:id/refresh", app.accounts.RefreshAcmeAccount)
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/acmeaccounts/:id/deactivate", app.accounts.Deactivate)
 
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/acmeaccounts/:id/authorizations", app.authorizations.GetAccountAuthorizations)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/acmeaccounts/:id/pre-authorizations", app.authorizations.PreAuthorize)
+
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/acmeaccounts/:id/orders-reconciliation", app.orders.GetAccountOrdersReconciliation)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/acmeaccounts/:id/orders-reconciliation", app.orders.ReconcileAccountOrders)
+
 	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/acmeaccounts/:id", app.accounts.DeleteAccount)
 
 	// certificates
@@ -125,10 +166,26 @@ func (app *Application) makeRouterAndRoutes() {
 
 	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/certificates/:certid", app.certificates.DeleteCert)
 
+	// bulk certificate operations
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/bulk/certificates", app.orders.BulkCertificates)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/bulk/certificates/:operationid", app.orders.GetBulkOperation)
+
 	// orders (for certificates)
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/orders/currentvalid", app.orders.GetAllValidCurrentOrders)
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/orders/fulfilling/status", app.orders.GetFulfillWorkStatus)
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/orders/post-process/status", app.orders.GetPostProcessWorkStatus)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/orders/workers", app.orders.GetWorkers)
+	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/orders/workers", app.orders.PutWorkers)
+	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/orders/fulfilling/jobs/:jobid", app.orders.DeleteFulfillJob)
+	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/orders/post-process/jobs/:jobid", app.orders.DeletePostProcessJob)
+
+	// deploy targets
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/certificates/:certid/deploy-targets", app.deployTargets.GetCertDeployTargets)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/certificates/:certid/deploy-targets/:targetid", app.deployTargets.GetOneDeployTarget)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/deploy-targets", app.deployTargets.PostNewDeployTarget)
+	router.handleAPIRouteSecure(http.MethodPut, apiUrlPath+"/v1/certificates/:certid/deploy-targets/:targetid", app.deployTargets.PutDeployTargetUpdate)
+	router.handleAPIRouteSecure(http.MethodDelete, apiUrlPath+"/v1/certificates/:certid/deploy-targets/:targetid", app.deployTargets.DeleteDeployTarget)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/deploy-targets/:targetid/test", app.deployTargets.TestDeployTarget)
 
 	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/certificates/:certid/orders", app.orders.GetCertOrders)
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders", app.orders.NewOrder)
@@ -138,8 +195,14 @@ func (app *Application) makeRouterAndRoutes() {
 
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders/:orderid", app.orders.FulfillExistingOrder)
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/revoke", app.orders.RevokeOrder)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/deactivate-authorizations", app.orders.DeactivateOrderAuths)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/chains", app.orders.GetOrderChains)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/chains/:chainindex/select", app.orders.SelectOrderChain)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/events", app.orders.GetOrderEvents)
+	router.handleAPIRouteSecure(http.MethodGet, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/postprocess/results", app.orders.GetOrderPostProcessResults)
 
 	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/postprocess", app.orders.PostProcessOrder)
+	router.handleAPIRouteSecure(http.MethodPost, apiUrlPath+"/v1/certificates/:certid/orders/:orderid/postprocess/client", app.orders.PushToClients)
 
 	// download keys and certs
 	router.handleAPIRouteDownloadWithAPIKey(http.MethodGet, apiKeyDownloadUrlPath+"/privatekeys/:name", app.download.DownloadKeyViaHeader)
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -49,7 +50,7 @@ func (app *Application) LoadHttpsCertificate() error {
 	}
 
 	// make tls certificate
-	tlsCert, err := tls.X509KeyPair([]byte(*order.Pem), []byte(order.FinalizedKey.Pem))
+	tlsCert, err := key_crypto.X509KeyPair(*order.Pem, order.FinalizedKey.Pem)
 	if err != nil {
 		return fmt.Errorf("failed to make x509 key pair (%s)", err)
 	}
@@ -0,0 +1,98 @@
+package authorizations
+
+import (
+	"certwarden-backend/pkg/acme"
+	"time"
+)
+
+// Authorization is an ACME authorization that is being tracked for an account
+type Authorization struct {
+	ID              int
+	AcmeAccountID   int
+	IdentifierType  string
+	IdentifierValue string
+	Wildcard        bool
+	URL             string
+	Status          string
+	Expires         int
+	CreatedAt       int
+	UpdatedAt       int
+}
+
+// validAt returns true if the authorization is valid and not yet expired at the
+// specified time
+func (auth Authorization) validAt(t time.Time) bool {
+	return auth.Status == "valid" && int64(auth.Expires) > t.Unix()
+}
+
+// authorizationResponse is the JSON response for an authorization
+type authorizationResponse struct {
+	ID              int    `json:"id"`
+	AcmeAccountID   int    `json:"acme_account_id"`
+	IdentifierType  string `json:"identifier_type"`
+	IdentifierValue string `json:"identifier_value"`
+	Wildcard        bool   `json:"wildcard"`
+	URL             string `json:"url"`
+	Status          string `json:"status"`
+	Expires         int    `json:"expires"`
+	Valid           bool   `json:"valid"`
+	CreatedAt       int    `json:"created_at"`
+	UpdatedAt       int    `json:"updated_at"`
+}
+
+func (auth Authorization) response() authorizationResponse {
+	return authorizationResponse{
+		ID:              auth.ID,
+		AcmeAccountID:   auth.AcmeAccountID,
+		IdentifierType:  auth.IdentifierType,
+		IdentifierValue: auth.IdentifierValue,
+		Wildcard:        auth.Wildcard,
+		URL:             auth.URL,
+		Status:          auth.Status,
+		Expires:         auth.Expires,
+		Valid:           auth.validAt(time.Now()),
+		CreatedAt:       auth.CreatedAt,
+		UpdatedAt:       auth.UpdatedAt,
+	}
+}
+
+// UpsertPayload is used to save the current state of an ACME authorization. If the
+// authorization URL is not yet in storage, it is added.
+type UpsertPayload struct {
+	AcmeAccountID   int
+	IdentifierType  string
+	IdentifierValue string
+	Wildcard        bool
+	URL             string
+	Status          string
+	Expires         int
+	UpdatedAt       int
+}
+
+// makeUpsertPayload creates the payload to save the ACME authorization for the
+// specified account
+func makeUpsertPayload(accountId int, auth acme.Authorization) UpsertPayload {
+	return UpsertPayload{
+		AcmeAccountID:   accountId,
+		IdentifierType:  string(auth.Identifier.Type),
+		IdentifierValue: auth.Identifier.Value,
+		Wildcard:        auth.Wildcard,
+		URL:             auth.Location,
+		Status:          auth.Status,
+		Expires:         auth.Expires.ToUnixTime(),
+		UpdatedAt:       int(time.Now().Unix()),
+	}
+}
+
+// recordAuth saves the state of the ACME authorization to storage. Errors are logged
+// but not returned since tracking is not critical to fulfilling authorizations.
+func (service *Service) recordAuth(accountId int, auth acme.Authorization) {
+	if auth.Location == "" {
+		return
+	}
+
+	err := service.storage.PutAuthorization(makeUpsertPayload(accountId, auth))
+	if err != nil {
+		service.logger.Errorf("authorizations: failed to save auth %s (%s)", auth.Location, err)
+	}
+}
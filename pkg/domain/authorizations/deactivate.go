@@ -0,0 +1,39 @@
+package authorizations
+
+import (
+	"certwarden-backend/pkg/acme"
+	"errors"
+	"fmt"
+)
+
+// DeactivateAuths deactivates each of the auth URLs that is 'pending' (and also 'valid'
+// if includeValid is true). Auths in any other status are left alone. The number of
+// auths deactivated is returned along with any errors.
+func (service *Service) DeactivateAuths(authUrls []string, accountId int, key acme.AccountKey, acmeService *acme.Service, includeValid bool) (deactivated int, err error) {
+	for _, authUrl := range authUrls {
+		// PaG the authorization for its current status
+		auth, getErr := acmeService.GetAuth(authUrl, key)
+		if getErr != nil {
+			err = errors.Join(err, fmt.Errorf("authorizations: failed to get auth %s (%w)", authUrl, getErr))
+			continue
+		}
+
+		// only deactivate pending (and optionally valid)
+		if auth.Status != "pending" && (!includeValid || auth.Status != "valid") {
+			service.recordAuth(accountId, auth)
+			continue
+		}
+
+		auth, deactErr := acmeService.DeactivateAuth(authUrl, key)
+		if deactErr != nil {
+			err = errors.Join(err, fmt.Errorf("authorizations: failed to deactivate auth %s (%w)", authUrl, deactErr))
+			continue
+		}
+
+		service.recordAuth(accountId, auth)
+		deactivated++
+		service.logger.Debugf("authorizations: deactivated auth %s (%s)", authUrl, auth.Identifier.Value)
+	}
+
+	return deactivated, err
+}
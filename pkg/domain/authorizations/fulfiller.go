@@ -2,17 +2,22 @@ package authorizations
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/datatypes/order_events"
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // acceptable final (non-error) authorization statuses (see: rfc8555 s 7.1.6)
 var finalAuthStatuses = []string{"valid", "invalid", "deactivated", "expired", "revoked"}
 
 // FulfillAuths attempts to validate each of the auth URLs in the slice of auth URLs. It returns an error if any
-// auth was not confirmed as in a final state (e.g., 'invalid' auth will not throw an error).
-func (service *Service) FulfillAuths(authUrls []string, key acme.AccountKey, acmeService *acme.Service) error {
+// auth was not confirmed as in a final state (e.g., 'invalid' auth will not throw an error). accountId is the
+// Cert Warden ID of the account the auths belong to and is used to track the auths' state. The steps of
+// fulfilling the auths are sent to recordEvent (which may be nil). Solving stops if ctx is canceled.
+func (service *Service) FulfillAuths(ctx context.Context, authUrls []string, accountId int, key acme.AccountKey, acmeService *acme.Service, recordEvent order_events.Recorder) error {
 	// aysnc checking the authz for validity
 	var wg sync.WaitGroup
 	wgSize := len(authUrls)
@@ -24,7 +29,7 @@ func (service *Service) FulfillAuths(authUrls []string, key acme.AccountKey, acm
 	for i := range authUrls {
 		go func(authUrl string) {
 			defer wg.Done()
-			err := service.fulfillAuth(authUrl, key, acmeService)
+			err := service.fulfillAuth(ctx, authUrl, accountId, key, acmeService, recordEvent)
 			wgErrors <- err
 		}(authUrls[i])
 	}
@@ -53,7 +58,7 @@ func (service *Service) FulfillAuths(authUrls []string, key acme.AccountKey, acm
 // fulfillAuth attempts to validate an auth URL by calling the challenge solver. If multiple calls are made for
 // the same auth, the additional calls will wait in a queue to proceed in turn. An error is returned if the auth
 // is not confirmed as in a final state.
-func (service *Service) fulfillAuth(authUrl string, key acme.AccountKey, acmeService *acme.Service) error {
+func (service *Service) fulfillAuth(ctx context.Context, authUrl string, accountId int, key acme.AccountKey, acmeService *acme.Service, recordEvent order_events.Recorder) error {
 	// use a map and signal channels to ensure the same auth is not attempted to be solved simultaneously
 	for {
 		// add auth
@@ -64,8 +69,12 @@ func (service *Service) fulfillAuth(authUrl string, key acme.AccountKey, acmeSer
 			break
 		}
 
-		// block until the other thread working this auth signals done
-		<-signal
+		// block until the other thread working this auth signals done (or canceled)
+		select {
+		case <-signal:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 
 		// loop to try and Add to authsWorking again
 	}
@@ -89,15 +98,28 @@ func (service *Service) fulfillAuth(authUrl string, key acme.AccountKey, acmeSer
 
 	// work the auth
 
-	// PaG the authorization
+	// PaG the authorization (always, the CA's state is authoritative even if the auth is
+	// recorded locally as valid, e.g. it may have been deactivated)
 	auth, err := acmeService.GetAuth(authUrl, key)
 	if err != nil {
+		recordEvent.Record(order_events.TypeAuthorizationFailed, authUrl, err.Error())
 		return err
 	}
+	recordEvent.Record(order_events.TypeAuthorizationFetched, auth.Identifier.Value, fmt.Sprintf("status: %s", auth.Status))
+
+	// log if the locally recorded state (e.g. from pre-authorization) disagrees
+	knownAuth, err := service.storage.GetAuthorizationByUrl(authUrl)
+	if err == nil && knownAuth.AcmeAccountID == accountId && knownAuth.validAt(time.Now()) {
+		if auth.Status == "valid" {
+			service.logger.Debugf("authorizations: auth %s is already valid (expires %s)", authUrl, time.Unix(int64(knownAuth.Expires), 0))
+		} else {
+			service.logger.Warnf("authorizations: auth %s was recorded as valid but the CA reports status %s", authUrl, auth.Status)
+		}
+	}
 
 	// call solver if auth is 'pending' (i.e., needs solving)
 	if auth.Status == "pending" {
-		err = service.challenges.Solve(auth.Identifier, auth.Challenges, key, acmeService)
+		err = service.challenges.Solve(ctx, auth.Identifier, auth.Challenges, key, acmeService, recordEvent)
 		// return error if couldn't solve
 		if err != nil {
 			return err
@@ -118,8 +140,12 @@ func (service *Service) fulfillAuth(authUrl string, key acme.AccountKey, acmeSer
 		}
 	}
 
+	// track the auth state
+	service.recordAuth(accountId, auth)
+
 	// if not final, return error
 	if !isFinal {
+		recordEvent.Record(order_events.TypeAuthorizationFailed, auth.Identifier.Value, fmt.Sprintf("status (%s) is not final", auth.Status))
 		return fmt.Errorf("authorizations: auth %s status (%s) is not final", authUrl, auth.Status)
 	}
 
@@ -0,0 +1,208 @@
+package authorizations
+
+import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/acme_accounts"
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/validation"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+var (
+	errAccountNotUsable     = errors.New("authorizations: acme account is not usable")
+	errNewAuthzUnsupported  = errors.New("authorizations: acme server does not support pre-authorization")
+	errIdentifiersBad       = errors.New("authorizations: identifiers are missing or invalid")
+	errIdentifierIsWildcard = errors.New("authorizations: wildcard identifiers cannot be pre-authorized")
+)
+
+// authorizationsResponse is the JSON response containing a list of authorizations
+type authorizationsResponse struct {
+	output.JsonResponse
+	Authorizations []authorizationResponse `json:"authorizations"`
+}
+
+// usableAccount returns the usable account with the specified id
+func (service *Service) usableAccount(accountId int) (acme_accounts.Account, *output.Error) {
+	accounts, err := service.accounts.GetUsableAccounts()
+	if err != nil {
+		service.logger.Error(err)
+		return acme_accounts.Account{}, output.ErrStorageGeneric
+	}
+
+	for i := range accounts {
+		if accounts[i].ID == accountId {
+			return accounts[i], nil
+		}
+	}
+
+	service.logger.Debug(errAccountNotUsable)
+	return acme_accounts.Account{}, output.ErrValidationFailed
+}
+
+// GetAccountAuthorizations returns the tracked authorizations for an ACME account
+func (service *Service) GetAccountAuthorizations(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	accountId, err := strconv.Atoi(idParam)
+	if err != nil || !validation.IsIdExistingValidRange(accountId) {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// get from storage
+	auths, err := service.storage.GetAuthorizationsByAccount(accountId)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// write response
+	response := &authorizationsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Authorizations = []authorizationResponse{}
+	for i := range auths {
+		response.Authorizations = append(response.Authorizations, auths[i].response())
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// preAuthorizePayload is the payload to pre-authorize identifiers
+type preAuthorizePayload struct {
+	Identifiers []string `json:"identifiers"`
+}
+
+// PreAuthorize creates new authorizations (newAuthz) for the specified identifiers
+// using the specified ACME account and then attempts to fulfill them in the background.
+// Once valid, orders containing these identifiers will not require challenge solving
+// until the authorizations expire.
+func (service *Service) PreAuthorize(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	accountId, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// decode payload
+	var payload preAuthorizePayload
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	// account
+	account, outErr := service.usableAccount(accountId)
+	if outErr != nil {
+		return outErr
+	}
+	// identifiers
+	if len(payload.Identifiers) == 0 {
+		service.logger.Debug(errIdentifiersBad)
+		return output.ErrValidationFailed
+	}
+	for _, identifier := range payload.Identifiers {
+		// see: RFC8555 7.4.1
+		if strings.HasPrefix(identifier, "*") {
+			service.logger.Debug(errIdentifierIsWildcard)
+			return output.ErrValidationFailed
+		}
+		if !validation.DomainValid(identifier, false) {
+			service.logger.Debug(errIdentifiersBad)
+			return output.ErrValidationFailed
+		}
+	}
+	// acme server must support newAuthz
+	acmeService, err := service.acmeServerService.AcmeService(account.AcmeServer.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+	if !acmeService.SupportsNewAuthz() {
+		service.logger.Debug(errNewAuthzUnsupported)
+		return output.ErrValidationFailed
+	}
+	// end validation
+
+	key, err := account.AcmeAccountKey()
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+
+	// don't send if rate limited
+	until := acmeService.RateLimitedUntil(key.Kid)
+	if until != nil {
+		service.logger.Infof("authorizations: not pre-authorizing, acme rate limited until %s", until.Format(time.RFC1123))
+		return output.ErrAcmeRateLimited
+	}
+
+	// create authz for each identifier
+	authUrls := []string{}
+	for _, identifier := range payload.Identifiers {
+		auth, err := acmeService.NewAuthz(acme.NewAuthzPayload{
+			Identifier: acme.Identifier{Type: acme.IdentifierTypeDns, Value: identifier},
+		}, key)
+		if err != nil {
+			service.logger.Errorf("authorizations: failed to pre-authorize %s (%s)", identifier, err)
+			if acmeService.RateLimitedUntil(key.Kid) != nil {
+				return output.ErrAcmeRateLimited
+			}
+			return output.ErrInternal
+		}
+
+		service.recordAuth(account.ID, auth)
+		authUrls = append(authUrls, auth.Location)
+	}
+
+	// fulfill in the background (solving challenges can take a while)
+	go func() {
+		err := service.FulfillAuths(service.shutdownContext, authUrls, account.ID, key, acmeService, nil)
+		if err != nil {
+			service.logger.Errorf("authorizations: pre-authorization for account %d failed (%s)", account.ID, err)
+			return
+		}
+		service.logger.Infof("authorizations: pre-authorization for account %d completed", account.ID)
+	}()
+
+	// return the tracked auths for the account
+	auths, err := service.storage.GetAuthorizationsByAccount(account.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// write response
+	response := &authorizationsResponse{}
+	response.StatusCode = http.StatusAccepted
+	response.Message = "pre-authorization started"
+	response.Authorizations = []authorizationResponse{}
+	for i := range auths {
+		response.Authorizations = append(response.Authorizations, auths[i].response())
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
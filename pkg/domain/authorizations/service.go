@@ -3,7 +3,10 @@ package authorizations
 import (
 	"certwarden-backend/pkg/challenges"
 	"certwarden-backend/pkg/datatypes/safemap"
+	"certwarden-backend/pkg/domain/acme_accounts"
 	"certwarden-backend/pkg/domain/acme_servers"
+	"certwarden-backend/pkg/output"
+	"context"
 	"errors"
 
 	"go.uber.org/zap"
@@ -13,15 +16,31 @@ var errServiceComponent = errors.New("authorizations: necessary service componen
 
 // App interface is for connecting to the main app
 type App interface {
+	GetShutdownContext() context.Context
 	GetLogger() *zap.SugaredLogger
+	GetOutputter() *output.Service
+	GetAuthorizationsStorage() Storage
 	GetChallengesService() *challenges.Service
 	GetAcmeServerService() *acme_servers.Service
+	GetAcctsService() *acme_accounts.Service
+}
+
+// Storage interface for storage functions
+type Storage interface {
+	GetAuthorizationsByAccount(accountId int) ([]Authorization, error)
+	GetAuthorizationByUrl(url string) (Authorization, error)
+
+	PutAuthorization(payload UpsertPayload) error
 }
 
 // service struct
 type Service struct {
+	shutdownContext   context.Context
 	logger            *zap.SugaredLogger
+	output            *output.Service
+	storage           Storage
 	acmeServerService *acme_servers.Service
+	accounts          *acme_accounts.Service
 	challenges        *challenges.Service
 	authsWorking      *safemap.SafeMap[chan struct{}] // tracks auths currently being worked
 }
@@ -30,18 +49,39 @@ type Service struct {
 func NewService(app App) (service *Service, err error) {
 	service = new(Service)
 
+	// shutdown context
+	service.shutdownContext = app.GetShutdownContext()
+
 	// logger
 	service.logger = app.GetLogger()
 	if service.logger == nil {
 		return nil, errServiceComponent
 	}
 
+	// output service
+	service.output = app.GetOutputter()
+	if service.output == nil {
+		return nil, errServiceComponent
+	}
+
+	// storage
+	service.storage = app.GetAuthorizationsStorage()
+	if service.storage == nil {
+		return nil, errServiceComponent
+	}
+
 	// acme services
 	service.acmeServerService = app.GetAcmeServerService()
 	if service.acmeServerService == nil {
 		return nil, errServiceComponent
 	}
 
+	// account service
+	service.accounts = app.GetAcctsService()
+	if service.accounts == nil {
+		return nil, errServiceComponent
+	}
+
 	// challenge solver
 	service.challenges = app.GetChallengesService()
 	if service.challenges == nil {
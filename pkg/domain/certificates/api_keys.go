@@ -0,0 +1,55 @@
+package certificates
+
+import (
+	"certwarden-backend/pkg/randomness"
+	"time"
+)
+
+// StageCertNewApiKey generates a new API key and saves it as the cert's api_key_new. The
+// cert must not already have a new API key.
+func (service *Service) StageCertNewApiKey(cert Certificate) (Certificate, error) {
+	// verify new api key is empty
+	if cert.ApiKeyNew != "" {
+		return Certificate{}, ErrApiKeyNewExists
+	}
+
+	// generate new api key
+	newApiKey, err := randomness.GenerateApiKey()
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	// update storage
+	err = service.storage.PutCertNewApiKey(cert.ID, newApiKey, int(time.Now().Unix()))
+	if err != nil {
+		return Certificate{}, err
+	}
+	cert.ApiKeyNew = newApiKey
+
+	return cert, nil
+}
+
+// PromoteCertNewApiKey discards the cert's api_key, replaces it with the cert's
+// api_key_new, and then blanks api_key_new. The cert must have a new API key.
+func (service *Service) PromoteCertNewApiKey(cert Certificate) (Certificate, error) {
+	// verify new api key is not empty (need something to promote)
+	if cert.ApiKeyNew == "" {
+		return Certificate{}, ErrApiKeyNewNone
+	}
+
+	// set current api key from new key
+	err := service.storage.PutCertApiKey(cert.ID, cert.ApiKeyNew, int(time.Now().Unix()))
+	if err != nil {
+		return Certificate{}, err
+	}
+	cert.ApiKey = cert.ApiKeyNew
+
+	// set new key to blank
+	err = service.storage.PutCertNewApiKey(cert.ID, "", int(time.Now().Unix()))
+	if err != nil {
+		return Certificate{}, err
+	}
+	cert.ApiKeyNew = ""
+
+	return cert, nil
+}
@@ -4,6 +4,7 @@ import (
 	"certwarden-backend/pkg/acme"
 	"certwarden-backend/pkg/domain/acme_accounts"
 	"certwarden-backend/pkg/domain/private_keys"
+	"time"
 )
 
 // Certificate is a single certificate with all of its fields
@@ -30,6 +31,14 @@ type Certificate struct {
 	PostProcessingCommand      string
 	PostProcessingEnvironment  []string
 	PostProcessingClientKeyB64 string
+	Profile                    string
+	RequestedValidityHours     int
+	PostProcessingTimeout      int // seconds, 0 for the default
+	PostProcessingArgs         []string
+	PostProcessingWorkingDir   string
+	PostProcessingWriteFiles   bool
+	PostProcessingClients      []ClientTarget // none for the subject on the default port
+	PostProcessingClientCAPem  string         // trusted when verifying the clients' tls
 }
 
 // certificateSummaryResponse is a JSON response containing only
@@ -104,6 +113,14 @@ type certificateDetailedResponse struct {
 	PostProcessingCommand      string              `json:"post_processing_command"`
 	PostProcessingEnvironment  []string            `json:"post_processing_environment"`
 	PostProcessingClientKeyB64 string              `json:"post_processing_client_key"`
+	Profile                    string              `json:"profile"`
+	RequestedValidityHours     int                 `json:"requested_validity_hours"`
+	PostProcessingTimeout      int                 `json:"post_processing_timeout"`
+	PostProcessingArgs         []string            `json:"post_processing_args"`
+	PostProcessingWorkingDir   string              `json:"post_processing_working_dir"`
+	PostProcessingWriteFiles   bool                `json:"post_processing_write_files"`
+	PostProcessingClients      []ClientTarget      `json:"post_processing_clients"`
+	PostProcessingClientCAPem  string              `json:"post_processing_client_ca_pem"`
 }
 
 func (cert Certificate) detailedResponse() certificateDetailedResponse {
@@ -130,11 +147,20 @@ func (cert Certificate) detailedResponse() certificateDetailedResponse {
 		PostProcessingCommand:      cert.PostProcessingCommand,
 		PostProcessingEnvironment:  cert.PostProcessingEnvironment,
 		PostProcessingClientKeyB64: cert.PostProcessingClientKeyB64,
+		Profile:                    cert.Profile,
+		RequestedValidityHours:     cert.RequestedValidityHours,
+		PostProcessingTimeout:      cert.PostProcessingTimeout,
+		PostProcessingArgs:         cert.PostProcessingArgs,
+		PostProcessingWorkingDir:   cert.PostProcessingWorkingDir,
+		PostProcessingWriteFiles:   cert.PostProcessingWriteFiles,
+		PostProcessingClients:      cert.PostProcessingClients,
+		PostProcessingClientCAPem:  cert.PostProcessingClientCAPem,
 	}
 }
 
-// NewOrderPayload creates the appropriate newOrder payload for ACME
-func (cert *Certificate) NewOrderPayload() acme.NewOrderPayload {
+// NewOrderPayload creates the appropriate newOrder payload for ACME. If validityHours is
+// greater than 0, notBefore and notAfter are set to request that validity period.
+func (cert *Certificate) NewOrderPayload(validityHours int) acme.NewOrderPayload {
 	var identifiers []acme.Identifier
 
 	// subject is always required and should be first
@@ -148,7 +174,11 @@ func (cert *Certificate) NewOrderPayload() acme.NewOrderPayload {
 		}
 	}
 
-	return acme.NewOrderPayload{
+	payload := acme.NewOrderPayload{
 		Identifiers: identifiers,
+		Profile:     cert.Profile,
 	}
+	payload.SetValidity(time.Duration(validityHours) * time.Hour)
+
+	return payload
 }
@@ -0,0 +1,73 @@
+package certificates
+
+import (
+	"certwarden-backend/pkg/validation"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var (
+	errClientTargetHostBad = errors.New("client target host must be a valid hostname or ip address")
+	errClientTargetPortBad = errors.New("client target port must be 0 (default) to 65535")
+	errClientTargetPathBad = errors.New("client target path must be blank (default) or an absolute url path")
+)
+
+// maxClientTargets is the maximum number of client targets a certificate may have
+const maxClientTargets = 20
+
+// ClientTarget is an endpoint of a certwarden-client that the certificate's key and
+// certificate are pushed to. Blank fields use the defaults (the certificate's subject,
+// the client's default port, and the client's install route).
+type ClientTarget struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	Path string `json:"path"`
+}
+
+// Valid returns an error if the ClientTarget is not valid
+func (ct ClientTarget) Valid() error {
+	// host (blank = subject)
+	if ct.Host != "" && !validation.DomainValid(ct.Host, false) && net.ParseIP(ct.Host) == nil {
+		return errClientTargetHostBad
+	}
+
+	// port (0 = default)
+	if ct.Port < 0 || ct.Port > 65535 {
+		return errClientTargetPortBad
+	}
+
+	// path (blank = default)
+	if ct.Path != "" {
+		u, err := url.Parse(ct.Path)
+		if err != nil || !strings.HasPrefix(ct.Path, "/") || u.Host != "" || u.Scheme != "" {
+			return errClientTargetPathBad
+		}
+	}
+
+	return nil
+}
+
+// clientTargetsValid returns an error if there are too many targets or any target is
+// not valid
+func clientTargetsValid(targets []ClientTarget) error {
+	if len(targets) > maxClientTargets {
+		return ErrPostProcessingClientsBad
+	}
+
+	for i := range targets {
+		err := targets[i].Valid()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clientCAPemValid returns true if caPem is blank or contains at least one certificate
+func clientCAPemValid(caPem string) bool {
+	return caPem == "" || x509.NewCertPool().AppendCertsFromPEM([]byte(caPem))
+}
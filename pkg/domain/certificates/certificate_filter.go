@@ -0,0 +1,49 @@
+package certificates
+
+import (
+	"certwarden-backend/pkg/pagination_sort"
+	"strings"
+)
+
+// Filter selects certificates by ACME account and/or subject suffix. Fields that are
+// not set match every certificate.
+type Filter struct {
+	AcmeAccountID *int   `json:"acme_account_id"`
+	SubjectSuffix string `json:"subject_suffix"`
+}
+
+// IsEmpty returns true if no fields of the filter are set (i.e. it matches every
+// certificate)
+func (filter Filter) IsEmpty() bool {
+	return filter.AcmeAccountID == nil && filter.SubjectSuffix == ""
+}
+
+// matches returns true if the cert meets all of the filter's criteria
+func (filter Filter) matches(cert Certificate) bool {
+	if filter.AcmeAccountID != nil && cert.CertificateAccount.ID != *filter.AcmeAccountID {
+		return false
+	}
+
+	if filter.SubjectSuffix != "" && !strings.HasSuffix(strings.ToLower(cert.Subject), strings.ToLower(filter.SubjectSuffix)) {
+		return false
+	}
+
+	return true
+}
+
+// FilterCertificates returns all of the certificates that match the filter
+func (service *Service) FilterCertificates(filter Filter) ([]Certificate, error) {
+	certs, _, err := service.storage.GetAllCerts(pagination_sort.Query{})
+	if err != nil {
+		return nil, err
+	}
+
+	matchingCerts := []Certificate{}
+	for i := range certs {
+		if filter.matches(certs[i]) {
+			matchingCerts = append(matchingCerts, certs[i])
+		}
+	}
+
+	return matchingCerts, nil
+}
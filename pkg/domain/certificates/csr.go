@@ -63,8 +63,8 @@ func (cert *Certificate) MakeCsrDer() (csr []byte, err error) {
 		ExtraExtensions: extraExts,
 	}
 
-	// cert's private key for signing
-	certKey, err := key_crypto.PemStringToKey(cert.CertificateKey.Pem, cert.CertificateKey.Algorithm)
+	// cert's key signer (private key may be in memory or in an external signer)
+	certKey, err := key_crypto.PemStringToSigner(cert.CertificateKey.Pem, cert.CertificateKey.Algorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package certificates
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"errors"
 	"fmt"
@@ -22,7 +23,7 @@ func (service *Service) DeleteCert(w http.ResponseWriter, r *http.Request) *outp
 	}
 
 	// verify cert id exists
-	_, outErr := service.GetCertificate(id)
+	cert, outErr := service.GetCertificate(id)
 	if outErr != nil {
 		return outErr
 	}
@@ -33,6 +34,7 @@ func (service *Service) DeleteCert(w http.ResponseWriter, r *http.Request) *outp
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, cert.detailedResponse(), nil)
 
 	// write response
 	response := &output.JsonResponse{}
@@ -61,34 +63,23 @@ func (service *Service) RemoveOldApiKey(w http.ResponseWriter, r *http.Request)
 
 	// validation
 	// get cert (validate exists)
-	cert, outErr := service.GetCertificate(certId)
+	oldCert, outErr := service.GetCertificate(certId)
 	if outErr != nil {
 		return outErr
 	}
-
-	// verify new api key is not empty (need something to promote)
-	if cert.ApiKeyNew == "" {
-		service.logger.Debug(errors.New("new api key does not exist"))
-		return output.ErrValidationFailed
-	}
 	// validation -- end
 
-	// update storage
-	// set current api key from new key
-	err = service.storage.PutCertApiKey(certId, cert.ApiKeyNew, int(time.Now().Unix()))
-	if err != nil {
-		service.logger.Error(err)
-		return output.ErrStorageGeneric
-	}
-	cert.ApiKey = cert.ApiKeyNew
-
-	// set new key to blank
-	err = service.storage.PutCertNewApiKey(certId, "", int(time.Now().Unix()))
+	// promote new api key
+	cert, err := service.PromoteCertNewApiKey(oldCert)
 	if err != nil {
+		if errors.Is(err, ErrApiKeyNewNone) {
+			service.logger.Debug(err)
+			return output.ErrValidationFailed
+		}
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
-	cert.ApiKeyNew = ""
+	audit.SetChanges(r, oldCert.detailedResponse(), cert.detailedResponse())
 
 	// write response
 	response := &certificateResponse{}
@@ -130,7 +121,9 @@ func (service *Service) DisableClientKey(w http.ResponseWriter, r *http.Request)
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	oldCert := cert
 	cert.PostProcessingClientKeyB64 = ""
+	audit.SetChanges(r, oldCert.detailedResponse(), cert.detailedResponse())
 
 	// write response
 	response := &certificateResponse{}
@@ -1,6 +1,7 @@
 package certificates
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/domain/private_keys"
 	"certwarden-backend/pkg/domain/private_keys/key_crypto"
 	"certwarden-backend/pkg/output"
@@ -9,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -33,14 +35,22 @@ type NewPayload struct {
 	PreferredRootCN           *string             `json:"preferred_root_cn"`
 	PostProcessingCommand     *string             `json:"post_processing_command"`
 	PostProcessingEnvironment []string            `json:"post_processing_environment"`
+	Profile                   *string             `json:"profile"`
+	RequestedValidityHours    *int                `json:"requested_validity_hours"`
+	PostProcessingTimeout     *int                `json:"post_processing_timeout"`
+	PostProcessingArgs        []string            `json:"post_processing_args"`
+	PostProcessingWorkingDir  *string             `json:"post_processing_working_dir"`
+	PostProcessingWriteFiles  *bool               `json:"post_processing_write_files"`
 	// for post processing client, user submits enable or not, if enable key is generated and stored
 	// bool is not stored anywhere (disabled == blank key value)
-	PostProcessingClientEnable *bool  `json:"post_processing_client_enable"`
-	PostProcessingClientKeyB64 string `json:"-"`
-	ApiKey                     string `json:"-"`
-	ApiKeyViaUrl               bool   `json:"-"`
-	CreatedAt                  int    `json:"-"`
-	UpdatedAt                  int    `json:"-"`
+	PostProcessingClientEnable *bool          `json:"post_processing_client_enable"`
+	PostProcessingClientKeyB64 string         `json:"-"`
+	PostProcessingClients      []ClientTarget `json:"post_processing_clients"`
+	PostProcessingClientCAPem  *string        `json:"post_processing_client_ca_pem"`
+	ApiKey                     string         `json:"-"`
+	ApiKeyViaUrl               bool           `json:"-"`
+	CreatedAt                  int            `json:"-"`
+	UpdatedAt                  int            `json:"-"`
 }
 
 // PostNewCert creates a new certificate object in storage. No actual encryption certificate
@@ -153,6 +163,33 @@ func (service *Service) PostNewCert(w http.ResponseWriter, r *http.Request) *out
 		payload.PreferredRootCN = new(string)
 	}
 
+	// profile (blank for acme server's default)
+	if payload.Profile == nil {
+		payload.Profile = new(string)
+	}
+	if !service.accountProfileValid(*payload.Profile, *payload.AcmeAccountID) {
+		service.logger.Debug(ErrProfileBad)
+		return output.ErrValidationFailed
+	}
+
+	// requested validity (0 for CA's default)
+	if payload.RequestedValidityHours == nil {
+		payload.RequestedValidityHours = new(int)
+	}
+	if *payload.RequestedValidityHours < 0 {
+		service.logger.Debug(ErrValidityBad)
+		return output.ErrValidationFailed
+	}
+
+	// post processing timeout (0 for default)
+	if payload.PostProcessingTimeout == nil {
+		payload.PostProcessingTimeout = new(int)
+	}
+	if *payload.PostProcessingTimeout < 0 || *payload.PostProcessingTimeout > maxPostProcessingTimeout {
+		service.logger.Debug(ErrPostProcessingTimeoutBad)
+		return output.ErrValidationFailed
+	}
+
 	// post processing command / env (don't check valid path, just let errors log if its bad)
 	if payload.PostProcessingCommand == nil {
 		payload.PostProcessingCommand = new(string)
@@ -160,10 +197,39 @@ func (service *Service) PostNewCert(w http.ResponseWriter, r *http.Request) *out
 	if payload.PostProcessingEnvironment == nil {
 		payload.PostProcessingEnvironment = []string{}
 	}
+	if payload.PostProcessingArgs == nil {
+		payload.PostProcessingArgs = []string{}
+	}
+	if payload.PostProcessingWorkingDir == nil {
+		payload.PostProcessingWorkingDir = new(string)
+	}
+	if *payload.PostProcessingWorkingDir != "" && !filepath.IsAbs(*payload.PostProcessingWorkingDir) {
+		service.logger.Debug(ErrPostProcessingWorkingDirBad)
+		return output.ErrValidationFailed
+	}
+	if payload.PostProcessingWriteFiles == nil {
+		payload.PostProcessingWriteFiles = new(bool)
+	}
 	// post processing client enable
 	if payload.PostProcessingClientEnable == nil {
 		payload.PostProcessingClientEnable = new(bool)
 	}
+	// post processing clients (none = subject on default port) and ca
+	if payload.PostProcessingClients == nil {
+		payload.PostProcessingClients = []ClientTarget{}
+	}
+	err = clientTargetsValid(payload.PostProcessingClients)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+	if payload.PostProcessingClientCAPem == nil {
+		payload.PostProcessingClientCAPem = new(string)
+	}
+	if !clientCAPemValid(*payload.PostProcessingClientCAPem) {
+		service.logger.Debug(ErrPostProcessingClientCAPemBad)
+		return output.ErrValidationFailed
+	}
 	// end validation
 
 	// if new key was generated, save it to storage
@@ -220,6 +286,7 @@ func (service *Service) PostNewCert(w http.ResponseWriter, r *http.Request) *out
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, nil, newCert.detailedResponse())
 
 	// write response
 	response := &certificateResponse{}
@@ -248,32 +315,23 @@ func (service *Service) StageNewApiKey(w http.ResponseWriter, r *http.Request) *
 
 	// validation
 	// get cert (validate exists)
-	cert, outErr := service.GetCertificate(certId)
+	oldCert, outErr := service.GetCertificate(certId)
 	if outErr != nil {
 		return outErr
 	}
-
-	// verify new api key is empty
-	if cert.ApiKeyNew != "" {
-		service.logger.Debug(errors.New("new api key already exists"))
-		return output.ErrValidationFailed
-	}
 	// validation -- end
 
-	// generate new api key
-	newApiKey, err := randomness.GenerateApiKey()
+	// generate and save new api key
+	cert, err := service.StageCertNewApiKey(oldCert)
 	if err != nil {
+		if errors.Is(err, ErrApiKeyNewExists) {
+			service.logger.Debug(err)
+			return output.ErrValidationFailed
+		}
 		service.logger.Error(err)
 		return output.ErrInternal
 	}
-
-	// update storage
-	err = service.storage.PutCertNewApiKey(certId, newApiKey, int(time.Now().Unix()))
-	if err != nil {
-		service.logger.Error(err)
-		return output.ErrStorageGeneric
-	}
-	cert.ApiKeyNew = newApiKey
+	audit.SetChanges(r, oldCert.detailedResponse(), cert.detailedResponse())
 
 	// write response
 	response := &certificateResponse{}
@@ -322,7 +380,9 @@ func (service *Service) MakeNewClientKey(w http.ResponseWriter, r *http.Request)
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	oldCert := cert
 	cert.PostProcessingClientKeyB64 = clientKey
+	audit.SetChanges(r, oldCert.detailedResponse(), cert.detailedResponse())
 
 	// write response
 	response := &certificateResponse{}
@@ -1,9 +1,11 @@
 package certificates
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -27,6 +29,14 @@ type DetailsUpdatePayload struct {
 	PreferredRootCN           *string             `json:"preferred_root_cn"`
 	PostProcessingCommand     *string             `json:"post_processing_command"`
 	PostProcessingEnvironment []string            `json:"post_processing_environment"`
+	Profile                   *string             `json:"profile"`
+	RequestedValidityHours    *int                `json:"requested_validity_hours"`
+	PostProcessingTimeout     *int                `json:"post_processing_timeout"`
+	PostProcessingArgs        []string            `json:"post_processing_args"`
+	PostProcessingWorkingDir  *string             `json:"post_processing_working_dir"`
+	PostProcessingWriteFiles  *bool               `json:"post_processing_write_files"`
+	PostProcessingClients     []ClientTarget      `json:"post_processing_clients"`
+	PostProcessingClientCAPem *string             `json:"post_processing_client_ca_pem"`
 	ApiKey                    *string             `json:"api_key"`
 	ApiKeyNew                 *string             `json:"api_key_new"`
 	ApiKeyViaUrl              *bool               `json:"api_key_via_url"`
@@ -107,7 +117,44 @@ func (service *Service) PutDetailsCert(w http.ResponseWriter, r *http.Request) *
 		}
 	}
 
-	// post processing command & env are optional but nothing to validate
+	// profile (optional, blank for acme server's default)
+	if payload.Profile != nil && !service.profileValid(*payload.Profile, cert.CertificateAccount.AcmeServer.ID) {
+		service.logger.Debug(ErrProfileBad)
+		return output.ErrValidationFailed
+	}
+
+	// requested validity (optional, 0 for CA's default)
+	if payload.RequestedValidityHours != nil && *payload.RequestedValidityHours < 0 {
+		service.logger.Debug(ErrValidityBad)
+		return output.ErrValidationFailed
+	}
+
+	// post processing timeout (optional, 0 for default)
+	if payload.PostProcessingTimeout != nil && (*payload.PostProcessingTimeout < 0 || *payload.PostProcessingTimeout > maxPostProcessingTimeout) {
+		service.logger.Debug(ErrPostProcessingTimeoutBad)
+		return output.ErrValidationFailed
+	}
+
+	// post processing working dir (optional, blank for the app's working directory)
+	if payload.PostProcessingWorkingDir != nil && *payload.PostProcessingWorkingDir != "" && !filepath.IsAbs(*payload.PostProcessingWorkingDir) {
+		service.logger.Debug(ErrPostProcessingWorkingDirBad)
+		return output.ErrValidationFailed
+	}
+
+	// post processing clients & ca (optional)
+	if payload.PostProcessingClients != nil {
+		err = clientTargetsValid(payload.PostProcessingClients)
+		if err != nil {
+			service.logger.Debug(err)
+			return output.ErrValidationFailed
+		}
+	}
+	if payload.PostProcessingClientCAPem != nil && !clientCAPemValid(*payload.PostProcessingClientCAPem) {
+		service.logger.Debug(ErrPostProcessingClientCAPemBad)
+		return output.ErrValidationFailed
+	}
+
+	// post processing command, args & env are optional but nothing to validate
 
 	// end validation
 
@@ -121,6 +168,7 @@ func (service *Service) PutDetailsCert(w http.ResponseWriter, r *http.Request) *
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, cert.detailedResponse(), updatedCert.detailedResponse())
 
 	// write response
 	response := &certificateResponse{}
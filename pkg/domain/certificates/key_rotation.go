@@ -0,0 +1,56 @@
+package certificates
+
+import (
+	"certwarden-backend/pkg/domain/private_keys"
+	"certwarden-backend/pkg/randomness"
+	"fmt"
+	"time"
+)
+
+// RotateCertKey generates a new private key pem (using the same algorithm as the cert's
+// current key) and replaces the pem of the cert's key in place. The key keeps its id,
+// name, and API keys so clients that download the key by name receive the new key. The
+// old pem is saved as a new 'retired' key (with API access disabled) that is used by the
+// cert's existing orders, as their certificates match the old pem.
+// Note: Until a new order using the rotated key is valid, the key downloaded by clients
+// will not match the cert's current certificate, so a new order should be placed
+// immediately after rotating.
+func (service *Service) RotateCertKey(cert Certificate) (retiredKeyId int, err error) {
+	now := time.Now()
+	key := cert.CertificateKey
+
+	newPem, err := key.Algorithm.GeneratePrivateKeyPem()
+	if err != nil {
+		return -1, err
+	}
+
+	// retired key is not downloadable
+	apiKey, err := randomness.GenerateApiKey()
+	if err != nil {
+		return -1, err
+	}
+	retiredName := fmt.Sprintf("%s_retired_%d", key.Name, now.Unix())
+	retiredDesc := fmt.Sprintf("key %s before it was rotated, used by certificate %s's previous orders", key.Name, cert.Name)
+	apiKeyDisabled := true
+
+	retiredKeyId, err = service.storage.PutKeyPemRotation(key.ID, key.Algorithm.StorageValue(), newPem, private_keys.NewPayload{
+		Name:           &retiredName,
+		Description:    &retiredDesc,
+		ApiKey:         apiKey,
+		ApiKeyDisabled: &apiKeyDisabled,
+		ApiKeyViaUrl:   false,
+		CreatedAt:      int(now.Unix()),
+		UpdatedAt:      int(now.Unix()),
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return retiredKeyId, nil
+}
+
+// UndoRotateCertKey reverts a RotateCertKey by restoring the retired key's pem to the
+// cert's key and then deleting the retired key
+func (service *Service) UndoRotateCertKey(cert Certificate, retiredKeyId int) error {
+	return service.storage.PutKeyPemRotationUndo(cert.CertificateKey.ID, retiredKeyId, int(time.Now().Unix()))
+}
@@ -2,6 +2,7 @@ package certificates
 
 import (
 	"certwarden-backend/pkg/domain/acme_accounts"
+	"certwarden-backend/pkg/domain/acme_servers"
 	"certwarden-backend/pkg/domain/private_keys"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/pagination_sort"
@@ -19,6 +20,7 @@ type App interface {
 	GetCertificatesStorage() Storage
 	GetKeysService() *private_keys.Service
 	GetAcctsService() *acme_accounts.Service
+	GetAcmeServerService() *acme_servers.Service
 }
 
 // Storage interface for storage functions
@@ -37,15 +39,18 @@ type Storage interface {
 	DeleteCert(id int) (err error)
 
 	PostNewKey(private_keys.NewPayload) (private_keys.Key, error)
+	PutKeyPemRotation(keyId int, newAlgorithmValue string, newPem string, retiredKey private_keys.NewPayload) (retiredKeyId int, err error)
+	PutKeyPemRotationUndo(keyId int, retiredKeyId int, updateTimeUnix int) (err error)
 }
 
 // Keys service struct
 type Service struct {
-	logger   *zap.SugaredLogger
-	output   *output.Service
-	storage  Storage
-	keys     *private_keys.Service
-	accounts *acme_accounts.Service
+	logger            *zap.SugaredLogger
+	output            *output.Service
+	storage           Storage
+	keys              *private_keys.Service
+	accounts          *acme_accounts.Service
+	acmeServerService *acme_servers.Service
 }
 
 // NewService creates a new service
@@ -82,5 +87,11 @@ func NewService(app App) (*Service, error) {
 		return nil, errServiceComponent
 	}
 
+	// acme server service
+	service.acmeServerService = app.GetAcmeServerService()
+	if service.acmeServerService == nil {
+		return nil, errServiceComponent
+	}
+
 	return service, nil
 }
@@ -2,6 +2,7 @@ package certificates
 
 import (
 	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/pagination_sort"
 	"certwarden-backend/pkg/storage"
 	"certwarden-backend/pkg/validation"
 	"errors"
@@ -24,10 +25,33 @@ var (
 	ErrApiKeyBad    = errors.New("api key is not valid (must be at least 10 chars in length)")
 	ErrApiKeyNewBad = errors.New("api key (new) is not valid (must be at least 10 chars in length)")
 
+	// api key staging / promotion
+	ErrApiKeyNewExists = errors.New("new api key already exists")
+	ErrApiKeyNewNone   = errors.New("new api key does not exist")
+
 	// domain
 	ErrDomainBad = errors.New("domain or subject name not valid")
+
+	// profile
+	ErrProfileBad = errors.New("profile is not offered by the acme server")
+
+	// validity
+	ErrValidityBad = errors.New("requested validity hours must not be negative")
+
+	// post processing timeout
+	ErrPostProcessingTimeoutBad = errors.New("post processing timeout must be 0 (default) to 86400 seconds")
+
+	// post processing working directory
+	ErrPostProcessingWorkingDirBad = errors.New("post processing working directory must be an absolute path")
+
+	// post processing client
+	ErrPostProcessingClientsBad     = errors.New("post processing clients are not valid (maximum of 20)")
+	ErrPostProcessingClientCAPemBad = errors.New("post processing client ca pem is not valid")
 )
 
+// maxPostProcessingTimeout is the maximum post processing timeout (in seconds)
+const maxPostProcessingTimeout = 24 * 60 * 60
+
 // GetCertificate returns the Certificate for the specified id.
 func (service *Service) GetCertificate(id int) (Certificate, *output.Error) {
 	// if id is not in valid range, it is definitely not valid
@@ -123,3 +147,55 @@ func subjectAltsValid(alts []string) bool {
 
 	return true
 }
+
+// profileValid returns true if the profile is blank (server default) or is one of
+// the profiles advertised by the specified acme server
+func (service *Service) profileValid(profile string, acmeServerId int) bool {
+	if profile == "" {
+		return true
+	}
+
+	acmeService, err := service.acmeServerService.AcmeService(acmeServerId)
+	if err != nil {
+		return false
+	}
+
+	return acmeService.ProfileValid(profile)
+}
+
+// accountProfileValid returns true if the profile is valid for the acme server the
+// specified account belongs to
+func (service *Service) accountProfileValid(profile string, acmeAccountId int) bool {
+	if profile == "" {
+		return true
+	}
+
+	accounts, err := service.accounts.GetUsableAccounts()
+	if err != nil {
+		return false
+	}
+	for _, account := range accounts {
+		if account.ID == acmeAccountId {
+			return service.profileValid(profile, account.AcmeServer.ID)
+		}
+	}
+
+	return false
+}
+
+// CertificatesByAccount returns all of the certificates that use the specified ACME account
+func (service *Service) CertificatesByAccount(accountId int) ([]Certificate, error) {
+	certs, _, err := service.storage.GetAllCerts(pagination_sort.Query{})
+	if err != nil {
+		return nil, err
+	}
+
+	accountCerts := []Certificate{}
+	for i := range certs {
+		if certs[i].CertificateAccount.ID == accountId {
+			accountCerts = append(accountCerts, certs[i])
+		}
+	}
+
+	return accountCerts, nil
+}
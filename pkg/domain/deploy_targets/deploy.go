@@ -0,0 +1,104 @@
+package deploy_targets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// testTimeout is how long a connection test may take
+const testTimeout = 30 * time.Second
+
+var errTypeUnsupported = errors.New("deploy target type is not supported")
+
+// Content is the key and certificate content that is deployed
+type Content struct {
+	KeyPem       string
+	CertChainPem string // leaf and chain
+	CertPem      string // leaf only
+	ChainPem     string // chain only
+}
+
+// fileContent pairs a path with its content and mode
+type fileContent struct {
+	path    string
+	content string
+	mode    string
+}
+
+// fileContents returns the files that should be written for the configured paths
+func (files Files) fileContents(content Content) []fileContent {
+	fcs := []fileContent{}
+
+	if files.KeyPath != "" {
+		fcs = append(fcs, fileContent{path: files.KeyPath, content: content.KeyPem, mode: files.KeyMode})
+	}
+	if files.CertChainPath != "" {
+		fcs = append(fcs, fileContent{path: files.CertChainPath, content: content.CertChainPem, mode: files.CertMode})
+	}
+	if files.CertPath != "" {
+		fcs = append(fcs, fileContent{path: files.CertPath, content: content.CertPem, mode: files.CertMode})
+	}
+	if files.ChainPath != "" {
+		fcs = append(fcs, fileContent{path: files.ChainPath, content: content.ChainPem, mode: files.CertMode})
+	}
+
+	return fcs
+}
+
+// GetEnabled returns the enabled deploy targets of the specified certificate
+func (service *Service) GetEnabled(certId int) ([]DeployTarget, error) {
+	targets, err := service.storage.GetCertDeployTargets(certId)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := []DeployTarget{}
+	for i := range targets {
+		if targets[i].Enabled {
+			enabled = append(enabled, targets[i])
+		}
+	}
+
+	return enabled, nil
+}
+
+// Deploy deploys the content to the target. It returns any output produced by the
+// target (e.g. from an ssh reload command) which may be non-empty even if there is an
+// error.
+func (service *Service) Deploy(ctx context.Context, target DeployTarget, content Content) (string, error) {
+	var output string
+	var err error
+
+	switch target.Type {
+	case TypeFile:
+		err = deployFile(target.Config.File, content)
+	case TypeSSH:
+		output, err = service.deploySSH(ctx, target.Config.SSH, content)
+	case TypeKubernetes:
+		err = service.deployKubernetes(ctx, target.Config.Kubernetes, content)
+	default:
+		err = errTypeUnsupported
+	}
+
+	if err != nil {
+		return output, fmt.Errorf("deploy target %s (%s): %w", target.Name, target.Type, err)
+	}
+
+	return output, nil
+}
+
+// testTarget checks that the target is reachable and writable without deploying anything
+func (service *Service) testTarget(ctx context.Context, target DeployTarget) error {
+	switch target.Type {
+	case TypeFile:
+		return testFile(target.Config.File)
+	case TypeSSH:
+		return service.testSSH(ctx, target.Config.SSH)
+	case TypeKubernetes:
+		return service.testKubernetes(ctx, target.Config.Kubernetes)
+	default:
+		return errTypeUnsupported
+	}
+}
@@ -0,0 +1,134 @@
+package deploy_targets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+var errConfigMissing = errors.New("deploy target config is missing")
+
+// deployFile writes the content to the local paths of the config
+func deployFile(cfg *FileConfig, content Content) error {
+	if cfg == nil {
+		return errConfigMissing
+	}
+
+	uid, gid, err := lookupOwner(cfg.Owner, cfg.Group)
+	if err != nil {
+		return err
+	}
+
+	for _, fc := range cfg.fileContents(content) {
+		mode, err := parseMode(fc.mode)
+		if err != nil {
+			return err
+		}
+
+		err = writeFileAtomic(fc.path, []byte(fc.content), os.FileMode(mode), uid, gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// testFile confirms each configured path's directory exists and is writable
+func testFile(cfg *FileConfig) error {
+	if cfg == nil {
+		return errConfigMissing
+	}
+
+	_, _, err := lookupOwner(cfg.Owner, cfg.Group)
+	if err != nil {
+		return err
+	}
+
+	for _, fc := range cfg.fileContents(Content{}) {
+		f, err := os.CreateTemp(filepath.Dir(fc.path), ".certwarden-test-*")
+		if err != nil {
+			return err
+		}
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory as path, sets its
+// mode and ownership, and then renames it to path so readers never see a partial file.
+// uid and gid of -1 leave ownership unchanged.
+func writeFileAtomic(path string, data []byte, mode os.FileMode, uid, gid int) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	// no-op once renamed
+	defer os.Remove(tmpName)
+
+	_, err = f.Write(data)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmpName, mode)
+	if err != nil {
+		return err
+	}
+
+	if uid != -1 || gid != -1 {
+		err = os.Chown(tmpName, uid, gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmpName, path)
+}
+
+// lookupOwner returns the uid and gid for the specified owner and group (names or
+// numeric ids). Blank values return -1 (unchanged).
+func lookupOwner(owner, group string) (uid int, gid int, err error) {
+	uid, gid = -1, -1
+
+	if owner != "" {
+		uid, err = strconv.Atoi(owner)
+		if err != nil {
+			u, err := user.Lookup(owner)
+			if err != nil {
+				return -1, -1, fmt.Errorf("failed to lookup owner %s (%w)", owner, err)
+			}
+			uid, err = strconv.Atoi(u.Uid)
+			if err != nil {
+				return -1, -1, err
+			}
+		}
+	}
+
+	if group != "" {
+		gid, err = strconv.Atoi(group)
+		if err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return -1, -1, fmt.Errorf("failed to lookup group %s (%w)", group, err)
+			}
+			gid, err = strconv.Atoi(g.Gid)
+			if err != nil {
+				return -1, -1, err
+			}
+		}
+	}
+
+	return uid, gid, nil
+}
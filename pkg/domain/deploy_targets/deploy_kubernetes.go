@@ -0,0 +1,141 @@
+package deploy_targets
+
+import (
+	"bytes"
+	"certwarden-backend/pkg/httpclient"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxKubernetesErrBody is the maximum number of bytes of an error response's body that
+// are included in the returned error
+const maxKubernetesErrBody = 1024
+
+// kubernetesSecret is the subset of a Kubernetes Secret object that is sent
+type kubernetesSecret struct {
+	ApiVersion string            `json:"apiVersion,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Metadata   *kubernetesMeta   `json:"metadata,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data"` // []byte marshals to base64 as Kubernetes expects
+}
+
+type kubernetesMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// kubernetesClient returns an http client that trusts the config's CA (if any)
+func (service *Service) kubernetesClient(cfg *KubernetesConfig) (*httpclient.Client, error) {
+	return service.httpClient.WithOptions(httpclient.Options{RootCAsPem: cfg.CACertPem})
+}
+
+// secretsUrl returns the url of the namespace's secrets collection
+func (cfg *KubernetesConfig) secretsUrl() string {
+	return strings.TrimSuffix(cfg.ApiServer, "/") + "/api/v1/namespaces/" + url.PathEscape(cfg.Namespace) + "/secrets"
+}
+
+// kubernetesDo does a request authenticated with the config's token and returns an
+// error if the response status is not one of okStatus
+func kubernetesDo(ctx context.Context, client *httpclient.Client, cfg *KubernetesConfig, method, reqUrl, contentType string, body []byte, okStatus ...int) (int, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+cfg.Token)
+	header.Set("Accept", "application/json")
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	resp, err := client.DoContext(ctx, method, reqUrl, bodyReader, header)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatus {
+		if resp.StatusCode == status {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return resp.StatusCode, nil
+		}
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxKubernetesErrBody))
+	return resp.StatusCode, fmt.Errorf("kubernetes api %s %s returned status %d (%s)", method, reqUrl, resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+// deployKubernetes updates the tls Secret's key and certificate, creating the Secret if
+// it doesn't exist
+func (service *Service) deployKubernetes(ctx context.Context, cfg *KubernetesConfig, content Content) error {
+	if cfg == nil {
+		return errConfigMissing
+	}
+
+	client, err := service.kubernetesClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	data := map[string][]byte{
+		"tls.crt": []byte(content.CertChainPem),
+		"tls.key": []byte(content.KeyPem),
+	}
+
+	// try to update existing
+	patch, err := json.Marshal(kubernetesSecret{Data: data})
+	if err != nil {
+		return err
+	}
+
+	secretUrl := cfg.secretsUrl() + "/" + url.PathEscape(cfg.SecretName)
+	status, err := kubernetesDo(ctx, client, cfg, http.MethodPatch, secretUrl, "application/merge-patch+json", patch, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+
+	// doesn't exist, create it
+	secret, err := json.Marshal(kubernetesSecret{
+		ApiVersion: "v1",
+		Kind:       "Secret",
+		Metadata: &kubernetesMeta{
+			Name:      cfg.SecretName,
+			Namespace: cfg.Namespace,
+		},
+		Type: "kubernetes.io/tls",
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = kubernetesDo(ctx, client, cfg, http.MethodPost, cfg.secretsUrl(), "application/json", secret, http.StatusCreated, http.StatusOK)
+	return err
+}
+
+// testKubernetes confirms the api server is reachable and the token can read the Secret
+// (a Secret that doesn't exist yet is ok since deploy will create it)
+func (service *Service) testKubernetes(ctx context.Context, cfg *KubernetesConfig) error {
+	if cfg == nil {
+		return errConfigMissing
+	}
+
+	client, err := service.kubernetesClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	secretUrl := cfg.secretsUrl() + "/" + url.PathEscape(cfg.SecretName)
+	_, err = kubernetesDo(ctx, client, cfg, http.MethodGet, secretUrl, "", nil, http.StatusOK, http.StatusNotFound)
+	return err
+}
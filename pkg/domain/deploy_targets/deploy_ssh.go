@@ -0,0 +1,160 @@
+package deploy_targets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// dialSSH connects to the configured host. The connection is closed if ctx is done
+// before the returned cleanup func is called.
+func (service *Service) dialSSH(ctx context.Context, cfg *SSHConfig) (*ssh.Client, func(), error) {
+	if cfg == nil {
+		return nil, nil, errConfigMissing
+	}
+
+	signer, err := service.sshSigner(cfg.PrivateKeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if cfg.HostKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+		if err != nil {
+			return nil, nil, err
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	} else if !cfg.InsecureIgnoreHostKey {
+		return nil, nil, ErrHostKeyBad
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// close conn if ctx ends (which also unblocks the handshake and any sessions)
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		stop()
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+
+	cleanup := func() {
+		stop()
+		_ = client.Close()
+	}
+
+	return client, cleanup, nil
+}
+
+// runSSH runs cmd in a new session, with stdin as its input, and returns its combined
+// output
+func runSSH(client *ssh.Client, cmd string, stdin []byte) (string, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	var out bytes.Buffer
+	session.Stdout = &out
+	session.Stderr = &out
+	if stdin != nil {
+		session.Stdin = bytes.NewReader(stdin)
+	}
+
+	err = session.Run(cmd)
+	return out.String(), err
+}
+
+// deploySSH uploads the content to the remote paths and then runs the reload command
+// (if any)
+func (service *Service) deploySSH(ctx context.Context, cfg *SSHConfig, content Content) (string, error) {
+	client, cleanup, err := service.dialSSH(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	// upload each file (write to temp file then move so it is replaced atomically)
+	for _, fc := range cfg.fileContents(content) {
+		out, err := runSSH(client, sshUploadCommand(fc.path, fc.mode), []byte(fc.content))
+		if err != nil {
+			return out, sshErr(ctx, fmt.Errorf("failed to upload %s (%w)", fc.path, err))
+		}
+	}
+
+	// reload
+	if cfg.ReloadCommand == "" {
+		return "", nil
+	}
+
+	out, err := runSSH(client, cfg.ReloadCommand, nil)
+	if err != nil {
+		return out, sshErr(ctx, fmt.Errorf("reload command failed (%w)", err))
+	}
+
+	return out, nil
+}
+
+// testSSH connects to the host and confirms a command can be run
+func (service *Service) testSSH(ctx context.Context, cfg *SSHConfig) error {
+	client, cleanup, err := service.dialSSH(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, err = runSSH(client, "true", nil)
+	return sshErr(ctx, err)
+}
+
+// sshErr returns the ctx error instead of err if ctx ended (since the conn being
+// closed is what caused err)
+func sshErr(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return errors.Join(ctx.Err(), err)
+	}
+	return err
+}
+
+// sshUploadCommand returns the remote shell command to write stdin to remotePath with
+// the specified mode. The content is written to a new uniquely named temp file in the
+// same directory (created by mktemp, readable only by the owner) which is then moved
+// over remotePath, so the file is replaced atomically and is never exposed with the
+// remote umask's permissions.
+func sshUploadCommand(remotePath string, mode string) string {
+	tmpTemplate := path.Join(path.Dir(remotePath), "."+path.Base(remotePath)+".tmp-XXXXXX")
+
+	return fmt.Sprintf(`umask 077; tmp=$(mktemp %s) || exit 1; { cat > "$tmp" && chmod %s "$tmp" && mv -f "$tmp" %s; } || { rm -f "$tmp"; exit 1; }`,
+		shellQuote(tmpTemplate), mode, shellQuote(remotePath))
+}
+
+// shellQuote quotes s for use as a single argument in a posix shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
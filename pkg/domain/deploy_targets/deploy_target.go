@@ -0,0 +1,136 @@
+package deploy_targets
+
+import "certwarden-backend/pkg/output"
+
+// Type is the kind of place a certificate is deployed to
+type Type string
+
+const (
+	TypeFile       Type = "file"
+	TypeSSH        Type = "ssh"
+	TypeKubernetes Type = "kubernetes"
+)
+
+// Files are the paths that the key and certificate are deployed to. Blank paths are
+// skipped.
+type Files struct {
+	KeyPath       string `json:"key_path"`
+	CertChainPath string `json:"cert_chain_path"` // leaf and chain
+	CertPath      string `json:"cert_path"`       // leaf only
+	ChainPath     string `json:"chain_path"`      // chain only
+	KeyMode       string `json:"key_mode"`        // octal, e.g. 0600
+	CertMode      string `json:"cert_mode"`       // octal, e.g. 0644
+}
+
+// FileConfig deploys files to local paths
+type FileConfig struct {
+	Files
+	Owner string `json:"owner"` // user name or uid (blank to not change)
+	Group string `json:"group"` // group name or gid (blank to not change)
+}
+
+// SSHConfig uploads files to a remote host over SSH and optionally runs a command
+// (e.g. to reload a service) afterwards
+type SSHConfig struct {
+	Files
+	Host                  string `json:"host"`
+	Port                  int    `json:"port"`
+	User                  string `json:"user"`
+	PrivateKeyID          int    `json:"private_key_id"` // stored private key used to authenticate
+	HostKey               string `json:"host_key"`       // authorized_keys format
+	InsecureIgnoreHostKey bool   `json:"insecure_ignore_host_key"`
+	ReloadCommand         string `json:"reload_command"`
+}
+
+// KubernetesConfig creates or updates a kubernetes.io/tls Secret using the Kubernetes API
+type KubernetesConfig struct {
+	ApiServer  string `json:"api_server"`
+	Token      string `json:"token"`
+	CACertPem  string `json:"ca_cert_pem"` // trusted in addition to system roots
+	Namespace  string `json:"namespace"`
+	SecretName string `json:"secret_name"`
+}
+
+// Config is the configuration of a deploy target; only the field matching the target's
+// Type is set
+type Config struct {
+	File       *FileConfig       `json:"file,omitempty"`
+	SSH        *SSHConfig        `json:"ssh,omitempty"`
+	Kubernetes *KubernetesConfig `json:"kubernetes,omitempty"`
+}
+
+// redacted returns a copy of the config with secrets redacted so it can be returned
+// by the api
+func (config Config) redacted() Config {
+	if config.Kubernetes != nil {
+		k8s := *config.Kubernetes
+		k8s.Token = output.RedactString(k8s.Token)
+		config.Kubernetes = &k8s
+	}
+
+	return config
+}
+
+// keepRedactedSecrets replaces any secret in config that was sent back in its redacted
+// form with the value from oldConfig, so clients can update a config they received from
+// the api without re-entering its secrets
+func (config *Config) keepRedactedSecrets(oldConfig Config) {
+	if config.Kubernetes != nil && oldConfig.Kubernetes != nil &&
+		config.Kubernetes.Token == output.RedactString(oldConfig.Kubernetes.Token) {
+		config.Kubernetes.Token = oldConfig.Kubernetes.Token
+	}
+}
+
+// DeployTarget is a place a certificate's key and certificate are deployed to when the
+// certificate's orders are post processed
+type DeployTarget struct {
+	ID            int
+	CertificateID int
+	Name          string
+	Description   string
+	Type          Type
+	Config        Config
+	Enabled       bool
+	CreatedAt     int
+	UpdatedAt     int
+}
+
+// deployTargetSummaryResponse is a JSON response containing only fields
+// desired for the summary
+type deployTargetSummaryResponse struct {
+	ID            int    `json:"id"`
+	CertificateID int    `json:"certificate_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Type          Type   `json:"type"`
+	Enabled       bool   `json:"enabled"`
+}
+
+func (target DeployTarget) summaryResponse() deployTargetSummaryResponse {
+	return deployTargetSummaryResponse{
+		ID:            target.ID,
+		CertificateID: target.CertificateID,
+		Name:          target.Name,
+		Description:   target.Description,
+		Type:          target.Type,
+		Enabled:       target.Enabled,
+	}
+}
+
+// deployTargetDetailedResponse is a JSON response containing all fields
+// that can be returned as JSON
+type deployTargetDetailedResponse struct {
+	deployTargetSummaryResponse
+	Config
+	CreatedAt int `json:"created_at"`
+	UpdatedAt int `json:"updated_at"`
+}
+
+func (target DeployTarget) detailedResponse() deployTargetDetailedResponse {
+	return deployTargetDetailedResponse{
+		deployTargetSummaryResponse: target.summaryResponse(),
+		Config:                      target.Config.redacted(),
+		CreatedAt:                   target.CreatedAt,
+		UpdatedAt:                   target.UpdatedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"fmt"
+	"net/http"
+)
+
+// DeleteDeployTarget deletes a deploy target from storage
+func (service *Service) DeleteDeployTarget(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id params
+	certId, targetId, outErr := service.parseIdParams(r)
+	if outErr != nil {
+		return outErr
+	}
+
+	// validation
+	target, outErr := service.GetDeployTarget(certId, targetId)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	// delete from storage
+	err := service.storage.DeleteDeployTarget(targetId)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, target.detailedResponse(), nil)
+
+	// write response
+	response := &output.JsonResponse{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("deleted deploy target (id: %d)", targetId),
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/output"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// certDeployTargetsResponse provides the json response struct
+// to answer a query for a certificate's deploy targets
+type certDeployTargetsResponse struct {
+	output.JsonResponse
+	DeployTargets []deployTargetSummaryResponse `json:"deploy_targets"`
+}
+
+// GetCertDeployTargets returns all of a certificate's deploy targets as JSON
+func (service *Service) GetCertDeployTargets(w http.ResponseWriter, r *http.Request) *output.Error {
+	// params
+	certIdParam := httprouter.ParamsFromContext(r.Context()).ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validate cert exists
+	_, outErr := service.certificates.GetCertificate(certId)
+	if outErr != nil {
+		return outErr
+	}
+
+	// get from storage
+	targets, err := service.storage.GetCertDeployTargets(certId)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// populate summaries for output
+	outputTargets := []deployTargetSummaryResponse{}
+	for i := range targets {
+		outputTargets = append(outputTargets, targets[i].summaryResponse())
+	}
+
+	// write response
+	response := &certDeployTargetsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.DeployTargets = outputTargets
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+type deployTargetResponse struct {
+	output.JsonResponse
+	DeployTarget deployTargetDetailedResponse `json:"deploy_target"`
+}
+
+// GetOneDeployTarget returns a single deploy target as JSON
+func (service *Service) GetOneDeployTarget(w http.ResponseWriter, r *http.Request) *output.Error {
+	// params
+	certId, targetId, outErr := service.parseIdParams(r)
+	if outErr != nil {
+		return outErr
+	}
+
+	// get the target from storage (and validate id)
+	target, outErr := service.GetDeployTarget(certId, targetId)
+	if outErr != nil {
+		return outErr
+	}
+
+	// write response
+	response := &deployTargetResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.DeployTarget = target.detailedResponse()
+
+	err := service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// parseIdParams returns the certificate and deploy target ids from the request's params
+func (service *Service) parseIdParams(r *http.Request) (certId int, targetId int, outErr *output.Error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certId, err := strconv.Atoi(params.ByName("certid"))
+	if err != nil {
+		service.logger.Debug(err)
+		return 0, 0, output.ErrValidationFailed
+	}
+
+	targetId, err = strconv.Atoi(params.ByName("targetid"))
+	if err != nil {
+		service.logger.Debug(err)
+		return 0, 0, output.ErrValidationFailed
+	}
+
+	return certId, targetId, nil
+}
@@ -0,0 +1,106 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// NewPayload is a struct for posting a new deploy target
+type NewPayload struct {
+	CertificateID int     `json:"-"`
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	Type          *Type   `json:"type"`
+	Config        *Config `json:"config"`
+	Enabled       *bool   `json:"enabled"`
+	CreatedAt     int     `json:"-"`
+	UpdatedAt     int     `json:"-"`
+}
+
+// PostNewDeployTarget creates a new deploy target for a certificate and saves it to storage
+func (service *Service) PostNewDeployTarget(w http.ResponseWriter, r *http.Request) *output.Error {
+	var payload NewPayload
+
+	// decode body into payload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// get cert id param
+	certIdParam := httprouter.ParamsFromContext(r.Context()).ByName("certid")
+	payload.CertificateID, err = strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// do validation
+	// cert
+	_, outErr := service.certificates.GetCertificate(payload.CertificateID)
+	if outErr != nil {
+		return outErr
+	}
+	// name (missing or invalid)
+	if payload.Name == nil || !service.nameValid(payload.CertificateID, *payload.Name, nil) {
+		service.logger.Debug(ErrNameBad)
+		return output.ErrValidationFailed
+	}
+	// description (if none, set to blank)
+	if payload.Description == nil {
+		payload.Description = new(string)
+	}
+	// type & config
+	if payload.Type == nil {
+		service.logger.Debug(ErrTypeBad)
+		return output.ErrValidationFailed
+	}
+	if payload.Config == nil {
+		service.logger.Debug(ErrConfigBad)
+		return output.ErrValidationFailed
+	}
+	err = service.validateConfig(*payload.Type, payload.Config)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+	// enabled (default true)
+	if payload.Enabled == nil {
+		payload.Enabled = new(bool)
+		*payload.Enabled = true
+	}
+	// end validation
+
+	// add additional details to the payload before saving
+	payload.CreatedAt = int(time.Now().Unix())
+	payload.UpdatedAt = payload.CreatedAt
+
+	// save to storage
+	newTarget, err := service.storage.PostNewDeployTarget(payload)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, nil, newTarget.detailedResponse())
+
+	// write response
+	response := &deployTargetResponse{}
+	response.StatusCode = http.StatusCreated
+	response.Message = "created deploy target"
+	response.DeployTarget = newTarget.detailedResponse()
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// UpdatePayload is the struct for editing an existing deploy target. Only non-nil fields
+// are updated. The type can't be changed, but the config can be replaced (in full).
+type UpdatePayload struct {
+	ID          int     `json:"-"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Config      *Config `json:"config"`
+	Enabled     *bool   `json:"enabled"`
+	UpdatedAt   int     `json:"-"`
+}
+
+// PutDeployTargetUpdate updates a deploy target that already exists in storage.
+// Only fields received in the payload (non-nil) are updated.
+func (service *Service) PutDeployTargetUpdate(w http.ResponseWriter, r *http.Request) *output.Error {
+	// parse payload
+	var payload UpdatePayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// get id params
+	certId, targetId, outErr := service.parseIdParams(r)
+	if outErr != nil {
+		return outErr
+	}
+	payload.ID = targetId
+
+	// validation
+	// id
+	oldTarget, outErr := service.GetDeployTarget(certId, payload.ID)
+	if outErr != nil {
+		return outErr
+	}
+	// name (optional - check if not nil)
+	if payload.Name != nil && !service.nameValid(certId, *payload.Name, &payload.ID) {
+		service.logger.Debug(ErrNameBad)
+		return output.ErrValidationFailed
+	}
+	// config (optional)
+	if payload.Config != nil {
+		payload.Config.keepRedactedSecrets(oldTarget.Config)
+		err = service.validateConfig(oldTarget.Type, payload.Config)
+		if err != nil {
+			service.logger.Debug(err)
+			return output.ErrValidationFailed
+		}
+	}
+	// Description and Enabled do not need validation
+	// end validation
+
+	// add additional details to the payload before saving
+	payload.UpdatedAt = int(time.Now().Unix())
+
+	// save to storage
+	updatedTarget, err := service.storage.PutDeployTargetUpdate(payload)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	audit.SetChanges(r, oldTarget.detailedResponse(), updatedTarget.detailedResponse())
+
+	// write response
+	response := &deployTargetResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "updated deploy target"
+	response.DeployTarget = updatedTarget.detailedResponse()
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
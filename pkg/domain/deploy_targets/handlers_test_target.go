@@ -0,0 +1,56 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/output"
+	"context"
+	"net/http"
+)
+
+// testTargetResponse is the JSON response to a deploy target connection test
+type testTargetResponse struct {
+	output.JsonResponse
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TestDeployTarget checks that a deploy target can be connected to (and written to)
+// without deploying anything. A failed test is reported in the response rather than
+// as an error since the request itself succeeded.
+func (service *Service) TestDeployTarget(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id params
+	certId, targetId, outErr := service.parseIdParams(r)
+	if outErr != nil {
+		return outErr
+	}
+
+	// validation
+	target, outErr := service.GetDeployTarget(certId, targetId)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	ctx, cancel := context.WithTimeout(r.Context(), testTimeout)
+	defer cancel()
+
+	err := service.testTarget(ctx, target)
+
+	// write response
+	response := &testTargetResponse{}
+	response.StatusCode = http.StatusOK
+	response.Success = err == nil
+	if err != nil {
+		response.Message = "deploy target test failed"
+		response.Error = err.Error()
+	} else {
+		response.Message = "deploy target test succeeded"
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
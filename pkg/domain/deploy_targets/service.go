@@ -0,0 +1,80 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/domain/private_keys"
+	"certwarden-backend/pkg/httpclient"
+	"certwarden-backend/pkg/output"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+var errServiceComponent = errors.New("deploy targets: necessary service component is missing")
+
+// App interface is for connecting to the main app
+type App interface {
+	GetLogger() *zap.SugaredLogger
+	GetOutputter() *output.Service
+	GetDeployTargetsStorage() Storage
+	GetCertificatesService() *certificates.Service
+	GetHttpClient() *httpclient.Client
+}
+
+// Storage interface for storage functions
+type Storage interface {
+	GetCertDeployTargets(certId int) ([]DeployTarget, error)
+	GetOneDeployTargetById(id int) (DeployTarget, error)
+
+	PostNewDeployTarget(NewPayload) (DeployTarget, error)
+	PutDeployTargetUpdate(UpdatePayload) (DeployTarget, error)
+	DeleteDeployTarget(id int) error
+
+	GetOneKeyById(id int) (private_keys.Key, error)
+}
+
+// Service struct
+type Service struct {
+	logger       *zap.SugaredLogger
+	output       *output.Service
+	storage      Storage
+	certificates *certificates.Service
+	httpClient   *httpclient.Client
+}
+
+// NewService creates a new deploy targets service
+func NewService(app App) (*Service, error) {
+	service := new(Service)
+
+	// logger
+	service.logger = app.GetLogger()
+	if service.logger == nil {
+		return nil, errServiceComponent
+	}
+
+	// output service
+	service.output = app.GetOutputter()
+	if service.output == nil {
+		return nil, errServiceComponent
+	}
+
+	// storage
+	service.storage = app.GetDeployTargetsStorage()
+	if service.storage == nil {
+		return nil, errServiceComponent
+	}
+
+	// certificates
+	service.certificates = app.GetCertificatesService()
+	if service.certificates == nil {
+		return nil, errServiceComponent
+	}
+
+	// http client
+	service.httpClient = app.GetHttpClient()
+	if service.httpClient == nil {
+		return nil, errServiceComponent
+	}
+
+	return service, nil
+}
@@ -0,0 +1,268 @@
+package deploy_targets
+
+import (
+	"certwarden-backend/pkg/output"
+	"certwarden-backend/pkg/storage"
+	"certwarden-backend/pkg/validation"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrIdBad        = errors.New("deploy target id is invalid")
+	ErrNameBad      = errors.New("deploy target name is not valid")
+	ErrTypeBad      = errors.New("deploy target type is not valid (must be file, ssh, or kubernetes)")
+	ErrConfigBad    = errors.New("deploy target config does not match its type")
+	ErrPathsNone    = errors.New("deploy target must specify at least one file path")
+	ErrPathBad      = errors.New("deploy target file paths must be absolute")
+	ErrModeBad      = errors.New("deploy target file mode is not valid (must be octal, e.g. 0600)")
+	ErrHostBad      = errors.New("deploy target ssh host or port is not valid")
+	ErrUserBad      = errors.New("deploy target ssh user is not valid")
+	ErrSSHKeyBad    = errors.New("deploy target ssh private key id is not valid (must be an existing key that can be used for ssh)")
+	ErrHostKeyBad   = errors.New("deploy target ssh host key is not valid (must be authorized_keys format, or insecure ignore host key must be enabled)")
+	ErrApiServerBad = errors.New("deploy target kubernetes api server is not valid (must be an absolute https url)")
+	ErrTokenBad     = errors.New("deploy target kubernetes token must be specified")
+	ErrCACertBad    = errors.New("deploy target kubernetes ca certificate pem is not valid")
+	ErrSecretBad    = errors.New("deploy target kubernetes namespace or secret name is not valid")
+)
+
+const (
+	defaultKeyMode  = "0600"
+	defaultCertMode = "0644"
+	defaultSSHPort  = 22
+)
+
+// GetDeployTarget returns the DeployTarget for the specified id, confirming it belongs
+// to the specified certificate
+func (service *Service) GetDeployTarget(certId int, id int) (DeployTarget, *output.Error) {
+	// basic check
+	if !validation.IsIdExistingValidRange(id) {
+		service.logger.Debug(ErrIdBad)
+		return DeployTarget{}, output.ErrValidationFailed
+	}
+
+	// get from storage
+	target, err := service.storage.GetOneDeployTargetById(id)
+	if err != nil {
+		// special error case for no record found
+		if errors.Is(err, storage.ErrNoRecord) {
+			service.logger.Debug(err)
+			return DeployTarget{}, output.ErrNotFound
+		} else {
+			service.logger.Error(err)
+			return DeployTarget{}, output.ErrStorageGeneric
+		}
+	}
+
+	// must belong to the cert
+	if target.CertificateID != certId {
+		service.logger.Debug(ErrIdBad)
+		return DeployTarget{}, output.ErrNotFound
+	}
+
+	return target, nil
+}
+
+// nameValid returns true if the specified name is acceptable and not already in use by
+// another of the certificate's deploy targets. If an id is specified, the name will also
+// be accepted if the name is already in use by the specified id.
+func (service *Service) nameValid(certId int, name string, targetId *int) bool {
+	// basic character/length check
+	if !validation.NameValid(name) {
+		return false
+	}
+
+	// make sure the name isn't already in use by the cert
+	targets, err := service.storage.GetCertDeployTargets(certId)
+	if err != nil {
+		return false
+	}
+
+	for i := range targets {
+		if targets[i].Name == name && (targetId == nil || targets[i].ID != *targetId) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateConfig checks the config is complete and valid for the specified type and
+// sets defaults for any optional fields that are blank
+func (service *Service) validateConfig(targetType Type, config *Config) error {
+	switch targetType {
+	case TypeFile:
+		if config.File == nil || config.SSH != nil || config.Kubernetes != nil {
+			return ErrConfigBad
+		}
+		return validateFiles(&config.File.Files)
+
+	case TypeSSH:
+		if config.SSH == nil || config.File != nil || config.Kubernetes != nil {
+			return ErrConfigBad
+		}
+		return service.validateSSH(config.SSH)
+
+	case TypeKubernetes:
+		if config.Kubernetes == nil || config.File != nil || config.SSH != nil {
+			return ErrConfigBad
+		}
+		return validateKubernetes(config.Kubernetes)
+
+	default:
+		return ErrTypeBad
+	}
+}
+
+// validateFiles checks the paths and modes of Files and sets default modes
+func validateFiles(files *Files) error {
+	paths := []string{files.KeyPath, files.CertChainPath, files.CertPath, files.ChainPath}
+	anyPath := false
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		anyPath = true
+		// paths are also used remotely, so check unix style absolute too
+		if !filepath.IsAbs(path) && path[0] != '/' {
+			return ErrPathBad
+		}
+	}
+	if !anyPath {
+		return ErrPathsNone
+	}
+
+	// modes
+	if files.KeyMode == "" {
+		files.KeyMode = defaultKeyMode
+	}
+	if files.CertMode == "" {
+		files.CertMode = defaultCertMode
+	}
+	if _, err := parseMode(files.KeyMode); err != nil {
+		return ErrModeBad
+	}
+	if _, err := parseMode(files.CertMode); err != nil {
+		return ErrModeBad
+	}
+
+	return nil
+}
+
+// parseMode parses an octal file mode string (e.g. 0640)
+func parseMode(mode string) (uint32, error) {
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if m > 0777 {
+		return 0, ErrModeBad
+	}
+
+	return uint32(m), nil
+}
+
+// validateSSH checks an SSHConfig and sets its defaults
+func (service *Service) validateSSH(cfg *SSHConfig) error {
+	err := validateFiles(&cfg.Files)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Port == 0 {
+		cfg.Port = defaultSSHPort
+	}
+	if cfg.Host == "" || cfg.Port < 1 || cfg.Port > 65535 {
+		return ErrHostBad
+	}
+	if cfg.User == "" {
+		return ErrUserBad
+	}
+
+	_, err = service.sshSigner(cfg.PrivateKeyID)
+	if err != nil {
+		return err
+	}
+
+	if cfg.HostKey == "" {
+		if !cfg.InsecureIgnoreHostKey {
+			return ErrHostKeyBad
+		}
+	} else {
+		_, _, _, _, err = ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+		if err != nil {
+			return ErrHostKeyBad
+		}
+	}
+
+	return nil
+}
+
+// sshSigner returns the signer for the specified stored private key
+func (service *Service) sshSigner(keyId int) (ssh.Signer, error) {
+	if !validation.IsIdExistingValidRange(keyId) {
+		return nil, ErrSSHKeyBad
+	}
+
+	key, err := service.storage.GetOneKeyById(keyId)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", ErrSSHKeyBad, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(key.Pem))
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", ErrSSHKeyBad, err)
+	}
+
+	return signer, nil
+}
+
+// validateKubernetes checks a KubernetesConfig
+func validateKubernetes(cfg *KubernetesConfig) error {
+	u, err := url.Parse(cfg.ApiServer)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return ErrApiServerBad
+	}
+
+	if cfg.Token == "" {
+		return ErrTokenBad
+	}
+
+	if cfg.CACertPem != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(cfg.CACertPem)) {
+		return ErrCACertBad
+	}
+
+	if !kubernetesNameValid(cfg.Namespace) || !kubernetesNameValid(cfg.SecretName) {
+		return ErrSecretBad
+	}
+
+	return nil
+}
+
+// kubernetesNameValid returns true if name is a valid DNS-1123 subdomain, which is
+// what Kubernetes requires for namespace and secret names
+func kubernetesNameValid(name string) bool {
+	if len(name) < 1 || len(name) > 253 {
+		return false
+	}
+
+	for i, c := range name {
+		alphaNum := (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+		if alphaNum {
+			continue
+		}
+		// '-' and '.' allowed except at start or end
+		if (c == '-' || c == '.') && i != 0 && i != len(name)-1 {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,168 @@
+package deploy_targets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFiles(t *testing.T) {
+	files := Files{CertChainPath: "/etc/ssl/cert.pem"}
+	if err := validateFiles(&files); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if files.KeyMode != defaultKeyMode || files.CertMode != defaultCertMode {
+		t.Errorf("default modes not set (key: %s, cert: %s)", files.KeyMode, files.CertMode)
+	}
+
+	bad := []Files{
+		{},
+		{KeyPath: "relative/key.pem"},
+		{KeyPath: "/etc/ssl/key.pem", KeyMode: "0999"},
+		{KeyPath: "/etc/ssl/key.pem", CertMode: "1777"},
+	}
+	for i := range bad {
+		if err := validateFiles(&bad[i]); err == nil {
+			t.Errorf("expected error for %+v", bad[i])
+		}
+	}
+}
+
+func TestKubernetesNameValid(t *testing.T) {
+	tests := map[string]bool{
+		"default":       true,
+		"my-tls.secret": true,
+		"":              false,
+		"-leading":      false,
+		"trailing.":     false,
+		"Upper":         false,
+		"under_score":   false,
+	}
+
+	for name, want := range tests {
+		if got := kubernetesNameValid(name); got != want {
+			t.Errorf("kubernetesNameValid(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"/etc/ssl/cert.pem": `'/etc/ssl/cert.pem'`,
+		"it's":              `'it'"'"'s'`,
+		"$(rm -rf /)":       `'$(rm -rf /)'`,
+	}
+
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestDeployFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &FileConfig{
+		Files: Files{
+			KeyPath:       filepath.Join(dir, "key.pem"),
+			CertChainPath: filepath.Join(dir, "certchain.pem"),
+			KeyMode:       "0600",
+			CertMode:      "0640",
+		},
+	}
+
+	err := deployFile(cfg, Content{KeyPem: "key", CertChainPem: "chain"})
+	if err != nil {
+		t.Fatalf("deploy failed: %s", err)
+	}
+
+	for path, want := range map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		cfg.KeyPath:       {"key", 0600},
+		cfg.CertChainPath: {"chain", 0640},
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %s", path, err)
+		}
+		if string(data) != want.content {
+			t.Errorf("%s content = %q, want %q", path, data, want.content)
+		}
+		info, _ := os.Stat(path)
+		if info.Mode().Perm() != want.mode {
+			t.Errorf("%s mode = %o, want %o", path, info.Mode().Perm(), want.mode)
+		}
+	}
+
+	// no temp files left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected 2 files in dir, found %d", len(entries))
+	}
+}
+
+func TestConfigRedaction(t *testing.T) {
+	config := Config{Kubernetes: &KubernetesConfig{Token: "secret-service-account-token"}}
+
+	redacted := config.redacted()
+	if redacted.Kubernetes.Token == config.Kubernetes.Token {
+		t.Fatal("token was not redacted")
+	}
+	if config.Kubernetes.Token != "secret-service-account-token" {
+		t.Fatal("redacting modified the original config")
+	}
+
+	// redacted token sent back is replaced with the stored token
+	update := Config{Kubernetes: &KubernetesConfig{Token: redacted.Kubernetes.Token}}
+	update.keepRedactedSecrets(config)
+	if update.Kubernetes.Token != config.Kubernetes.Token {
+		t.Errorf("redacted token not restored, got %s", update.Kubernetes.Token)
+	}
+
+	// new token is kept
+	update = Config{Kubernetes: &KubernetesConfig{Token: "new-token-value"}}
+	update.keepRedactedSecrets(config)
+	if update.Kubernetes.Token != "new-token-value" {
+		t.Errorf("new token replaced, got %s", update.Kubernetes.Token)
+	}
+}
+
+func TestSSHUploadCommand(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "it's key.pem")
+
+	// run the command with a local shell (in place of the remote one)
+	cmd := exec.Command("sh", "-c", sshUploadCommand(target, "0640"))
+	cmd.Stdin = strings.NewReader("content")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("upload command failed: %s (%s)", err, out)
+	}
+
+	b, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "content" {
+		t.Errorf("file content is %q, want %q", b, "content")
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("file mode is %o, want 640", info.Mode().Perm())
+	}
+
+	// temp file must not be left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the target file in dir, found %d entries", len(entries))
+	}
+}
@@ -1,10 +1,12 @@
 package orders
 
 import (
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/pagination_sort"
 	"certwarden-backend/pkg/randomness"
 	"context"
 	"database/sql"
+	"errors"
 	"sync"
 	"time"
 )
@@ -88,6 +90,9 @@ func (service *Service) startAutoOrderService(cfg *Config, ctx context.Context,
 
 			// order expiring certificates
 			service.orderExpiringCerts()
+
+			// reconcile storage with the acme servers' account orders lists
+			service.reconcileAllAccountsOrders()
 		}
 	}()
 }
@@ -116,6 +121,68 @@ func (service *Service) retryIncompleteOrders() (err error) {
 	return nil
 }
 
+// expiringAt returns the time at which the order's certificate is considered expiring. This
+// is the earlier of the two thresholds (% valid remaining and backstop time remaining).
+func (order Order) expiringAt() (time.Time, error) {
+	// nil checks (should not be possible)
+	if order.ValidFrom == nil {
+		return time.Time{}, errors.New("valid order somehow missing validFrom time")
+	}
+	if order.ValidTo == nil {
+		return time.Time{}, errors.New("valid order somehow missing validTo time")
+	}
+
+	// calculate the threshhold dates using the ratio and backstop values
+	// Option 1: validTo - (validTo - validFrom) * expiringRemainingValidFraction
+	totalDuration := order.ValidTo.Sub(*order.ValidFrom)
+	remainingValidFractionThresholdDate := order.ValidTo.Add(-1 * time.Duration(float64(totalDuration)*expiringRemainingValidFraction))
+
+	// Option 2: validTo - expiringMinRemaining
+	remainingValidMinThresholdDate := order.ValidTo.Add(-1 * expiringMinRemaining)
+
+	// whichever comes first
+	if remainingValidFractionThresholdDate.Before(remainingValidMinThresholdDate) {
+		return remainingValidFractionThresholdDate, nil
+	}
+	return remainingValidMinThresholdDate, nil
+}
+
+// ExpiringCerts returns the certificates that are expiring or that will become expiring within
+// lookahead. Expiring is determined the same way as it is for automatic ordering.
+func (service *Service) ExpiringCerts(lookahead time.Duration) ([]notifications.ExpiringCert, error) {
+	// get slice of all currently valid orders
+	allValidOrders, _, err := service.storage.GetAllValidCurrentOrders(pagination_sort.Query{})
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(lookahead)
+
+	expiringCerts := []notifications.ExpiringCert{}
+	for _, validOrder := range allValidOrders {
+		expiringAt, err := validOrder.expiringAt()
+		if err != nil {
+			service.logger.Errorf("orders: %s", err)
+			continue
+		}
+
+		if cutoff.Before(expiringAt) {
+			continue
+		}
+
+		expiringCerts = append(expiringCerts, notifications.ExpiringCert{
+			CertificateID:      validOrder.Certificate.ID,
+			CertificateName:    validOrder.Certificate.Name,
+			CertificateSubject: validOrder.Certificate.Subject,
+			OrderID:            validOrder.ID,
+			ValidTo:            *validOrder.ValidTo,
+			ExpiringAt:         expiringAt,
+		})
+	}
+
+	return expiringCerts, nil
+}
+
 // orderExpiringCerts automatically orders any certficates that have surpassed their expiration
 // threshold (either percentage wise or the hardcoded backstop value)
 func (service *Service) orderExpiringCerts() {
@@ -133,56 +200,21 @@ func (service *Service) orderExpiringCerts() {
 
 	// review every currently valid cert
 	for _, validOrder := range allValidOrders {
-		// nil checks (should not be possible)
-		if validOrder.ValidFrom == nil {
-			service.logger.Errorf("orders: valid order somehow missing validFrom time")
-			continue
-		}
-		if validOrder.ValidTo == nil {
-			service.logger.Errorf("orders: valid order somehow missing validTo time")
+		expiringAt, err := validOrder.expiringAt()
+		if err != nil {
+			service.logger.Errorf("orders: %s", err)
 			continue
 		}
 
-		// calculate the threshhold dates using the ratio and backstop values
-		// Option 1: validTo - (validTo - validFrom) * expiringRemainingValidFraction
-		totalDuration := validOrder.ValidTo.Sub(*validOrder.ValidFrom)
-		remainingValidFractionThresholdDate := validOrder.ValidTo.Add(-1 * time.Duration(float64(totalDuration)*expiringRemainingValidFraction))
-
-		// Option 2: validTo - expiringMinRemaining
-		remainingValidMinThresholdDate := validOrder.ValidTo.Add(-1 * expiringMinRemaining)
-
-		// abort if not past either threshold (% remaining and backstop time remaining)
-		if now.Before(remainingValidFractionThresholdDate) && now.Before(remainingValidMinThresholdDate) {
-			// Now is before the thresholds, so not expiring, skip this one
+		// abort if not past the threshold
+		if now.Before(expiringAt) {
+			// Now is before the threshold, so not expiring, skip this one
 			continue
 		}
 
 		// this order is considered expiring -- proceed
-
-		// check for an existing incomplete order
-		orderId, err := service.storage.GetNewestIncompleteCertOrderId(validOrder.Certificate.ID)
-		if err != nil {
-			// unable to get existing incomplete order -> place new order
-			// if error other than NoRows, log it
-			if err != sql.ErrNoRows {
-				service.logger.Errorf("orders: failed to fetch newest incomplete order id for cert %s (%s); will try to place new order", validOrder.Certificate.Name, err)
-			}
-
-			// place new order
-			service.logger.Debugf("orders: placing new order for expiring cert %s", validOrder.Certificate.Name)
-			_, outErr := service.placeNewOrderAndFulfill(validOrder.Certificate.ID, false)
-			if outErr != nil {
-				service.logger.Errorf("orders: failed to place new order for cert %s (%s)", validOrder.Certificate.Name, err)
-			}
-
-		} else {
-			// no error, retry existing order
-			service.logger.Debugf("orders: retrying order %d to refresh cert %s", orderId, validOrder.Certificate.Name)
-			err = service.fulfillOrder(orderId, false)
-			if err != nil {
-				service.logger.Errorf("orders: failed to retry order %d for cert %s (%s)", orderId, validOrder.Certificate.Name, err)
-			}
-		}
+		service.notifyCertExpiring(validOrder, now)
+		service.refreshCert(validOrder, false)
 
 		// sleep a little so slew of new orders don't hit ACME all at once
 		// cancel on shutdown context
@@ -206,3 +238,32 @@ func (service *Service) orderExpiringCerts() {
 
 	service.logger.Info("orders: expiring certificates added to order queue")
 }
+
+// refreshCert gets a new certificate to replace the certificate of validOrder, either
+// by retrying an existing incomplete order for the certificate or by placing a new one
+func (service *Service) refreshCert(validOrder Order, highPriority bool) {
+	// check for an existing incomplete order
+	orderId, err := service.storage.GetNewestIncompleteCertOrderId(validOrder.Certificate.ID)
+	if err != nil {
+		// unable to get existing incomplete order -> place new order
+		// if error other than NoRows, log it
+		if err != sql.ErrNoRows {
+			service.logger.Errorf("orders: failed to fetch newest incomplete order id for cert %s (%s); will try to place new order", validOrder.Certificate.Name, err)
+		}
+
+		// place new order
+		service.logger.Debugf("orders: placing new order for cert %s", validOrder.Certificate.Name)
+		_, outErr := service.placeNewOrderAndFulfill(validOrder.Certificate.ID, highPriority, nil)
+		if outErr != nil {
+			service.logger.Errorf("orders: failed to place new order for cert %s (%s)", validOrder.Certificate.Name, outErr)
+		}
+
+	} else {
+		// no error, retry existing order
+		service.logger.Debugf("orders: retrying order %d to refresh cert %s", orderId, validOrder.Certificate.Name)
+		err = service.fulfillOrder(orderId, highPriority)
+		if err != nil {
+			service.logger.Errorf("orders: failed to retry order %d for cert %s (%s)", orderId, validOrder.Certificate.Name, err)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/storage"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxBulkCertificates is the maximum number of certificates one bulk operation can
+	// act on
+	maxBulkCertificates = 1000
+	// maxBulkOperations is the number of bulk operations (and their results) that are
+	// kept in memory
+	maxBulkOperations = 50
+)
+
+// bulkAction is an action that can be performed on many certificates at once
+type bulkAction string
+
+const (
+	bulkActionOrder           bulkAction = "order"
+	bulkActionRotateKey       bulkAction = "rotate_key"
+	bulkActionStageApiKey     bulkAction = "stage_api_key"
+	bulkActionRemoveOldApiKey bulkAction = "remove_old_api_key"
+	bulkActionPostProcess     bulkAction = "post_process"
+)
+
+// bulkStatus is the state of a bulk operation
+type bulkStatus string
+
+const (
+	bulkStatusRunning   bulkStatus = "running"
+	bulkStatusComplete  bulkStatus = "complete"
+	bulkStatusCancelled bulkStatus = "cancelled" // app shutdown before completion
+)
+
+var (
+	errBulkActionBad    = errors.New("orders: bulk action is not valid")
+	errBulkSelectionBad = errors.New("orders: bulk operation must specify either certificate ids or a (non-empty) filter")
+	errBulkTooMany      = fmt.Errorf("orders: bulk operation exceeds max of %d certificates", maxBulkCertificates)
+	errBulkNoValidOrder = errors.New("certificate does not have a current valid order to post process")
+)
+
+// bulkPayload is the payload to perform an action on many certificates. Certificates
+// are selected by either a list of ids or a filter.
+type bulkPayload struct {
+	Action         bulkAction           `json:"action"`
+	CertificateIDs []int                `json:"certificate_ids"`
+	Filter         *certificates.Filter `json:"filter"`
+}
+
+// validate returns an error if the payload isn't valid
+func (payload bulkPayload) validate() error {
+	switch payload.Action {
+	case bulkActionOrder, bulkActionRotateKey, bulkActionStageApiKey, bulkActionRemoveOldApiKey, bulkActionPostProcess:
+		// valid
+	default:
+		return errBulkActionBad
+	}
+
+	// exactly one selection method, and the filter must select something (to avoid
+	// accidentally acting on every certificate)
+	if (len(payload.CertificateIDs) > 0) == (payload.Filter != nil) {
+		return errBulkSelectionBad
+	}
+	if payload.Filter != nil && payload.Filter.IsEmpty() {
+		return errBulkSelectionBad
+	}
+
+	if len(payload.CertificateIDs) > maxBulkCertificates {
+		return errBulkTooMany
+	}
+
+	return nil
+}
+
+// bulkItemResult is the outcome of a bulk action on one certificate
+type bulkItemResult struct {
+	CertificateID   int    `json:"certificate_id"`
+	CertificateName string `json:"certificate_name"`
+	Success         bool   `json:"success"`
+	Error           string `json:"error,omitempty"`
+	OrderID         *int   `json:"order_id,omitempty"`
+	RetiredKeyID    *int   `json:"retired_key_id,omitempty"` // rotate_key only
+}
+
+// bulkOperation is a bulk action that runs in the background, along with the results
+// for the certificates it has acted on so far
+type bulkOperation struct {
+	ID          int              `json:"id"`
+	Action      bulkAction       `json:"action"`
+	Status      bulkStatus       `json:"status"`
+	Total       int              `json:"total"`
+	Succeeded   int              `json:"succeeded"`
+	Failed      int              `json:"failed"`
+	Results     []bulkItemResult `json:"results"`
+	CreatedAt   int              `json:"created_at"`
+	CompletedAt *int             `json:"completed_at"`
+}
+
+// addResult adds the result to the operation and updates its counts
+func (op *bulkOperation) addResult(result bulkItemResult) {
+	op.Results = append(op.Results, result)
+	if result.Success {
+		op.Succeeded++
+	} else {
+		op.Failed++
+	}
+}
+
+// snapshot returns a copy of the operation that is safe to use after the lock is
+// released
+func (op *bulkOperation) snapshot() bulkOperation {
+	snap := *op
+	snap.Results = append([]bulkItemResult{}, op.Results...)
+	return snap
+}
+
+// startBulkOperation saves a new bulk operation and then performs the action on each
+// of the certs in the background. Results that are already known (e.g. certificate ids
+// that don't exist) are included in the operation's results.
+func (service *Service) startBulkOperation(action bulkAction, certs []certificates.Certificate, knownResults []bulkItemResult) bulkOperation {
+	service.bulkMu.Lock()
+	defer service.bulkMu.Unlock()
+
+	service.bulkNextId++
+	op := &bulkOperation{
+		ID:        service.bulkNextId,
+		Action:    action,
+		Status:    bulkStatusRunning,
+		Total:     len(certs) + len(knownResults),
+		Results:   []bulkItemResult{},
+		CreatedAt: int(time.Now().Unix()),
+	}
+	for _, result := range knownResults {
+		op.addResult(result)
+	}
+
+	// drop the oldest finished operations if there are too many
+	service.bulkOperations = append(service.bulkOperations, op)
+	for i := 0; len(service.bulkOperations) > maxBulkOperations && i < len(service.bulkOperations); {
+		if service.bulkOperations[i].Status == bulkStatusRunning {
+			i++
+			continue
+		}
+		service.bulkOperations = append(service.bulkOperations[:i], service.bulkOperations[i+1:]...)
+	}
+
+	service.shutdownWaitgroup.Add(1)
+	go func() {
+		defer service.shutdownWaitgroup.Done()
+		service.runBulkOperation(op, certs)
+	}()
+
+	return op.snapshot()
+}
+
+// runBulkOperation performs the operation's action on each of the certs, adding each
+// result to the operation as it completes
+func (service *Service) runBulkOperation(op *bulkOperation, certs []certificates.Certificate) {
+	status := bulkStatusComplete
+	for _, cert := range certs {
+		// stop if shutting down
+		if service.shutdownContext.Err() != nil {
+			status = bulkStatusCancelled
+			break
+		}
+
+		result := service.doBulkAction(op.Action, cert)
+		if !result.Success {
+			service.logger.Errorf("orders: bulk %s failed for certificate %s (%s)", op.Action, cert.Name, result.Error)
+		}
+
+		service.bulkMu.Lock()
+		op.addResult(result)
+		service.bulkMu.Unlock()
+	}
+
+	service.bulkMu.Lock()
+	defer service.bulkMu.Unlock()
+
+	op.Status = status
+	completedAt := int(time.Now().Unix())
+	op.CompletedAt = &completedAt
+
+	service.logger.Infof("orders: bulk %s operation %d %s (%d succeeded, %d failed)", op.Action, op.ID, status, op.Succeeded, op.Failed)
+}
+
+// getBulkOperation returns a snapshot of the specified bulk operation
+func (service *Service) getBulkOperation(id int) (bulkOperation, bool) {
+	service.bulkMu.Lock()
+	defer service.bulkMu.Unlock()
+
+	for _, op := range service.bulkOperations {
+		if op.ID == id {
+			return op.snapshot(), true
+		}
+	}
+
+	return bulkOperation{}, false
+}
+
+// doBulkAction performs action on cert and returns the result
+func (service *Service) doBulkAction(action bulkAction, cert certificates.Certificate) bulkItemResult {
+	result := bulkItemResult{
+		CertificateID:   cert.ID,
+		CertificateName: cert.Name,
+	}
+
+	var err error
+	switch action {
+	case bulkActionOrder:
+		result.OrderID, err = service.bulkPlaceOrder(cert.ID)
+
+	case bulkActionRotateKey:
+		result.OrderID, result.RetiredKeyID, err = service.bulkRotateKey(cert)
+
+	case bulkActionStageApiKey:
+		_, err = service.certificates.StageCertNewApiKey(cert)
+
+	case bulkActionRemoveOldApiKey:
+		_, err = service.certificates.PromoteCertNewApiKey(cert)
+
+	case bulkActionPostProcess:
+		result.OrderID, err = service.bulkPostProcess(cert)
+
+	default:
+		err = errBulkActionBad
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Success = true
+	}
+
+	return result
+}
+
+// bulkPlaceOrder places a new order for the cert and adds it to the low priority
+// fulfilling queue (so a large bulk operation doesn't delay manually placed orders)
+func (service *Service) bulkPlaceOrder(certId int) (*int, error) {
+	newOrder, outErr := service.placeNewOrderAndFulfill(certId, false, nil)
+	if outErr != nil {
+		return nil, outErr
+	}
+
+	return &newOrder.ID, nil
+}
+
+// bulkRotateKey rotates the cert's key (in place, the old pem is kept as a retired key
+// for the existing orders) and places a new order so the new pem is used. If the order
+// can't be placed, the key rotation is rolled back.
+func (service *Service) bulkRotateKey(cert certificates.Certificate) (orderId *int, retiredKeyId *int, err error) {
+	retiredId, err := service.certificates.RotateCertKey(cert)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orderId, err = service.bulkPlaceOrder(cert.ID)
+	if err != nil {
+		undoErr := service.certificates.UndoRotateCertKey(cert, retiredId)
+		if undoErr != nil {
+			return nil, &retiredId, fmt.Errorf("failed to place order (%s) and failed to roll back key rotation, key %s has a new pem that has no certificate yet (old pem is retired key %d) (%s)",
+				err, cert.CertificateKey.Name, retiredId, undoErr)
+		}
+		return nil, nil, fmt.Errorf("failed to place order, key rotation was rolled back (%s)", err)
+	}
+
+	return orderId, &retiredId, nil
+}
+
+// bulkPostProcess adds the cert's newest valid order to the low priority post
+// processing queue
+func (service *Service) bulkPostProcess(cert certificates.Certificate) (*int, error) {
+	order, err := service.storage.GetCertNewestValidOrderById(cert.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRecord) {
+			return nil, errBulkNoValidOrder
+		}
+		return nil, err
+	}
+
+	// verify not known revoked, not past validTo, and finalized key isn't deleted
+	if order.KnownRevoked || order.ValidTo == nil || order.ValidTo.Before(time.Now()) || order.FinalizedKey == nil {
+		return nil, errBulkNoValidOrder
+	}
+
+	err = service.postProcess(order.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order.ID, nil
+}
@@ -0,0 +1,32 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/certificates"
+	"testing"
+)
+
+func TestBulk_PayloadValidate(t *testing.T) {
+	accountId := 1
+	tooMany := make([]int, maxBulkCertificates+1)
+
+	tests := []struct {
+		name    string
+		payload bulkPayload
+		wantErr error
+	}{
+		{"ids", bulkPayload{Action: bulkActionOrder, CertificateIDs: []int{1, 2}}, nil},
+		{"filter", bulkPayload{Action: bulkActionPostProcess, Filter: &certificates.Filter{SubjectSuffix: ".example.com"}}, nil},
+		{"bad action", bulkPayload{Action: "delete", CertificateIDs: []int{1}}, errBulkActionBad},
+		{"no selection", bulkPayload{Action: bulkActionStageApiKey}, errBulkSelectionBad},
+		{"both selections", bulkPayload{Action: bulkActionRotateKey, CertificateIDs: []int{1}, Filter: &certificates.Filter{AcmeAccountID: &accountId}}, errBulkSelectionBad},
+		{"empty filter", bulkPayload{Action: bulkActionRemoveOldApiKey, Filter: &certificates.Filter{}}, errBulkSelectionBad},
+		{"too many", bulkPayload{Action: bulkActionOrder, CertificateIDs: tooMany}, errBulkTooMany},
+	}
+
+	for _, tt := range tests {
+		err := tt.payload.validate()
+		if err != tt.wantErr {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
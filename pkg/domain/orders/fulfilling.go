@@ -1,6 +1,8 @@
 package orders
 
 import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/randomness"
 	"fmt"
 	"time"
 )
@@ -9,10 +11,13 @@ import (
 // variables needed to actually Do the job
 type orderFulfillJob struct {
 	service *Service
+	jobCancel
 
+	jobID        int // persisted job record
 	addedToQueue time.Time
 	highPriority bool
 	orderID      int
+	acmeServerID int // for per server concurrency limit
 }
 
 // makeFulfillingJob makes an orderFulfillJob
@@ -34,9 +39,58 @@ func (service *Service) makeFulfillingJob(orderID int, highPriority bool) (*orde
 		addedToQueue: time.Now(),
 		highPriority: highPriority,
 		orderID:      orderID,
+		acmeServerID: order.Certificate.CertificateAccount.AcmeServer.ID,
 	}, nil
 }
 
+// rescheduleIfRateLimited checks if the account with kid is currently rate limited by
+// acmeService. If it is, a copy of the job is delayed until the rate limit expires and
+// true is returned.
+func (j *orderFulfillJob) rescheduleIfRateLimited(acmeService *acme.Service, kid string, workerID int) bool {
+	until := acmeService.RateLimitedUntil(kid)
+	if until == nil {
+		return false
+	}
+
+	// add random seconds to avoid all delayed jobs hitting the server at the same moment
+	runAt := until.Add(time.Duration(randomness.GenerateInsecureInt(60)) * time.Second)
+
+	newJob := &orderFulfillJob{
+		service: j.service,
+
+		jobID:        j.jobID,
+		addedToQueue: time.Now(),
+		highPriority: j.highPriority,
+		orderID:      j.orderID,
+		acmeServerID: j.acmeServerID,
+	}
+
+	err := j.service.orderFulfilling.AddJobAfter(newJob, runAt)
+	if err != nil {
+		// if the job is already queued, that's fine (it will run anyway)
+		j.service.logger.Debugf("orders: fulfilling worker %d: order %d: could not delay job (%s)", workerID, j.orderID, err)
+		j.service.recordJobFinished(j.jobID, nil)
+	} else {
+		j.service.recordJobDelayed(j.jobID, runAt)
+		j.service.logger.Infof("orders: fulfilling worker %d: order %d: acme rate limited, fulfillment rescheduled for %s", workerID, j.orderID, runAt.Format(time.RFC1123))
+	}
+
+	return true
+}
+
+// cleanupInvalidOrderAuths deactivates any authorizations of an invalid order that are
+// still pending, so they don't linger and count against the CA's pending authorization
+// limits. Errors are logged only.
+func (j *orderFulfillJob) cleanupInvalidOrderAuths(acmeOrder acme.Order, accountId int, key acme.AccountKey, acmeService *acme.Service, workerID int) {
+	deactivated, err := j.service.authorizations.DeactivateAuths(acmeOrder.Authorizations, accountId, key, acmeService, false)
+	if err != nil {
+		j.service.logger.Errorf("orders: fulfilling worker %d: failed to clean up pending authorizations (%s)", workerID, err)
+	}
+	if deactivated > 0 {
+		j.service.logger.Infof("orders: fulfilling worker %d: deactivated %d pending authorization(s) of invalid order %d", workerID, deactivated, j.orderID)
+	}
+}
+
 // Description implements part of the Job interface and returns a string
 // that will be used for logging purposes
 func (j *orderFulfillJob) Description() string {
@@ -53,3 +107,14 @@ func (j *orderFulfillJob) Equal(j2 *orderFulfillJob) bool {
 func (j *orderFulfillJob) IsHighPriority() bool {
 	return j.highPriority
 }
+
+// ConcurrencyGroup implements job_manager's GroupedJob so the number of orders being
+// fulfilled at the same time for one ACME server can be capped
+func (j *orderFulfillJob) ConcurrencyGroup() (group string, limit int) {
+	return fmt.Sprintf("acme server %d", j.acmeServerID), j.service.acmeServerService.MaxConcurrentOrders(j.acmeServerID)
+}
+
+// persistedID returns the id of the job's persisted record
+func (j *orderFulfillJob) persistedID() int {
+	return j.jobID
+}
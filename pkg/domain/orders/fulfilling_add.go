@@ -2,6 +2,7 @@ package orders
 
 import (
 	"fmt"
+	"time"
 )
 
 // fulfillOrder queues the specified order ID with the specified priority level
@@ -13,9 +14,31 @@ func (service *Service) fulfillOrder(orderID int, isHighPriority bool) (err erro
 		return err
 	}
 
+	// don't persist a duplicate
+	if service.orderFulfilling.JobExists(newJob) != nil {
+		return fmt.Errorf("orders: fulfilling: failed to add order id %d (job already exists)", orderID)
+	}
+
+	// persist the job so it can be recovered after a restart
+	newJob.jobID, err = service.storage.PostJob(Job{
+		Kind:         JobKindFulfill,
+		OrderID:      orderID,
+		HighPriority: isHighPriority,
+		State:        JobStateQueued,
+		CreatedAt:    int(newJob.addedToQueue.Unix()),
+		UpdatedAt:    int(time.Now().Unix()),
+	})
+	if err != nil {
+		return fmt.Errorf("orders: fulfilling: failed to save job for order id %d (%s)", orderID, err)
+	}
+
 	// add to the Job Manager
 	err = service.orderFulfilling.AddJob(newJob)
 	if err != nil {
+		// remove the record of the job that wasn't added
+		if deleteErr := service.storage.DeleteJob(newJob.jobID); deleteErr != nil {
+			service.logger.Errorf("orders: fulfilling: failed to delete job %d record (%s)", newJob.jobID, deleteErr)
+		}
 		return fmt.Errorf("orders: fulfilling: failed to add order id %d (%s)", orderID, err)
 	}
 
@@ -2,8 +2,10 @@ package orders
 
 import (
 	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/datatypes/order_events"
 	"certwarden-backend/pkg/randomness"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -13,6 +15,36 @@ func (j *orderFulfillJob) Do(workerID int) {
 	// log end of Do (regardless of outcome)
 	defer j.service.logger.Infof("orders: fulfilling worker %d: order %d done", workerID, j.orderID)
 
+	// record job state (a job interrupted by shutdown is left running so it is recovered
+	// on the next start, and a rescheduled job already recorded its delay)
+	j.service.recordJobRunning(j.jobID, workerID)
+	recordEvent := j.service.orderEventRecorder(j.orderID)
+
+	// job context (canceled on shutdown or if the job is canceled)
+	ctx, cancel := j.start(j.service.shutdownContext)
+	defer cancel()
+
+	var err error
+	completed := false
+	rescheduled := false
+	defer func() {
+		if j.service.shutdownContext.Err() != nil || rescheduled {
+			return
+		}
+		if j.wasCanceled() {
+			j.service.recordJobFinished(j.jobID, errJobCanceled)
+			recordEvent.Record(order_events.TypeFulfillFailed, "", errJobCanceled.Error())
+		} else if completed {
+			j.service.recordJobFinished(j.jobID, nil)
+		} else if err != nil {
+			j.service.recordJobFinished(j.jobID, err)
+			recordEvent.Record(order_events.TypeFulfillFailed, "", err.Error())
+		} else {
+			j.service.recordJobFinished(j.jobID, errJobNotCompleted)
+			recordEvent.Record(order_events.TypeFulfillFailed, "", errJobNotCompleted.Error())
+		}
+	}()
+
 	// get the relevant order from db
 	order, err := j.service.storage.GetOneOrder(j.orderID)
 	if err != nil {
@@ -23,9 +55,23 @@ func (j *orderFulfillJob) Do(workerID int) {
 	// always info log ordering
 	j.service.logger.Infof("orders: fulfilling worker %d: ordering order id %d (certificate name: %s, subject: %s)", workerID, order.ID, order.Certificate.Name, order.Certificate.Subject)
 
+	// acmeOrder to hold the Order responses and to later update storage
+	var acmeOrder acme.Order
+
+	// record and notify of the outcome when the fulfiller is done (unless shutting down or
+	// rescheduled due to rate limiting, in which case the order isn't done and will be retried
+	// later, or canceled by the user)
+	defer func() {
+		if j.service.shutdownContext.Err() != nil || rescheduled || j.wasCanceled() {
+			return
+		}
+		j.service.observeOrderOutcome(acmeOrder, completed)
+		j.service.notifyOrderOutcome(order, acmeOrder, completed, err)
+	}()
+
 	// update certificate timestamp after fulfiller is done
 	defer func() {
-		err = j.service.storage.UpdateCertUpdatedTime(order.Certificate.ID)
+		err := j.service.storage.UpdateCertUpdatedTime(order.Certificate.ID)
 		if err != nil {
 			j.service.logger.Errorf("orders: fulfilling worker %d: update cert time error: %s", workerID, err)
 		}
@@ -45,9 +91,6 @@ func (j *orderFulfillJob) Do(workerID int) {
 		return // done, failed
 	}
 
-	// acmeOrder to hold the Order responses and to later update storage
-	var acmeOrder acme.Order
-
 	// acmeService to avoid repeated logic
 	acmeService, err := j.service.acmeServerService.AcmeService(order.Certificate.CertificateAccount.AcmeServer.ID)
 	if err != nil {
@@ -55,8 +98,14 @@ func (j *orderFulfillJob) Do(workerID int) {
 		return // done, failed
 	}
 
+	// if rate limited, don't contact the server and instead try again once the limit expires
+	if j.rescheduleIfRateLimited(acmeService, key.Kid, workerID) {
+		rescheduled = true
+		return // done, rescheduled
+	}
+
 	// exponential backoff for retrying while 'processing'
-	bo := randomness.BackoffACME(j.service.shutdownContext)
+	bo := randomness.BackoffACME(ctx)
 
 	// Use loop to retry order. Cap loop at 2 hours to avoid indefinite loop if something unexpected
 	// occurs (e.g., somethign broken with the acme server).
@@ -65,6 +114,12 @@ func (j *orderFulfillJob) Do(workerID int) {
 
 fulfillLoop:
 	for time.Since(startTime) <= timeoutLength {
+		// stop if canceled (or shutting down)
+		if ctx.Err() != nil {
+			j.service.logger.Infof("orders: fulfilling worker %d: order job canceled", workerID)
+			return
+		}
+
 		// Get the order (for most recent Order object and Status)
 		acmeOrder, err = acmeService.GetOrder(order.Location, key)
 		if err != nil {
@@ -76,10 +131,16 @@ fulfillLoop:
 			acmeErr := new(acme.Error)
 			if errors.As(err, &acmeErr) && acmeErr.Status == http.StatusNotFound {
 				j.service.storage.PutOrderInvalid(order.ID)
+				acmeOrder.Status = "invalid"
+				completed = true
 				return // done, permanent status
 			}
 
 			j.service.logger.Errorf("orders: fulfilling worker %d: get order error: %s", workerID, err)
+			if j.rescheduleIfRateLimited(acmeService, key.Kid, workerID) {
+				rescheduled = true
+				return // done, rescheduled
+			}
 			return // done, failed
 		}
 
@@ -94,9 +155,13 @@ fulfillLoop:
 		switch acmeOrder.Status {
 
 		case "pending": // needs to be authed
-			err = j.service.authorizations.FulfillAuths(acmeOrder.Authorizations, key, acmeService)
+			err = j.service.authorizations.FulfillAuths(ctx, acmeOrder.Authorizations, order.Certificate.CertificateAccount.ID, key, acmeService, recordEvent)
 			if err != nil {
 				j.service.logger.Errorf("orders: fulfilling worker %d: fulfill auths error: %s", workerID, err)
+				if j.rescheduleIfRateLimited(acmeService, key.Kid, workerID) {
+					rescheduled = true
+					return // done, rescheduled
+				}
 				return // done, failed
 			}
 
@@ -114,8 +179,13 @@ fulfillLoop:
 			_, err = acmeService.FinalizeOrder(acmeOrder.Finalize, csr, key)
 			if err != nil {
 				j.service.logger.Errorf("orders: fulfilling worker %d: finalize order error: %s", workerID, err)
+				if j.rescheduleIfRateLimited(acmeService, key.Kid, workerID) {
+					rescheduled = true
+					return // done, rescheduled
+				}
 				return // done, failed
 			}
+			recordEvent.Record(order_events.TypeFinalized, "", acmeOrder.Finalize)
 
 			// should be valid on next check (or maybe processing - sleep a little to try and avoid 'processing')
 			time.Sleep(7 * time.Second)
@@ -128,35 +198,50 @@ fulfillLoop:
 				continue
 			}
 
-			cert, err := acmeService.DownloadCertificate(*acmeOrder.Certificate, key, order.Certificate.PreferredRootCN)
+			var chains []*acme.Certificate
+			chains, err = acmeService.DownloadCertificateChains(*acmeOrder.Certificate, key)
 			if err != nil {
 				j.service.logger.Errorf("orders: fulfilling worker %d: download cert error: %s", workerID, err)
+				if j.rescheduleIfRateLimited(acmeService, key.Kid, workerID) {
+					rescheduled = true
+					return // done, rescheduled
+				}
 				return // done, failed
 			}
 
-			// process pem and save to storage
-			err = j.saveAcmeCert(order.ID, cert)
+			// process preferred chain's pem and save to storage
+			err = j.saveAcmeCert(order.ID, acme.SelectChain(chains, order.Certificate.PreferredRootCN))
 			if err != nil {
 				j.service.logger.Errorf("orders: fulfilling worker %d: save pem error: %s", workerID, err)
 				return // done, failed
 			}
 
+			recordEvent.Record(order_events.TypeDownloaded, "", fmt.Sprintf("%d chain(s) from %s", len(chains), *acmeOrder.Certificate))
+
+			// also keep all chains
+			j.service.saveOrderChains(order.ID, chains)
+
 			// done
 			break fulfillLoop
 
 		case "processing":
-			// sleep and loop again, ACME server is working on it
-			delayTimer := time.NewTimer(bo.NextBackOff())
+			// sleep and loop again, ACME server is working on it (honor the server's Retry-After
+			// if it is longer than the backoff)
+			delay := bo.NextBackOff()
+			if acmeOrder.RetryAfter != nil && time.Until(*acmeOrder.RetryAfter) > delay {
+				delay = time.Until(*acmeOrder.RetryAfter)
+			}
+			delayTimer := time.NewTimer(delay)
 
 			select {
-			// cancel on shutdown context
-			case <-j.service.shutdownContext.Done():
+			// cancel on job context (shutdown or canceled job)
+			case <-ctx.Done():
 				// ensure timer releases resources
 				if !delayTimer.Stop() {
 					<-delayTimer.C
 				}
 
-				j.service.logger.Errorf("orders: fulfilling worker %d: order job canceled due to shutdown", workerID)
+				j.service.logger.Errorf("orders: fulfilling worker %d: order job canceled", workerID)
 				return
 
 			case <-delayTimer.C:
@@ -165,6 +250,12 @@ fulfillLoop:
 
 		case "invalid": // break, irrecoverable - final status
 			j.service.logger.Infof("orders: fulfilling worker %d: order status invalid; acme error: %s", workerID, acmeOrder.Error)
+			if acmeOrder.Error != nil {
+				recordEvent.Record(order_events.TypeFulfillFailed, "", fmt.Sprintf("order status invalid; acme error: %s", acmeOrder.Error))
+			} else {
+				recordEvent.Record(order_events.TypeFulfillFailed, "", "order status invalid")
+			}
+			j.cleanupInvalidOrderAuths(acmeOrder, order.Certificate.CertificateAccount.ID, key, acmeService, workerID)
 			break fulfillLoop
 
 		// Note: there is no 'expired' Status case. If the order expires it simply moves to 'invalid'.
@@ -194,7 +285,7 @@ fulfillLoop:
 	// if order valid, do post processing
 	if acmeOrder.Status == "valid" {
 		// send to post-processing queue
-		if order.hasPostProcessingToDo() {
+		if j.service.hasPostProcessingToDo(order) {
 			err = j.service.postProcess(j.orderID, j.IsHighPriority())
 			if err != nil {
 				j.service.logger.Errorf("orders: fulfilling worker %d: failed to add post process job (%s)")
@@ -213,6 +304,7 @@ fulfillLoop:
 	}
 
 	// success
+	completed = true
 	j.service.logger.Infof("orders: fulfilling worker %d: order id %d completed with status %s (certificate name: %s, subject: %s)", workerID, order.ID, acmeOrder.Status, order.Certificate.Name, order.Certificate.Subject)
 
 }
@@ -0,0 +1,125 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// bulkOperationResponse is the JSON response containing a bulk operation
+type bulkOperationResponse struct {
+	output.JsonResponse
+	Operation bulkOperation `json:"bulk_operation"`
+}
+
+// BulkCertificates starts an action (order, key rotation, api key staging or removal,
+// or post processing) on each of the specified certificates. The action runs in the
+// background; the response contains the operation id which can be used to fetch the
+// result for each certificate. A failure for one certificate does not stop the
+// remaining ones and is reported in the results.
+// endpoint: /api/v1/bulk/certificates
+func (service *Service) BulkCertificates(w http.ResponseWriter, r *http.Request) *output.Error {
+	// decode payload
+	var payload bulkPayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	err = payload.validate()
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+	// validation -- end
+
+	// select certificates
+	certs := []certificates.Certificate{}
+	knownResults := []bulkItemResult{}
+	if payload.Filter != nil {
+		certs, err = service.certificates.FilterCertificates(*payload.Filter)
+		if err != nil {
+			service.logger.Error(err)
+			return output.ErrStorageGeneric
+		}
+		if len(certs) > maxBulkCertificates {
+			service.logger.Debug(errBulkTooMany)
+			return output.ErrValidationFailed
+		}
+	} else {
+		seen := make(map[int]struct{})
+		for _, certId := range payload.CertificateIDs {
+			// skip duplicates
+			if _, exists := seen[certId]; exists {
+				continue
+			}
+			seen[certId] = struct{}{}
+
+			cert, outErr := service.certificates.GetCertificate(certId)
+			if outErr != nil {
+				knownResults = append(knownResults, bulkItemResult{
+					CertificateID: certId,
+					Error:         outErr.Message,
+				})
+				continue
+			}
+			certs = append(certs, cert)
+		}
+	}
+
+	// start in background
+	op := service.startBulkOperation(payload.Action, certs, knownResults)
+
+	// write response
+	response := &bulkOperationResponse{}
+	response.StatusCode = http.StatusAccepted
+	response.Message = fmt.Sprintf("bulk %s operation %d started for %d certificate(s)", op.Action, op.ID, op.Total)
+	response.Operation = op
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// GetBulkOperation returns the status of a bulk operation and the results for the
+// certificates it has acted on so far
+// endpoint: /api/v1/bulk/certificates/:operationid
+func (service *Service) GetBulkOperation(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("operationid")
+	opId, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	op, exists := service.getBulkOperation(opId)
+	if !exists {
+		return output.ErrNotFound
+	}
+
+	// write response
+	response := &bulkOperationResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Operation = op
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
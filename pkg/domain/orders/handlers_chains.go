@@ -0,0 +1,217 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/output"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// orderChainsResponse is the response for an order's available chains
+type orderChainsResponse struct {
+	output.JsonResponse
+	Chains []orderChainResponse `json:"chains"`
+}
+
+// getOrderChains returns the chains stored for the order. If none are stored yet (e.g.
+// the order was completed before chains were stored), the chains are downloaded from
+// the ACME server and saved.
+func (service *Service) getOrderChains(order Order) ([]OrderChain, *output.Error) {
+	chains, err := service.storage.GetOrderChains(order.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return nil, output.ErrStorageGeneric
+	}
+
+	// stored chains or nothing to download
+	if len(chains) > 0 || order.Status != "valid" || order.CertificateUrl == nil {
+		return chains, nil
+	}
+
+	// download chains
+	key, err := order.Certificate.CertificateAccount.AcmeAccountKey()
+	if err != nil {
+		service.logger.Error(err)
+		return nil, output.ErrInternal
+	}
+
+	acmeService, err := service.acmeServerService.AcmeService(order.Certificate.CertificateAccount.AcmeServer.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return nil, output.ErrInternal
+	}
+
+	// don't send if rate limited
+	if acmeService.RateLimitedUntil(key.Kid) != nil {
+		return nil, output.ErrAcmeRateLimited
+	}
+
+	acmeChains, err := acmeService.DownloadCertificateChains(*order.CertificateUrl, key)
+	if err != nil {
+		service.logger.Error(err)
+		if acmeService.RateLimitedUntil(key.Kid) != nil {
+			return nil, output.ErrAcmeRateLimited
+		}
+		return nil, output.ErrInternal
+	}
+
+	service.saveOrderChains(order.ID, acmeChains)
+
+	return makeOrderChains(acmeChains), nil
+}
+
+// GetOrderChains is a handler that returns all of the certificate chains (default and
+// alternates) offered by the ACME server for the order, along with each chain's issuers
+// endpoint: /api/v1/certificates/:certid/orders/:orderid/chains
+func (service *Service) GetOrderChains(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get params
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certIdParam := params.ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	orderIdParam := params.ByName("orderid")
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation / get order
+	order, outErr := service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	chains, outErr := service.getOrderChains(order)
+	if outErr != nil {
+		return outErr
+	}
+
+	// write response
+	response := &orderChainsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Chains = []orderChainResponse{}
+	for _, chain := range chains {
+		response.Chains = append(response.Chains, orderChainResponse{
+			Index:    chain.Index,
+			URL:      chain.URL,
+			RootCN:   chain.RootCN,
+			Issuers:  chain.Issuers,
+			Selected: chain.Pem == order.PemContent(),
+			Default:  chain.Index == 0,
+		})
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// SelectOrderChain is a handler that switches the order's certificate pem to the
+// specified stored chain, without downloading it from the ACME server again
+// endpoint: /api/v1/certificates/:certid/orders/:orderid/chains/:chainindex/select
+func (service *Service) SelectOrderChain(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get params
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certIdParam := params.ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	orderIdParam := params.ByName("orderid")
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	chainIndexParam := params.ByName("chainindex")
+	chainIndex, err := strconv.Atoi(chainIndexParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation / get order
+	order, outErr := service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+
+	chains, outErr := service.getOrderChains(order)
+	if outErr != nil {
+		return outErr
+	}
+
+	var chain *OrderChain
+	for i := range chains {
+		if chains[i].Index == chainIndex {
+			chain = &chains[i]
+			break
+		}
+	}
+	if chain == nil {
+		return output.ErrNotFound
+	}
+	// end validation
+
+	acmeCert, err := acme.ParseCertificateChain(chain.Pem)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+
+	err = service.storage.UpdateOrderCert(order.ID, &CertPayload{
+		AcmeCert:  acmeCert,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// update Server Cert (if this order was for this app)
+	if service.serverCertificateName != nil && *service.serverCertificateName == order.Certificate.Name {
+		err = service.loadHttpsCertificateFunc()
+		if err != nil {
+			service.logger.Errorf("orders: failed to load app's https certificate after chain change (%s)", err)
+		}
+	}
+
+	// get updated order
+	order, outErr = service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+
+	// write response
+	response := &orderResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "selected order chain"
+	response.Order = order.summaryResponse(service)
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
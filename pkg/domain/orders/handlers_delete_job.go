@@ -0,0 +1,65 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/output"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// deleteJob cancels the job of the specified kind using the job id param
+func (service *Service) deleteJob(w http.ResponseWriter, r *http.Request, kind JobKind) *output.Error {
+	// get params
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("jobid")
+	jobId, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// cancel
+	running, err := service.cancelJob(kind, jobId)
+	if err != nil {
+		if errors.Is(err, errJobNotFound) {
+			service.logger.Debug(err)
+			return output.ErrNotFound
+		}
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// write response
+	response := &output.JsonResponse{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("canceled job (id: %d)", jobId),
+	}
+	if running {
+		response.StatusCode = http.StatusAccepted
+		response.Message = fmt.Sprintf("canceling running job (id: %d)", jobId)
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// DeleteFulfillJob cancels a fulfilling job; a waiting or delayed job is removed from
+// the queue and a running job is stopped
+// endpoint: /api/v1/orders/fulfilling/jobs/:jobid
+func (service *Service) DeleteFulfillJob(w http.ResponseWriter, r *http.Request) *output.Error {
+	return service.deleteJob(w, r, JobKindFulfill)
+}
+
+// DeletePostProcessJob cancels a post processing job; a waiting job is removed from
+// the queue and a running job is stopped
+// endpoint: /api/v1/orders/post-process/jobs/:jobid
+func (service *Service) DeletePostProcessJob(w http.ResponseWriter, r *http.Request) *output.Error {
+	return service.deleteJob(w, r, JobKindPostProcess)
+}
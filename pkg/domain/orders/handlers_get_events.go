@@ -0,0 +1,67 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/output"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// orderEventsResponse is the response for an order's event history
+type orderEventsResponse struct {
+	output.JsonResponse
+	Events []orderEventResponse `json:"events"`
+}
+
+// GetOrderEvents is a handler that returns the event history (the steps taken while
+// creating, fulfilling, and post processing) of the specified order, oldest first
+// endpoint: /api/v1/certificates/:certid/orders/:orderid/events
+func (service *Service) GetOrderEvents(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get params
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certIdParam := params.ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	orderIdParam := params.ByName("orderid")
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation / get order
+	order, outErr := service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	events, err := service.storage.GetOrderEvents(order.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// write response
+	response := &orderEventsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Events = []orderEventResponse{}
+	for _, event := range events {
+		response.Events = append(response.Events, event.response())
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
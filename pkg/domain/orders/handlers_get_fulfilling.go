@@ -3,90 +3,109 @@ package orders
 import (
 	"certwarden-backend/pkg/output"
 	"net/http"
+	"time"
 )
 
 // orderJobResponse contains the json response struct for one order job
 type orderJobResponse struct {
+	JobID        int                  `json:"job_id"`
 	AddedToQueue int                  `json:"added_to_queue"` // unix time job was requested
 	HighPriority bool                 `json:"high_priority"`
 	Order        orderSummaryResponse `json:"order"`
 }
 
+// orderDelayedJobResponse contains the json response struct for one delayed order job
+type orderDelayedJobResponse struct {
+	orderJobResponse
+	RunAt int `json:"run_at"` // unix time job will be added to the queue
+}
+
 // orderWorkStatusResponse contains the full response to a GET request for the status
 // of the a work service
 type orderWorkStatusResponse struct {
 	output.JsonResponse
 	JobsWorking map[int]*orderJobResponse `json:"jobs_working"` // [workerid]
 	JobsWaiting []orderJobResponse        `json:"jobs_waiting"`
+	JobsDelayed []orderDelayedJobResponse `json:"jobs_delayed"`
 }
 
-// GetFulfillWorkStatus returns all fulfilling jobs with workers and waiting in queue
-func (service *Service) GetFulfillWorkStatus(w http.ResponseWriter, r *http.Request) *output.Error {
-	// get jobs from manager
-	mgrJobs := service.orderFulfilling.AllCurrentJobs()
+// makeWorkStatusResponse builds the work status response for the specified kind of
+// job from the persisted jobs that are queued or running
+func (service *Service) makeWorkStatusResponse(kind JobKind, workerIDs []int) (*orderWorkStatusResponse, error) {
+	// get jobs from storage
+	jobs, err := service.storage.GetActiveJobs(kind)
+	if err != nil {
+		return nil, err
+	}
 
 	// get Order IDs for all jobs (to query db)
 	orderIDs := []int{}
-	for _, mgrWorkingJob := range mgrJobs.WorkingJobs {
-		// only add working if work isn't idle (i.e. it has a job)
-		if mgrWorkingJob != nil {
-			orderIDs = append(orderIDs, mgrWorkingJob.orderID)
-		}
-	}
-	for _, mgrWaitingJob := range mgrJobs.WaitingJobs {
-		orderIDs = append(orderIDs, mgrWaitingJob.orderID)
+	for _, job := range jobs {
+		orderIDs = append(orderIDs, job.OrderID)
 	}
 
 	// lookup all orders in db
 	orders, err := service.storage.GetOrders(orderIDs)
 	if err != nil {
-		service.logger.Errorf("orders: failed to convert fulfilling jobs to order objects (%s)", err)
-		return output.ErrInternal
+		return nil, err
 	}
 
-	// build working part of response
+	// workers are idle unless a running job says otherwise
 	workingResp := make(map[int]*orderJobResponse)
-	for workerID, mgrWorkingJob := range mgrJobs.WorkingJobs {
-		// find order that matches this workerID, and then make response
-		if mgrWorkingJob == nil {
-			workingResp[workerID] = nil
-		} else {
-			for _, order := range orders {
-				if mgrWorkingJob.orderID == order.ID {
-					workingResp[workerID] = &orderJobResponse{
-						AddedToQueue: int(mgrWorkingJob.addedToQueue.Unix()),
-						HighPriority: mgrWorkingJob.IsHighPriority(),
-						Order:        order.summaryResponse(service),
-					}
-					break
-				}
-			}
-		}
+	for _, workerID := range workerIDs {
+		workingResp[workerID] = nil
 	}
-
-	// build waiting part of response
 	waitingResp := []orderJobResponse{}
-	for _, mgrWaitingJob := range mgrJobs.WaitingJobs {
+	delayedResp := []orderDelayedJobResponse{}
+
+	now := time.Now()
+	for _, job := range jobs {
 		for _, order := range orders {
-			if mgrWaitingJob.orderID == order.ID {
-				waitingResp = append(waitingResp, orderJobResponse{
-					AddedToQueue: int(mgrWaitingJob.addedToQueue.Unix()),
-					HighPriority: mgrWaitingJob.IsHighPriority(),
-					Order:        order.summaryResponse(service),
+			if job.OrderID != order.ID {
+				continue
+			}
+
+			jobResp := orderJobResponse{
+				JobID:        job.ID,
+				AddedToQueue: job.CreatedAt,
+				HighPriority: job.HighPriority,
+				Order:        order.summaryResponse(service),
+			}
+
+			switch {
+			case job.State == JobStateRunning && job.WorkerID != nil:
+				workingResp[*job.WorkerID] = &jobResp
+			case job.delayed(now):
+				delayedResp = append(delayedResp, orderDelayedJobResponse{
+					orderJobResponse: jobResp,
+					RunAt:            *job.RunAt,
 				})
-				break
+			default:
+				waitingResp = append(waitingResp, jobResp)
 			}
+
+			break
 		}
 	}
 
-	// final response
-	jobsResp := &orderWorkStatusResponse{
+	return &orderWorkStatusResponse{
 		JsonResponse: output.JsonResponse{
 			StatusCode: http.StatusOK,
 			Message:    "ok",
 		},
 		JobsWorking: workingResp,
 		JobsWaiting: waitingResp,
+		JobsDelayed: delayedResp,
+	}, nil
+}
+
+// GetFulfillWorkStatus returns all fulfilling jobs with workers, waiting in queue, and
+// delayed (e.g. due to rate limiting)
+func (service *Service) GetFulfillWorkStatus(w http.ResponseWriter, r *http.Request) *output.Error {
+	jobsResp, err := service.makeWorkStatusResponse(JobKindFulfill, service.orderFulfilling.WorkerIDs())
+	if err != nil {
+		service.logger.Errorf("orders: failed to get fulfilling jobs (%s)", err)
+		return output.ErrInternal
 	}
 
 	// serve final response
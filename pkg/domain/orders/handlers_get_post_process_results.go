@@ -0,0 +1,67 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/output"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// postProcessResultsResponse is the response for an order's post processing history
+type postProcessResultsResponse struct {
+	output.JsonResponse
+	Results []postProcessResultResponse `json:"post_process_results"`
+}
+
+// GetOrderPostProcessResults is a handler that returns the outcome of each post processing
+// run (client and command) of the specified order, newest first
+// endpoint: /api/v1/certificates/:certid/orders/:orderid/postprocess/results
+func (service *Service) GetOrderPostProcessResults(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get params
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certIdParam := params.ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	orderIdParam := params.ByName("orderid")
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation / get order
+	order, outErr := service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	results, err := service.storage.GetPostProcessResults(order.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+
+	// write response
+	response := &postProcessResultsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Results = []postProcessResultResponse{}
+	for _, result := range results {
+		response.Results = append(response.Results, result.response())
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"certwarden-backend/pkg/output"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -16,6 +17,12 @@ type orderResponse struct {
 	Order orderSummaryResponse `json:"order"`
 }
 
+// newOrderPayload contains the optional settings for a manually placed order
+type newOrderPayload struct {
+	// ValidityHours overrides the certificate's requested validity (0 for CA's default)
+	ValidityHours *int `json:"validity_hours"`
+}
+
 // NewOrder sends the account information to the ACME new-order endpoint
 // which creates a new order for the certificate. If an order already exists
 // ACME may send back the existing order instead of creating a new one
@@ -29,14 +36,28 @@ func (service *Service) NewOrder(w http.ResponseWriter, r *http.Request) *output
 		return output.ErrValidationFailed
 	}
 
+	// decode optional payload (body may be empty)
+	var payload newOrderPayload
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil && !errors.Is(err, io.EOF) {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
 	// get certificate (validate exists)
 	_, outErr := service.certificates.GetCertificate(certId)
 	if outErr != nil {
 		return outErr
 	}
 
+	// validity (optional)
+	if payload.ValidityHours != nil && *payload.ValidityHours < 0 {
+		service.logger.Debug(errors.New("orders: validity hours must not be negative"))
+		return output.ErrValidationFailed
+	}
+
 	// place order and kickoff high-priority fulfillment
-	newOrder, outErr := service.placeNewOrderAndFulfill(certId, true)
+	newOrder, outErr := service.placeNewOrderAndFulfill(certId, true, payload.ValidityHours)
 	if outErr != nil {
 		return outErr
 	}
@@ -189,6 +210,7 @@ func (service *Service) RevokeOrder(w http.ResponseWriter, r *http.Request) *out
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	service.notifyCertRevoked(order, payload.ReasonCode)
 
 	// update certificate timestamp
 	err = service.storage.UpdateCertUpdatedTime(certId)
@@ -217,3 +239,81 @@ func (service *Service) RevokeOrder(w http.ResponseWriter, r *http.Request) *out
 
 	return nil
 }
+
+// deactivateAuthsResponse is the response to deactivating an order's authorizations
+type deactivateAuthsResponse struct {
+	orderResponse
+	Deactivated int `json:"deactivated_authorizations"`
+}
+
+// DeactivateOrderAuths is a handler that deactivates the order's authorizations that
+// are 'pending' or 'valid'. This releases pending authorizations (which count against
+// some CAs' limits) and removes the account's authority for the identifiers.
+func (service *Service) DeactivateOrderAuths(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get params
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certIdParam := params.ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	orderIdParam := params.ByName("orderid")
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation / get order
+	order, outErr := service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+	// end validation
+
+	// get account key
+	key, err := order.Certificate.CertificateAccount.AcmeAccountKey()
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+
+	acmeService, err := service.acmeServerService.AcmeService(order.Certificate.CertificateAccount.AcmeServer.ID)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+
+	// don't send if rate limited
+	if acmeService.RateLimitedUntil(key.Kid) != nil {
+		return output.ErrAcmeRateLimited
+	}
+
+	// deactivate
+	deactivated, err := service.authorizations.DeactivateAuths(order.Authorizations, order.Certificate.CertificateAccount.ID, key, acmeService, true)
+	if err != nil {
+		service.logger.Error(err)
+		if acmeService.RateLimitedUntil(key.Kid) != nil {
+			return output.ErrAcmeRateLimited
+		}
+		return output.ErrInternal
+	}
+
+	// write response
+	response := &deactivateAuthsResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "deactivated order authorizations"
+	response.Order = order.summaryResponse(service)
+	response.Deactivated = deactivated
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
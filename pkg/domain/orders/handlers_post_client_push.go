@@ -0,0 +1,90 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/output"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+var errNoClientKey = errors.New("certificate does not have a client key")
+
+// clientPushResponse is the response to a manual client push
+type clientPushResponse struct {
+	output.JsonResponse
+	Results []postProcessResultResponse `json:"post_process_results"`
+}
+
+// PushToClients immediately pushes the specified order to each of the certificate's
+// clients (bypassing the post processing queue and retries) and returns the result of
+// each push. A failed push is reported in the results rather than as an error.
+// endpoint: /api/v1/certificates/:certid/orders/:orderid/postprocess/client
+func (service *Service) PushToClients(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get params
+	params := httprouter.ParamsFromContext(r.Context())
+
+	certIdParam := params.ByName("certid")
+	certId, err := strconv.Atoi(certIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	orderIdParam := params.ByName("orderid")
+	orderId, err := strconv.Atoi(orderIdParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation / get order
+	order, outErr := service.getOrder(certId, orderId)
+	if outErr != nil {
+		return outErr
+	}
+
+	// verify client is configured
+	if order.Certificate.PostProcessingClientKeyB64 == "" {
+		service.logger.Debug(errNoClientKey)
+		return output.ErrValidationFailed
+	}
+
+	// verify valid, not known revoked, not past validTo, and finalized key isn't deleted
+	if order.Status != "valid" || order.KnownRevoked || order.ValidTo == nil || order.ValidTo.Before(time.Now()) || order.FinalizedKey == nil {
+		service.logger.Debug(fmt.Errorf("orders: cant push order %d to clients (status: %s, knownrevoked: %t)", orderId, order.Status, order.KnownRevoked))
+		return output.ErrValidationFailed
+	}
+	// end validation
+
+	// push (limited by the cert's post processing timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), order.postProcessTimeout())
+	defer cancel()
+
+	results, err := service.pushToClients(ctx, order, 0)
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrInternal
+	}
+
+	// write response
+	response := &clientPushResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = fmt.Sprintf("pushed order %d to %d client(s)", order.ID, len(results))
+	response.Results = []postProcessResultResponse{}
+	for _, result := range results {
+		response.Results = append(response.Results, result.response())
+	}
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
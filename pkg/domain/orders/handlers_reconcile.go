@@ -0,0 +1,101 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/acme_accounts"
+	"certwarden-backend/pkg/output"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+var errReconcileAccountNotUsable = errors.New("orders: acme account is not usable")
+
+// reconcileReportResponse is the JSON response containing an account's orders
+// reconciliation report
+type reconcileReportResponse struct {
+	output.JsonResponse
+	Report reconcileReport `json:"orders_reconciliation"`
+}
+
+// GetAccountOrdersReconciliation returns the most recent orders reconciliation report
+// for the specified ACME account
+func (service *Service) GetAccountOrdersReconciliation(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	accountId, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// get report
+	service.reconcileMu.Lock()
+	report, exists := service.reconcileReports[accountId]
+	service.reconcileMu.Unlock()
+	if !exists {
+		return output.ErrNotFound
+	}
+
+	// write response
+	response := &reconcileReportResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "ok"
+	response.Report = report
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// ReconcileAccountOrders reconciles the orders in storage with the specified ACME
+// account's orders list on the ACME server and returns the report
+func (service *Service) ReconcileAccountOrders(w http.ResponseWriter, r *http.Request) *output.Error {
+	// get id param
+	idParam := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	accountId, err := strconv.Atoi(idParam)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	accounts, err := service.accounts.GetUsableAccounts()
+	if err != nil {
+		service.logger.Error(err)
+		return output.ErrStorageGeneric
+	}
+	var account *acme_accounts.Account
+	for i := range accounts {
+		if accounts[i].ID == accountId {
+			account = &accounts[i]
+			break
+		}
+	}
+	if account == nil {
+		service.logger.Debug(errReconcileAccountNotUsable)
+		return output.ErrValidationFailed
+	}
+	// end validation
+
+	report := service.reconcileAccountOrders(*account)
+
+	// write response
+	response := &reconcileReportResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = "reconciled account orders"
+	response.Report = report
+
+	err = service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/output"
+	"encoding/json"
+	"net/http"
+)
+
+// maxWorkers is the maximum number of workers for each job manager
+const maxWorkers = 50
+
+// workersSettings contains the number of workers for each job manager
+type workersSettings struct {
+	FulfillingWorkers     int `json:"fulfilling_workers"`
+	PostProcessingWorkers int `json:"post_processing_workers"`
+}
+
+// workersResponse is the response for the workers settings
+type workersResponse struct {
+	output.JsonResponse
+	Workers workersSettings `json:"workers"`
+}
+
+// currentWorkers returns the current worker settings
+func (service *Service) currentWorkers() workersSettings {
+	return workersSettings{
+		FulfillingWorkers:     service.orderFulfilling.WorkerCount(),
+		PostProcessingWorkers: service.postProcessing.WorkerCount(),
+	}
+}
+
+// writeWorkersResponse writes the current worker settings
+func (service *Service) writeWorkersResponse(w http.ResponseWriter, message string) *output.Error {
+	response := &workersResponse{}
+	response.StatusCode = http.StatusOK
+	response.Message = message
+	response.Workers = service.currentWorkers()
+
+	err := service.output.WriteJSON(w, response)
+	if err != nil {
+		service.logger.Errorf("orders: failed to write json (%s)", err)
+		return output.ErrWriteJsonError
+	}
+
+	return nil
+}
+
+// GetWorkers returns the number of fulfilling and post processing workers
+// endpoint: /api/v1/orders/workers
+func (service *Service) GetWorkers(w http.ResponseWriter, r *http.Request) *output.Error {
+	return service.writeWorkersResponse(w, "ok")
+}
+
+// workersPayload is the payload to change the number of workers; only non-nil
+// fields are changed
+type workersPayload struct {
+	FulfillingWorkers     *int `json:"fulfilling_workers"`
+	PostProcessingWorkers *int `json:"post_processing_workers"`
+}
+
+// PutWorkers changes the number of fulfilling and/or post processing workers while
+// the app is running (the config values are used again after a restart). If the
+// number is reduced, busy workers stop after their current job.
+// endpoint: /api/v1/orders/workers
+func (service *Service) PutWorkers(w http.ResponseWriter, r *http.Request) *output.Error {
+	// decode body into payload
+	var payload workersPayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		service.logger.Debug(err)
+		return output.ErrValidationFailed
+	}
+
+	// validation
+	for _, count := range []*int{payload.FulfillingWorkers, payload.PostProcessingWorkers} {
+		if count != nil && (*count < 1 || *count > maxWorkers) {
+			service.logger.Debugf("orders: worker count must be 1 to %d", maxWorkers)
+			return output.ErrValidationFailed
+		}
+	}
+	// end validation
+
+	oldWorkers := service.currentWorkers()
+
+	if payload.FulfillingWorkers != nil {
+		err = service.orderFulfilling.SetWorkerCount(*payload.FulfillingWorkers)
+		if err != nil {
+			service.logger.Error(err)
+			return output.ErrInternal
+		}
+	}
+	if payload.PostProcessingWorkers != nil {
+		err = service.postProcessing.SetWorkerCount(*payload.PostProcessingWorkers)
+		if err != nil {
+			service.logger.Error(err)
+			return output.ErrInternal
+		}
+	}
+
+	newWorkers := service.currentWorkers()
+	audit.SetChanges(r, oldWorkers, newWorkers)
+	service.logger.Infof("orders: workers set to %d fulfilling and %d post processing", newWorkers.FulfillingWorkers, newWorkers.PostProcessingWorkers)
+
+	return service.writeWorkersResponse(w, "updated workers")
+}
@@ -0,0 +1,174 @@
+package orders
+
+import (
+	"errors"
+	"time"
+)
+
+// JobKind is the type of work a persisted job performs
+type JobKind string
+
+const (
+	JobKindFulfill     JobKind = "fulfill"
+	JobKindPostProcess JobKind = "post_process"
+)
+
+// JobState is the state of a persisted job
+type JobState string
+
+const (
+	JobStateQueued  JobState = "queued"
+	JobStateRunning JobState = "running"
+	JobStateDone    JobState = "done"
+	JobStateFailed  JobState = "failed"
+)
+
+// finishedJobRetention is how long done and failed jobs are kept in storage
+const finishedJobRetention = 30 * 24 * time.Hour
+
+// Job is a persisted order fulfilling or post processing job. Jobs are recorded in
+// storage when they're added to a job manager so that queued (and interrupted) work
+// can be recovered when the application restarts.
+type Job struct {
+	ID           int
+	Kind         JobKind
+	OrderID      int
+	HighPriority bool
+	State        JobState
+	WorkerID     *int
+	RunAt        *int // unix time a delayed job will be added to the queue
+	Attempt      int  // number of previous failed attempts (post processing retries)
+	Error        string
+	CreatedAt    int
+	UpdatedAt    int
+}
+
+// delayed returns true if the job is queued but not to be run until a future time
+func (j Job) delayed(now time.Time) bool {
+	return j.State == JobStateQueued && j.RunAt != nil && int64(*j.RunAt) > now.Unix()
+}
+
+// recordJobRunning updates storage to indicate the job is being worked. Errors are
+// logged only.
+func (service *Service) recordJobRunning(jobID int, workerID int) {
+	err := service.storage.PutJobRunning(jobID, workerID, int(time.Now().Unix()))
+	if err != nil {
+		service.logger.Errorf("orders: failed to record job %d as running (%s)", jobID, err)
+	}
+}
+
+// recordJobDelayed updates storage to indicate the job is queued again, but won't be
+// run until runAt. Errors are logged only.
+func (service *Service) recordJobDelayed(jobID int, runAt time.Time) {
+	runAtUnix := int(runAt.Unix())
+	err := service.storage.PutJobQueued(jobID, &runAtUnix, int(time.Now().Unix()))
+	if err != nil {
+		service.logger.Errorf("orders: failed to record job %d as delayed (%s)", jobID, err)
+	}
+}
+
+// recordJobRetry updates storage to indicate the job failed and will be attempted again
+// at runAt. Errors are logged only.
+func (service *Service) recordJobRetry(jobID int, attempt int, runAt time.Time, jobErr error) {
+	err := service.storage.PutJobRetry(jobID, attempt, int(runAt.Unix()), jobErr.Error(), int(time.Now().Unix()))
+	if err != nil {
+		service.logger.Errorf("orders: failed to record job %d retry (%s)", jobID, err)
+	}
+}
+
+// recordJobFinished updates storage with the final state of the job. A nil jobErr
+// means the job is done, otherwise it failed. Errors are logged only.
+func (service *Service) recordJobFinished(jobID int, jobErr error) {
+	state := JobStateDone
+	errMsg := ""
+	if jobErr != nil {
+		state = JobStateFailed
+		errMsg = jobErr.Error()
+	}
+
+	err := service.storage.PutJobFinished(jobID, state, errMsg, int(time.Now().Unix()))
+	if err != nil {
+		service.logger.Errorf("orders: failed to record job %d as %s (%s)", jobID, state, err)
+	}
+}
+
+// errJobNotCompleted is recorded for a fulfill job that ended without completing its
+// order when no more specific error is available
+var errJobNotCompleted = errors.New("order fulfillment did not complete")
+
+// recoverJobs re-adds any jobs that were queued or running when the application last
+// stopped to the job managers. Old finished jobs are also pruned from storage.
+func (service *Service) recoverJobs() {
+	now := time.Now()
+
+	// prune old finished jobs
+	err := service.storage.DeleteFinishedJobs(int(now.Add(-finishedJobRetention).Unix()))
+	if err != nil {
+		service.logger.Errorf("orders: failed to prune old finished jobs (%s)", err)
+	}
+
+	// recover in order of kind so any post processing of orders is recovered after the
+	// fulfilling jobs
+	for _, kind := range []JobKind{JobKindFulfill, JobKindPostProcess} {
+		jobs, err := service.storage.GetActiveJobs(kind)
+		if err != nil {
+			service.logger.Errorf("orders: failed to get %s jobs to recover (%s)", kind, err)
+			continue
+		}
+
+		for _, job := range jobs {
+			err = service.recoverJob(job, now)
+			if err != nil {
+				service.logger.Errorf("orders: failed to recover %s job %d for order %d (%s)", kind, job.ID, job.OrderID, err)
+				service.recordJobFinished(job.ID, err)
+				continue
+			}
+			service.logger.Infof("orders: recovered %s job %d for order %d", kind, job.ID, job.OrderID)
+		}
+	}
+}
+
+// recoverJob adds a single persisted job back to the appropriate job manager
+func (service *Service) recoverJob(job Job, now time.Time) error {
+	// a running job was interrupted; it goes back to the queue (keeping any delay)
+	var runAt *int
+	if job.delayed(now) {
+		runAt = job.RunAt
+	}
+	err := service.storage.PutJobQueued(job.ID, runAt, int(now.Unix()))
+	if err != nil {
+		return err
+	}
+
+	switch job.Kind {
+	case JobKindFulfill:
+		newJob, err := service.makeFulfillingJob(job.OrderID, job.HighPriority)
+		if err != nil {
+			return err
+		}
+		newJob.jobID = job.ID
+		newJob.addedToQueue = time.Unix(int64(job.CreatedAt), 0)
+
+		if runAt != nil {
+			return service.orderFulfilling.AddJobAfter(newJob, time.Unix(int64(*runAt), 0))
+		}
+		return service.orderFulfilling.AddJob(newJob)
+
+	case JobKindPostProcess:
+		newJob, err := service.makePostProcessJob(job.OrderID, job.HighPriority)
+		if err != nil {
+			return err
+		}
+		newJob.jobID = job.ID
+		newJob.addedToQueue = time.Unix(int64(job.CreatedAt), 0)
+		newJob.attempt = job.Attempt
+
+		if runAt != nil {
+			return service.postProcessing.AddJobAfter(newJob, time.Unix(int64(*runAt), 0))
+		}
+		return service.postProcessing.AddJob(newJob)
+
+	default:
+		return errors.New("unknown job kind")
+	}
+}
@@ -0,0 +1,155 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/datatypes/job_manager"
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// errJobCanceled is recorded for jobs that were canceled by a user
+	errJobCanceled = errors.New("job canceled")
+
+	// errJobNotFound is returned when trying to cancel a job that isn't queued or running
+	errJobNotFound = errors.New("job not found (or already finished)")
+)
+
+// jobCancel allows a job to be canceled before or while it runs
+type jobCancel struct {
+	mu         sync.Mutex
+	cancelFunc context.CancelFunc
+	canceled   bool
+}
+
+// start returns the context the job should run with. If the job was already canceled,
+// the returned context is already done. The returned CancelFunc must be called when
+// the job is done.
+func (jc *jobCancel) start(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+
+	jc.cancelFunc = cancel
+	if jc.canceled {
+		cancel()
+	}
+
+	return ctx, cancel
+}
+
+// cancel cancels the job's context (or, if the job hasn't started, ensures the job's
+// context is done as soon as it starts)
+func (jc *jobCancel) cancel() {
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+
+	jc.canceled = true
+	if jc.cancelFunc != nil {
+		jc.cancelFunc()
+	}
+}
+
+// wasCanceled returns true if the job was canceled
+func (jc *jobCancel) wasCanceled() bool {
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+
+	return jc.canceled
+}
+
+// cancelableJob is a job that has a persisted record and can be canceled
+type cancelableJob[V any] interface {
+	job_manager.Job[V]
+	persistedID() int
+	cancel()
+}
+
+// cancelManagerJob finds the job with the persisted jobID in mgr. A waiting or delayed
+// job is removed from mgr, and a running job is canceled. found is false if the job
+// is not in mgr, and running is true if the job was running (in which case the job
+// records its own outcome once it stops).
+func cancelManagerJob[V cancelableJob[V]](mgr *job_manager.Manager[V], jobID int) (found bool, running bool) {
+	mgrJobs := mgr.AllCurrentJobs()
+
+	// running
+	var zeroVal V
+	for _, workingJob := range mgrJobs.WorkingJobs {
+		if !workingJob.Equal(zeroVal) && workingJob.persistedID() == jobID {
+			workingJob.cancel()
+			return true, true
+		}
+	}
+
+	// waiting or delayed
+	queued := []V{}
+	queued = append(queued, mgrJobs.WaitingJobs...)
+	for _, delayedJob := range mgrJobs.DelayedJobs {
+		queued = append(queued, delayedJob.Job)
+	}
+	for _, queuedJob := range queued {
+		if queuedJob.persistedID() != jobID {
+			continue
+		}
+
+		removedJob, ok := mgr.RemoveJob(queuedJob)
+		if !ok {
+			// the job started running after the jobs were listed
+			if workingJob, ok := mgr.WorkingJob(queuedJob); ok {
+				workingJob.cancel()
+				return true, true
+			}
+			return false, false
+		}
+		removedJob.cancel()
+		return true, false
+	}
+
+	return false, false
+}
+
+// cancelJob cancels the persisted job of the specified kind. A waiting or delayed job
+// is removed from its manager and recorded as failed. A running job's context is
+// canceled and running is returned true; the job stops (and records its outcome)
+// asynchronously.
+func (service *Service) cancelJob(kind JobKind, jobID int) (running bool, err error) {
+	// confirm the job is active
+	jobs, err := service.storage.GetActiveJobs(kind)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	for _, job := range jobs {
+		if job.ID == jobID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false, errJobNotFound
+	}
+
+	// cancel in the manager
+	inManager := false
+	switch kind {
+	case JobKindFulfill:
+		inManager, running = cancelManagerJob(service.orderFulfilling, jobID)
+	case JobKindPostProcess:
+		inManager, running = cancelManagerJob(service.postProcessing, jobID)
+	}
+
+	// running job records its own outcome; otherwise record the cancel (this also cleans
+	// up a stale record of a job that isn't in the manager)
+	if !running {
+		service.recordJobFinished(jobID, errJobCanceled)
+	}
+
+	if inManager {
+		service.logger.Infof("orders: %s job %d canceled", kind, jobID)
+	} else {
+		service.logger.Infof("orders: %s job %d was not in the job manager, marked canceled", kind, jobID)
+	}
+
+	return running, nil
+}
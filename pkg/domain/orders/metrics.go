@@ -0,0 +1,89 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/metrics"
+	"certwarden-backend/pkg/pagination_sort"
+)
+
+// job queue names (metric label values)
+const (
+	metricsQueueFulfilling     = "order_fulfilling"
+	metricsQueuePostProcessing = "post_processing"
+)
+
+// observeOrderOutcome records the outcome of fulfilling an order. If completed is false, the
+// fulfiller stopped before the order reached a final status and the outcome is 'failed'.
+func (service *Service) observeOrderOutcome(acmeOrder acme.Order, completed bool) {
+	status := "failed"
+	if completed && acmeOrder.Status != "" {
+		status = acmeOrder.Status
+	}
+
+	metrics.OrderOutcomes.Inc(status)
+}
+
+// registerMetrics registers the orders metrics that are computed each time metrics
+// are collected
+func (service *Service) registerMetrics() {
+	// certificate expiration
+	metrics.NewGaugeFunc("certwarden_certificate_expiry_timestamp_seconds",
+		"Unix time at which the current valid certificate expires.", []string{"certificate"},
+		func() []metrics.Sample {
+			validOrders, _, err := service.storage.GetAllValidCurrentOrders(pagination_sort.Query{})
+			if err != nil {
+				service.logger.Errorf("orders: failed to get valid orders for metrics (%s)", err)
+				return nil
+			}
+
+			samples := []metrics.Sample{}
+			for _, order := range validOrders {
+				if order.ValidTo == nil {
+					continue
+				}
+				samples = append(samples, metrics.Sample{
+					LabelValues: []string{order.Certificate.Name},
+					Value:       float64(order.ValidTo.Unix()),
+				})
+			}
+
+			return samples
+		})
+
+	// job managers
+	metrics.NewGaugeFunc("certwarden_jobs_waiting",
+		"Number of jobs waiting in the queue.", []string{"queue"},
+		func() []metrics.Sample {
+			fulfillingWaiting, _, _ := service.orderFulfilling.Stats()
+			postWaiting, _, _ := service.postProcessing.Stats()
+
+			return []metrics.Sample{
+				{LabelValues: []string{metricsQueueFulfilling}, Value: float64(fulfillingWaiting)},
+				{LabelValues: []string{metricsQueuePostProcessing}, Value: float64(postWaiting)},
+			}
+		})
+
+	metrics.NewGaugeFunc("certwarden_job_workers_busy",
+		"Number of workers currently working a job.", []string{"queue"},
+		func() []metrics.Sample {
+			_, fulfillingBusy, _ := service.orderFulfilling.Stats()
+			_, postBusy, _ := service.postProcessing.Stats()
+
+			return []metrics.Sample{
+				{LabelValues: []string{metricsQueueFulfilling}, Value: float64(fulfillingBusy)},
+				{LabelValues: []string{metricsQueuePostProcessing}, Value: float64(postBusy)},
+			}
+		})
+
+	metrics.NewGaugeFunc("certwarden_job_workers",
+		"Total number of workers.", []string{"queue"},
+		func() []metrics.Sample {
+			_, _, fulfillingWorkers := service.orderFulfilling.Stats()
+			_, _, postWorkers := service.postProcessing.Stats()
+
+			return []metrics.Sample{
+				{LabelValues: []string{metricsQueueFulfilling}, Value: float64(fulfillingWorkers)},
+				{LabelValues: []string{metricsQueuePostProcessing}, Value: float64(postWorkers)},
+			}
+		})
+}
@@ -0,0 +1,90 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/app/notifications"
+	"fmt"
+	"time"
+)
+
+// orderNotificationData returns the event data common to all order related notifications
+func orderNotificationData(order Order) map[string]any {
+	data := map[string]any{
+		"certificate_id":      order.Certificate.ID,
+		"certificate_name":    order.Certificate.Name,
+		"certificate_subject": order.Certificate.Subject,
+		"order_id":            order.ID,
+		"dns_identifiers":     order.DnsIdentifiers,
+	}
+
+	if order.ValidTo != nil {
+		data["valid_to"] = order.ValidTo.Unix()
+	}
+
+	return data
+}
+
+// notifyOrderOutcome sends a notification about the result of fulfilling an order. If
+// completed is false, the fulfiller stopped before the order reached a final status and
+// err (if not nil) is the reason why.
+func (service *Service) notifyOrderOutcome(order Order, acmeOrder acme.Order, completed bool, err error) {
+	data := orderNotificationData(order)
+	data["status"] = acmeOrder.Status
+
+	// valid
+	if completed && acmeOrder.Status == "valid" {
+		service.notifications.Notify(notifications.EventTypeOrderValid,
+			fmt.Sprintf("order %d for certificate %s is valid", order.ID, order.Certificate.Name), data)
+		return
+	}
+
+	// invalid or failed
+	var msg string
+	if completed {
+		msg = fmt.Sprintf("order %d for certificate %s is %s", order.ID, order.Certificate.Name, acmeOrder.Status)
+		if acmeOrder.Error != nil {
+			data["acme_error"] = acmeOrder.Error
+		}
+	} else {
+		msg = fmt.Sprintf("order %d for certificate %s failed to complete", order.ID, order.Certificate.Name)
+		if err != nil {
+			data["error"] = err.Error()
+		}
+	}
+
+	service.notifications.Notify(notifications.EventTypeOrderFailed, msg, data)
+}
+
+// notifyPostProcessingFailed sends a notification that a post processing step (e.g. client
+// or command) for order failed
+func (service *Service) notifyPostProcessingFailed(order Order, step string, err error) {
+	data := orderNotificationData(order)
+	data["post_processing_step"] = step
+	data["error"] = err.Error()
+
+	service.notifications.Notify(notifications.EventTypePostProcessingFailed,
+		fmt.Sprintf("post processing (%s) of order %d for certificate %s failed", step, order.ID, order.Certificate.Name), data)
+}
+
+// notifyCertExpiring sends a notification that the certificate of validOrder is expiring
+func (service *Service) notifyCertExpiring(validOrder Order, now time.Time) {
+	data := orderNotificationData(validOrder)
+
+	msg := fmt.Sprintf("certificate %s is expiring", validOrder.Certificate.Name)
+	if validOrder.ValidTo != nil {
+		daysRemaining := int(validOrder.ValidTo.Sub(now).Hours() / 24)
+		data["days_remaining"] = daysRemaining
+		msg = fmt.Sprintf("certificate %s is expiring in %d day(s)", validOrder.Certificate.Name, daysRemaining)
+	}
+
+	service.notifications.Notify(notifications.EventTypeCertExpiring, msg, data)
+}
+
+// notifyCertRevoked sends a notification that the certificate of order was revoked
+func (service *Service) notifyCertRevoked(order Order, reasonCode int) {
+	data := orderNotificationData(order)
+	data["reason_code"] = reasonCode
+
+	service.notifications.Notify(notifications.EventTypeCertRevoked,
+		fmt.Sprintf("order %d for certificate %s was revoked", order.ID, order.Certificate.Name), data)
+}
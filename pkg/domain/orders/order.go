@@ -13,24 +13,27 @@ import (
 // Order is a single ACME order object
 // Finalized key is included as the cert may change keys after an order is finalized.
 type Order struct {
-	ID             int
-	Certificate    certificates.Certificate
-	Location       string
-	Status         string
-	KnownRevoked   bool
-	Error          *acme.Error
-	Expires        *int
-	DnsIdentifiers []string
-	Authorizations []string
-	Finalize       string
-	FinalizedKey   *private_keys.Key
-	CertificateUrl *string
-	Pem            *string
-	ValidFrom      *time.Time
-	ValidTo        *time.Time
-	ChainRootCN    *string
-	CreatedAt      int
-	UpdatedAt      int
+	ID           int
+	Certificate  certificates.Certificate
+	Location     string
+	Status       string
+	KnownRevoked bool
+	// UnknownToServer is set if orders reconciliation found the ACME server no longer
+	// recognizes the order
+	UnknownToServer bool
+	Error           *acme.Error
+	Expires         *int
+	DnsIdentifiers  []string
+	Authorizations  []string
+	Finalize        string
+	FinalizedKey    *private_keys.Key
+	CertificateUrl  *string
+	Pem             *string
+	ValidFrom       *time.Time
+	ValidTo         *time.Time
+	ChainRootCN     *string
+	CreatedAt       int
+	UpdatedAt       int
 }
 
 // orderSummaryResponse is a JSON response containing only
@@ -41,12 +44,14 @@ type orderSummaryResponse struct {
 	Certificate       orderCertificateSummaryResponse `json:"certificate"`
 	Status            string                          `json:"status"`
 	KnownRevoked      bool                            `json:"known_revoked"`
+	UnknownToServer   bool                            `json:"unknown_to_server"`
 	Error             *acme.Error                     `json:"error"`
 	DnsIdentifiers    []string                        `json:"dns_identifiers"`
 	FinalizedKey      *orderKeySummaryResponse        `json:"finalized_key"`
 	ValidFrom         *int                            `json:"valid_from"`
 	ValidTo           *int                            `json:"valid_to"`
 	ChainRootCN       *string                         `json:"chain_root_cn"`
+	RateLimitedUntil  *int                            `json:"rate_limited_until"`
 	CreatedAt         int                             `json:"created_at"`
 	UpdatedAt         int                             `json:"updated_at"`
 }
@@ -106,6 +111,19 @@ func (order Order) summaryResponse(service *Service) orderSummaryResponse {
 		validToUnix = &validToUnixVal
 	}
 
+	// rate limit state of the order's account (only relevant if the order isn't done)
+	var rateLimitedUntilUnix *int
+	if order.Status != "valid" && order.Status != "invalid" {
+		acmeService, err := service.acmeServerService.AcmeService(order.Certificate.CertificateAccount.AcmeServer.ID)
+		if err == nil {
+			rateLimitedUntil := acmeService.RateLimitedUntil(order.Certificate.CertificateAccount.Kid)
+			if rateLimitedUntil != nil {
+				rateLimitedUntilUnixVal := int(rateLimitedUntil.Unix())
+				rateLimitedUntilUnix = &rateLimitedUntilUnixVal
+			}
+		}
+	}
+
 	return orderSummaryResponse{
 		FulfillmentWorker: fulfillingWorker,
 		ID:                order.ID,
@@ -127,16 +145,18 @@ func (order Order) summaryResponse(service *Service) orderSummaryResponse {
 			PostProcessingCommand:      order.Certificate.PostProcessingCommand,
 			PostProcessingClientKeyB64: order.Certificate.PostProcessingClientKeyB64,
 		},
-		Status:         order.Status,
-		KnownRevoked:   order.KnownRevoked,
-		Error:          order.Error,
-		DnsIdentifiers: order.DnsIdentifiers,
-		FinalizedKey:   finalKey,
-		ValidFrom:      validFromUnix,
-		ValidTo:        validToUnix,
-		ChainRootCN:    order.ChainRootCN,
-		CreatedAt:      order.CreatedAt,
-		UpdatedAt:      order.UpdatedAt,
+		Status:           order.Status,
+		KnownRevoked:     order.KnownRevoked,
+		UnknownToServer:  order.UnknownToServer,
+		Error:            order.Error,
+		DnsIdentifiers:   order.DnsIdentifiers,
+		FinalizedKey:     finalKey,
+		ValidFrom:        validFromUnix,
+		ValidTo:          validToUnix,
+		ChainRootCN:      order.ChainRootCN,
+		RateLimitedUntil: rateLimitedUntilUnix,
+		CreatedAt:        order.CreatedAt,
+		UpdatedAt:        order.UpdatedAt,
 	}
 }
 
@@ -187,6 +207,9 @@ func (order Order) PemContentChainOnly() string {
 
 	// remove any extraneouse chars before the first cert begins (spaces and such)
 	beginIndex := bytes.Index(chain, []byte{45}) // ascii code for dash character
+	if beginIndex < 0 {
+		return ""
+	}
 
 	// return pem content
 	return string(chain[beginIndex:])
@@ -209,3 +232,19 @@ func (order *Order) hasPostProcessingToDo() bool {
 
 	return false
 }
+
+// hasPostProcessingToDo returns if the order has any post processing actions, including
+// enabled deploy targets of its certificate
+func (service *Service) hasPostProcessingToDo(order Order) bool {
+	if order.hasPostProcessingToDo() {
+		return true
+	}
+
+	targets, err := service.deployTargets.GetEnabled(order.Certificate.ID)
+	if err != nil {
+		service.logger.Errorf("orders: failed to get deploy targets of certificate %d (%s)", order.Certificate.ID, err)
+		return false
+	}
+
+	return len(targets) > 0
+}
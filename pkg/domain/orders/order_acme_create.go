@@ -1,13 +1,18 @@
 package orders
 
 import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/datatypes/order_events"
 	"certwarden-backend/pkg/output"
 	"errors"
+	"net/http"
+	"time"
 )
 
 // placeNewOrderAndFulfill creates a new ACME order for the specified Certificate ID,
-// and prioritizes the order as specified. It returns the new orderId.
-func (service *Service) placeNewOrderAndFulfill(certId int, highPriority bool) (Order, *output.Error) {
+// and prioritizes the order as specified. If validityHours is not nil, it overrides the
+// certificate's requested validity. It returns the new orderId.
+func (service *Service) placeNewOrderAndFulfill(certId int, highPriority bool, validityHours *int) (Order, *output.Error) {
 	// get cert
 	cert, outErr := service.certificates.GetCertificate(certId)
 	if outErr != nil {
@@ -28,9 +33,42 @@ func (service *Service) placeNewOrderAndFulfill(certId int, highPriority bool) (
 		return Order{}, output.ErrInternal
 	}
 
-	acmeResponse, err := acmeService.NewOrder(cert.NewOrderPayload(), key)
+	// don't send if rate limited
+	until := acmeService.RateLimitedUntil(key.Kid)
+	if until != nil {
+		service.logger.Infof("orders: not placing new order for certificate %s, acme rate limited until %s", cert.Name, until.Format(time.RFC1123))
+		return Order{}, output.ErrAcmeRateLimited
+	}
+
+	// profile must still be offered by the server (it may have been withdrawn since the
+	// certificate was configured)
+	if !acmeService.ProfileValid(cert.Profile) {
+		service.logger.Errorf("orders: not placing new order for certificate %s, acme server no longer offers profile %s", cert.Name, cert.Profile)
+		return Order{}, output.ErrValidationFailed
+	}
+
+	// requested validity
+	requestedValidity := cert.RequestedValidityHours
+	if validityHours != nil {
+		requestedValidity = *validityHours
+	}
+	newOrderPayload := cert.NewOrderPayload(requestedValidity)
+
+	acmeResponse, err := acmeService.NewOrder(newOrderPayload, key)
+	// if the CA rejected the requested validity, fall back to the CA's default validity
+	acmeErr := new(acme.Error)
+	if err != nil && newOrderPayload.HasValidity() && errors.As(err, &acmeErr) &&
+		acmeErr.Status == http.StatusBadRequest && !acmeErr.IsRateLimited() {
+		service.logger.Warnf("orders: acme server rejected requested validity for certificate %s (%s), retrying with the server's default validity", cert.Name, acmeErr.Detail)
+
+		newOrderPayload.SetValidity(0)
+		acmeResponse, err = acmeService.NewOrder(newOrderPayload, key)
+	}
 	if err != nil {
 		service.logger.Error(err)
+		if acmeService.RateLimitedUntil(key.Kid) != nil {
+			return Order{}, output.ErrAcmeRateLimited
+		}
 		return Order{}, output.ErrInternal
 	}
 	service.logger.Debugf("orders: new order location: %s", acmeResponse.Location)
@@ -50,6 +88,8 @@ func (service *Service) placeNewOrderAndFulfill(certId int, highPriority bool) (
 	} else if err != nil {
 		service.logger.Error(err)
 		return Order{}, output.ErrStorageGeneric
+	} else {
+		service.orderEventRecorder(orderId).Record(order_events.TypeCreated, "", acmeResponse.Location)
 	}
 
 	// update certificate timestamp
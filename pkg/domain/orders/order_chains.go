@@ -0,0 +1,55 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/acme"
+	"time"
+)
+
+// OrderChain is one of the certificate chains the ACME server offered for an order
+type OrderChain struct {
+	Index     int
+	URL       string
+	Pem       string
+	RootCN    string
+	Issuers   []acme.ChainIssuer
+	CreatedAt int
+}
+
+// orderChainResponse is the JSON response for an order chain
+type orderChainResponse struct {
+	Index    int                `json:"index"`
+	URL      string             `json:"url"`
+	RootCN   string             `json:"root_cn"`
+	Issuers  []acme.ChainIssuer `json:"issuers"`
+	Selected bool               `json:"selected"`
+	Default  bool               `json:"default"`
+}
+
+// makeOrderChains converts downloaded acme certificate chains to OrderChains for storage
+func makeOrderChains(chains []*acme.Certificate) []OrderChain {
+	now := int(time.Now().Unix())
+
+	orderChains := []OrderChain{}
+	for i := range chains {
+		orderChains = append(orderChains, OrderChain{
+			Index:     i,
+			URL:       chains[i].URL(),
+			Pem:       chains[i].PEM(),
+			RootCN:    chains[i].ChainRootCN(),
+			Issuers:   chains[i].Issuers(),
+			CreatedAt: now,
+		})
+	}
+
+	return orderChains
+}
+
+// saveOrderChains saves all of the downloaded chains for the order so the order's chain
+// can later be switched without downloading from the ACME server again. Errors are logged
+// only since the order's certificate is saved separately.
+func (service *Service) saveOrderChains(orderId int, chains []*acme.Certificate) {
+	err := service.storage.PutOrderChains(orderId, makeOrderChains(chains))
+	if err != nil {
+		service.logger.Errorf("orders: failed to save chains for order %d (%s)", orderId, err)
+	}
+}
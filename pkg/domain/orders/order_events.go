@@ -0,0 +1,58 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/datatypes/order_events"
+	"time"
+)
+
+// OrderEvent is one step in the history of an order (e.g. a challenge being provisioned
+// or the certificate being downloaded)
+type OrderEvent struct {
+	ID         int
+	OrderID    int
+	Type       order_events.Type
+	Identifier string
+	Detail     string
+	ExitCode   *int
+	CreatedAt  int
+}
+
+// orderEventResponse is the JSON response for an order event
+type orderEventResponse struct {
+	ID         int               `json:"id"`
+	Type       order_events.Type `json:"type"`
+	Identifier string            `json:"identifier"`
+	Detail     string            `json:"detail"`
+	ExitCode   *int              `json:"exit_code,omitempty"`
+	CreatedAt  int               `json:"created_at"`
+}
+
+// response returns the JSON response for the event
+func (event OrderEvent) response() orderEventResponse {
+	return orderEventResponse{
+		ID:         event.ID,
+		Type:       event.Type,
+		Identifier: event.Identifier,
+		Detail:     event.Detail,
+		ExitCode:   event.ExitCode,
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+// orderEventRecorder returns a Recorder that saves events to the specified order's
+// event history. Errors are logged only since the history is informational.
+func (service *Service) orderEventRecorder(orderId int) order_events.Recorder {
+	return func(event order_events.Event) {
+		err := service.storage.PostOrderEvent(OrderEvent{
+			OrderID:    orderId,
+			Type:       event.Type,
+			Identifier: event.Identifier,
+			Detail:     event.Detail,
+			ExitCode:   event.ExitCode,
+			CreatedAt:  int(time.Now().Unix()),
+		})
+		if err != nil {
+			service.logger.Errorf("orders: failed to save order %d event %s (%s)", orderId, event.Type, err)
+		}
+	}
+}
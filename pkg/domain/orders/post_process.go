@@ -9,11 +9,14 @@ import (
 // to actually Do the job
 type postProcessJob struct {
 	service *Service
+	jobCancel
 
+	jobID         int // persisted job record
 	addedToQueue  time.Time
 	highPriority  bool
 	orderID       int
 	certificateID int
+	attempt       int // number of previous failed attempts
 }
 
 // makeFulfillingJob makes an orderFulfillJob
@@ -30,7 +33,7 @@ func (service *Service) makePostProcessJob(orderID int, highPriority bool) (*pos
 	}
 
 	// confirm order actually has post processing to do
-	if !order.hasPostProcessingToDo() {
+	if !service.hasPostProcessingToDo(order) {
 		return nil, fmt.Errorf("orders: post processing: failed to make post process job for order id %d (certificate %s has no post processing configured)", orderID, order.Certificate.Name)
 	}
 
@@ -60,3 +63,8 @@ func (j *postProcessJob) Equal(j2 *postProcessJob) bool {
 func (j *postProcessJob) IsHighPriority() bool {
 	return j.highPriority
 }
+
+// persistedID returns the id of the job's persisted record
+func (j *postProcessJob) persistedID() int {
+	return j.jobID
+}
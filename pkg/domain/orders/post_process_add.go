@@ -2,6 +2,7 @@ package orders
 
 import (
 	"fmt"
+	"time"
 )
 
 // postProcess queues a post processing job for the specified order ID with the specified
@@ -13,9 +14,31 @@ func (service *Service) postProcess(orderID int, isHighPriority bool) (err error
 		return err
 	}
 
+	// don't persist a duplicate
+	if service.postProcessing.JobExists(newJob) != nil {
+		return fmt.Errorf("orders: post processing: failed to add order id %d (job already exists)", orderID)
+	}
+
+	// persist the job so it can be recovered after a restart
+	newJob.jobID, err = service.storage.PostJob(Job{
+		Kind:         JobKindPostProcess,
+		OrderID:      orderID,
+		HighPriority: isHighPriority,
+		State:        JobStateQueued,
+		CreatedAt:    int(newJob.addedToQueue.Unix()),
+		UpdatedAt:    int(time.Now().Unix()),
+	})
+	if err != nil {
+		return fmt.Errorf("orders: post processing: failed to save job for order id %d (%s)", orderID, err)
+	}
+
 	// add to the Job Manager
 	err = service.postProcessing.AddJob(newJob)
 	if err != nil {
+		// remove the record of the job that wasn't added
+		if deleteErr := service.storage.DeleteJob(newJob.jobID); deleteErr != nil {
+			service.logger.Errorf("orders: post processing: failed to delete job %d record (%s)", newJob.jobID, deleteErr)
+		}
 		return fmt.Errorf("orders: post processing: failed to add order id %d (%s)", orderID, err)
 	}
 
@@ -1,17 +1,141 @@
 package orders
 
+import (
+	"certwarden-backend/pkg/datatypes/order_events"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// postProcessFailure is a post processing step that failed
+type postProcessFailure struct {
+	step string
+	err  error
+}
+
 // Do actually runs the post processing task(s)
 func (j *postProcessJob) Do(workerID int) {
+	// record job state (a job interrupted by shutdown is left running so it is recovered
+	// on the next start)
+	j.service.recordJobRunning(j.jobID, workerID)
+
+	// job context (canceled on shutdown or if the job is canceled)
+	ctx, cancel := j.start(j.service.shutdownContext)
+	defer cancel()
+
+	var jobErrs []error
+	retried := false
+	defer func() {
+		if j.service.shutdownContext.Err() != nil || retried {
+			return
+		}
+		if j.wasCanceled() {
+			j.service.recordJobFinished(j.jobID, errJobCanceled)
+			return
+		}
+		j.service.recordJobFinished(j.jobID, errors.Join(jobErrs...))
+	}()
+
+	// don't start if canceled before running
+	if ctx.Err() != nil {
+		return
+	}
+
 	// get order
 	order, err := j.service.storage.GetOneOrder(j.orderID)
 	if err != nil {
 		j.service.logger.Errorf("orders: ost processing worker %d: failed to get order %d from db for post processing (%s)", workerID, j.orderID, err)
+		jobErrs = append(jobErrs, err)
 		return // done, failed
 	}
 
+	recordEvent := j.service.orderEventRecorder(j.orderID)
+
+	// post processing is killed if it runs longer than the certificate's timeout
+	runCtx, cancelRun := context.WithTimeout(ctx, order.postProcessTimeout())
+	defer cancelRun()
+
+	var failures []postProcessFailure
+
 	// run client post processing
-	j.doClientPostProcess(order, workerID)
+	clientFailures := j.doClientPostProcess(runCtx, order, workerID)
+	for _, failure := range clientFailures {
+		failures = append(failures, failure)
+		jobErrs = append(jobErrs, fmt.Errorf("%s: %s", failure.step, failure.err))
+		recordEvent.Record(order_events.TypePostProcessFailed, failure.step, failure.err.Error())
+	}
+	if len(clientFailures) == 0 && order.Certificate.PostProcessingClientKeyB64 != "" {
+		recordEvent.Record(order_events.TypePostProcessed, "client", "client notified")
+	}
 
 	// run command post processing
-	j.doScriptOrBinaryPostProcess(order, workerID)
+	if ctx.Err() != nil {
+		return
+	}
+	err = j.doScriptOrBinaryPostProcess(runCtx, order, workerID, recordEvent)
+	if err != nil {
+		failures = append(failures, postProcessFailure{step: string(PostProcessMethodCommand), err: err})
+		jobErrs = append(jobErrs, fmt.Errorf("command: %s", err))
+	}
+
+	// deploy to targets
+	if ctx.Err() != nil {
+		return
+	}
+	deployFailures := j.doDeployPostProcess(runCtx, order, workerID, recordEvent)
+	for _, failure := range deployFailures {
+		failures = append(failures, failure)
+		jobErrs = append(jobErrs, fmt.Errorf("%s: %s", failure.step, failure.err))
+	}
+
+	// done if success, or if canceled or shutting down (timing out is a failure)
+	if len(failures) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	// try again later if there are retries remaining
+	if j.retry(workerID, errors.Join(jobErrs...)) {
+		retried = true
+		return
+	}
+
+	// out of retries, notify
+	for _, failure := range failures {
+		j.service.notifyPostProcessingFailed(order, failure.step, failure.err)
+	}
+}
+
+// retry schedules the job to run again after a backoff delay if it has retries remaining.
+// It returns true if the job was rescheduled. All post processing methods are run again
+// on retry.
+func (j *postProcessJob) retry(workerID int, jobErr error) bool {
+	if j.attempt >= postProcessMaxRetries {
+		return false
+	}
+
+	runAt := time.Now().Add(postProcessRetryDelay(j.attempt))
+
+	newJob := &postProcessJob{
+		service: j.service,
+
+		jobID:         j.jobID,
+		addedToQueue:  time.Now(),
+		highPriority:  j.highPriority,
+		orderID:       j.orderID,
+		certificateID: j.certificateID,
+		attempt:       j.attempt + 1,
+	}
+
+	err := j.service.postProcessing.AddJobAfter(newJob, runAt)
+	if err != nil {
+		// if the job is already queued again, that's fine (it will run anyway)
+		j.service.logger.Debugf("orders: post processing worker %d: order %d: could not schedule retry (%s)", workerID, j.orderID, err)
+		return false
+	}
+
+	j.service.recordJobRetry(j.jobID, newJob.attempt, runAt, jobErr)
+	j.service.logger.Warnf("orders: post processing worker %d: order %d: post processing failed (attempt %d of %d), retrying at %s", workerID, j.orderID, newJob.attempt, postProcessMaxRetries+1, runAt.Format(time.RFC1123))
+
+	return true
 }
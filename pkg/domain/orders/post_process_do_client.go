@@ -2,17 +2,25 @@ package orders
 
 import (
 	"bytes"
+	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/httpclient"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 const postProcessClientPostRoute = "/certwardenclient/api/v1/install"
+const postProcessClientLegacyPostRoute = "/legocerthubclient/api/v1/install"
 const postProcessClientPort = 5055
 
 // postProcessInnerClientPayload is the data that will be marshalled and
@@ -28,28 +36,60 @@ type postProcessClientPayload struct {
 	Payload string `json:"payload"`
 }
 
-// doClientPostProcess sends a data payload to the client located
-// at certificate's CN, using the encryption key specified on certificate
-func (j *postProcessJob) doClientPostProcess(order Order, workerID int) {
-	// no-op if no client key
-	if order.Certificate.PostProcessingClientKeyB64 == "" {
-		j.service.logger.Debugf("orders: post processing worker %d: order %d: skipping client notify (cert does not have a client key) (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
-		return
+// clientEndpoint is the url a client is pushed to
+type clientEndpoint struct {
+	url string
+	// legacyUrl is tried if url is not found (only for the default route)
+	legacyUrl string
+}
+
+// clientEndpoints returns the endpoints of the certificate's clients. If none are
+// configured, the certificate's subject on the default port is used.
+func clientEndpoints(cert certificates.Certificate) []clientEndpoint {
+	targets := cert.PostProcessingClients
+	if len(targets) == 0 {
+		targets = []certificates.ClientTarget{{}}
+	}
+
+	endpoints := []clientEndpoint{}
+	for _, target := range targets {
+		host := target.Host
+		if host == "" {
+			host = cert.Subject
+		}
+		port := target.Port
+		if port == 0 {
+			port = postProcessClientPort
+		}
+		hostPort := net.JoinHostPort(host, strconv.Itoa(port))
+
+		endpoint := clientEndpoint{}
+		if target.Path == "" {
+			endpoint.url = "https://" + hostPort + postProcessClientPostRoute
+			// TODO: Remove backwards compat
+			endpoint.legacyUrl = "https://" + hostPort + postProcessClientLegacyPostRoute
+		} else {
+			endpoint.url = "https://" + hostPort + target.Path
+		}
+
+		endpoints = append(endpoints, endpoint)
 	}
 
-	j.service.logger.Infof("orders: post processing worker %d: order %d: attempting to notify client (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
+	return endpoints
+}
 
+// makeClientPayload returns the encrypted payload of the order's key and certificate
+// for the certificate's client key
+func makeClientPayload(order Order) ([]byte, error) {
 	// decode AES key
 	aesKey, err := base64.RawURLEncoding.DecodeString(order.Certificate.PostProcessingClientKeyB64)
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: invalid aes key (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, fmt.Errorf("invalid aes key (%s)", err)
 	}
 
 	// verify pem exists (should never trigger)
 	if order.Pem == nil || order.FinalizedKey == nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: something really weird happened and pem content is nil (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, errors.New("something really weird happened and pem content is nil")
 	}
 
 	// make inner payload for client
@@ -59,29 +99,25 @@ func (j *postProcessJob) doClientPostProcess(order Order, workerID int) {
 	}
 	innerPayloadJson, err := json.Marshal(innerPayload)
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to marshal inner payload (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, fmt.Errorf("failed to marshal inner payload (%s)", err)
 	}
 
 	// make AES-GCM for encrypting
 	aes, err := aes.NewCipher(aesKey)
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to make cipher (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, fmt.Errorf("failed to make cipher (%s)", err)
 	}
 
 	gcm, err := cipher.NewGCM(aes)
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to make gcm AEAD (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, fmt.Errorf("failed to make gcm AEAD (%s)", err)
 	}
 
 	// make nonce and encrypt
 	nonce := make([]byte, gcm.NonceSize())
 	_, err = rand.Read(nonce)
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to make nonce (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, fmt.Errorf("failed to make nonce (%s)", err)
 	}
 	// note: dst==nonce on purpose (so nonce is prepended)
 	encryptedInnerData := gcm.Seal(nonce, nonce, innerPayloadJson, nil)
@@ -93,46 +129,127 @@ func (j *postProcessJob) doClientPostProcess(order Order, workerID int) {
 
 	dataPayload, err := json.Marshal(payload)
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to marshal outer payload (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil, fmt.Errorf("failed to marshal outer payload (%s)", err)
+	}
+
+	return dataPayload, nil
+}
+
+// pushToClients sends the order's key and certificate to each of the certificate's
+// clients. Every client is attempted (even if an earlier one fails) and the outcome of
+// each is saved to the order's post processing results and returned. An error is
+// returned (instead of results) if the push couldn't be attempted at all.
+func (service *Service) pushToClients(ctx context.Context, order Order, attempt int) ([]PostProcessResult, error) {
+	if order.Certificate.PostProcessingClientKeyB64 == "" {
+		return nil, errNoClientKey
+	}
+
+	dataPayload, err := makeClientPayload(order)
+	if err != nil {
+		return nil, fmt.Errorf("notify client failed: %s (cert: %d, cn: %s)", err, order.Certificate.ID, order.Certificate.Subject)
+	}
+
+	// custom ca trust
+	client := service.httpClient
+	if order.Certificate.PostProcessingClientCAPem != "" {
+		client, err = service.httpClient.WithOptions(httpclient.Options{RootCAsPem: order.Certificate.PostProcessingClientCAPem})
+		if err != nil {
+			return nil, fmt.Errorf("notify client failed: failed to make http client (%s) (cert: %d, cn: %s)", err, order.Certificate.ID, order.Certificate.Subject)
+		}
 	}
 
+	results := []PostProcessResult{}
+	for _, endpoint := range clientEndpoints(order.Certificate) {
+		result := PostProcessResult{
+			OrderID:   order.ID,
+			Method:    PostProcessMethodClient,
+			ClientURL: endpoint.url,
+			Attempt:   attempt,
+			StartedAt: int(time.Now().Unix()),
+		}
+
+		var pushErr error
+		result.HttpStatus, pushErr = service.pushToClient(ctx, client, order, endpoint, dataPayload)
+
+		result = service.recordPostProcessResult(result, pushErr)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// pushToClient posts the payload to a single client endpoint and returns the response
+// status (if a response was received)
+func (service *Service) pushToClient(ctx context.Context, client *httpclient.Client, order Order, endpoint clientEndpoint, dataPayload []byte) (*int, error) {
+	service.logger.Infof("orders: order %d: attempting to notify client %s (cert: %d, cn: %s)", order.ID, endpoint.url, order.Certificate.ID, order.Certificate.Subject)
+
 	// send post to client
-	postTo := fmt.Sprintf("https://%s:%d%s", order.Certificate.Subject, postProcessClientPort, postProcessClientPostRoute)
-	resp, err := j.service.httpClient.Post(postTo, "application/json", bytes.NewBuffer(dataPayload))
+	resp, err := client.PostContext(ctx, endpoint.url, "application/json", bytes.NewBuffer(dataPayload))
 	if err != nil {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to post to client (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-		return
+		service.logger.Errorf("orders: order %d: notify client %s failed: failed to post to client (%s) (cert: %d, cn: %s)", order.ID, endpoint.url, err, order.Certificate.ID, order.Certificate.Subject)
+		return nil, fmt.Errorf("notify client failed: failed to post to client (%s) (cert: %d, cn: %s)", err, order.Certificate.ID, order.Certificate.Subject)
 	}
 
 	// ensure body is read and closed
 	defer resp.Body.Close()
 	_, _ = io.Copy(io.Discard, resp.Body)
+	status := resp.StatusCode
 
 	// if got 404 (route not found), try old route
 	// TODO: Remove backwards compat
-	if resp.StatusCode == http.StatusNotFound {
-		postTo = fmt.Sprintf("https://%s:%d%s", order.Certificate.Subject, postProcessClientPort, "/legocerthubclient/api/v1/install")
-		resp, err = j.service.httpClient.Post(postTo, "application/json", bytes.NewBuffer(dataPayload))
-
+	if resp.StatusCode == http.StatusNotFound && endpoint.legacyUrl != "" {
+		resp, err = client.PostContext(ctx, endpoint.legacyUrl, "application/json", bytes.NewBuffer(dataPayload))
 		if err != nil {
-			j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: failed to post pre-rename route to client (%s) (cert: %d, cn: %s)", workerID, order.ID, err, order.Certificate.ID, order.Certificate.Subject)
-			return
+			service.logger.Errorf("orders: order %d: notify client %s failed: failed to post pre-rename route to client (%s) (cert: %d, cn: %s)", order.ID, endpoint.url, err, order.Certificate.ID, order.Certificate.Subject)
+			return &status, fmt.Errorf("notify client failed: failed to post pre-rename route to client (%s) (cert: %d, cn: %s)", err, order.Certificate.ID, order.Certificate.Subject)
 		}
 
 		// Log WARN so user knows to update client
-		j.service.logger.Warnf("orders: post processing worker %d: order %d: notify client used pre-app rename route; update client asap (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
+		service.logger.Warnf("orders: order %d: notify client %s used pre-app rename route; update client asap (cert: %d, cn: %s)", order.ID, endpoint.url, order.Certificate.ID, order.Certificate.Subject)
 
 		// ensure body is read and closed
 		defer resp.Body.Close()
 		_, _ = io.Copy(io.Discard, resp.Body)
+		status = resp.StatusCode
 	}
 
 	// error if not 200
-	if resp.StatusCode != http.StatusOK {
-		j.service.logger.Errorf("orders: post processing worker %d: order %d: notify client failed: post status %d (cert: %d, cn: %s)", workerID, order.ID, resp.StatusCode, order.Certificate.ID, order.Certificate.Subject)
-		return
+	if status != http.StatusOK {
+		service.logger.Errorf("orders: order %d: notify client %s failed: post status %d (cert: %d, cn: %s)", order.ID, endpoint.url, status, order.Certificate.ID, order.Certificate.Subject)
+		return &status, fmt.Errorf("notify client failed: post status %d (cert: %d, cn: %s)", status, order.Certificate.ID, order.Certificate.Subject)
+	}
+
+	service.logger.Infof("orders: order %d: client %s notify completed", order.ID, endpoint.url)
+
+	return &status, nil
+}
+
+// doClientPostProcess sends a data payload to each of the certificate's clients, using
+// the encryption key specified on certificate. A failure is returned for each client
+// that was not successfully notified. The outcomes are saved to the order's post
+// processing results. The posts are canceled if ctx is canceled.
+func (j *postProcessJob) doClientPostProcess(ctx context.Context, order Order, workerID int) []postProcessFailure {
+	// no-op if no client key
+	if order.Certificate.PostProcessingClientKeyB64 == "" {
+		j.service.logger.Debugf("orders: post processing worker %d: order %d: skipping client notify (cert does not have a client key) (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
+		return nil
+	}
+
+	results, err := j.service.pushToClients(ctx, order, j.attempt)
+	if err != nil {
+		j.service.logger.Errorf("orders: post processing worker %d: order %d: %s", workerID, order.ID, err)
+		return []postProcessFailure{{step: string(PostProcessMethodClient), err: err}}
+	}
+
+	var failures []postProcessFailure
+	for _, result := range results {
+		if !result.Success {
+			failures = append(failures, postProcessFailure{
+				step: fmt.Sprintf("%s %s", PostProcessMethodClient, result.ClientURL),
+				err:  errors.New(result.Error),
+			})
+		}
 	}
 
-	j.service.logger.Infof("orders: post processing worker %d: order %d: client notify completed", workerID, order.ID)
+	return failures
 }
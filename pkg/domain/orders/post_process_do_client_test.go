@@ -0,0 +1,38 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/certificates"
+	"testing"
+)
+
+func TestPostProcess_ClientEndpoints(t *testing.T) {
+	cert := certificates.Certificate{Subject: "example.com"}
+
+	// default
+	endpoints := clientEndpoints(cert)
+	if len(endpoints) != 1 || endpoints[0].url != "https://example.com:5055/certwardenclient/api/v1/install" || endpoints[0].legacyUrl == "" {
+		t.Errorf("unexpected default endpoints %+v", endpoints)
+	}
+
+	// configured
+	cert.PostProcessingClients = []certificates.ClientTarget{
+		{Host: "lb1.example.com"},
+		{Host: "2001:db8::1", Port: 8443, Path: "/install"},
+		{Port: 443},
+	}
+	want := []clientEndpoint{
+		{url: "https://lb1.example.com:5055/certwardenclient/api/v1/install", legacyUrl: "https://lb1.example.com:5055/legocerthubclient/api/v1/install"},
+		{url: "https://[2001:db8::1]:8443/install"},
+		{url: "https://example.com:443/certwardenclient/api/v1/install", legacyUrl: "https://example.com:443/legocerthubclient/api/v1/install"},
+	}
+
+	endpoints = clientEndpoints(cert)
+	if len(endpoints) != len(want) {
+		t.Fatalf("got %d endpoints, want %d", len(endpoints), len(want))
+	}
+	for i := range want {
+		if endpoints[i] != want[i] {
+			t.Errorf("endpoint %d = %+v, want %+v", i, endpoints[i], want[i])
+		}
+	}
+}
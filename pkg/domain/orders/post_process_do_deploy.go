@@ -0,0 +1,98 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/datatypes/order_events"
+	"certwarden-backend/pkg/domain/deploy_targets"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// doDeployPostProcess deploys the order's key and certificate to each of the
+// certificate's enabled deploy targets. Every target is attempted (even if an earlier
+// one fails) and the outcome of each is saved to the order's post processing results.
+// A failure is returned for each target that failed.
+func (j *postProcessJob) doDeployPostProcess(ctx context.Context, order Order, workerID int, recordEvent order_events.Recorder) []postProcessFailure {
+	targets, err := j.service.deployTargets.GetEnabled(order.Certificate.ID)
+	if err != nil {
+		j.service.logger.Errorf("orders: post processing worker %d: order %d: failed to get deploy targets (%s)", workerID, order.ID, err)
+		return []postProcessFailure{{step: string(PostProcessMethodDeploy), err: err}}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// verify pem exists (should never trigger)
+	if order.Pem == nil || order.FinalizedKey == nil {
+		err = errors.New("order pem or finalized key is missing")
+		j.service.logger.Errorf("orders: post processing worker %d: order %d: deploy failed: %s", workerID, order.ID, err)
+		return []postProcessFailure{{step: string(PostProcessMethodDeploy), err: err}}
+	}
+
+	content := deploy_targets.Content{
+		KeyPem:       order.FinalizedKey.Pem,
+		CertChainPem: order.PemContent(),
+		CertPem:      order.PemContentNoChain(),
+		ChainPem:     order.PemContentChainOnly(),
+	}
+
+	var failures []postProcessFailure
+	for _, target := range targets {
+		// stop if canceled or shutting down
+		if ctx.Err() != nil {
+			failures = append(failures, postProcessFailure{step: string(PostProcessMethodDeploy), err: ctx.Err()})
+			break
+		}
+
+		err = j.deployToTarget(ctx, order, target, content, workerID)
+		step := fmt.Sprintf("deploy target %s", target.Name)
+		if err != nil {
+			failures = append(failures, postProcessFailure{step: step, err: err})
+			recordEvent.Record(order_events.TypePostProcessFailed, step, err.Error())
+		} else {
+			recordEvent.Record(order_events.TypePostProcessed, step, "deployed")
+		}
+	}
+
+	return failures
+}
+
+// deployToTarget deploys content to a single target and saves the result
+func (j *postProcessJob) deployToTarget(ctx context.Context, order Order, target deploy_targets.DeployTarget, content deploy_targets.Content, workerID int) (err error) {
+	// record result when done
+	targetId := target.ID
+	result := PostProcessResult{
+		OrderID:          order.ID,
+		Method:           PostProcessMethodDeploy,
+		DeployTargetID:   &targetId,
+		DeployTargetName: target.Name,
+		Attempt:          j.attempt,
+		StartedAt:        int(time.Now().Unix()),
+	}
+	defer func() {
+		j.service.recordPostProcessResult(result, err)
+	}()
+
+	j.service.logger.Infof("orders: post processing worker %d: order %d: deploying to target %s (%s)", workerID, order.ID, target.Name, target.Type)
+
+	out, err := j.service.deployTargets.Deploy(ctx, target, content)
+	result.Stdout = truncatePostProcessOutput(out)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out (%w)", err)
+		}
+		j.service.logger.Errorf("orders: post processing worker %d: order %d: deploy failed: %s", workerID, order.ID, err)
+		return err
+	}
+
+	j.service.logger.Infof("orders: post processing worker %d: order %d: deployed to target %s", workerID, order.ID, target.Name)
+	return nil
+}
+
+// truncatePostProcessOutput limits output to the same size as command output
+func truncatePostProcessOutput(out string) string {
+	lb := &limitedBuffer{max: maxPostProcessOutput}
+	_, _ = lb.Write([]byte(out))
+	return lb.String()
+}
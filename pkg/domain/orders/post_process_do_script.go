@@ -2,91 +2,131 @@ package orders
 
 import (
 	"certwarden-backend/pkg/datatypes/environment"
+	"certwarden-backend/pkg/datatypes/order_events"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
-	"strings"
+	"time"
 )
 
 // doScriptOrBinaryPost executes the certificate's post processing command. if the cert
-// does not have a command, this is a no-op
-func (j *postProcessJob) doScriptOrBinaryPostProcess(order Order, workerID int) {
+// does not have a command, this is a no-op. An error is returned if the command
+// could not be run or did not complete successfully. The command's exit code is sent to
+// recordEvent and the outcome (including output) is saved to the order's post processing
+// results. The command is killed if ctx is canceled (or times out).
+func (j *postProcessJob) doScriptOrBinaryPostProcess(ctx context.Context, order Order, workerID int, recordEvent order_events.Recorder) (err error) {
 	// no-op if no command
 	if order.Certificate.PostProcessingCommand == "" {
 		j.service.logger.Debugf("orders: post processing worker %d: order %d: skipping command (cert does not have a command to run) (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
-		return
+		return nil
 	}
 
+	// record failure if the command never ran (otherwise the outcome is recorded with the
+	// exit code)
+	ran := false
+	defer func() {
+		if err != nil && !ran {
+			recordEvent.Record(order_events.TypePostProcessFailed, "command", err.Error())
+		}
+	}()
+
+	// record result when done
+	stdout := &limitedBuffer{max: maxPostProcessOutput}
+	stderr := &limitedBuffer{max: maxPostProcessOutput}
+	result := PostProcessResult{
+		OrderID:   order.ID,
+		Method:    PostProcessMethodCommand,
+		Attempt:   j.attempt,
+		StartedAt: int(time.Now().Unix()),
+	}
+	defer func() {
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+		j.service.recordPostProcessResult(result, err)
+	}()
+
 	j.service.logger.Infof("orders: post processing worker %d: order %d: attempting to run command (cert: %d, cn: %s)", workerID, order.ID, order.Certificate.ID, order.Certificate.Subject)
 
 	// nil checks
 	if order.Pem == nil {
 		err := fmt.Errorf("orders: post processing worker %d: order %d: command failed: order pem is nil (should never happen)", workerID, order.ID)
 		j.service.logger.Error(err)
-		return
+		return err
 	}
 	if order.FinalizedKey == nil {
 		err := fmt.Errorf("orders: post processing worker %d: order %d: command failed: finalized key no longer exists", workerID, order.ID)
 		j.service.logger.Error(err)
-		return
+		return err
 	}
 
-	// user specified environment can have placeholders for certain values (so user can set
-	// their own name for the environment variable)
-	// {{PRIVATE_KEY_NAME}}					= the `Name` of the private key used to finalize the order
-	// {{PRIVATE_KEY_PEM}}					= the pem of the private key
-	// {{CERTIFICATE_NAME}}					= the `Name` of the certificate
-	// {{CERTIFICATE_PEM}}					= the pem of the complete certificate chain for the order
-	// {{CERTIFICATE_COMMON_NAME}}	= the common name of the certificate
+	// user specified environment values and arguments can have placeholders for certain
+	// values (so user can set their own name for the environment variable); see
+	// post_process_placeholders.go for the list
 
 	// make Params (which sanitizes the env params and handles things like removing quotes)
 	envParams, invalidParams := environment.NewParams(order.Certificate.PostProcessingEnvironment)
 	if len(invalidParams) > 0 {
 		j.service.logger.Errorf("orders: post processing worker %d: order %d: %s are not properly formatted environment param(s), they will be skipped", workerID, order.ID, invalidParams)
 	}
+	envMap := envParams.KeyValMap()
 
-	// make environ from Params and update placeholders with proper values
-	environ := []string{}
-	for key, val := range envParams.KeyValMap() {
-		switch upperVal := strings.ToUpper(val); upperVal {
-		case "{{PRIVATE_KEY_NAME}}":
-			val = order.FinalizedKey.Name
-
-		case "{{PRIVATE_KEY_PEM}}":
-			val = order.FinalizedKey.Pem
-
-		case "{{CERTIFICATE_NAME}}":
-			val = order.Certificate.Name
-
-		case "{{CERTIFICATE_PEM}}":
-			val = *order.Pem
+	envValues := []string{}
+	for _, val := range envMap {
+		envValues = append(envValues, val)
+	}
+	used := usedPlaceholders(envValues, order.Certificate.PostProcessingArgs)
+
+	// if configured, content is written to a temporary directory (removed once the command
+	// is done) and the placeholders are replaced with the file paths
+	fileDir := ""
+	if order.Certificate.PostProcessingWriteFiles {
+		fileDir, err = os.MkdirTemp("", "certwarden-post-process-")
+		if err != nil {
+			j.service.logger.Errorf("orders: post processing worker %d: order %d: command failed: failed to make temp directory (%s)", workerID, order.ID, err)
+			return fmt.Errorf("failed to make temp directory (%s)", err)
+		}
+		defer func() {
+			removeErr := os.RemoveAll(fileDir)
+			if removeErr != nil {
+				j.service.logger.Errorf("orders: post processing worker %d: order %d: failed to remove temp directory %s (%s)", workerID, order.ID, fileDir, removeErr)
+			}
+		}()
+	}
 
-		case "{{CERTIFICATE_COMMON_NAME}}":
-			val = order.Certificate.Subject
+	placeholderValues, err := makePlaceholderValues(order, used, fileDir)
+	if err != nil {
+		j.service.logger.Errorf("orders: post processing worker %d: order %d: command failed: failed to make placeholder values (%s)", workerID, order.ID, err)
+		return fmt.Errorf("failed to make placeholder values (%s)", err)
+	}
 
-		default:
-			// no-op - user specified some other value
-		}
+	// make environ from Params and update placeholders with proper values
+	environ := []string{}
+	for key, val := range envMap {
+		environ = append(environ, key+"="+replacePlaceholders(val, placeholderValues))
+	}
 
-		// append to environment
-		environ = append(environ, key+"="+val)
+	// make args and update placeholders with proper values
+	cmdArgs := []string{}
+	for _, arg := range order.Certificate.PostProcessingArgs {
+		cmdArgs = append(cmdArgs, replacePlaceholders(arg, placeholderValues))
 	}
 
 	// open and read (up to) the first 512 bytes of post processing script/binary to decide if it is binary or not
 	f, err := os.Open(order.Certificate.PostProcessingCommand)
 	if err != nil {
 		j.service.logger.Errorf("orders: post processing worker %d: order %d: script/binary failed to open: %s", workerID, order.ID, err)
-		return
+		return fmt.Errorf("script/binary failed to open: %s", err)
 	}
 	defer f.Close()
 
 	fInfo, err := f.Stat()
 	if err != nil {
 		j.service.logger.Errorf("orders: post processing worker %d: order %d: script/binary failed to stat: %s", workerID, order.ID, err)
-		return
+		return fmt.Errorf("script/binary failed to stat: %s", err)
 	}
 
 	bufLen := 512
@@ -98,47 +138,74 @@ func (j *postProcessJob) doScriptOrBinaryPostProcess(order Order, workerID int)
 	_, err = io.ReadFull(f, firstBytes)
 	if err != nil {
 		j.service.logger.Errorf("orders: post processing worker %d: order %d: script/binary failed to read: %s", workerID, order.ID, err)
-		return
+		return fmt.Errorf("script/binary failed to read: %s", err)
 	}
 
 	// check if the file is binary and run it directly if so
 	cmd := &exec.Cmd{}
 	if http.DetectContentType(firstBytes) == "application/octet-stream" {
 		// binary found
-		cmd = exec.Command(order.Certificate.PostProcessingCommand)
+		cmd = exec.CommandContext(ctx, order.Certificate.PostProcessingCommand, cmdArgs...)
 
 	} else {
 		// try to run as script if it wasn't an octet-stream
 		// if app failed to get suitable shell at startup, post processing is disabled
 		if j.service.shellPath == "" {
 			j.service.logger.Errorf("orders: post processing worker %d: order %d: commaind failed to run post processing script (no suitable shell was found during startup)", workerID, order.ID)
-			return
+			return errors.New("command failed to run post processing script (no suitable shell was found during startup)")
 		}
 
 		// make args for command
 		// 0 - script name (e.g. /path/to/script.sh)
-		args := []string{order.Certificate.PostProcessingCommand}
+		// 1+ - user specified args
+		args := append([]string{order.Certificate.PostProcessingCommand}, cmdArgs...)
 
 		// make command
-		cmd = exec.Command(j.service.shellPath, args...)
+		cmd = exec.CommandContext(ctx, j.service.shellPath, args...)
 	}
 
 	// set command environment (default OS + environ from above)
 	cmd.Env = append(os.Environ(), environ...)
 
+	// working directory (blank is the app's working directory)
+	cmd.Dir = order.Certificate.PostProcessingWorkingDir
+
+	// capture output; if the command is killed, don't wait indefinitely for any child
+	// processes that still hold the output open
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = postProcessWaitDelay
+
 	// run command
-	result, err := cmd.Output()
-	j.service.logger.Debugf("orders: post processing worker %d: order %d: command output: %s", workerID, order.ID, string(result))
-	if err != nil {
-		// try to get stderr and log it too
-		exitErr := new(exec.ExitError)
-		if errors.As(err, &exitErr) {
-			j.service.logger.Errorf("orders: post processing worker %d: order %d: command std err: %s", workerID, order.ID, exitErr.Stderr)
+	err = cmd.Run()
+	j.service.logger.Debugf("orders: post processing worker %d: order %d: command output: %s", workerID, order.ID, stdout.String())
+
+	// note a timeout (as opposed to the command failing on its own)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out (%s)", err)
+	}
+
+	// record outcome (exit code is only available if the command started)
+	if cmd.ProcessState != nil {
+		ran = true
+		exitCode := cmd.ProcessState.ExitCode()
+		result.ExitCode = &exitCode
+		if err != nil {
+			recordEvent.RecordExitCode(order_events.TypePostProcessFailed, "command", err.Error(), cmd.ProcessState.ExitCode())
+		} else {
+			recordEvent.RecordExitCode(order_events.TypePostProcessed, "command", "command completed", cmd.ProcessState.ExitCode())
 		}
+	}
+
+	if err != nil {
+		// log stderr too
+		j.service.logger.Errorf("orders: post processing worker %d: order %d: command std err: %s", workerID, order.ID, stderr.String())
 
 		j.service.logger.Errorf("orders: post processing worker %d: order %d: command failed: error: %s", workerID, order.ID, err)
-		return
+		return fmt.Errorf("command failed: error: %s", err)
 	}
 
 	j.service.logger.Infof("orders: post processing worker %d: order %d: command completed", workerID, order.ID)
+
+	return nil
 }
@@ -0,0 +1,176 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
+	"certwarden-backend/pkg/randomness"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// user specified environment values and arguments of the post processing command can
+// contain placeholders for certain values
+const (
+	placeholderPrivateKeyName         = "{{PRIVATE_KEY_NAME}}"        // the `Name` of the private key used to finalize the order
+	placeholderPrivateKeyPem          = "{{PRIVATE_KEY_PEM}}"         // the pem of the private key
+	placeholderCertificateName        = "{{CERTIFICATE_NAME}}"        // the `Name` of the certificate
+	placeholderCertificatePem         = "{{CERTIFICATE_PEM}}"         // the pem of the complete certificate chain for the order
+	placeholderCertificateCommonName  = "{{CERTIFICATE_COMMON_NAME}}" // the common name of the certificate
+	placeholderCertificateLeafPem     = "{{CERTIFICATE_LEAF_PEM}}"    // the pem of only the leaf certificate
+	placeholderCertificateChainPem    = "{{CERTIFICATE_CHAIN_PEM}}"   // the pem of only the chain (without the leaf)
+	placeholderCertificateFingerprint = "{{CERTIFICATE_FINGERPRINT}}" // sha256 fingerprint (hex) of the leaf certificate
+	placeholderCertificateSerial      = "{{CERTIFICATE_SERIAL}}"      // serial number (hex) of the leaf certificate
+	placeholderCertificateNotAfter    = "{{CERTIFICATE_NOT_AFTER}}"   // expiration of the leaf certificate (RFC 3339)
+	placeholderCertificateSANs        = "{{CERTIFICATE_SANS}}"        // comma separated DNS names of the leaf certificate
+	placeholderOrderID                = "{{ORDER_ID}}"                // the id of the order
+	placeholderPKCS12B64              = "{{PKCS12_B64}}"              // base64 of a pkcs12 file of the key and certificate chain
+	placeholderPKCS12Password         = "{{PKCS12_PASSWORD}}"         // the (random) password of the pkcs12 file
+)
+
+// placeholderFilenames are the files that content is written to when the certificate is
+// configured to write files (the placeholder is then replaced with the file's path)
+var placeholderFilenames = map[string]string{
+	placeholderPrivateKeyPem:       "key.pem",
+	placeholderCertificatePem:      "certchain.pem",
+	placeholderCertificateLeafPem:  "cert.pem",
+	placeholderCertificateChainPem: "chain.pem",
+	placeholderPKCS12B64:           "cert.p12",
+}
+
+// placeholderRegex matches anything that could be a placeholder
+var placeholderRegex = regexp.MustCompile(`\{\{[A-Za-z0-9_]+\}\}`)
+
+// usedPlaceholders returns the set of (upper case) placeholders contained in the strings
+func usedPlaceholders(strs ...[]string) map[string]bool {
+	used := make(map[string]bool)
+	for _, strSlice := range strs {
+		for _, s := range strSlice {
+			for _, match := range placeholderRegex.FindAllString(s, -1) {
+				used[strings.ToUpper(match)] = true
+			}
+		}
+	}
+
+	return used
+}
+
+// replacePlaceholders replaces all placeholders in s (case insensitive) that have a value.
+// Any other text that looks like a placeholder is left unchanged.
+func replacePlaceholders(s string, values map[string]string) string {
+	return placeholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+		val, ok := values[strings.ToUpper(match)]
+		if !ok {
+			return match
+		}
+		return val
+	})
+}
+
+// makePlaceholderValues returns the values of the used placeholders for the order. If
+// fileDir is not blank, pem and pkcs12 content is written to files in that directory and
+// the placeholder value is the file's path instead of the content.
+func makePlaceholderValues(order Order, used map[string]bool, fileDir string) (map[string]string, error) {
+	values := map[string]string{
+		placeholderPrivateKeyName:        order.FinalizedKey.Name,
+		placeholderPrivateKeyPem:         order.FinalizedKey.Pem,
+		placeholderCertificateName:       order.Certificate.Name,
+		placeholderCertificatePem:        order.PemContent(),
+		placeholderCertificateCommonName: order.Certificate.Subject,
+		placeholderCertificateLeafPem:    order.PemContentNoChain(),
+		placeholderCertificateChainPem:   order.PemContentChainOnly(),
+		placeholderOrderID:               strconv.Itoa(order.ID),
+	}
+
+	// values from the parsed leaf
+	leafBlock, _ := pem.Decode([]byte(order.PemContent()))
+	if leafBlock == nil {
+		return nil, errors.New("failed to decode certificate pem")
+	}
+	leaf, err := x509.ParseCertificate(leafBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(leaf.Raw)
+	values[placeholderCertificateFingerprint] = hex.EncodeToString(fingerprint[:])
+	values[placeholderCertificateSerial] = leaf.SerialNumber.Text(16)
+	values[placeholderCertificateNotAfter] = leaf.NotAfter.Format(time.RFC3339)
+	values[placeholderCertificateSANs] = strings.Join(leaf.DNSNames, ",")
+
+	// pkcs12 (only if needed)
+	var pkcs12Der []byte
+	if used[placeholderPKCS12B64] || used[placeholderPKCS12Password] {
+		password, err := randomness.GenerateApiKey()
+		if err != nil {
+			return nil, err
+		}
+
+		pkcs12Der, err = makeOrderPKCS12(order, leaf, password)
+		if err != nil {
+			return nil, err
+		}
+
+		values[placeholderPKCS12B64] = base64.StdEncoding.EncodeToString(pkcs12Der)
+		values[placeholderPKCS12Password] = password
+	}
+
+	// write files and replace content with path
+	if fileDir != "" {
+		for placeholder, filename := range placeholderFilenames {
+			if !used[placeholder] {
+				continue
+			}
+
+			content := []byte(values[placeholder])
+			if placeholder == placeholderPKCS12B64 {
+				content = pkcs12Der
+			}
+
+			path := filepath.Join(fileDir, filename)
+			err = os.WriteFile(path, content, 0600)
+			if err != nil {
+				return nil, err
+			}
+			values[placeholder] = path
+		}
+	}
+
+	return values, nil
+}
+
+// makeOrderPKCS12 returns a pkcs12 file of the order's key and certificate chain
+func makeOrderPKCS12(order Order, leaf *x509.Certificate, password string) ([]byte, error) {
+	privateKey, err := key_crypto.PemStringToKey(order.FinalizedKey.Pem, order.FinalizedKey.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []*x509.Certificate
+	rest := []byte(order.PemContentChainOnly())
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cert)
+	}
+
+	return pkcs12.Modern2023.Encode(privateKey, leaf, chain, password)
+}
@@ -0,0 +1,39 @@
+package orders
+
+import (
+	"testing"
+)
+
+func TestPostProcess_Placeholders(t *testing.T) {
+	used := usedPlaceholders([]string{"{{certificate_pem}}", "plain"}, []string{"--serial={{CERTIFICATE_SERIAL}}", "{{NOT_A_PLACEHOLDER}}"})
+	for _, placeholder := range []string{placeholderCertificatePem, placeholderCertificateSerial, "{{NOT_A_PLACEHOLDER}}"} {
+		if !used[placeholder] {
+			t.Errorf("expected %s to be used", placeholder)
+		}
+	}
+	if len(used) != 3 {
+		t.Errorf("expected 3 used placeholders, got %d", len(used))
+	}
+
+	values := map[string]string{
+		placeholderCertificateSerial: "abc123",
+		placeholderOrderID:           "7",
+	}
+
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"{{CERTIFICATE_SERIAL}}", "abc123"},
+		{"{{certificate_serial}}", "abc123"},
+		{"--serial={{CERTIFICATE_SERIAL}} --order={{ORDER_ID}}", "--serial=abc123 --order=7"},
+		{"{{UNKNOWN}}", "{{UNKNOWN}}"},
+		{"no placeholder", "no placeholder"},
+	}
+
+	for _, test := range tests {
+		if out := replacePlaceholders(test.in, values); out != test.expected {
+			t.Errorf("replacePlaceholders(%s): expected '%s', got '%s'", test.in, test.expected, out)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package orders
+
+import (
+	"bytes"
+	"time"
+)
+
+// PostProcessMethod is the way post processing was done
+type PostProcessMethod string
+
+const (
+	PostProcessMethodClient  PostProcessMethod = "client"
+	PostProcessMethodCommand PostProcessMethod = "command"
+	PostProcessMethodDeploy  PostProcessMethod = "deploy"
+)
+
+const (
+	// maxPostProcessOutput is the maximum number of bytes of a command's stdout and
+	// of its stderr that are saved
+	maxPostProcessOutput = 16 * 1024
+
+	// defaultPostProcessTimeout is used for certificates that don't specify a timeout
+	defaultPostProcessTimeout = 10 * time.Minute
+
+	// postProcessWaitDelay is how long to wait for a killed command's output to close
+	postProcessWaitDelay = 5 * time.Second
+
+	// postProcessMaxRetries is the number of times failed post processing is retried
+	postProcessMaxRetries = 3
+
+	// postProcessRetryBaseDelay is the delay before the first retry, each subsequent
+	// retry waits 4 times longer than the previous one
+	postProcessRetryBaseDelay = time.Minute
+)
+
+// PostProcessResult is the outcome of running one post processing method for an order
+type PostProcessResult struct {
+	ID               int
+	OrderID          int
+	Method           PostProcessMethod
+	DeployTargetID   *int   // deploy only (nil if the target was since deleted)
+	DeployTargetName string // deploy only
+	ClientURL        string // client only
+	Attempt          int
+	Success          bool
+	StartedAt        int
+	EndedAt          int
+	ExitCode         *int // command only
+	HttpStatus       *int // client only
+	Stdout           string
+	Stderr           string
+	Error            string
+}
+
+// postProcessResultResponse is the JSON response for a post processing result
+type postProcessResultResponse struct {
+	ID               int               `json:"id"`
+	Method           PostProcessMethod `json:"method"`
+	DeployTargetID   *int              `json:"deploy_target_id,omitempty"`
+	DeployTargetName string            `json:"deploy_target_name,omitempty"`
+	ClientURL        string            `json:"client_url,omitempty"`
+	Attempt          int               `json:"attempt"`
+	Success          bool              `json:"success"`
+	StartedAt        int               `json:"started_at"`
+	EndedAt          int               `json:"ended_at"`
+	ExitCode         *int              `json:"exit_code,omitempty"`
+	HttpStatus       *int              `json:"http_status,omitempty"`
+	Stdout           string            `json:"stdout"`
+	Stderr           string            `json:"stderr"`
+	Error            string            `json:"error"`
+}
+
+// response returns the JSON response for the result
+func (result PostProcessResult) response() postProcessResultResponse {
+	return postProcessResultResponse{
+		ID:               result.ID,
+		Method:           result.Method,
+		DeployTargetID:   result.DeployTargetID,
+		DeployTargetName: result.DeployTargetName,
+		ClientURL:        result.ClientURL,
+		Attempt:          result.Attempt,
+		Success:          result.Success,
+		StartedAt:        result.StartedAt,
+		EndedAt:          result.EndedAt,
+		ExitCode:         result.ExitCode,
+		HttpStatus:       result.HttpStatus,
+		Stdout:           result.Stdout,
+		Stderr:           result.Stderr,
+		Error:            result.Error,
+	}
+}
+
+// recordPostProcessResult finishes the result (setting end time and error), saves it, and
+// returns the finished result. Errors are logged only since the history is informational.
+func (service *Service) recordPostProcessResult(result PostProcessResult, resultErr error) PostProcessResult {
+	result.EndedAt = int(time.Now().Unix())
+	result.Success = resultErr == nil
+	if resultErr != nil {
+		result.Error = resultErr.Error()
+	}
+
+	err := service.storage.PostPostProcessResult(result)
+	if err != nil {
+		service.logger.Errorf("orders: failed to save order %d post processing %s result (%s)", result.OrderID, result.Method, err)
+	}
+
+	return result
+}
+
+// limitedBuffer is an io.Writer that keeps only the first max bytes written to it and
+// discards the rest
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write implements io.Writer. It never returns an error so a command isn't stopped
+// because its output was too long.
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := lb.max - lb.buf.Len()
+	if remaining < len(p) {
+		lb.truncated = true
+		if remaining > 0 {
+			lb.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+
+	return lb.buf.Write(p)
+}
+
+// String returns the buffered output, noting if it was truncated
+func (lb *limitedBuffer) String() string {
+	if lb.truncated {
+		return lb.buf.String() + "\n[output truncated]"
+	}
+	return lb.buf.String()
+}
+
+// postProcessRetryDelay returns how long to wait before retrying post processing that
+// failed on the specified attempt (0 being the first)
+func postProcessRetryDelay(attempt int) time.Duration {
+	delay := postProcessRetryBaseDelay
+	for i := 0; i < attempt; i++ {
+		delay *= 4
+	}
+	return delay
+}
+
+// postProcessTimeout returns how long post processing of the order may run before it is
+// killed
+func (order Order) postProcessTimeout() time.Duration {
+	if order.Certificate.PostProcessingTimeout > 0 {
+		return time.Duration(order.Certificate.PostProcessingTimeout) * time.Second
+	}
+	return defaultPostProcessTimeout
+}
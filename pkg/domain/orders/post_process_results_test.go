@@ -0,0 +1,38 @@
+package orders
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostProcess_LimitedBuffer(t *testing.T) {
+	lb := &limitedBuffer{max: 8}
+
+	n, err := lb.Write([]byte("hello"))
+	if n != 5 || err != nil {
+		t.Fatalf("write returned %d, %v", n, err)
+	}
+	if lb.String() != "hello" {
+		t.Errorf("expected 'hello', got '%s'", lb.String())
+	}
+
+	// over limit is accepted but discarded
+	n, err = lb.Write([]byte(" world"))
+	if n != 6 || err != nil {
+		t.Fatalf("write returned %d, %v", n, err)
+	}
+	if !strings.HasPrefix(lb.String(), "hello wo") || !strings.HasSuffix(lb.String(), "[output truncated]") {
+		t.Errorf("unexpected truncated output '%s'", lb.String())
+	}
+}
+
+func TestPostProcess_RetryDelay(t *testing.T) {
+	expected := []time.Duration{time.Minute, 4 * time.Minute, 16 * time.Minute}
+
+	for attempt, delay := range expected {
+		if postProcessRetryDelay(attempt) != delay {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, delay, postProcessRetryDelay(attempt))
+		}
+	}
+}
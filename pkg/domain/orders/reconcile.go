@@ -0,0 +1,357 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/acme_accounts"
+	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/domain/private_keys"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccountOrderRef is a minimal reference to an order in storage, used to reconcile
+// storage with an account's orders list on the ACME server
+type AccountOrderRef struct {
+	ID              int
+	CertificateID   int
+	CertificateName string
+	Location        string
+	Status          string
+	KnownRevoked    bool
+	UnknownToServer bool
+	ValidTo         *time.Time
+}
+
+// reconcileOrder is a single order in a reconciliation report
+type reconcileOrder struct {
+	OrderID         *int     `json:"order_id,omitempty"`
+	CertificateID   *int     `json:"certificate_id,omitempty"`
+	CertificateName string   `json:"certificate_name,omitempty"`
+	Location        string   `json:"location"`
+	Status          string   `json:"status"`
+	DnsIdentifiers  []string `json:"dns_identifiers,omitempty"`
+	Note            string   `json:"note,omitempty"`
+}
+
+// reconcileReport is the outcome of reconciling an account's orders in storage
+// with the account's orders list on the ACME server
+type reconcileReport struct {
+	AccountID    int    `json:"acme_account_id"`
+	AccountName  string `json:"acme_account_name"`
+	RanAt        int    `json:"ran_at"`
+	ServerOrders int    `json:"server_orders"`
+	LocalOrders  int    `json:"local_orders"`
+	// Imported are orders the server listed that were not in storage and were added
+	Imported []reconcileOrder `json:"imported"`
+	// Unmatched are orders the server listed that were not in storage but no certificate
+	// with matching identifiers exists, so they could not be imported
+	Unmatched []reconcileOrder `json:"unmatched"`
+	// UnknownToServer are orders in storage that the server no longer recognizes (these
+	// are also flagged in storage)
+	UnknownToServer []reconcileOrder `json:"unknown_to_server"`
+	Errors          []string         `json:"errors"`
+}
+
+// identifiersMatch returns true if the certificate's subject and alt names are
+// exactly the same set as the dns identifiers (case insensitive, any order)
+func identifiersMatch(cert certificates.Certificate, dnsIds []string) bool {
+	certIds := map[string]struct{}{strings.ToLower(cert.Subject): {}}
+	for _, alt := range cert.SubjectAltNames {
+		certIds[strings.ToLower(alt)] = struct{}{}
+	}
+
+	orderIds := map[string]struct{}{}
+	for _, id := range dnsIds {
+		orderIds[strings.ToLower(id)] = struct{}{}
+	}
+
+	if len(certIds) != len(orderIds) {
+		return false
+	}
+	for id := range orderIds {
+		if _, exists := certIds[id]; !exists {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reconcileAccountOrders fetches the account's orders list from the ACME server and
+// compares it to the orders in storage. Orders the server has that aren't in storage
+// are imported (if a certificate with matching identifiers exists) and orders in storage
+// that the server no longer recognizes are flagged (and unflagged if the server
+// recognizes them again). The report is saved and returned.
+func (service *Service) reconcileAccountOrders(account acme_accounts.Account) reconcileReport {
+	report := reconcileReport{
+		AccountID:       account.ID,
+		AccountName:     account.Name,
+		RanAt:           int(time.Now().Unix()),
+		Imported:        []reconcileOrder{},
+		Unmatched:       []reconcileOrder{},
+		UnknownToServer: []reconcileOrder{},
+		Errors:          []string{},
+	}
+
+	// save report when done
+	defer func() {
+		service.reconcileMu.Lock()
+		defer service.reconcileMu.Unlock()
+		service.reconcileReports[account.ID] = report
+	}()
+
+	// account key and acme service
+	key, err := account.AcmeAccountKey()
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to get account key (%s)", err))
+		return report
+	}
+
+	acmeService, err := service.acmeServerService.AcmeService(account.AcmeServer.ID)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to get acme service (%s)", err))
+		return report
+	}
+
+	// don't send if rate limited
+	until := acmeService.RateLimitedUntil(key.Kid)
+	if until != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("acme rate limited until %s", until.Format(time.RFC1123)))
+		return report
+	}
+
+	// server's orders
+	serverOrderUrls, err := acmeService.GetAccountOrders(key)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to get orders list from acme server (%s)", err))
+		return report
+	}
+	report.ServerOrders = len(serverOrderUrls)
+
+	// local orders
+	localRefs, err := service.storage.GetAccountOrderRefs(account.ID)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to get orders from storage (%s)", err))
+		return report
+	}
+	report.LocalOrders = len(localRefs)
+
+	localLocations := make(map[string]struct{}, len(localRefs))
+	for _, ref := range localRefs {
+		localLocations[ref.Location] = struct{}{}
+	}
+
+	// import orders the server knows about that aren't in storage
+	var certs []certificates.Certificate
+	serverLocations := make(map[string]struct{}, len(serverOrderUrls))
+	for _, orderUrl := range serverOrderUrls {
+		serverLocations[orderUrl] = struct{}{}
+		if _, exists := localLocations[orderUrl]; exists {
+			continue
+		}
+
+		// lazy load certs
+		if certs == nil {
+			certs, err = service.certificates.CertificatesByAccount(account.ID)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("failed to get certificates from storage (%s)", err))
+				return report
+			}
+		}
+
+		imported, err := service.importServerOrder(orderUrl, certs, key, acmeService)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to import order %s (%s)", orderUrl, err))
+			// partially imported (e.g. cert download failed) still counts as imported
+			if imported.OrderID == nil {
+				continue
+			}
+		}
+		if imported.OrderID == nil {
+			report.Unmatched = append(report.Unmatched, imported)
+		} else {
+			report.Imported = append(report.Imported, imported)
+		}
+	}
+
+	// flag local orders the server no longer recognizes; servers don't list invalid
+	// orders and may omit old ones, so only check orders that are still relevant and
+	// confirm with the server before flagging
+	for _, ref := range localRefs {
+		if _, exists := serverLocations[ref.Location]; exists {
+			service.setOrderUnknownToServer(ref, false, &report)
+			continue
+		}
+		if ref.Status == "invalid" || ref.KnownRevoked || (ref.ValidTo != nil && ref.ValidTo.Before(time.Now())) {
+			continue
+		}
+
+		_, err = acmeService.GetOrder(ref.Location, key)
+		if err != nil {
+			acmeErr := new(acme.Error)
+			if errors.As(err, &acmeErr) && acmeErr.Status == http.StatusNotFound {
+				orderId := ref.ID
+				certId := ref.CertificateID
+				report.UnknownToServer = append(report.UnknownToServer, reconcileOrder{
+					OrderID:         &orderId,
+					CertificateID:   &certId,
+					CertificateName: ref.CertificateName,
+					Location:        ref.Location,
+					Status:          ref.Status,
+				})
+				service.setOrderUnknownToServer(ref, true, &report)
+				continue
+			}
+
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to check order %d (%s)", ref.ID, err))
+			continue
+		}
+
+		service.setOrderUnknownToServer(ref, false, &report)
+	}
+
+	service.logger.Infof("orders: reconciled account %s orders (server: %d, local: %d, imported: %d, unmatched: %d, unknown to server: %d, errors: %d)",
+		account.Name, report.ServerOrders, report.LocalOrders, len(report.Imported), len(report.Unmatched), len(report.UnknownToServer), len(report.Errors))
+
+	return report
+}
+
+// setOrderUnknownToServer updates the order's unknown to server flag in storage, if it
+// changed
+func (service *Service) setOrderUnknownToServer(ref AccountOrderRef, unknown bool, report *reconcileReport) {
+	if ref.UnknownToServer == unknown {
+		return
+	}
+
+	err := service.storage.PutOrderUnknownToServer(ref.ID, unknown)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to update unknown to server flag of order %d (%s)", ref.ID, err))
+	}
+}
+
+// certKeyMatches returns true if the public key of the leaf certificate in certPem is
+// the public key of key
+func certKeyMatches(certPem string, key private_keys.Key) (bool, error) {
+	block, _ := pem.Decode([]byte(certPem))
+	if block == nil {
+		return false, errors.New("failed to decode certificate pem")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, err
+	}
+
+	signer, err := key.Signer()
+	if err != nil {
+		return false, err
+	}
+
+	pub, ok := leaf.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return false, errors.New("unsupported certificate public key type")
+	}
+
+	return pub.Equal(signer.Public()), nil
+}
+
+// importServerOrder fetches the order from the ACME server and, if a certificate with
+// matching identifiers exists, saves it to storage. The order is saved as metadata only,
+// unless it is valid and its certificate was issued for the certificate's current private
+// key; in that case the certificate is also downloaded and saved (and the key is recorded
+// as the order's finalized key) so it can be used like any other order. If no certificate
+// matches, the returned order has a nil OrderID.
+func (service *Service) importServerOrder(orderUrl string, certs []certificates.Certificate, key acme.AccountKey, acmeService *acme.Service) (reconcileOrder, error) {
+	acmeOrder, err := acmeService.GetOrder(orderUrl, key)
+	if err != nil {
+		return reconcileOrder{}, err
+	}
+
+	imported := reconcileOrder{
+		Location:       orderUrl,
+		Status:         acmeOrder.Status,
+		DnsIdentifiers: acmeOrder.Identifiers.DnsIdentifiers(),
+	}
+
+	// find matching cert
+	var cert *certificates.Certificate
+	for i := range certs {
+		if identifiersMatch(certs[i], imported.DnsIdentifiers) {
+			cert = &certs[i]
+			break
+		}
+	}
+	if cert == nil {
+		return imported, nil
+	}
+
+	// save order
+	orderId, err := service.storage.PostNewOrder(makeNewOrderAcmePayload(*cert, acmeOrder))
+	if err != nil {
+		return reconcileOrder{}, err
+	}
+	imported.OrderID = &orderId
+	imported.CertificateID = &cert.ID
+	imported.CertificateName = cert.Name
+
+	// download cert if valid
+	if acmeOrder.Status == "valid" && acmeOrder.Certificate != nil {
+		chains, err := acmeService.DownloadCertificateChains(*acmeOrder.Certificate, key)
+		if err != nil {
+			return imported, fmt.Errorf("order imported but failed to download certificate (%w)", err)
+		}
+		acmeCert := acme.SelectChain(chains, cert.PreferredRootCN)
+		if acmeCert == nil {
+			return imported, errors.New("order imported but server returned no certificate")
+		}
+
+		// only save the cert if it can be used with the cert's key; otherwise this order
+		// could become the cert's current order without a usable key
+		matches, err := certKeyMatches(acmeCert.PEM(), cert.CertificateKey)
+		if err != nil {
+			return imported, fmt.Errorf("order imported but failed to check certificate key (%w)", err)
+		}
+		if !matches {
+			imported.Note = "imported without its certificate (certificate was not issued for the certificate's current private key)"
+			return imported, nil
+		}
+
+		err = service.storage.UpdateFinalizedKey(orderId, cert.CertificateKey.ID)
+		if err != nil {
+			return imported, fmt.Errorf("order imported but failed to save finalized key (%w)", err)
+		}
+
+		err = service.storage.UpdateOrderCert(orderId, &CertPayload{
+			AcmeCert:  acmeCert,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return imported, fmt.Errorf("order imported but failed to save certificate (%w)", err)
+		}
+		service.saveOrderChains(orderId, chains)
+	}
+
+	return imported, nil
+}
+
+// reconcileAllAccountsOrders reconciles the orders of all usable accounts
+func (service *Service) reconcileAllAccountsOrders() {
+	accounts, err := service.accounts.GetUsableAccounts()
+	if err != nil {
+		service.logger.Errorf("orders: failed to get accounts for orders reconciliation (%s)", err)
+		return
+	}
+
+	for i := range accounts {
+		// stop if shutting down
+		if service.shutdownContext.Err() != nil {
+			return
+		}
+		_ = service.reconcileAccountOrders(accounts[i])
+	}
+}
@@ -0,0 +1,78 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/domain/private_keys"
+	"certwarden-backend/pkg/domain/private_keys/key_crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestReconcile_IdentifiersMatch(t *testing.T) {
+	cert := certificates.Certificate{
+		Subject:         "example.com",
+		SubjectAltNames: []string{"www.example.com", "*.example.com"},
+	}
+
+	tests := []struct {
+		dnsIds   []string
+		expected bool
+	}{
+		{[]string{"example.com", "www.example.com", "*.example.com"}, true},
+		{[]string{"*.example.com", "WWW.example.com", "example.com"}, true},
+		{[]string{"example.com", "www.example.com"}, false},
+		{[]string{"example.com", "www.example.com", "*.example.com", "other.com"}, false},
+		{[]string{"example.com", "www.example.com", "other.com"}, false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		if identifiersMatch(cert, test.dnsIds) != test.expected {
+			t.Errorf("identifiersMatch(%v): expected %t", test.dnsIds, test.expected)
+		}
+	}
+}
+
+func TestReconcile_CertKeyMatches(t *testing.T) {
+	alg := key_crypto.AlgorithmByStorageValue("ecdsap256")
+	newKey := func() private_keys.Key {
+		keyPem, err := alg.GeneratePrivateKeyPem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return private_keys.Key{Algorithm: alg, Pem: keyPem}
+	}
+	certKey := newKey()
+	otherKey := newKey()
+
+	signer, err := certKey.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	if matches, err := certKeyMatches(certPem, certKey); err != nil || !matches {
+		t.Errorf("cert's own key: got (%t, %v), want (true, nil)", matches, err)
+	}
+	if matches, err := certKeyMatches(certPem, otherKey); err != nil || matches {
+		t.Errorf("other key: got (%t, %v), want (false, nil)", matches, err)
+	}
+	if _, err := certKeyMatches("not a pem", certKey); err == nil {
+		t.Error("expected error for invalid pem")
+	}
+}
@@ -0,0 +1,285 @@
+package orders
+
+import (
+	"certwarden-backend/pkg/datatypes/order_events"
+	"certwarden-backend/pkg/datatypes/safecert"
+	"certwarden-backend/pkg/pagination_sort"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// revocationCheckInitialDelay is how long after start the first revocation check runs
+	revocationCheckInitialDelay = 5 * time.Minute
+	// revocationCheckTimeout is the max time to check the revocation status of one order
+	revocationCheckTimeout = 30 * time.Second
+	// maxCRLSize is the maximum size of a CRL that is read
+	maxCRLSize = 20 * 1024 * 1024
+)
+
+var (
+	errRevocationCheckNotAvailable = errors.New("certificate has no usable ocsp server or crl distribution point")
+	errCRLStatusCode               = errors.New("crl server returned non-200 status code")
+	errCRLTooLarge                 = errors.New("crl exceeds max size")
+	errCRLExpired                  = errors.New("crl is past its next update time")
+	errOCSPSerialMismatch          = errors.New("ocsp response is for a different certificate")
+)
+
+// revocationStatus is the result of checking an order's certificate for revocation
+type revocationStatus struct {
+	revoked    bool
+	reasonCode int
+	source     string // ocsp or crl
+}
+
+// startRevocationCheckService starts a go routine that periodically checks all current
+// valid orders to see if their certificates were revoked by the CA (i.e. not by this
+// app). Revoked certificates are immediately re-ordered.
+func (service *Service) startRevocationCheckService(cfg *Config, ctx context.Context, wg *sync.WaitGroup) {
+	// dont run if not enabled
+	if !*cfg.RevocationCheckEnable {
+		return
+	}
+
+	interval := time.Duration(*cfg.RevocationCheckHours) * time.Hour
+
+	// log start and update wg
+	service.logger.Infof("orders: starting certificate revocation check service; checks will run every %d hour(s)", *cfg.RevocationCheckHours)
+	wg.Add(1)
+
+	// service routine
+	go func() {
+		defer wg.Done()
+
+		// first run shortly after start, then on the interval
+		delayTimer := time.NewTimer(revocationCheckInitialDelay)
+
+		// indefinite service loop
+		for {
+			select {
+			case <-ctx.Done():
+				// ensure timer releases resources
+				if !delayTimer.Stop() {
+					<-delayTimer.C
+				}
+
+				// close routine
+				service.logger.Info("orders: certificate revocation check service shutdown complete")
+				return
+
+			case <-delayTimer.C:
+				// proceed to run
+			}
+
+			service.checkCurrentOrdersRevocation(ctx)
+
+			delayTimer.Reset(interval)
+		}
+	}()
+}
+
+// checkCurrentOrdersRevocation checks the revocation status of the certificate of each
+// current valid order and handles any that have been revoked
+func (service *Service) checkCurrentOrdersRevocation(shutdownCtx context.Context) {
+	service.logger.Info("orders: checking current certificates for revocation")
+
+	validOrders, _, err := service.storage.GetAllValidCurrentOrders(pagination_sort.Query{})
+	if err != nil {
+		service.logger.Errorf("orders: error checking certificates for revocation: %s", err)
+		return
+	}
+
+	// cache CRLs for the run, many certs will share the same issuer's CRL
+	crlCache := make(map[string]*x509.RevocationList)
+
+	revokedCount := 0
+	for _, validOrder := range validOrders {
+		// stop early on shutdown
+		if shutdownCtx.Err() != nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(shutdownCtx, revocationCheckTimeout)
+		status, err := service.orderRevocationStatus(ctx, validOrder, crlCache)
+		cancel()
+		if err != nil {
+			service.logger.Debugf("orders: unable to check revocation status of order %d (cert %s) (%s)", validOrder.ID, validOrder.Certificate.Name, err)
+			continue
+		}
+
+		if status.revoked {
+			revokedCount++
+			service.handleExternalRevocation(validOrder, status)
+		}
+	}
+
+	service.logger.Infof("orders: revocation check complete; %d revoked certificate(s) found", revokedCount)
+}
+
+// handleExternalRevocation marks the order as revoked, notifies of the revocation, and
+// places a high priority order to replace the revoked certificate
+func (service *Service) handleExternalRevocation(order Order, status revocationStatus) {
+	service.logger.Warnf("orders: order %d (cert %s) was revoked by its CA (reason code %d, source: %s); reordering", order.ID, order.Certificate.Name, status.reasonCode, status.source)
+
+	err := service.storage.RevokeOrder(order.ID)
+	if err != nil {
+		service.logger.Errorf("orders: failed to mark order %d as revoked (%s)", order.ID, err)
+		return
+	}
+
+	service.orderEventRecorder(order.ID).Record(order_events.TypeRevoked, status.source,
+		fmt.Sprintf("certificate was revoked by its CA (reason code %d)", status.reasonCode))
+	service.notifyCertRevoked(order, status.reasonCode)
+
+	err = service.storage.UpdateCertUpdatedTime(order.Certificate.ID)
+	if err != nil {
+		service.logger.Error(err)
+		// no return
+	}
+
+	service.refreshCert(order, true)
+}
+
+// leafAndIssuer parses the order's pem and returns the leaf certificate and its issuer
+func (order Order) leafAndIssuer() (leaf, issuer *x509.Certificate, err error) {
+	rest := []byte(order.PemContent())
+	certs := []*x509.Certificate{}
+	for len(certs) < 2 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) < 2 {
+		return nil, nil, errors.New("order pem does not contain both a leaf and issuer certificate")
+	}
+
+	return certs[0], certs[1], nil
+}
+
+// orderRevocationStatus checks the revocation status of the order's certificate. OCSP is
+// tried first and if it doesn't give a definitive answer, the CRL(s) are checked.
+func (service *Service) orderRevocationStatus(ctx context.Context, order Order, crlCache map[string]*x509.RevocationList) (revocationStatus, error) {
+	leaf, issuer, err := order.leafAndIssuer()
+	if err != nil {
+		return revocationStatus{}, err
+	}
+
+	// OCSP
+	ocspResp, ocspErr := safecert.FetchOCSPResponse(ctx, leaf, issuer, service.httpClient)
+	if ocspErr == nil && (ocspResp.SerialNumber == nil || ocspResp.SerialNumber.Cmp(leaf.SerialNumber) != 0) {
+		// should never happen, the response is parsed for leaf
+		ocspErr = errOCSPSerialMismatch
+	}
+	if ocspErr == nil {
+		switch ocspResp.Status {
+		case ocsp.Good:
+			return revocationStatus{source: "ocsp"}, nil
+		case ocsp.Revoked:
+			return revocationStatus{revoked: true, reasonCode: ocspResp.RevocationReason, source: "ocsp"}, nil
+		}
+		// unknown, fall back to CRL
+	}
+
+	// CRL
+	if len(leaf.CRLDistributionPoints) <= 0 {
+		if ocspErr != nil && !errors.Is(ocspErr, safecert.ErrOCSPNotAvailable) {
+			return revocationStatus{}, ocspErr
+		}
+		return revocationStatus{}, errRevocationCheckNotAvailable
+	}
+
+	var crlErr error
+	for _, crlURL := range leaf.CRLDistributionPoints {
+		crl, ok := crlCache[crlURL]
+		if !ok {
+			crl, crlErr = service.fetchCRL(ctx, crlURL, issuer)
+			if crlErr != nil {
+				continue
+			}
+			crlCache[crlURL] = crl
+		}
+
+		revoked, reasonCode := crlRevocationStatus(crl, leaf)
+		return revocationStatus{revoked: revoked, reasonCode: reasonCode, source: "crl"}, nil
+	}
+
+	return revocationStatus{}, crlErr
+}
+
+// fetchCRL fetches the CRL at url and verifies it was signed by issuer
+func (service *Service) fetchCRL(ctx context.Context, url string, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	resp, err := service.httpClient.DoContext(ctx, http.MethodGet, url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w (%d)", errCRLStatusCode, resp.StatusCode)
+	}
+
+	crlBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxCRLSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(crlBytes) > maxCRLSize {
+		return nil, errCRLTooLarge
+	}
+
+	crl, err := x509.ParseRevocationList(crlBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyCRL(crl, issuer, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return crl, nil
+}
+
+// verifyCRL returns an error if crl wasn't signed by issuer or if it is stale (i.e. its
+// next update time has passed)
+func verifyCRL(crl *x509.RevocationList, issuer *x509.Certificate, now time.Time) error {
+	err := crl.CheckSignatureFrom(issuer)
+	if err != nil {
+		return err
+	}
+
+	if !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(now) {
+		return errCRLExpired
+	}
+
+	return nil
+}
+
+// crlRevocationStatus returns if leaf is revoked according to crl and, if so, the reason
+// code for the revocation
+func crlRevocationStatus(crl *x509.RevocationList, leaf *x509.Certificate) (revoked bool, reasonCode int) {
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber != nil && entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+			return true, entry.ReasonCode
+		}
+	}
+
+	return false, 0
+}
@@ -0,0 +1,77 @@
+package orders
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRevocation_CRLRevocationStatus(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crlDer, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(42), RevocationTime: time.Now(), ReasonCode: 1},
+		},
+	}, ca, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := x509.ParseRevocationList(crlDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyCRL(crl, ca, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// stale crl must not be used
+	if err = verifyCRL(crl, ca, time.Now().Add(2*time.Hour)); err != errCRLExpired {
+		t.Errorf("stale crl returned %v (expected %v)", err, errCRLExpired)
+	}
+
+	tests := []struct {
+		serial     int64
+		revoked    bool
+		reasonCode int
+	}{
+		{serial: 42, revoked: true, reasonCode: 1},
+		{serial: 43, revoked: false, reasonCode: 0},
+	}
+
+	for _, tt := range tests {
+		revoked, reasonCode := crlRevocationStatus(crl, &x509.Certificate{SerialNumber: big.NewInt(tt.serial)})
+		if revoked != tt.revoked || reasonCode != tt.reasonCode {
+			t.Errorf("serial %d: got (%t, %d), want (%t, %d)", tt.serial, revoked, reasonCode, tt.revoked, tt.reasonCode)
+		}
+	}
+}
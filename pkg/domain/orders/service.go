@@ -2,9 +2,12 @@ package orders
 
 import (
 	"certwarden-backend/pkg/datatypes/job_manager"
+	"certwarden-backend/pkg/domain/acme_accounts"
 	"certwarden-backend/pkg/domain/acme_servers"
+	"certwarden-backend/pkg/domain/app/notifications"
 	"certwarden-backend/pkg/domain/authorizations"
 	"certwarden-backend/pkg/domain/certificates"
+	"certwarden-backend/pkg/domain/deploy_targets"
 	"certwarden-backend/pkg/httpclient"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/pagination_sort"
@@ -27,9 +30,12 @@ type App interface {
 	GetOrderStorage() Storage
 	GetAcmeServerService() *acme_servers.Service
 	GetCertificatesService() *certificates.Service
+	GetNotificationsService() *notifications.Service
+	GetDeployTargetsService() *deploy_targets.Service
 
 	// for fulfiller
 	GetAuthsService() *authorizations.Service
+	GetAcctsService() *acme_accounts.Service
 	GetShutdownWaitGroup() *sync.WaitGroup
 
 	IsHttps() bool
@@ -53,10 +59,31 @@ type Storage interface {
 	UpdateFinalizedKey(orderId int, keyId int) (err error)
 	UpdateOrderCert(orderId int, CertPayload *CertPayload) (err error)
 	RevokeOrder(orderId int) (err error)
+	PutOrderUnknownToServer(orderId int, unknown bool) (err error)
 
 	GetAllValidCurrentOrders(q pagination_sort.Query) (orders []Order, totalRows int, err error)
 	GetAllIncompleteOrderIds() (orderIds []int, err error)
 	GetNewestIncompleteCertOrderId(certId int) (orderId int, err error)
+	GetAccountOrderRefs(accountId int) (refs []AccountOrderRef, err error)
+
+	GetOrderChains(orderId int) (chains []OrderChain, err error)
+	PutOrderChains(orderId int, chains []OrderChain) (err error)
+
+	GetOrderEvents(orderId int) (events []OrderEvent, err error)
+	PostOrderEvent(event OrderEvent) (err error)
+
+	GetPostProcessResults(orderId int) (results []PostProcessResult, err error)
+	PostPostProcessResult(result PostProcessResult) (err error)
+
+	// jobs
+	GetActiveJobs(kind JobKind) (jobs []Job, err error)
+	PostJob(job Job) (newId int, err error)
+	PutJobRunning(jobId int, workerId int, updatedAt int) (err error)
+	PutJobQueued(jobId int, runAt *int, updatedAt int) (err error)
+	PutJobRetry(jobId int, attempt int, runAt int, errMsg string, updatedAt int) (err error)
+	PutJobFinished(jobId int, state JobState, errMsg string, updatedAt int) (err error)
+	DeleteJob(jobId int) (err error)
+	DeleteFinishedJobs(updatedBefore int) (err error)
 
 	// certs
 	UpdateCertUpdatedTime(certId int) (err error)
@@ -67,17 +94,25 @@ type Config struct {
 	AutomaticOrderingEnable *bool `yaml:"auto_order_enable"`
 	RefreshTimeHour         *int  `yaml:"refresh_time_hour"`
 	RefreshTimeMinute       *int  `yaml:"refresh_time_minute"`
+	FulfillingWorkers       *int  `yaml:"fulfilling_workers"`
+	PostProcessingWorkers   *int  `yaml:"post_processing_workers"`
+	RevocationCheckEnable   *bool `yaml:"revocation_check_enable"`
+	RevocationCheckHours    *int  `yaml:"revocation_check_interval_hours"`
 }
 
 // service struct
 type Service struct {
 	shutdownContext   context.Context
+	shutdownWaitgroup *sync.WaitGroup
 	logger            *zap.SugaredLogger
 	output            *output.Service
 	storage           Storage
 	acmeServerService *acme_servers.Service
 	authorizations    *authorizations.Service
+	accounts          *acme_accounts.Service
 	certificates      *certificates.Service
+	notifications     *notifications.Service
+	deployTargets     *deploy_targets.Service
 
 	serverCertificateName    *string
 	loadHttpsCertificateFunc func() error
@@ -86,14 +121,24 @@ type Service struct {
 
 	postProcessing  *job_manager.Manager[*postProcessJob]
 	orderFulfilling *job_manager.Manager[*orderFulfillJob]
+
+	// most recent orders reconciliation report for each account
+	reconcileMu      sync.Mutex
+	reconcileReports map[int]reconcileReport
+
+	// bulk certificate operations (most recent last)
+	bulkMu         sync.Mutex
+	bulkNextId     int
+	bulkOperations []*bulkOperation
 }
 
 // NewService creates a new private_key service
 func NewService(app App, cfg *Config) (*Service, error) {
 	service := new(Service)
 
-	// shutdown context
+	// shutdown context & wait group
 	service.shutdownContext = app.GetShutdownContext()
+	service.shutdownWaitgroup = app.GetShutdownWaitGroup()
 
 	// logger
 	service.logger = app.GetLogger()
@@ -125,12 +170,30 @@ func NewService(app App, cfg *Config) (*Service, error) {
 		return nil, errServiceComponent
 	}
 
+	// accounts
+	service.accounts = app.GetAcctsService()
+	if service.accounts == nil {
+		return nil, errServiceComponent
+	}
+
 	// certificates
 	service.certificates = app.GetCertificatesService()
 	if service.certificates == nil {
 		return nil, errServiceComponent
 	}
 
+	// notifications
+	service.notifications = app.GetNotificationsService()
+	if service.notifications == nil {
+		return nil, errServiceComponent
+	}
+
+	// deploy targets
+	service.deployTargets = app.GetDeployTargetsService()
+	if service.deployTargets == nil {
+		return nil, errServiceComponent
+	}
+
 	// needed to reload App cert on update
 	service.serverCertificateName = app.HttpsCertificateName()
 	service.loadHttpsCertificateFunc = app.LoadHttpsCertificate
@@ -164,21 +227,34 @@ func NewService(app App, cfg *Config) (*Service, error) {
 	service.httpClient = app.GetHttpClient()
 
 	// make post process job manager
-	postWorkers := 3
-	service.postProcessing = job_manager.NewManager[*postProcessJob](postWorkers, "post processing", app.GetShutdownContext(), app.GetShutdownWaitGroup(), app.GetLogger())
+	service.postProcessing = job_manager.NewManager[*postProcessJob](*cfg.PostProcessingWorkers, "post processing", app.GetShutdownContext(), app.GetShutdownWaitGroup(), app.GetLogger())
 	if service.postProcessing == nil {
 		return nil, errServiceComponent
 	}
 
 	// make order fulfill job manager
-	fulfillingWorkers := 3
-	service.orderFulfilling = job_manager.NewManager[*orderFulfillJob](fulfillingWorkers, "order fulfilling", app.GetShutdownContext(), app.GetShutdownWaitGroup(), app.GetLogger())
+	service.orderFulfilling = job_manager.NewManager[*orderFulfillJob](*cfg.FulfillingWorkers, "order fulfilling", app.GetShutdownContext(), app.GetShutdownWaitGroup(), app.GetLogger())
 	if service.orderFulfilling == nil {
 		return nil, errServiceComponent
 	}
 
+	// resend held orders when an acme server's concurrency limit changes
+	service.acmeServerService.OnMaxConcurrentOrdersChange(service.orderFulfilling.RedispatchParkedJobs)
+
+	// recover any jobs that didn't finish before the last shutdown
+	service.recoverJobs()
+
+	// orders reconciliation reports
+	service.reconcileReports = make(map[int]reconcileReport)
+
+	// metrics
+	service.registerMetrics()
+
 	// start service to automatically place and complete orders
 	service.startAutoOrderService(cfg, app.GetShutdownContext(), app.GetShutdownWaitGroup())
 
+	// start service to detect certificates revoked by their CA
+	service.startRevocationCheckService(cfg, app.GetShutdownContext(), app.GetShutdownWaitGroup())
+
 	return service, nil
 }
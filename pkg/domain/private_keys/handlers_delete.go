@@ -1,6 +1,7 @@
 package private_keys
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/output"
 	"errors"
 	"fmt"
@@ -22,7 +23,7 @@ func (service *Service) DeleteKey(w http.ResponseWriter, r *http.Request) *outpu
 	}
 
 	// validate key exists
-	_, outErr := service.getKey(id)
+	key, outErr := service.getKey(id)
 	if outErr != nil {
 		return outErr
 	}
@@ -45,6 +46,7 @@ func (service *Service) DeleteKey(w http.ResponseWriter, r *http.Request) *outpu
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, key.detailedResponse(), nil)
 
 	// write response
 	response := &output.JsonResponse{
@@ -86,6 +88,8 @@ func (service *Service) RemoveOldApiKey(w http.ResponseWriter, r *http.Request)
 	}
 	// validation -- end
 
+	oldKey := key
+
 	// update storage
 	// set current api key from new key
 	err = service.storage.PutKeyApiKey(keyId, key.ApiKeyNew, int(time.Now().Unix()))
@@ -102,6 +106,7 @@ func (service *Service) RemoveOldApiKey(w http.ResponseWriter, r *http.Request)
 		return output.ErrStorageGeneric
 	}
 	key.ApiKeyNew = ""
+	audit.SetChanges(r, oldKey.detailedResponse(), key.detailedResponse())
 
 	// write response
 	response := &privateKeyResponse{}
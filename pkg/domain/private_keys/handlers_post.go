@@ -1,6 +1,7 @@
 package private_keys
 
 import (
+	"certwarden-backend/pkg/domain/app/audit"
 	"certwarden-backend/pkg/domain/private_keys/key_crypto"
 	"certwarden-backend/pkg/output"
 	"certwarden-backend/pkg/randomness"
@@ -104,6 +105,7 @@ func (service *Service) PostNewKey(w http.ResponseWriter, r *http.Request) *outp
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	audit.SetChanges(r, nil, newKey.detailedResponse())
 
 	// write response
 	response := &privateKeyResponse{}
@@ -158,7 +160,9 @@ func (service *Service) StageNewApiKey(w http.ResponseWriter, r *http.Request) *
 		service.logger.Error(err)
 		return output.ErrStorageGeneric
 	}
+	oldKey := key
 	key.ApiKeyNew = newApiKey
+	audit.SetChanges(r, oldKey.detailedResponse(), key.detailedResponse())
 
 	// write response
 	response := &privateKeyResponse{}
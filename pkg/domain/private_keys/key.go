@@ -82,8 +82,8 @@ func (key Key) Modtime() time.Time {
 
 // end Output Methods
 
-// CryptoPrivateKey() provides a crypto.PrivateKey for the Key
-// for the Account
-func (key *Key) CryptoPrivateKey() (cryptoKey crypto.PrivateKey, err error) {
-	return (key_crypto.PemStringToKey(key.Pem, key.Algorithm))
+// Signer() provides a crypto.Signer for the Key (the private key may be in memory or
+// in an external signer's secure module)
+func (key *Key) Signer() (signer crypto.Signer, err error) {
+	return (key_crypto.PemStringToSigner(key.Pem, key.Algorithm))
 }
@@ -0,0 +1,239 @@
+package key_crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// External signer keys are stored as a pem reference instead of private key material.
+// The private key stays in a secure module (e.g. an HSM via PKCS#11, or a cloud KMS)
+// and all signing is done by a local plugin executable. The pem looks like:
+//
+//	-----BEGIN EXTERNAL SIGNER KEY-----
+//	Plugin: my-hsm
+//	Key-Id: certwarden-account-1
+//
+//	<base64 DER PKIX public key>
+//	-----END EXTERNAL SIGNER KEY-----
+//
+// Plugin is the name of a plugin from the config file (the executable path is never
+// taken from the pem). When a new external key is added, the public key may be omitted
+// and it will be fetched from the plugin.
+const (
+	externalSignerPemType         = "EXTERNAL SIGNER KEY"
+	externalSignerPemHeaderPlugin = "Plugin"
+	externalSignerPemHeaderKeyId  = "Key-Id"
+)
+
+// externalSignerTimeout is the maximum time a plugin may take to respond
+const externalSignerTimeout = 30 * time.Second
+
+var (
+	errExternalSignerPluginUnknown = errors.New("external signer plugin is not configured")
+	errExternalSignerBadPem        = errors.New("external signer key pem must contain Plugin and Key-Id headers")
+	errExternalSignerMismatch      = errors.New("external signer key pem public key does not match plugin's public key")
+)
+
+// externalSignerPlugins maps plugin names to their executable paths
+var (
+	externalSignerPlugins   = map[string]string{}
+	externalSignerPluginsMu sync.RWMutex
+)
+
+// SetExternalSignerPlugins sets the available external signer plugins (name: path to
+// executable). This should be called once during app start.
+func SetExternalSignerPlugins(plugins map[string]string) {
+	externalSignerPluginsMu.Lock()
+	defer externalSignerPluginsMu.Unlock()
+
+	externalSignerPlugins = map[string]string{}
+	for name, path := range plugins {
+		externalSignerPlugins[name] = path
+	}
+}
+
+// externalSignerPluginPath returns the executable path for the named plugin
+func externalSignerPluginPath(name string) (string, error) {
+	externalSignerPluginsMu.RLock()
+	defer externalSignerPluginsMu.RUnlock()
+
+	path, exists := externalSignerPlugins[name]
+	if !exists || path == "" {
+		return "", fmt.Errorf("%w (%s)", errExternalSignerPluginUnknown, name)
+	}
+
+	return path, nil
+}
+
+// externalSignerRequest is sent to the plugin as JSON on stdin. Operation is
+// `public_key` or `sign`.
+type externalSignerRequest struct {
+	Operation string `json:"operation"`
+	KeyId     string `json:"key_id"`
+	// sign only: hash name (e.g. SHA-256) and base64 (std) digest to sign
+	Hash   string `json:"hash,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// externalSignerResponse is read from the plugin as JSON on stdout. PublicKey is
+// base64 (std) DER PKIX. Signature is base64 (std) and in the same format Go's
+// crypto.Signer uses (PKCS#1 v1.5 for RSA, ASN.1 DER for ECDSA).
+type externalSignerResponse struct {
+	PublicKey string `json:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// externalSigner is a crypto.Signer that delegates signing to a plugin
+type externalSigner struct {
+	plugin    string
+	keyId     string
+	publicKey crypto.PublicKey
+}
+
+// Public returns the signer's public key
+func (es *externalSigner) Public() crypto.PublicKey {
+	return es.publicKey
+}
+
+// Sign sends the digest to the plugin for signing
+func (es *externalSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, isPss := opts.(*rsa.PSSOptions); isPss {
+		return nil, errors.New("external signer: rsa pss is not supported")
+	}
+
+	resp, err := callExternalSignerPlugin(es.plugin, externalSignerRequest{
+		Operation: "sign",
+		KeyId:     es.keyId,
+		Hash:      opts.HashFunc().String(),
+		Digest:    base64.StdEncoding.EncodeToString(digest),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("external signer: failed to decode signature (%s)", err)
+	}
+
+	return signature, nil
+}
+
+// callExternalSignerPlugin runs the named plugin with the request and returns its response
+func callExternalSignerPlugin(plugin string, req externalSignerRequest) (*externalSignerResponse, error) {
+	path, err := externalSignerPluginPath(plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	reqJson, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalSignerTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(reqJson)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("external signer: plugin %s %s failed (%s: %s)", plugin, req.Operation, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	resp := new(externalSignerResponse)
+	err = json.Unmarshal(stdout.Bytes(), resp)
+	if err != nil {
+		return nil, fmt.Errorf("external signer: plugin %s returned invalid json (%s)", plugin, err)
+	}
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("external signer: plugin %s %s error (%s)", plugin, req.Operation, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// externalSignerPublicKey fetches the DER PKIX public key of the key from the plugin
+func externalSignerPublicKey(plugin, keyId string) ([]byte, error) {
+	resp, err := callExternalSignerPlugin(plugin, externalSignerRequest{
+		Operation: "public_key",
+		KeyId:     keyId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(resp.PublicKey)
+}
+
+// externalSignerPemDecode returns the externalSigner for an external signer pem block
+func externalSignerPemDecode(pemBlock *pem.Block) (*externalSigner, error) {
+	plugin := pemBlock.Headers[externalSignerPemHeaderPlugin]
+	keyId := pemBlock.Headers[externalSignerPemHeaderKeyId]
+	if plugin == "" || keyId == "" {
+		return nil, errExternalSignerBadPem
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(pemBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalSigner{
+		plugin:    plugin,
+		keyId:     keyId,
+		publicKey: publicKey,
+	}, nil
+}
+
+// standardizeExternalSignerPem validates an external signer key pem against its plugin
+// and returns it in a standard format (including the public key)
+func standardizeExternalSignerPem(rawKeyPem string) (string, error) {
+	pemBlock, rest := pem.Decode([]byte(rawKeyPem))
+	if pemBlock == nil || pemBlock.Type != externalSignerPemType || len(bytes.TrimSpace(rest)) != 0 {
+		return "", errUnsupportedPem
+	}
+
+	plugin := pemBlock.Headers[externalSignerPemHeaderPlugin]
+	keyId := pemBlock.Headers[externalSignerPemHeaderKeyId]
+	if plugin == "" || keyId == "" {
+		return "", errExternalSignerBadPem
+	}
+
+	publicKeyDer, err := externalSignerPublicKey(plugin, keyId)
+	if err != nil {
+		return "", err
+	}
+
+	// if pem included a public key, it must match the plugin
+	if len(pemBlock.Bytes) > 0 && !bytes.Equal(pemBlock.Bytes, publicKeyDer) {
+		return "", errExternalSignerMismatch
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type: externalSignerPemType,
+		Headers: map[string]string{
+			externalSignerPemHeaderPlugin: plugin,
+			externalSignerPemHeaderKeyId:  keyId,
+		},
+		Bytes: publicKeyDer,
+	})), nil
+}
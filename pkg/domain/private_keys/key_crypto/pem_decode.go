@@ -27,6 +27,21 @@ func ValidateAndStandardizeKeyPem(rawKeyPem string) (standardizedKeyPem string,
 	// remove leading and trailing whitespace
 	rawKeyPem = strings.TrimSpace(rawKeyPem)
 
+	// external signer keys are standardized separately (pem headers must be kept)
+	if strings.HasPrefix(rawKeyPem, "-----BEGIN "+externalSignerPemType+"-----") {
+		standardizedKeyPem, err = standardizeExternalSignerPem(rawKeyPem)
+		if err != nil {
+			return "", UnknownAlgorithm, err
+		}
+
+		_, alg, err = pemStringDecode(standardizedKeyPem, UnknownAlgorithm)
+		if err != nil {
+			return "", UnknownAlgorithm, err
+		}
+
+		return standardizedKeyPem, alg, nil
+	}
+
 	// check for exactly one beginning & one ending clause
 	if strings.Count(rawKeyPem, "-----BEGIN") != 1 ||
 		strings.Count(rawKeyPem, "-----END") != 1 {
@@ -106,6 +121,24 @@ func PemStringToKey(keyPem string, alg Algorithm) (crypto.PrivateKey, error) {
 	return privateKey, nil
 }
 
+// PemStringToSigner returns a crypto.Signer for a given pem string and verifies that
+// the pem string is of the specified algorithm type. For conventional key pems the key
+// is decoded into memory. For external signer key pems, signing is done by the key's
+// plugin and the private key never leaves its secure module.
+func PemStringToSigner(keyPem string, alg Algorithm) (crypto.Signer, error) {
+	privateKey, err := PemStringToKey(keyPem, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, errUnsupportedPem
+	}
+
+	return signer, nil
+}
+
 // pemStringDecode returns a crypto.PrivateKey after parsing the key pem string.
 // It also determines the algorithm used in the key pem string and if alg is specified
 // as something other than UnknownAlgorithm, the function will confirm alg matches the
@@ -195,6 +228,29 @@ func pemStringDecode(keyPem string, alg Algorithm) (privKey crypto.PrivateKey, i
 			return nil, UnknownAlgorithm, errUnsupportedPem
 		}
 
+	case externalSignerPemType:
+		var extSigner *externalSigner
+		extSigner, err = externalSignerPemDecode(pemBlock)
+		if err != nil {
+			return nil, UnknownAlgorithm, err
+		}
+
+		// find algorithm in list of supported algorithms
+		switch publicKey := extSigner.Public().(type) {
+		case *rsa.PublicKey:
+			identifiedAlg = rsaAlgorithmByBits(publicKey.N.BitLen())
+		case *ecdsa.PublicKey:
+			identifiedAlg = ecdsaAlgorithmByCurve(publicKey.Curve.Params().Name)
+		default:
+			identifiedAlg = UnknownAlgorithm
+		}
+		if identifiedAlg == UnknownAlgorithm {
+			return nil, UnknownAlgorithm, errUnsupportedAlgorithm
+		}
+
+		// success!
+		privKey = extSigner
+
 	default:
 		return nil, UnknownAlgorithm, errUnsupportedPem
 	}
@@ -0,0 +1,55 @@
+package key_crypto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+)
+
+// X509KeyPair is the same as tls.X509KeyPair except it also supports external signer
+// key pems (in which case handshakes are signed by the key's plugin)
+func X509KeyPair(certPem, keyPem string) (tls.Certificate, error) {
+	// conventional key
+	if !strings.HasPrefix(strings.TrimSpace(keyPem), "-----BEGIN "+externalSignerPemType+"-----") {
+		return tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+	}
+
+	// external signer key
+	signer, err := PemStringToSigner(keyPem, UnknownAlgorithm)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	tlsCert := tls.Certificate{
+		PrivateKey: signer,
+	}
+	rest := []byte(certPem)
+	for {
+		var certBlock *pem.Block
+		certBlock, rest = pem.Decode(rest)
+		if certBlock == nil {
+			break
+		}
+		if certBlock.Type == "CERTIFICATE" {
+			tlsCert.Certificate = append(tlsCert.Certificate, certBlock.Bytes)
+		}
+	}
+	if len(tlsCert.Certificate) == 0 {
+		return tls.Certificate{}, errors.New("tls: failed to find any certificate pem data")
+	}
+
+	// confirm key matches the leaf
+	tlsCert.Leaf, err = x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	leafPublicKey, ok := tlsCert.Leaf.PublicKey.(interface{ Equal(x any) bool })
+	if !ok || !leafPublicKey.Equal(signer.Public()) {
+		return tls.Certificate{}, errors.New("tls: private key does not match public key")
+	}
+
+	return tlsCert, nil
+}
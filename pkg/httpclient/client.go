@@ -1,17 +1,39 @@
 package httpclient
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
+// defaultTimeout is the client timeout if one is not specified in Options
+const defaultTimeout = 30 * time.Second
+
 // Client is a custom http.Client that includes the userAgent as
 // required by rfc8555 (section 6.1)
 type Client struct {
 	http      http.Client
 	userAgent string
+	proxyUrl  string
+}
+
+// Options are optional settings for a Client
+type Options struct {
+	// RootCAsPem are trusted in addition to the system roots
+	RootCAsPem string
+	// ClientCertificate is presented to servers that request one (mutual TLS)
+	ClientCertificate *tls.Certificate
+	// Timeout for requests (0 for default)
+	Timeout time.Duration
+	// ProxyURL (http, https, or socks5) that requests are sent through (blank to use
+	// the environment's proxy settings)
+	ProxyURL string
 }
 
 // New creates a new Client.  Client is just an http.Client with some wrapping to
@@ -21,7 +43,7 @@ func New(userAgent string) (client *Client) {
 	client = &Client{
 		http: http.Client{
 			// set client timeout
-			Timeout:   30 * time.Second,
+			Timeout:   defaultTimeout,
 			Transport: http.DefaultTransport,
 		},
 		userAgent: userAgent,
@@ -30,10 +52,89 @@ func New(userAgent string) (client *Client) {
 	return client
 }
 
+// NewWithOptions creates a new Client using the specified Options
+func NewWithOptions(userAgent string, opts Options) (*Client, error) {
+	// start from default transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// proxy
+	if opts.ProxyURL != "" {
+		proxyUrl, err := ParseProxyURL(opts.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	// tls
+	if opts.RootCAsPem != "" || opts.ClientCertificate != nil {
+		transport.TLSClientConfig = &tls.Config{}
+
+		if opts.RootCAsPem != "" {
+			rootCAs, err := x509.SystemCertPool()
+			if err != nil {
+				rootCAs = x509.NewCertPool()
+			}
+			if !rootCAs.AppendCertsFromPEM([]byte(opts.RootCAsPem)) {
+				return nil, errors.New("httpclient: failed to parse any root ca certificates from pem")
+			}
+			transport.TLSClientConfig.RootCAs = rootCAs
+		}
+
+		if opts.ClientCertificate != nil {
+			transport.TLSClientConfig.Certificates = []tls.Certificate{*opts.ClientCertificate}
+		}
+	}
+
+	// timeout
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Client{
+		http: http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		userAgent: userAgent,
+		proxyUrl:  opts.ProxyURL,
+	}, nil
+}
+
+// WithOptions creates a new Client with the same user agent as c and the specified
+// Options. If opts does not specify a proxy, c's proxy is used.
+func (c *Client) WithOptions(opts Options) (*Client, error) {
+	if opts.ProxyURL == "" {
+		opts.ProxyURL = c.proxyUrl
+	}
+
+	return NewWithOptions(c.userAgent, opts)
+}
+
+// ParseProxyURL parses and validates a proxy url
+func ParseProxyURL(proxyUrl string) (*url.URL, error) {
+	u, err := url.Parse(proxyUrl)
+	if err != nil {
+		return nil, fmt.Errorf("httpclient: bad proxy url (%s)", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+		return nil, errors.New("httpclient: proxy url must be http://, https://, or socks5:// and include a host")
+	}
+
+	return u, nil
+}
+
 // do creates a request with the specified parameters, modifies it in accord with ACME
 // spec and then executes the request
 func (c *Client) do(method string, url string, body io.Reader, addlHeader http.Header) (*http.Response, error) {
-	req, err := http.NewRequest(method, url, body)
+	return c.doContext(context.Background(), method, url, body, addlHeader)
+}
+
+// doContext is the same as do, but the request is canceled if ctx is done
+func (c *Client) doContext(ctx context.Context, method string, url string, body io.Reader, addlHeader http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -102,3 +203,18 @@ func (c *Client) PostWithHeader(url string, contentType string, body io.Reader,
 func (c *Client) Post(url string, contentType string, body io.Reader) (resp *http.Response, err error) {
 	return c.PostWithHeader(url, contentType, body, nil)
 }
+
+// DoContext does a request using the specified method, url, body, and additionally
+// specified headers. The request is canceled if ctx is done.
+func (c *Client) DoContext(ctx context.Context, method string, url string, body io.Reader, header http.Header) (*http.Response, error) {
+	return c.doContext(ctx, method, url, body, header)
+}
+
+// PostContext does a post request using the specified url, content type, and body. The
+// request is canceled if ctx is done.
+func (c *Client) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (resp *http.Response, err error) {
+	header := make(http.Header)
+	header.Set("Content-Type", contentType)
+
+	return c.doContext(ctx, http.MethodPost, url, body, header)
+}
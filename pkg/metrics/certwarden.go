@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// bucket boundaries (seconds)
+var (
+	// acmeRequestBuckets are for the latency of individual requests to ACME servers
+	acmeRequestBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	// challengeBuckets are for provisioning and deprovisioning challenge resources, which
+	// can take a long time for some providers (e.g. DNS APIs)
+	challengeBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+)
+
+// CertWarden metrics that are updated as events occur. Metrics that are computed
+// when scraped (e.g. certificate expiry) are registered by the service that owns
+// the data using NewGaugeFunc.
+var (
+	// OrderOutcomes counts completed order fulfillment attempts by their final status
+	OrderOutcomes = NewCounterVec("certwarden_order_outcomes_total",
+		"Total number of order fulfillment attempts by outcome status.", "status")
+
+	// ChallengeProvisionDuration is the time to provision challenge resources
+	ChallengeProvisionDuration = NewHistogramVec("certwarden_challenge_provision_duration_seconds",
+		"Time taken to provision challenge resources.", challengeBuckets, "provider_type", "provider_tag", "result")
+	// ChallengeDeprovisionDuration is the time to deprovision challenge resources
+	ChallengeDeprovisionDuration = NewHistogramVec("certwarden_challenge_deprovision_duration_seconds",
+		"Time taken to deprovision challenge resources.", challengeBuckets, "provider_type", "provider_tag", "result")
+
+	// AcmeRequests counts requests sent to ACME servers
+	AcmeRequests = NewCounterVec("certwarden_acme_requests_total",
+		"Total number of requests sent to ACME servers.", "server", "method", "code")
+	// AcmeRequestDuration is the latency of requests sent to ACME servers
+	AcmeRequestDuration = NewHistogramVec("certwarden_acme_request_duration_seconds",
+		"Latency of requests sent to ACME servers.", acmeRequestBuckets, "server", "method")
+
+	// BackupLastSuccess is the time of the last successful backup
+	BackupLastSuccess = NewGaugeVec("certwarden_backup_last_success_timestamp_seconds",
+		"Unix time of the last successful backup.")
+	// BackupLastFailure is the time of the last failed backup
+	BackupLastFailure = NewGaugeVec("certwarden_backup_last_failure_timestamp_seconds",
+		"Unix time of the last failed backup.")
+)
+
+// Result returns the value of a result label for err
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// ObserveAcmeRequest records a request to an ACME server that was started at start. The
+// server label is the host of requestUrl. If the request failed without a response, the
+// code label is "error".
+func ObserveAcmeRequest(method string, requestUrl string, start time.Time, statusCode int, err error) {
+	server := requestUrl
+	u, parseErr := url.Parse(requestUrl)
+	if parseErr == nil && u.Host != "" {
+		server = u.Host
+	}
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(statusCode)
+	}
+
+	AcmeRequests.Inc(server, method, code)
+	AcmeRequestDuration.Observe(time.Since(start).Seconds(), server, method)
+}
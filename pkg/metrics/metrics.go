@@ -0,0 +1,149 @@
+// Package metrics is a minimal implementation of Prometheus style metrics (counters,
+// gauges, and histograms) that are exported in the Prometheus text exposition format.
+// All metrics are registered in a single package level registry.
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric types
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// TextContentType is the Content-Type of the output of WriteText
+const TextContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// family is a named metric that may have multiple series (one for each
+// combination of label values)
+type family interface {
+	desc() *desc
+	writeSeries(w *bytes.Buffer)
+}
+
+// desc describes a metric family
+type desc struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+}
+
+// registry holds all registered metric families
+var registry = struct {
+	mu       sync.RWMutex
+	families map[string]family
+}{
+	families: make(map[string]family),
+}
+
+// register adds f to the registry. If a family with the same name is already
+// registered, it is replaced.
+func register(f family) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.families[f.desc().name] = f
+}
+
+// WriteText writes all registered metrics to w in the Prometheus text exposition
+// format. Metrics are sorted by name so the output is stable.
+func WriteText(w io.Writer) error {
+	registry.mu.RLock()
+	names := make([]string, 0, len(registry.families))
+	for name := range registry.families {
+		names = append(names, name)
+	}
+	families := make([]family, 0, len(names))
+	slices.Sort(names)
+	for _, name := range names {
+		families = append(families, registry.families[name])
+	}
+	registry.mu.RUnlock()
+
+	buf := &bytes.Buffer{}
+	for _, f := range families {
+		d := f.desc()
+		buf.WriteString("# HELP " + d.name + " " + escapeHelp(d.help) + "\n")
+		buf.WriteString("# TYPE " + d.name + " " + d.metricType + "\n")
+		f.writeSeries(buf)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// seriesKey returns the map key for the series with labelValues
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// sortedKeys returns the keys of a series map, sorted
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// writeSample writes a single sample line
+func writeSample(w *bytes.Buffer, name string, labelNames []string, labelValues []string, extraLabelName string, extraLabelValue string, value float64) {
+	w.WriteString(name)
+
+	if len(labelNames) > 0 || extraLabelName != "" {
+		w.WriteByte('{')
+		for i := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labelNames[i] + `="` + escapeLabelValue(labelValues[i]) + `"`)
+		}
+		if extraLabelName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraLabelName + `="` + escapeLabelValue(extraLabelValue) + `"`)
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// formatFloat formats v as a Prometheus sample value
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeHelp escapes a HELP string
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+// escapeLabelValue escapes a label value
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
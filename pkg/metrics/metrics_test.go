@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_WriteText(t *testing.T) {
+	counter := NewCounterVec("test_counter_total", "A test counter.", "status")
+	counter.Inc("valid")
+	counter.Add(2, "valid")
+	counter.Inc(`in"valid`)
+	counter.Inc("too", "many") // ignored
+	counter.Add(-1, "valid")   // ignored
+
+	gauge := NewGaugeVec("test_gauge", "A test gauge\nwith two lines.")
+	gauge.Set(1.5)
+
+	NewGaugeFunc("test_gauge_func", "A test gauge func.", []string{"queue"}, func() []Sample {
+		return []Sample{{LabelValues: []string{"a"}, Value: 3}}
+	})
+
+	histogram := NewHistogramVec("test_histogram_seconds", "A test histogram.", []float64{1, 0.5}, "server")
+	histogram.Observe(0.25, "x")
+	histogram.Observe(0.75, "x")
+	histogram.Observe(5, "x")
+
+	buf := &bytes.Buffer{}
+	err := WriteText(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	expected := []string{
+		"# HELP test_counter_total A test counter.\n# TYPE test_counter_total counter\n" +
+			"test_counter_total{status=\"in\\\"valid\"} 1\ntest_counter_total{status=\"valid\"} 3\n",
+		"# HELP test_gauge A test gauge\\nwith two lines.\n# TYPE test_gauge gauge\ntest_gauge 1.5\n",
+		"# TYPE test_gauge_func gauge\ntest_gauge_func{queue=\"a\"} 3\n",
+		"# TYPE test_histogram_seconds histogram\n" +
+			"test_histogram_seconds_bucket{server=\"x\",le=\"0.5\"} 1\n" +
+			"test_histogram_seconds_bucket{server=\"x\",le=\"1\"} 2\n" +
+			"test_histogram_seconds_bucket{server=\"x\",le=\"+Inf\"} 3\n" +
+			"test_histogram_seconds_sum{server=\"x\"} 6\n" +
+			"test_histogram_seconds_count{server=\"x\"} 3\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Errorf("output missing:\n%s\nfull output:\n%s", e, out)
+		}
+	}
+
+	// sorted by name
+	if strings.Index(out, "# HELP test_gauge ") > strings.Index(out, "# HELP test_gauge_func ") {
+		t.Error("metrics not sorted by name")
+	}
+}
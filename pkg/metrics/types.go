@@ -0,0 +1,238 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"sync"
+)
+
+// series is a single value with its label values
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// valueVec is the shared implementation of counters and gauges
+type valueVec struct {
+	d      desc
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newValueVec(metricType string, name string, help string, labelNames []string) *valueVec {
+	return &valueVec{
+		d: desc{
+			name:       name,
+			help:       help,
+			metricType: metricType,
+			labelNames: labelNames,
+		},
+		series: make(map[string]*series),
+	}
+}
+
+func (v *valueVec) desc() *desc {
+	return &v.d
+}
+
+// update calls updateFunc on the value of the series with labelValues (creating the
+// series if needed). If the wrong number of label values is specified, it is a no-op.
+func (v *valueVec) update(labelValues []string, updateFunc func(value *float64)) {
+	if len(labelValues) != len(v.d.labelNames) {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	s, exists := v.series[key]
+	if !exists {
+		s = &series{labelValues: slices.Clone(labelValues)}
+		v.series[key] = s
+	}
+	updateFunc(&s.value)
+}
+
+func (v *valueVec) writeSeries(w *bytes.Buffer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, key := range sortedKeys(v.series) {
+		s := v.series[key]
+		writeSample(w, v.d.name, v.d.labelNames, s.labelValues, "", "", s.value)
+	}
+}
+
+// CounterVec is a counter (a value that only increases) partitioned by labels
+type CounterVec struct {
+	*valueVec
+}
+
+// NewCounterVec creates and registers a new CounterVec
+func NewCounterVec(name string, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{newValueVec(typeCounter, name, help, labelNames)}
+	register(c)
+	return c
+}
+
+// Inc increments the counter with labelValues by 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta to the counter with labelValues. Negative deltas are ignored.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.update(labelValues, func(value *float64) { *value += delta })
+}
+
+// GaugeVec is a gauge (a value that can go up and down) partitioned by labels
+type GaugeVec struct {
+	*valueVec
+}
+
+// NewGaugeVec creates and registers a new GaugeVec
+func NewGaugeVec(name string, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{newValueVec(typeGauge, name, help, labelNames)}
+	register(g)
+	return g
+}
+
+// Set sets the gauge with labelValues to value
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(v *float64) { *v = value })
+}
+
+// Sample is a single gauge value returned by a GaugeFunc's collect function
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose values are collected by calling a function each time
+// metrics are written
+type GaugeFunc struct {
+	d       desc
+	collect func() []Sample
+}
+
+// NewGaugeFunc creates and registers a new GaugeFunc. If a metric with the same
+// name is already registered, it is replaced.
+func NewGaugeFunc(name string, help string, labelNames []string, collect func() []Sample) *GaugeFunc {
+	g := &GaugeFunc{
+		d: desc{
+			name:       name,
+			help:       help,
+			metricType: typeGauge,
+			labelNames: labelNames,
+		},
+		collect: collect,
+	}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) desc() *desc {
+	return &g.d
+}
+
+func (g *GaugeFunc) writeSeries(w *bytes.Buffer) {
+	for _, s := range g.collect() {
+		if len(s.LabelValues) != len(g.d.labelNames) {
+			continue
+		}
+		writeSample(w, g.d.name, g.d.labelNames, s.LabelValues, "", "", s.Value)
+	}
+}
+
+// histogramSeries is a single histogram with its label values
+type histogramSeries struct {
+	labelValues  []string
+	bucketCounts []uint64 // non-cumulative
+	sum          float64
+	count        uint64
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	d       desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// NewHistogramVec creates and registers a new HistogramVec using the specified
+// bucket upper bounds (+Inf is implicit)
+func NewHistogramVec(name string, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	h := &HistogramVec{
+		d: desc{
+			name:       name,
+			help:       help,
+			metricType: typeHistogram,
+			labelNames: labelNames,
+		},
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	register(h)
+	return h
+}
+
+func (h *HistogramVec) desc() *desc {
+	return &h.d
+}
+
+// Observe adds value to the histogram with labelValues. If the wrong number of
+// label values is specified, it is a no-op.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.d.labelNames) {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	s, exists := h.series[key]
+	if !exists {
+		s = &histogramSeries{
+			labelValues:  slices.Clone(labelValues),
+			bucketCounts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			s.bucketCounts[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *HistogramVec) writeSeries(w *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		cumulative := uint64(0)
+		for i, upperBound := range h.buckets {
+			cumulative += s.bucketCounts[i]
+			writeSample(w, h.d.name+"_bucket", h.d.labelNames, s.labelValues, "le", formatFloat(upperBound), float64(cumulative))
+		}
+		writeSample(w, h.d.name+"_bucket", h.d.labelNames, s.labelValues, "le", formatFloat(math.Inf(1)), float64(s.count))
+		writeSample(w, h.d.name+"_sum", h.d.labelNames, s.labelValues, "", "", s.sum)
+		writeSample(w, h.d.name+"_count", h.d.labelNames, s.labelValues, "", "", float64(s.count))
+	}
+}
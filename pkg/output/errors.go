@@ -9,6 +9,9 @@ var (
 	ErrInternal     = &Error{StatusCode: 500, Message: "error: internal error"}
 	ErrUnauthorized = &Error{StatusCode: 401, Message: "error: unauthorized"}
 
+	// auth
+	ErrTotpRequired = &Error{StatusCode: 403, Message: "error: valid totp required"}
+
 	// storage errors
 	ErrStorageGeneric = &Error{StatusCode: 500, Message: "error: storage error"}
 	ErrDeleteInUse    = &Error{StatusCode: 409, Message: "error: record in use, can't delete"}
@@ -23,6 +26,9 @@ var (
 
 	// order
 	ErrOrderInvalid = &Error{StatusCode: 400, Message: "error: order status is invalid (which cannot be recovered from)"}
+
+	// acme
+	ErrAcmeRateLimited = &Error{StatusCode: 429, Message: "error: acme server rate limit in effect (try again later)"}
 )
 
 // Error is the standardized error structure, it is the same as a regular message but also
@@ -0,0 +1,28 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WriteJSONLinesNoStoreCache sends a JSON Lines file with the specified filename using the
+// supplied data including a no-store header indicating the file should not be stored in cache.
+func (service *Service) WriteJSONLinesNoStoreCache(w http.ResponseWriter, r *http.Request, filename string, data []byte) {
+	// log output
+	service.logger.Debugf("writing json lines %s to client", filename)
+
+	// convert data to Reader
+	contentReader := bytes.NewReader(data)
+
+	// Set Cache-Control, Content-Type, and Content-Disposition headers explicitly
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/jsonl")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	// do not write HTTP Status, ServeContent will handle this
+
+	// ServeContent (technically fielname is not needed here since Content-Type is set explicitly above)
+	http.ServeContent(w, r, filename, time.Time{}, contentReader)
+}
@@ -2,6 +2,7 @@ package randomness
 
 import (
 	crypto_rand "crypto/rand"
+	"encoding/base32"
 	"encoding/base64"
 	"math/big"
 	math_rand "math/rand/v2"
@@ -13,6 +14,8 @@ const (
 	lengthApiKey        = 32
 	lengthFrontendNonce = 26
 	lengthHexSecret     = 64
+	lengthTotpSecret    = 20
+	lengthRecoveryCode  = 10
 )
 
 // character sets
@@ -20,6 +23,7 @@ const (
 	charSetBase64            = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz+/"
 	charSetNumbersAndLetters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	charSetHex               = "0123456789abcdef"
+	charSetRecoveryCode      = "0123456789abcdefghjkmnpqrstuvwxyz"
 )
 
 // generateRandomByteSlice populates a byte slice of length with data from
@@ -99,6 +103,30 @@ func GenerateHexSecret() ([]byte, error) {
 	return []byte(hexString), err
 }
 
+// GenerateTotpSecret generates a cryptographically secure TOTP secret (160 bits,
+// per RFC 4226 recommendation) and returns it base32 encoded without padding,
+// which is the format authenticator apps expect.
+func GenerateTotpSecret() (string, error) {
+	secret, err := generateRandomByteSlice(lengthTotpSecret)
+	if err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// GenerateRecoveryCode generates a cryptographically secure one time use recovery
+// code. The character set omits characters that are easily confused (i, l, o) and
+// the code is split in half with a hyphen for readability.
+func GenerateRecoveryCode() (string, error) {
+	s, err := generateSecureRandomString(charSetRecoveryCode, lengthRecoveryCode)
+	if err != nil {
+		return "", err
+	}
+
+	return s[:lengthRecoveryCode/2] + "-" + s[lengthRecoveryCode/2:], nil
+}
+
 // Insecure Randoms
 
 // GenerateInsecureInt creates a random int between [0, max). It is NOT
@@ -15,9 +15,14 @@ type accountDb struct {
 	status          string
 	email           string
 	acceptedTos     bool
-	createdAt       int
-	updatedAt       int
-	kid             string
+
+	keyRotationDays      int
+	keyRotationAlgorithm string
+	keyRotationGraceDays int
+
+	createdAt int
+	updatedAt int
+	kid       string
 }
 
 func (acct accountDb) toAccount() acme_accounts.Account {
@@ -30,8 +35,13 @@ func (acct accountDb) toAccount() acme_accounts.Account {
 		Status:      acct.status,
 		Email:       acct.email,
 		AcceptedTos: acct.acceptedTos,
-		CreatedAt:   acct.createdAt,
-		UpdatedAt:   acct.updatedAt,
-		Kid:         acct.kid,
+		KeyRotation: acme_accounts.KeyRotationPolicy{
+			IntervalDays:   acct.keyRotationDays,
+			AlgorithmValue: acct.keyRotationAlgorithm,
+			GraceDays:      acct.keyRotationGraceDays,
+		},
+		CreatedAt: acct.createdAt,
+		UpdatedAt: acct.updatedAt,
+		Kid:       acct.kid,
 	}
 }
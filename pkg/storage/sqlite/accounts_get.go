@@ -49,7 +49,8 @@ func (store *Storage) GetAllAccounts(q pagination_sort.Query) (accounts []acme_a
 	query := fmt.Sprintf(`
 	SELECT
 		aa.id, aa.name, aa.description, aa.status, aa.email, aa.accepted_tos,
-		aa.created_at, aa.updated_at, aa.kid,
+		aa.key_rotation_days, aa.key_rotation_algorithm, aa.key_rotation_grace_days, aa.created_at,
+		aa.updated_at, aa.kid,
 
 		aserv.id, aserv.name, aserv.description, aserv.directory_url, aserv.is_staging, aserv.created_at,
 		aserv.updated_at,
@@ -92,6 +93,9 @@ func (store *Storage) GetAllAccounts(q pagination_sort.Query) (accounts []acme_a
 			&oneAccount.status,
 			&oneAccount.email,
 			&oneAccount.acceptedTos,
+			&oneAccount.keyRotationDays,
+			&oneAccount.keyRotationAlgorithm,
+			&oneAccount.keyRotationGraceDays,
 			&oneAccount.createdAt,
 			&oneAccount.updatedAt,
 			&oneAccount.kid,
@@ -149,7 +153,8 @@ func (store *Storage) getOneAccount(id int, name string) (acme_accounts.Account,
 	query := `
 	SELECT
 		aa.id, aa.name, aa.description, aa.status, aa.email, aa.accepted_tos,
-		aa.created_at, aa.updated_at, aa.kid,
+		aa.key_rotation_days, aa.key_rotation_algorithm, aa.key_rotation_grace_days, aa.created_at,
+		aa.updated_at, aa.kid,
 
 		aserv.id, aserv.name, aserv.description, aserv.directory_url, aserv.is_staging, aserv.created_at,
 		aserv.updated_at,
@@ -174,6 +179,9 @@ func (store *Storage) getOneAccount(id int, name string) (acme_accounts.Account,
 		&oneAccount.status,
 		&oneAccount.email,
 		&oneAccount.acceptedTos,
+		&oneAccount.keyRotationDays,
+		&oneAccount.keyRotationAlgorithm,
+		&oneAccount.keyRotationGraceDays,
 		&oneAccount.createdAt,
 		&oneAccount.updatedAt,
 		&oneAccount.kid,
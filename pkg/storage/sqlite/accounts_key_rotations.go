@@ -0,0 +1,206 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/acme_accounts"
+	"certwarden-backend/pkg/storage"
+	"context"
+	"database/sql"
+)
+
+// keyRotationDb is a single account key rotation record, as database table fields
+// corresponds to acme_accounts.KeyRotation
+type keyRotationDb struct {
+	id              int
+	accountId       int
+	oldKeyId        int
+	oldKeyName      string
+	newKeyId        sql.NullInt32
+	newKeyName      string
+	automatic       bool
+	success         bool
+	errorMsg        string
+	oldKeyDeletedAt int
+	createdAt       int
+}
+
+// toKeyRotation maps the database key rotation to the acme_accounts KeyRotation
+func (kr keyRotationDb) toKeyRotation() acme_accounts.KeyRotation {
+	var newKeyId *int
+	if kr.newKeyId.Valid {
+		newKeyId = new(int)
+		*newKeyId = int(kr.newKeyId.Int32)
+	}
+
+	return acme_accounts.KeyRotation{
+		ID:              kr.id,
+		AccountID:       kr.accountId,
+		OldKeyID:        kr.oldKeyId,
+		OldKeyName:      kr.oldKeyName,
+		NewKeyID:        newKeyId,
+		NewKeyName:      kr.newKeyName,
+		Automatic:       kr.automatic,
+		Success:         kr.success,
+		Error:           kr.errorMsg,
+		OldKeyDeletedAt: kr.oldKeyDeletedAt,
+		CreatedAt:       kr.createdAt,
+	}
+}
+
+// keyRotationSelect is the common select for key rotation records
+const keyRotationSelect = `
+	SELECT
+		kr.id, kr.acme_account_id, kr.old_private_key_id, kr.old_private_key_name, kr.new_private_key_id,
+		kr.new_private_key_name, kr.automatic, kr.success, kr.error, kr.old_key_deleted_at, kr.created_at
+	FROM
+		acme_account_key_rotations kr
+	`
+
+// scanKeyRotations scans all of the rows into KeyRotations
+func scanKeyRotations(rows *sql.Rows) ([]acme_accounts.KeyRotation, error) {
+	rotations := []acme_accounts.KeyRotation{}
+	for rows.Next() {
+		var oneRotation keyRotationDb
+		err := rows.Scan(
+			&oneRotation.id,
+			&oneRotation.accountId,
+			&oneRotation.oldKeyId,
+			&oneRotation.oldKeyName,
+			&oneRotation.newKeyId,
+			&oneRotation.newKeyName,
+			&oneRotation.automatic,
+			&oneRotation.success,
+			&oneRotation.errorMsg,
+			&oneRotation.oldKeyDeletedAt,
+			&oneRotation.createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rotations = append(rotations, oneRotation.toKeyRotation())
+	}
+
+	return rotations, rows.Err()
+}
+
+// GetKeyRotations returns the key rotation history of the account, newest first
+func (store *Storage) GetKeyRotations(accountId int) ([]acme_accounts.KeyRotation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := keyRotationSelect + `
+	WHERE
+		kr.acme_account_id = $1
+	ORDER BY
+		kr.created_at DESC, kr.id DESC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanKeyRotations(rows)
+}
+
+// GetKeyRotationsPastGrace returns the successful automatic rotations whose old key
+// has not been deleted and whose account's grace period has passed
+func (store *Storage) GetKeyRotationsPastGrace(now int) ([]acme_accounts.KeyRotation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := keyRotationSelect + `
+		LEFT JOIN acme_accounts aa on (kr.acme_account_id = aa.id)
+	WHERE
+		kr.automatic = 1
+		AND
+		kr.success = 1
+		AND
+		kr.old_key_deleted_at = 0
+		AND
+		kr.created_at + (aa.key_rotation_grace_days * 86400) <= $1
+	ORDER BY
+		kr.created_at ASC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanKeyRotations(rows)
+}
+
+// PostKeyRotation records a key rotation
+func (store *Storage) PostKeyRotation(rotation acme_accounts.KeyRotation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// transaction
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = insertKeyRotation(ctx, tx, rotation)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertKeyRotation inserts the key rotation record using the transaction
+func insertKeyRotation(ctx context.Context, tx *sql.Tx, rotation acme_accounts.KeyRotation) error {
+	query := `
+	INSERT INTO acme_account_key_rotations (acme_account_id, old_private_key_id, old_private_key_name,
+		new_private_key_id, new_private_key_name, automatic, success, error, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		rotation.AccountID,
+		rotation.OldKeyID,
+		rotation.OldKeyName,
+		rotation.NewKeyID,
+		rotation.NewKeyName,
+		rotation.Automatic,
+		rotation.Success,
+		rotation.Error,
+		rotation.CreatedAt,
+	)
+
+	return err
+}
+
+// PutKeyRotationOldKeyDeleted records that the rotation's old key was deleted
+func (store *Storage) PutKeyRotationOldKeyDeleted(rotationId int, deletedAt int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	UPDATE
+		acme_account_key_rotations
+	SET
+		old_key_deleted_at = $1
+	WHERE
+		id = $2
+	`
+
+	result, err := store.db.ExecContext(ctx, query, deletedAt, rotationId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return storage.ErrNoRecord
+	}
+
+	return nil
+}
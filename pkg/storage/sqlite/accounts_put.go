@@ -83,11 +83,19 @@ func (store *Storage) PutAcmeAccountResponse(payload acme_accounts.AcmeAccount)
 	return updatedAccount, nil
 }
 
-// PutNewAccountKey updates the specified account to the new key id
+// PutNewAccountKey updates the specified account to the new key id and records the
+// rotation in the account's key rotation history (both in one transaction)
 func (store *Storage) PutNewAccountKey(payload acme_accounts.RolloverKeyPayload) (acme_accounts.Account, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
 	defer cancel()
 
+	// transaction
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return acme_accounts.Account{}, err
+	}
+	defer tx.Rollback()
+
 	query := `
 	UPDATE
 		acme_accounts
@@ -98,7 +106,7 @@ func (store *Storage) PutNewAccountKey(payload acme_accounts.RolloverKeyPayload)
 		id = $3
 	`
 
-	_, err := store.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		payload.PrivateKeyID,
 		payload.UpdatedAt,
 		payload.ID,
@@ -108,6 +116,62 @@ func (store *Storage) PutNewAccountKey(payload acme_accounts.RolloverKeyPayload)
 	}
 	// TODO: Handle 0 rows updated.
 
+	err = insertKeyRotation(ctx, tx, acme_accounts.KeyRotation{
+		AccountID:  payload.ID,
+		OldKeyID:   payload.OldKey.ID,
+		OldKeyName: payload.OldKey.Name,
+		NewKeyID:   payload.PrivateKeyID,
+		NewKeyName: payload.NewKey.Name,
+		Automatic:  payload.Automatic,
+		Success:    true,
+		CreatedAt:  payload.UpdatedAt,
+	})
+	if err != nil {
+		return acme_accounts.Account{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return acme_accounts.Account{}, err
+	}
+
+	// get updated account to return
+	updatedAccount, err := store.GetOneAccountById(payload.ID)
+	if err != nil {
+		return acme_accounts.Account{}, err
+	}
+
+	return updatedAccount, nil
+}
+
+// PutKeyRotationPolicy updates the account's automatic key rotation policy
+func (store *Storage) PutKeyRotationPolicy(payload acme_accounts.KeyRotationPolicyPayload) (acme_accounts.Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	UPDATE
+		acme_accounts
+	SET
+		key_rotation_days = case when $1 is null then key_rotation_days else $1 end,
+		key_rotation_algorithm = case when $2 is null then key_rotation_algorithm else $2 end,
+		key_rotation_grace_days = case when $3 is null then key_rotation_grace_days else $3 end,
+		updated_at = $4
+	WHERE
+		id = $5
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		payload.IntervalDays,
+		payload.AlgorithmValue,
+		payload.GraceDays,
+		payload.UpdatedAt,
+		payload.ID,
+	)
+	if err != nil {
+		return acme_accounts.Account{}, err
+	}
+
 	// get updated account to return
 	updatedAccount, err := store.GetOneAccountById(payload.ID)
 	if err != nil {
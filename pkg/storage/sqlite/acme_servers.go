@@ -1,6 +1,9 @@
 package sqlite
 
-import "certwarden-backend/pkg/domain/acme_servers"
+import (
+	"certwarden-backend/pkg/domain/acme_servers"
+	"database/sql"
+)
 
 // acmeServerDb is a single acme server, as database table fields
 // corresponds to acme_servers.Server
@@ -10,20 +13,43 @@ type acmeServerDb struct {
 	description  string
 	directoryUrl string
 	isStaging    bool
-	createdAt    int
-	updatedAt    int
+
+	tlsRootCAsPem    string
+	tlsClientCertPem string
+	tlsClientKeyId   sql.NullInt32
+	timeoutSeconds   int
+	proxyUrl         string
+
+	maxConcurrentOrders int
+
+	createdAt int
+	updatedAt int
 }
 
 // toServer maps the database acme server info to the acme_servers
 // Server object
 func (serv acmeServerDb) toServer() acme_servers.Server {
+	var clientKeyId *int
+	if serv.tlsClientKeyId.Valid {
+		clientKeyId = new(int)
+		*clientKeyId = int(serv.tlsClientKeyId.Int32)
+	}
+
 	return acme_servers.Server{
 		ID:           serv.id,
 		Name:         serv.name,
 		Description:  serv.description,
 		DirectoryURL: serv.directoryUrl,
 		IsStaging:    serv.isStaging,
-		CreatedAt:    serv.createdAt,
-		UpdatedAt:    serv.updatedAt,
+		ClientSettings: acme_servers.ClientSettings{
+			TlsRootCAsPem:    serv.tlsRootCAsPem,
+			TlsClientCertPem: serv.tlsClientCertPem,
+			TlsClientKeyID:   clientKeyId,
+			TimeoutSeconds:   serv.timeoutSeconds,
+			ProxyURL:         serv.proxyUrl,
+		},
+		MaxConcurrentOrders: serv.maxConcurrentOrders,
+		CreatedAt:           serv.createdAt,
+		UpdatedAt:           serv.updatedAt,
 	}
 }
@@ -40,8 +40,9 @@ func (store *Storage) GetAllAcmeServers(q pagination_sort.Query) (accounts []acm
 	// validated prior to this query being assembled!
 	query := fmt.Sprintf(`
 	SELECT
-		aserv.id, aserv.name, aserv.description, aserv.directory_url, aserv.is_staging, aserv.created_at,
-		aserv.updated_at,
+		aserv.id, aserv.name, aserv.description, aserv.directory_url, aserv.is_staging,
+		aserv.tls_root_cas_pem, aserv.tls_client_cert_pem, aserv.tls_client_key_id, aserv.timeout_seconds,
+		aserv.proxy_url, aserv.max_concurrent_orders, aserv.created_at, aserv.updated_at,
 
 		count(*) OVER() AS full_count
 	FROM
@@ -75,6 +76,12 @@ func (store *Storage) GetAllAcmeServers(q pagination_sort.Query) (accounts []acm
 			&oneServer.description,
 			&oneServer.directoryUrl,
 			&oneServer.isStaging,
+			&oneServer.tlsRootCAsPem,
+			&oneServer.tlsClientCertPem,
+			&oneServer.tlsClientKeyId,
+			&oneServer.timeoutSeconds,
+			&oneServer.proxyUrl,
+			&oneServer.maxConcurrentOrders,
 			&oneServer.createdAt,
 			&oneServer.updatedAt,
 
@@ -108,8 +115,9 @@ func (store Storage) dbGetOneServer(acmeServerId int, name string) (acme_servers
 
 	query := `
 	SELECT
-		aserv.id, aserv.name, aserv.description, aserv.directory_url, aserv.is_staging, aserv.created_at,
-		aserv.updated_at
+		aserv.id, aserv.name, aserv.description, aserv.directory_url, aserv.is_staging,
+		aserv.tls_root_cas_pem, aserv.tls_client_cert_pem, aserv.tls_client_key_id, aserv.timeout_seconds,
+		aserv.proxy_url, aserv.max_concurrent_orders, aserv.created_at, aserv.updated_at
 	FROM
 		acme_servers aserv
 	WHERE
@@ -127,6 +135,12 @@ func (store Storage) dbGetOneServer(acmeServerId int, name string) (acme_servers
 		&oneServerDb.description,
 		&oneServerDb.directoryUrl,
 		&oneServerDb.isStaging,
+		&oneServerDb.tlsRootCAsPem,
+		&oneServerDb.tlsClientCertPem,
+		&oneServerDb.tlsClientKeyId,
+		&oneServerDb.timeoutSeconds,
+		&oneServerDb.proxyUrl,
+		&oneServerDb.maxConcurrentOrders,
 		&oneServerDb.createdAt,
 		&oneServerDb.updatedAt,
 	)
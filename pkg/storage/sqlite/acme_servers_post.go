@@ -12,8 +12,10 @@ func (store *Storage) PostNewServer(payload acme_servers.NewPayload) (acme_serve
 	defer cancel()
 
 	query := `
-	INSERT INTO acme_servers (name, description, directory_url, is_staging, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6)
+	INSERT INTO acme_servers (name, description, directory_url, is_staging, tls_root_cas_pem,
+		tls_client_cert_pem, tls_client_key_id, timeout_seconds, proxy_url, max_concurrent_orders, created_at,
+		updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, case when $7 > 0 then $7 else null end, $8, $9, $10, $11, $12)
 	RETURNING id
 	`
 
@@ -24,6 +26,12 @@ func (store *Storage) PostNewServer(payload acme_servers.NewPayload) (acme_serve
 		payload.Description,
 		payload.DirectoryURL,
 		payload.IsStaging,
+		payload.TlsRootCAsPem,
+		payload.TlsClientCertPem,
+		payload.TlsClientKeyID,
+		payload.TimeoutSeconds,
+		payload.ProxyURL,
+		payload.MaxConcurrentOrders,
 		payload.CreatedAt,
 		payload.UpdatedAt,
 	).Scan(&acmeServerId)
@@ -19,9 +19,15 @@ func (store *Storage) PutServerUpdate(payload acme_servers.UpdatePayload) (acme_
 		description = case when $2 is null then description else $2 end,
 		directory_url = case when $3 is null then directory_url else $3 end,
 		is_staging = case when $4 is null then is_staging else $4 end,
-		updated_at = $5
+		tls_root_cas_pem = case when $5 is null then tls_root_cas_pem else $5 end,
+		tls_client_cert_pem = case when $6 is null then tls_client_cert_pem else $6 end,
+		tls_client_key_id = case when $7 is null then tls_client_key_id when $7 > 0 then $7 else null end,
+		timeout_seconds = case when $8 is null then timeout_seconds else $8 end,
+		proxy_url = case when $9 is null then proxy_url else $9 end,
+		max_concurrent_orders = case when $10 is null then max_concurrent_orders else $10 end,
+		updated_at = $11
 	WHERE
-		id = $6
+		id = $12
 	`
 
 	_, err := store.db.ExecContext(ctx, query,
@@ -29,6 +35,12 @@ func (store *Storage) PutServerUpdate(payload acme_servers.UpdatePayload) (acme_
 		payload.Description,
 		payload.DirectoryURL,
 		payload.IsStaging,
+		payload.TlsRootCAsPem,
+		payload.TlsClientCertPem,
+		payload.TlsClientKeyID,
+		payload.TimeoutSeconds,
+		payload.ProxyURL,
+		payload.MaxConcurrentOrders,
 		payload.UpdatedAt,
 		payload.ID,
 	)
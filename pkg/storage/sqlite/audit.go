@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"encoding/json"
+)
+
+// auditEventDb is a single audit event, as database table fields
+// corresponds to audit.Event
+type auditEventDb struct {
+	id         int
+	createdAt  int
+	actor      string
+	sourceIP   string
+	action     string
+	objectType string
+	objectID   string
+	success    bool
+	message    string
+	changes    string
+}
+
+// toAuditEvent maps the database audit event info to the audit Event
+// object
+func (event auditEventDb) toAuditEvent() audit.Event {
+	changes := audit.Changes{}
+	err := json.Unmarshal([]byte(event.changes), &changes)
+	if err != nil {
+		changes = audit.Changes{}
+	}
+
+	return audit.Event{
+		ID:         event.id,
+		CreatedAt:  event.createdAt,
+		Actor:      event.actor,
+		SourceIP:   event.sourceIP,
+		Action:     event.action,
+		ObjectType: event.objectType,
+		ObjectID:   event.objectID,
+		Success:    event.success,
+		Message:    event.message,
+		Changes:    changes,
+	}
+}
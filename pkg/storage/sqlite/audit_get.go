@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"certwarden-backend/pkg/pagination_sort"
+	"context"
+	"fmt"
+)
+
+// GetAuditEvents returns a slice of the audit events in the db that match the filter
+func (store *Storage) GetAuditEvents(q pagination_sort.Query, f audit.Filter) (events []audit.Event, totalRowCount int, err error) {
+	// validate and set sort
+	sortField := q.SortField()
+	switch sortField {
+	// allow these as-is
+	case "id":
+	case "created_at":
+	// default if not in allowed list
+	default:
+		sortField = "id"
+	}
+
+	sortDirection := q.SortDirection()
+	// default to newest first if no sort specified
+	if q.SortField() == "" {
+		sortDirection = "desc"
+	}
+
+	sort := sortField + " " + sortDirection
+
+	// do query
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// WARNING: SQL Injection is possible if the variables are not properly
+	// validated prior to this query being assembled!
+	query := fmt.Sprintf(`
+	SELECT
+		id, created_at, actor, source_ip, action, object_type, object_id, success, message,
+		changes,
+
+		count(*) OVER() AS full_count
+	FROM
+		audit_events
+	WHERE
+		($1 = '' OR actor = $1)
+		AND
+		($2 = '' OR action LIKE '%%' || $2 || '%%')
+		AND
+		($3 = '' OR object_type = $3)
+		AND
+		($4 = '' OR object_id = $4)
+		AND
+		($5 = 0 OR created_at >= $5)
+		AND
+		($6 = 0 OR created_at <= $6)
+	ORDER BY
+		%s
+	LIMIT
+		$7
+	OFFSET
+		$8
+	`, sort)
+
+	rows, err := store.db.QueryContext(ctx, query,
+		f.Actor,
+		f.Action,
+		f.ObjectType,
+		f.ObjectID,
+		f.Since,
+		f.Until,
+		q.Limit(),
+		q.Offset(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// for total row count
+	var totalRows int
+
+	for rows.Next() {
+		var oneEventDb auditEventDb
+		err = rows.Scan(
+			&oneEventDb.id,
+			&oneEventDb.createdAt,
+			&oneEventDb.actor,
+			&oneEventDb.sourceIP,
+			&oneEventDb.action,
+			&oneEventDb.objectType,
+			&oneEventDb.objectID,
+			&oneEventDb.success,
+			&oneEventDb.message,
+			&oneEventDb.changes,
+
+			&totalRows,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		events = append(events, oneEventDb.toAuditEvent())
+	}
+
+	return events, totalRows, nil
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/audit"
+	"context"
+	"encoding/json"
+)
+
+// PostNewAuditEvent saves an audit event to the db
+func (store *Storage) PostNewAuditEvent(event audit.Event) error {
+	// marshal changes
+	changes := []byte("{}")
+	if len(event.Changes) > 0 {
+		var err error
+		changes, err = json.Marshal(event.Changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO
+		audit_events (created_at, actor, source_ip, action, object_type, object_id, success,
+			message, changes)
+	VALUES (
+		$1,
+		$2,
+		$3,
+		$4,
+		$5,
+		$6,
+		$7,
+		$8,
+		$9
+	)
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		event.CreatedAt,
+		event.Actor,
+		event.SourceIP,
+		event.Action,
+		event.ObjectType,
+		event.ObjectID,
+		event.Success,
+		event.Message,
+		string(changes),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/authorizations"
+)
+
+// authorizationDb is a single tracked acme authorization, as database table fields
+// corresponds to authorizations.Authorization
+type authorizationDb struct {
+	id              int
+	acmeAccountId   int
+	identifierType  string
+	identifierValue string
+	wildcard        bool
+	url             string
+	status          string
+	expires         int
+	createdAt       int
+	updatedAt       int
+}
+
+// toAuthorization maps the database authorization info to the authorizations
+// Authorization object
+func (auth authorizationDb) toAuthorization() authorizations.Authorization {
+	return authorizations.Authorization{
+		ID:              auth.id,
+		AcmeAccountID:   auth.acmeAccountId,
+		IdentifierType:  auth.identifierType,
+		IdentifierValue: auth.identifierValue,
+		Wildcard:        auth.wildcard,
+		URL:             auth.url,
+		Status:          auth.status,
+		Expires:         auth.expires,
+		CreatedAt:       auth.createdAt,
+		UpdatedAt:       auth.updatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/authorizations"
+	"certwarden-backend/pkg/storage"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// GetAuthorizationsByAccount returns all of the tracked authorizations for the specified
+// account, ordered by identifier
+func (store *Storage) GetAuthorizationsByAccount(accountId int) ([]authorizations.Authorization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, acme_account_id, identifier_type, identifier_value, wildcard, url, status, expires,
+		created_at, updated_at
+	FROM
+		acme_authorizations
+	WHERE
+		acme_account_id = $1
+	ORDER BY
+		identifier_value ASC, expires DESC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	auths := []authorizations.Authorization{}
+	for rows.Next() {
+		var oneAuthDb authorizationDb
+		err = rows.Scan(
+			&oneAuthDb.id,
+			&oneAuthDb.acmeAccountId,
+			&oneAuthDb.identifierType,
+			&oneAuthDb.identifierValue,
+			&oneAuthDb.wildcard,
+			&oneAuthDb.url,
+			&oneAuthDb.status,
+			&oneAuthDb.expires,
+			&oneAuthDb.createdAt,
+			&oneAuthDb.updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		auths = append(auths, oneAuthDb.toAuthorization())
+	}
+
+	return auths, nil
+}
+
+// GetAuthorizationByUrl returns the tracked authorization with the specified url
+func (store *Storage) GetAuthorizationByUrl(url string) (authorizations.Authorization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, acme_account_id, identifier_type, identifier_value, wildcard, url, status, expires,
+		created_at, updated_at
+	FROM
+		acme_authorizations
+	WHERE
+		url = $1
+	`
+
+	row := store.db.QueryRowContext(ctx, query, url)
+
+	var oneAuthDb authorizationDb
+	err := row.Scan(
+		&oneAuthDb.id,
+		&oneAuthDb.acmeAccountId,
+		&oneAuthDb.identifierType,
+		&oneAuthDb.identifierValue,
+		&oneAuthDb.wildcard,
+		&oneAuthDb.url,
+		&oneAuthDb.status,
+		&oneAuthDb.expires,
+		&oneAuthDb.createdAt,
+		&oneAuthDb.updatedAt,
+	)
+
+	if err != nil {
+		// if no record exists
+		if errors.Is(err, sql.ErrNoRows) {
+			err = storage.ErrNoRecord
+		}
+		return authorizations.Authorization{}, err
+	}
+
+	return oneAuthDb.toAuthorization(), nil
+}
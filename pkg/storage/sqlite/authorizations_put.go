@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/authorizations"
+	"context"
+)
+
+// PutAuthorization saves the state of an acme authorization. If the authorization's url
+// is not already tracked, it is inserted.
+func (store *Storage) PutAuthorization(payload authorizations.UpsertPayload) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO acme_authorizations (acme_account_id, identifier_type, identifier_value, wildcard, url,
+		status, expires, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (url) DO UPDATE SET
+		status = excluded.status,
+		expires = excluded.expires,
+		updated_at = excluded.updated_at
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		payload.AcmeAccountID,
+		payload.IdentifierType,
+		payload.IdentifierValue,
+		payload.Wildcard,
+		payload.URL,
+		payload.Status,
+		payload.Expires,
+		payload.UpdatedAt,
+		payload.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
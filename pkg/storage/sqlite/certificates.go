@@ -29,6 +29,14 @@ type certificateDb struct {
 	postProcessingCommand      string
 	postProcessingEnvironment  jsonStringSlice // stored as json array
 	postProcessingClientKeyB64 string          // base64 raw url encoded AES 256 key
+	profile                    string
+	requestedValidityHours     int
+	postProcessingTimeout      int
+	postProcessingArgs         jsonStringSlice // stored as json array
+	postProcessingWorkingDir   string
+	postProcessingWriteFiles   bool
+	postProcessingClients      jsonClientTargetSlice // stored as json array
+	postProcessingClientCAPem  string
 }
 
 func (cert certificateDb) toCertificate() (certificates.Certificate, error) {
@@ -37,6 +45,11 @@ func (cert certificateDb) toCertificate() (certificates.Certificate, error) {
 		return certificates.Certificate{}, err
 	}
 
+	clients, err := cert.postProcessingClients.toClientTargetSlice()
+	if err != nil {
+		return certificates.Certificate{}, err
+	}
+
 	return certificates.Certificate{
 		ID:                         cert.id,
 		Name:                       cert.name,
@@ -60,5 +73,13 @@ func (cert certificateDb) toCertificate() (certificates.Certificate, error) {
 		PostProcessingCommand:      cert.postProcessingCommand,
 		PostProcessingEnvironment:  cert.postProcessingEnvironment.toSlice(),
 		PostProcessingClientKeyB64: cert.postProcessingClientKeyB64,
+		Profile:                    cert.profile,
+		RequestedValidityHours:     cert.requestedValidityHours,
+		PostProcessingTimeout:      cert.postProcessingTimeout,
+		PostProcessingArgs:         cert.postProcessingArgs.toSlice(),
+		PostProcessingWorkingDir:   cert.postProcessingWorkingDir,
+		PostProcessingWriteFiles:   cert.postProcessingWriteFiles,
+		PostProcessingClients:      clients,
+		PostProcessingClientCAPem:  cert.postProcessingClientCAPem,
 	}, nil
 }
@@ -48,7 +48,9 @@ func (store *Storage) GetAllCerts(q pagination_sort.Query) (certs []certificates
 		c.csr_org, c.csr_ou, c.csr_country, c.csr_state, c.csr_city, c.csr_extra_extensions, c.preferred_root_cn,
 		c.created_at, c.updated_at,
 		c.api_key, c.api_key_new, c.api_key_via_url, c.post_processing_command, c.post_processing_environment,
-		c.post_processing_client_key,
+		c.post_processing_client_key, c.profile, c.requested_validity_hours, c.post_processing_timeout,
+		c.post_processing_args, c.post_processing_working_dir, c.post_processing_write_files,
+		c.post_processing_clients, c.post_processing_client_ca_pem,
 		
 		pk.id, pk.name, pk.description, pk.algorithm, pk.pem, pk.api_key, pk.api_key_new,
 		pk.api_key_disabled, pk.api_key_via_url, pk.created_at, pk.updated_at,
@@ -113,6 +115,14 @@ func (store *Storage) GetAllCerts(q pagination_sort.Query) (certs []certificates
 			&oneCert.postProcessingCommand,
 			&oneCert.postProcessingEnvironment,
 			&oneCert.postProcessingClientKeyB64,
+			&oneCert.profile,
+			&oneCert.requestedValidityHours,
+			&oneCert.postProcessingTimeout,
+			&oneCert.postProcessingArgs,
+			&oneCert.postProcessingWorkingDir,
+			&oneCert.postProcessingWriteFiles,
+			&oneCert.postProcessingClients,
+			&oneCert.postProcessingClientCAPem,
 
 			&oneCert.certificateKeyDb.id,
 			&oneCert.certificateKeyDb.name,
@@ -195,7 +205,9 @@ func (store *Storage) getOneCert(id int, name string) (cert certificates.Certifi
 		c.csr_org, c.csr_ou, c.csr_country, c.csr_state, c.csr_city, c.csr_extra_extensions, c.preferred_root_cn,
 		c.created_at, c.updated_at,
 		c.api_key, c.api_key_new, c.api_key_via_url, c.post_processing_command, c.post_processing_environment,
-		c.post_processing_client_key,
+		c.post_processing_client_key, c.profile, c.requested_validity_hours, c.post_processing_timeout,
+		c.post_processing_args, c.post_processing_working_dir, c.post_processing_write_files,
+		c.post_processing_clients, c.post_processing_client_ca_pem,
 		
 		pk.id, pk.name, pk.description, pk.algorithm, pk.pem, pk.api_key, pk.api_key_new,
 		pk.api_key_disabled, pk.api_key_via_url, pk.created_at, pk.updated_at,
@@ -244,6 +256,14 @@ func (store *Storage) getOneCert(id int, name string) (cert certificates.Certifi
 		&oneCert.postProcessingCommand,
 		&oneCert.postProcessingEnvironment,
 		&oneCert.postProcessingClientKeyB64,
+		&oneCert.profile,
+		&oneCert.requestedValidityHours,
+		&oneCert.postProcessingTimeout,
+		&oneCert.postProcessingArgs,
+		&oneCert.postProcessingWorkingDir,
+		&oneCert.postProcessingWriteFiles,
+		&oneCert.postProcessingClients,
+		&oneCert.postProcessingClientCAPem,
 
 		&oneCert.certificateKeyDb.id,
 		&oneCert.certificateKeyDb.name,
@@ -19,8 +19,11 @@ func (store *Storage) PostNewCert(payload certificates.NewPayload) (certificates
 	INSERT INTO certificates (name, description, private_key_id, acme_account_id, subject, subject_alts, 
 		csr_org, csr_ou, csr_country, csr_state, csr_city, csr_extra_extensions, preferred_root_cn, 
 		created_at, updated_at, api_key, api_key_via_url,
-		post_processing_command, post_processing_environment, post_processing_client_key)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		post_processing_command, post_processing_environment, post_processing_client_key, profile,
+		requested_validity_hours, post_processing_timeout, post_processing_args, post_processing_working_dir,
+		post_processing_write_files, post_processing_clients, post_processing_client_ca_pem)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+		$24, $25, $26, $27, $28)
 	RETURNING id
 	`
 
@@ -46,6 +49,14 @@ func (store *Storage) PostNewCert(payload certificates.NewPayload) (certificates
 		payload.PostProcessingCommand,
 		makeJsonStringSlice(payload.PostProcessingEnvironment),
 		payload.PostProcessingClientKeyB64,
+		payload.Profile,
+		payload.RequestedValidityHours,
+		payload.PostProcessingTimeout,
+		makeJsonStringSlice(payload.PostProcessingArgs),
+		payload.PostProcessingWorkingDir,
+		payload.PostProcessingWriteFiles,
+		makeJsonClientTargetSlice(payload.PostProcessingClients),
+		payload.PostProcessingClientCAPem,
 	).Scan(&id)
 
 	if err != nil {
@@ -9,6 +9,19 @@ import (
 // PutDetailsCert saves details about the cert that can be updated at any time. It only updates
 // the details which are provided
 func (store *Storage) PutDetailsCert(payload certificates.DetailsUpdatePayload) (certificates.Certificate, error) {
+	// args are only updated if provided (unlike environment, for compatibility with
+	// clients that don't send them)
+	var postProcessingArgs *jsonStringSlice
+	if payload.PostProcessingArgs != nil {
+		args := makeJsonStringSlice(payload.PostProcessingArgs)
+		postProcessingArgs = &args
+	}
+	var postProcessingClients *jsonClientTargetSlice
+	if payload.PostProcessingClients != nil {
+		clients := makeJsonClientTargetSlice(payload.PostProcessingClients)
+		postProcessingClients = &clients
+	}
+
 	// database update
 	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
 	defer cancel()
@@ -33,9 +46,17 @@ func (store *Storage) PutDetailsCert(payload certificates.DetailsUpdatePayload)
 			api_key_via_url = case when $14 is null then api_key_via_url else $14 end,
 			post_processing_command = case when $15 is null then post_processing_command else $15 end,
 			post_processing_environment = case when $16 is null then post_processing_environment else $16 end,
-			updated_at = $17
+			profile = case when $17 is null then profile else $17 end,
+			requested_validity_hours = case when $18 is null then requested_validity_hours else $18 end,
+			post_processing_timeout = case when $19 is null then post_processing_timeout else $19 end,
+			post_processing_args = case when $20 is null then post_processing_args else $20 end,
+			post_processing_working_dir = case when $21 is null then post_processing_working_dir else $21 end,
+			post_processing_write_files = case when $22 is null then post_processing_write_files else $22 end,
+			post_processing_clients = case when $23 is null then post_processing_clients else $23 end,
+			post_processing_client_ca_pem = case when $24 is null then post_processing_client_ca_pem else $24 end,
+			updated_at = $25
 		WHERE
-			id = $18
+			id = $26
 		`
 
 	_, err := store.db.ExecContext(ctx, query,
@@ -55,6 +76,14 @@ func (store *Storage) PutDetailsCert(payload certificates.DetailsUpdatePayload)
 		payload.ApiKeyViaUrl,
 		payload.PostProcessingCommand,
 		makeJsonStringSlice(payload.PostProcessingEnvironment),
+		payload.Profile,
+		payload.RequestedValidityHours,
+		payload.PostProcessingTimeout,
+		postProcessingArgs,
+		payload.PostProcessingWorkingDir,
+		payload.PostProcessingWriteFiles,
+		postProcessingClients,
+		payload.PostProcessingClientCAPem,
 		payload.UpdatedAt,
 		payload.ID,
 	)
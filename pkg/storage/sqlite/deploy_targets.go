@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/deploy_targets"
+	"encoding/json"
+)
+
+// deployTargetDb is a single deploy target, as database table fields
+// corresponds to deploy_targets.DeployTarget
+type deployTargetDb struct {
+	id            int
+	certificateId int
+	name          string
+	description   string
+	targetType    string
+	config        string // json
+	enabled       bool
+	createdAt     int
+	updatedAt     int
+}
+
+// toDeployTarget maps the database deploy target info to the deploy_targets
+// DeployTarget object
+func (target deployTargetDb) toDeployTarget() (deploy_targets.DeployTarget, error) {
+	var config deploy_targets.Config
+	err := json.Unmarshal([]byte(target.config), &config)
+	if err != nil {
+		return deploy_targets.DeployTarget{}, err
+	}
+
+	return deploy_targets.DeployTarget{
+		ID:            target.id,
+		CertificateID: target.certificateId,
+		Name:          target.name,
+		Description:   target.description,
+		Type:          deploy_targets.Type(target.targetType),
+		Config:        config,
+		Enabled:       target.enabled,
+		CreatedAt:     target.createdAt,
+		UpdatedAt:     target.updatedAt,
+	}, nil
+}
+
+// makeDeployTargetConfig returns the json of a deploy target config for storage
+func makeDeployTargetConfig(config deploy_targets.Config) (string, error) {
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJson), nil
+}
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/storage"
+	"context"
+)
+
+// DeleteDeployTarget deletes a deploy target from the database (post processing
+// results that reference it keep its name but lose the reference)
+func (store *Storage) DeleteDeployTarget(id int) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	DELETE FROM
+		deploy_targets
+	WHERE
+		id = $1
+	`
+
+	result, err := store.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	// if nothing was deleted, the target didn't exist
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNoRecord
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/deploy_targets"
+	"certwarden-backend/pkg/storage"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// GetCertDeployTargets returns a slice of all of the deploy targets of the specified
+// certificate
+func (store *Storage) GetCertDeployTargets(certId int) (targets []deploy_targets.DeployTarget, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, certificate_id, name, description, type, config, enabled, created_at, updated_at
+	FROM
+		deploy_targets
+	WHERE
+		certificate_id = $1
+	ORDER BY
+		id
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, certId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var oneTargetDb deployTargetDb
+		err = rows.Scan(
+			&oneTargetDb.id,
+			&oneTargetDb.certificateId,
+			&oneTargetDb.name,
+			&oneTargetDb.description,
+			&oneTargetDb.targetType,
+			&oneTargetDb.config,
+			&oneTargetDb.enabled,
+			&oneTargetDb.createdAt,
+			&oneTargetDb.updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		target, err := oneTargetDb.toDeployTarget()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// GetOneDeployTargetById returns a deploy target based on unique id
+func (store *Storage) GetOneDeployTargetById(id int) (deploy_targets.DeployTarget, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, certificate_id, name, description, type, config, enabled, created_at, updated_at
+	FROM
+		deploy_targets
+	WHERE
+		id = $1
+	`
+
+	row := store.db.QueryRowContext(ctx, query, id)
+
+	var oneTargetDb deployTargetDb
+	err := row.Scan(
+		&oneTargetDb.id,
+		&oneTargetDb.certificateId,
+		&oneTargetDb.name,
+		&oneTargetDb.description,
+		&oneTargetDb.targetType,
+		&oneTargetDb.config,
+		&oneTargetDb.enabled,
+		&oneTargetDb.createdAt,
+		&oneTargetDb.updatedAt,
+	)
+
+	if err != nil {
+		// if no record exists
+		if errors.Is(err, sql.ErrNoRows) {
+			err = storage.ErrNoRecord
+		}
+		return deploy_targets.DeployTarget{}, err
+	}
+
+	return oneTargetDb.toDeployTarget()
+}
@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/deploy_targets"
+	"context"
+)
+
+// PostNewDeployTarget saves a new deploy target to the db
+func (store *Storage) PostNewDeployTarget(payload deploy_targets.NewPayload) (deploy_targets.DeployTarget, error) {
+	// config is stored as json
+	config, err := makeDeployTargetConfig(*payload.Config)
+	if err != nil {
+		return deploy_targets.DeployTarget{}, err
+	}
+
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO deploy_targets (certificate_id, name, description, type, config, enabled, created_at,
+		updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id
+	`
+
+	// insert and scan the new id
+	id := -1
+	err = store.db.QueryRowContext(ctx, query,
+		payload.CertificateID,
+		payload.Name,
+		payload.Description,
+		payload.Type,
+		config,
+		payload.Enabled,
+		payload.CreatedAt,
+		payload.UpdatedAt,
+	).Scan(&id)
+
+	if err != nil {
+		return deploy_targets.DeployTarget{}, err
+	}
+
+	// get new target to return
+	return store.GetOneDeployTargetById(id)
+}
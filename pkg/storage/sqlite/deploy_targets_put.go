@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/deploy_targets"
+	"context"
+)
+
+// PutDeployTargetUpdate updates an existing deploy target in the db using any non-null
+// fields specified in the UpdatePayload.
+func (store *Storage) PutDeployTargetUpdate(payload deploy_targets.UpdatePayload) (deploy_targets.DeployTarget, error) {
+	// config is stored as json
+	var config *string
+	if payload.Config != nil {
+		configJson, err := makeDeployTargetConfig(*payload.Config)
+		if err != nil {
+			return deploy_targets.DeployTarget{}, err
+		}
+		config = &configJson
+	}
+
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	UPDATE
+		deploy_targets
+	SET
+		name = case when $1 is null then name else $1 end,
+		description = case when $2 is null then description else $2 end,
+		config = case when $3 is null then config else $3 end,
+		enabled = case when $4 is null then enabled else $4 end,
+		updated_at = $5
+	WHERE
+		id = $6
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		payload.Name,
+		payload.Description,
+		config,
+		payload.Enabled,
+		payload.UpdatedAt,
+		payload.ID,
+	)
+	if err != nil {
+		return deploy_targets.DeployTarget{}, err
+	}
+
+	// get updated target to return
+	return store.GetOneDeployTargetById(payload.ID)
+}
@@ -0,0 +1,259 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/orders"
+	"certwarden-backend/pkg/storage"
+	"context"
+	"database/sql"
+)
+
+// jobDb is a single persisted job, as database table fields
+// corresponds to orders.Job
+type jobDb struct {
+	id           int
+	kind         string
+	orderId      int
+	highPriority bool
+	state        string
+	workerId     sql.NullInt64
+	runAt        sql.NullInt64
+	attempt      int
+	errorMsg     string
+	createdAt    int
+	updatedAt    int
+}
+
+// toJob maps the database job to the orders Job
+func (j jobDb) toJob() orders.Job {
+	var workerId *int
+	if j.workerId.Valid {
+		workerId = new(int)
+		*workerId = int(j.workerId.Int64)
+	}
+
+	var runAt *int
+	if j.runAt.Valid {
+		runAt = new(int)
+		*runAt = int(j.runAt.Int64)
+	}
+
+	return orders.Job{
+		ID:           j.id,
+		Kind:         orders.JobKind(j.kind),
+		OrderID:      j.orderId,
+		HighPriority: j.highPriority,
+		State:        orders.JobState(j.state),
+		WorkerID:     workerId,
+		RunAt:        runAt,
+		Attempt:      j.attempt,
+		Error:        j.errorMsg,
+		CreatedAt:    j.createdAt,
+		UpdatedAt:    j.updatedAt,
+	}
+}
+
+// GetActiveJobs returns all of the queued and running jobs of the specified kind,
+// high priority jobs first and then oldest first
+func (store *Storage) GetActiveJobs(kind orders.JobKind) ([]orders.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, kind, order_id, high_priority, state, worker_id, run_at, attempt, error, created_at, updated_at
+	FROM
+		jobs
+	WHERE
+		kind = $1
+		AND
+		state IN ("queued", "running")
+	ORDER BY
+		high_priority DESC, id ASC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, string(kind))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []orders.Job{}
+	for rows.Next() {
+		var oneJob jobDb
+		err = rows.Scan(
+			&oneJob.id,
+			&oneJob.kind,
+			&oneJob.orderId,
+			&oneJob.highPriority,
+			&oneJob.state,
+			&oneJob.workerId,
+			&oneJob.runAt,
+			&oneJob.attempt,
+			&oneJob.errorMsg,
+			&oneJob.createdAt,
+			&oneJob.updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, oneJob.toJob())
+	}
+
+	return jobs, rows.Err()
+}
+
+// PostJob saves a new job and returns its id
+func (store *Storage) PostJob(job orders.Job) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO jobs (kind, order_id, high_priority, state, worker_id, run_at, error, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id
+	`
+
+	id := -1
+	err := store.db.QueryRowContext(ctx, query,
+		string(job.Kind),
+		job.OrderID,
+		job.HighPriority,
+		string(job.State),
+		job.WorkerID,
+		job.RunAt,
+		job.Error,
+		job.CreatedAt,
+		job.UpdatedAt,
+	).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// PutJobRunning sets the job's state to running by the specified worker
+func (store *Storage) PutJobRunning(jobId int, workerId int, updatedAt int) error {
+	query := `
+	UPDATE
+		jobs
+	SET
+		state = "running",
+		worker_id = $1,
+		run_at = NULL,
+		updated_at = $2
+	WHERE
+		id = $3
+	`
+
+	return store.execJobUpdate(query, workerId, updatedAt, jobId)
+}
+
+// PutJobQueued sets the job's state to queued. If runAt is not nil, the job is delayed
+// until that time.
+func (store *Storage) PutJobQueued(jobId int, runAt *int, updatedAt int) error {
+	query := `
+	UPDATE
+		jobs
+	SET
+		state = "queued",
+		worker_id = NULL,
+		run_at = $1,
+		updated_at = $2
+	WHERE
+		id = $3
+	`
+
+	return store.execJobUpdate(query, runAt, updatedAt, jobId)
+}
+
+// PutJobRetry sets the job's state to queued for another attempt, delayed until runAt.
+// The error of the failed attempt is retained.
+func (store *Storage) PutJobRetry(jobId int, attempt int, runAt int, errMsg string, updatedAt int) error {
+	query := `
+	UPDATE
+		jobs
+	SET
+		state = "queued",
+		worker_id = NULL,
+		run_at = $1,
+		attempt = $2,
+		error = $3,
+		updated_at = $4
+	WHERE
+		id = $5
+	`
+
+	return store.execJobUpdate(query, runAt, attempt, errMsg, updatedAt, jobId)
+}
+
+// PutJobFinished sets the job's final state (done or failed) and error message
+func (store *Storage) PutJobFinished(jobId int, state orders.JobState, errMsg string, updatedAt int) error {
+	query := `
+	UPDATE
+		jobs
+	SET
+		state = $1,
+		error = $2,
+		worker_id = NULL,
+		run_at = NULL,
+		updated_at = $3
+	WHERE
+		id = $4
+	`
+
+	return store.execJobUpdate(query, string(state), errMsg, updatedAt, jobId)
+}
+
+// execJobUpdate executes a job update query and returns ErrNoRecord if no job
+// was updated
+func (store *Storage) execJobUpdate(query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	result, err := store.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return storage.ErrNoRecord
+	}
+
+	return nil
+}
+
+// DeleteJob deletes the specified job
+func (store *Storage) DeleteJob(jobId int) error {
+	query := `
+	DELETE FROM
+		jobs
+	WHERE
+		id = $1
+	`
+
+	return store.execJobUpdate(query, jobId)
+}
+
+// DeleteFinishedJobs deletes all done and failed jobs that were last updated before
+// the specified unix time
+func (store *Storage) DeleteFinishedJobs(updatedBefore int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	DELETE FROM
+		jobs
+	WHERE
+		state IN ("done", "failed")
+		AND
+		updated_at < $1
+	`
+
+	_, err := store.db.ExecContext(ctx, query, updatedBefore)
+	return err
+}
@@ -41,6 +41,34 @@ func (store *Storage) KeyInUse(id int) (inUse bool, err error) {
 		return true, nil
 	}
 
+	// check not in use as an acme server's tls client key
+	query = `
+	SELECT id
+	FROM acme_servers
+	WHERE tls_client_key_id = $1
+	`
+
+	row = store.db.QueryRowContext(ctx, query, id)
+	temp = -2
+	row.Scan(&temp)
+	if temp != -2 {
+		return true, nil
+	}
+
+	// check not in use as a deploy target's ssh key
+	query = `
+	SELECT id
+	FROM deploy_targets
+	WHERE type = "ssh" AND json_extract(config, '$.ssh.private_key_id') = $1
+	`
+
+	row = store.db.QueryRowContext(ctx, query, id)
+	temp = -2
+	row.Scan(&temp)
+	if temp != -2 {
+		return true, nil
+	}
+
 	// check not in use in certs
 	// if scan in succeeds, record exists in certificates
 	// this confirms a cert isn't trying to use this key in future orders
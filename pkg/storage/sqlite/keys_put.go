@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"certwarden-backend/pkg/domain/private_keys"
+	"certwarden-backend/pkg/storage"
 	"context"
 )
 
@@ -108,3 +109,176 @@ func (store *Storage) PutKeyApiKey(keyId int, apiKey string, updateTimeUnix int)
 
 	return nil
 }
+
+// PutKeyPemRotation replaces the pem (and algorithm) of the specified key in place, so
+// the key keeps its id, name, and api keys. The old pem is saved as retiredKey and any
+// orders that were finalized with the key are updated to reference the retired key
+// (since their certificates match the old pem). The retired key's id is returned.
+func (store *Storage) PutKeyPemRotation(keyId int, newAlgorithmValue string, newPem string, retiredKey private_keys.NewPayload) (retiredKeyId int, err error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// transaction
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -2, err
+	}
+	defer tx.Rollback()
+
+	// get old pem
+	query := `
+	SELECT algorithm, pem
+	FROM private_keys
+	WHERE id = $1
+	`
+
+	var oldAlgorithmValue, oldPem string
+	err = tx.QueryRowContext(ctx, query, keyId).Scan(&oldAlgorithmValue, &oldPem)
+	if err != nil {
+		return -2, err
+	}
+
+	// new pem (first, since pem must be unique)
+	query = `
+	UPDATE
+		private_keys
+	SET
+		algorithm = $1,
+		pem = $2,
+		updated_at = $3
+	WHERE
+		id = $4
+	`
+
+	_, err = tx.ExecContext(ctx, query, newAlgorithmValue, newPem, retiredKey.UpdatedAt, keyId)
+	if err != nil {
+		return -2, err
+	}
+
+	// save old pem as retired key
+	query = `
+	INSERT INTO private_keys (name, description, algorithm, pem, api_key, api_key_disabled, api_key_via_url, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		retiredKey.Name,
+		retiredKey.Description,
+		oldAlgorithmValue,
+		oldPem,
+		retiredKey.ApiKey,
+		retiredKey.ApiKeyDisabled,
+		retiredKey.ApiKeyViaUrl,
+		retiredKey.CreatedAt,
+		retiredKey.UpdatedAt,
+	).Scan(&retiredKeyId)
+	if err != nil {
+		return -2, err
+	}
+
+	// existing orders use the retired key
+	query = `
+	UPDATE
+		acme_orders
+	SET
+		finalized_key_id = $1
+	WHERE
+		finalized_key_id = $2
+	`
+
+	_, err = tx.ExecContext(ctx, query, retiredKeyId, keyId)
+	if err != nil {
+		return -2, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return -2, err
+	}
+
+	return retiredKeyId, nil
+}
+
+// PutKeyPemRotationUndo reverses PutKeyPemRotation by moving the retired key's orders
+// back to the key, deleting the retired key, and restoring the retired pem (and
+// algorithm) to the key
+func (store *Storage) PutKeyPemRotationUndo(keyId int, retiredKeyId int, updateTimeUnix int) (err error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// transaction
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// get retired pem
+	query := `
+	SELECT algorithm, pem
+	FROM private_keys
+	WHERE id = $1
+	`
+
+	var retiredAlgorithmValue, retiredPem string
+	err = tx.QueryRowContext(ctx, query, retiredKeyId).Scan(&retiredAlgorithmValue, &retiredPem)
+	if err != nil {
+		return err
+	}
+
+	// orders back to the key
+	query = `
+	UPDATE
+		acme_orders
+	SET
+		finalized_key_id = $1
+	WHERE
+		finalized_key_id = $2
+	`
+
+	_, err = tx.ExecContext(ctx, query, keyId, retiredKeyId)
+	if err != nil {
+		return err
+	}
+
+	// delete retired key
+	query = `
+	DELETE FROM
+		private_keys
+	WHERE
+		id = $1
+	`
+
+	_, err = tx.ExecContext(ctx, query, retiredKeyId)
+	if err != nil {
+		return err
+	}
+
+	// restore pem (after delete, since pem must be unique)
+	query = `
+	UPDATE
+		private_keys
+	SET
+		algorithm = $1,
+		pem = $2,
+		updated_at = $3
+	WHERE
+		id = $4
+	`
+
+	result, err := tx.ExecContext(ctx, query, retiredAlgorithmValue, retiredPem, updateTimeUnix, keyId)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	} else if rows == 0 {
+		return storage.ErrNoRecord
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/notifications"
+)
+
+// webhookDb is a single notification webhook, as database table fields
+// corresponds to notifications.Webhook
+type webhookDb struct {
+	id          int
+	name        string
+	description string
+	url         string
+	secret      string
+	events      jsonStringSlice
+	enabled     bool
+	createdAt   int
+	updatedAt   int
+}
+
+// toWebhook maps the database webhook info to the notifications Webhook
+// object
+func (webhook webhookDb) toWebhook() notifications.Webhook {
+	return notifications.Webhook{
+		ID:          webhook.id,
+		Name:        webhook.name,
+		Description: webhook.description,
+		URL:         webhook.url,
+		Secret:      webhook.secret,
+		Events:      webhook.events.toSlice(),
+		Enabled:     webhook.enabled,
+		CreatedAt:   webhook.createdAt,
+		UpdatedAt:   webhook.updatedAt,
+	}
+}
+
+// deliveryDb is a single notification delivery, as database table fields
+// corresponds to notifications.Delivery
+type deliveryDb struct {
+	id               int
+	webhookId        int
+	webhookName      string
+	eventId          string
+	eventType        string
+	payload          string
+	status           string
+	attempts         int
+	lastResponseCode int
+	lastError        string
+	nextAttemptAt    int
+	createdAt        int
+	updatedAt        int
+}
+
+// toDelivery maps the database delivery info to the notifications Delivery
+// object
+func (delivery deliveryDb) toDelivery() notifications.Delivery {
+	return notifications.Delivery{
+		ID:               delivery.id,
+		WebhookID:        delivery.webhookId,
+		WebhookName:      delivery.webhookName,
+		EventID:          delivery.eventId,
+		EventType:        delivery.eventType,
+		Payload:          delivery.payload,
+		Status:           delivery.status,
+		Attempts:         delivery.attempts,
+		LastResponseCode: delivery.lastResponseCode,
+		LastError:        delivery.lastError,
+		NextAttemptAt:    delivery.nextAttemptAt,
+		CreatedAt:        delivery.createdAt,
+		UpdatedAt:        delivery.updatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/storage"
+	"context"
+)
+
+// DeleteWebhook deletes a notification webhook from the database (its deliveries
+// are deleted by cascade)
+func (store *Storage) DeleteWebhook(id int) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	DELETE FROM
+		notification_webhooks
+	WHERE
+		id = $1
+	`
+
+	result, err := store.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	// if nothing was deleted, the webhook didn't exist
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNoRecord
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/notifications"
+	"certwarden-backend/pkg/pagination_sort"
+	"certwarden-backend/pkg/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// GetAllWebhooks returns a slice of all notification webhooks in the db
+func (store *Storage) GetAllWebhooks(q pagination_sort.Query) (webhooks []notifications.Webhook, totalRowCount int, err error) {
+	// validate and set sort
+	sortField := q.SortField()
+	switch sortField {
+	// allow these as-is
+	case "id":
+	case "name":
+	case "description":
+	case "url":
+	case "enabled":
+	// default if not in allowed list
+	default:
+		sortField = "name"
+	}
+
+	sort := sortField + " " + q.SortDirection()
+
+	// do query
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// WARNING: SQL Injection is possible if the variables are not properly
+	// validated prior to this query being assembled!
+	query := fmt.Sprintf(`
+	SELECT
+		id, name, description, url, secret, events, enabled, created_at, updated_at,
+
+		count(*) OVER() AS full_count
+	FROM
+		notification_webhooks
+	ORDER BY
+		%s
+	LIMIT
+		$1
+	OFFSET
+		$2
+	`, sort)
+
+	rows, err := store.db.QueryContext(ctx, query,
+		q.Limit(),
+		q.Offset(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// for total row count
+	var totalRows int
+
+	for rows.Next() {
+		var oneWebhookDb webhookDb
+		err = rows.Scan(
+			&oneWebhookDb.id,
+			&oneWebhookDb.name,
+			&oneWebhookDb.description,
+			&oneWebhookDb.url,
+			&oneWebhookDb.secret,
+			&oneWebhookDb.events,
+			&oneWebhookDb.enabled,
+			&oneWebhookDb.createdAt,
+			&oneWebhookDb.updatedAt,
+
+			&totalRows,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		webhooks = append(webhooks, oneWebhookDb.toWebhook())
+	}
+
+	return webhooks, totalRows, nil
+}
+
+// GetEnabledWebhooks returns a slice of all enabled notification webhooks in the db
+func (store *Storage) GetEnabledWebhooks() (webhooks []notifications.Webhook, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, name, description, url, secret, events, enabled, created_at, updated_at
+	FROM
+		notification_webhooks
+	WHERE
+		enabled = true
+	ORDER BY
+		id
+	`
+
+	rows, err := store.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var oneWebhookDb webhookDb
+		err = rows.Scan(
+			&oneWebhookDb.id,
+			&oneWebhookDb.name,
+			&oneWebhookDb.description,
+			&oneWebhookDb.url,
+			&oneWebhookDb.secret,
+			&oneWebhookDb.events,
+			&oneWebhookDb.enabled,
+			&oneWebhookDb.createdAt,
+			&oneWebhookDb.updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, oneWebhookDb.toWebhook())
+	}
+
+	return webhooks, nil
+}
+
+// GetOneWebhookById returns a notification webhook based on unique id
+func (store *Storage) GetOneWebhookById(id int) (notifications.Webhook, error) {
+	return store.getOneWebhook(id, "")
+}
+
+// GetOneWebhookByName returns a notification webhook based on unique name
+func (store *Storage) GetOneWebhookByName(name string) (notifications.Webhook, error) {
+	return store.getOneWebhook(-1, name)
+}
+
+// getOneWebhook returns a notification webhook based on unique id or unique name
+func (store *Storage) getOneWebhook(id int, name string) (notifications.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, name, description, url, secret, events, enabled, created_at, updated_at
+	FROM
+		notification_webhooks
+	WHERE
+		id = $1
+		OR
+		name = $2
+	`
+
+	row := store.db.QueryRowContext(ctx, query, id, name)
+
+	var oneWebhookDb webhookDb
+	err := row.Scan(
+		&oneWebhookDb.id,
+		&oneWebhookDb.name,
+		&oneWebhookDb.description,
+		&oneWebhookDb.url,
+		&oneWebhookDb.secret,
+		&oneWebhookDb.events,
+		&oneWebhookDb.enabled,
+		&oneWebhookDb.createdAt,
+		&oneWebhookDb.updatedAt,
+	)
+
+	if err != nil {
+		// if no record exists
+		if errors.Is(err, sql.ErrNoRows) {
+			err = storage.ErrNoRecord
+		}
+		return notifications.Webhook{}, err
+	}
+
+	return oneWebhookDb.toWebhook(), nil
+}
+
+// GetDeliveries returns a slice of notification deliveries for the specified webhook
+// id (or for all webhooks if webhookId is 0)
+func (store *Storage) GetDeliveries(q pagination_sort.Query, webhookId int) (deliveries []notifications.Delivery, totalRowCount int, err error) {
+	// validate and set sort
+	sortField := q.SortField()
+	switch sortField {
+	// allow these as-is
+	case "id":
+	case "event_type":
+	case "status":
+	case "created_at":
+	case "updated_at":
+	// default if not in allowed list
+	default:
+		sortField = "id"
+	}
+
+	sortDirection := q.SortDirection()
+	// default to newest first if no sort specified
+	if q.SortField() == "" {
+		sortDirection = "desc"
+	}
+
+	sort := "nd." + sortField + " " + sortDirection
+
+	// do query
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// WARNING: SQL Injection is possible if the variables are not properly
+	// validated prior to this query being assembled!
+	query := fmt.Sprintf(`
+	SELECT
+		nd.id, nd.webhook_id, IFNULL(nw.name, ''), nd.event_id, nd.event_type, nd.payload, nd.status,
+		nd.attempts, nd.last_response_code, nd.last_error, nd.next_attempt_at, nd.created_at,
+		nd.updated_at,
+
+		count(*) OVER() AS full_count
+	FROM
+		notification_deliveries nd
+		LEFT JOIN notification_webhooks nw on (nd.webhook_id = nw.id)
+	WHERE
+		($1 = 0 OR nd.webhook_id = $1)
+	ORDER BY
+		%s
+	LIMIT
+		$2
+	OFFSET
+		$3
+	`, sort)
+
+	rows, err := store.db.QueryContext(ctx, query,
+		webhookId,
+		q.Limit(),
+		q.Offset(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// for total row count
+	var totalRows int
+
+	for rows.Next() {
+		var oneDeliveryDb deliveryDb
+		err = rows.Scan(
+			&oneDeliveryDb.id,
+			&oneDeliveryDb.webhookId,
+			&oneDeliveryDb.webhookName,
+			&oneDeliveryDb.eventId,
+			&oneDeliveryDb.eventType,
+			&oneDeliveryDb.payload,
+			&oneDeliveryDb.status,
+			&oneDeliveryDb.attempts,
+			&oneDeliveryDb.lastResponseCode,
+			&oneDeliveryDb.lastError,
+			&oneDeliveryDb.nextAttemptAt,
+			&oneDeliveryDb.createdAt,
+			&oneDeliveryDb.updatedAt,
+
+			&totalRows,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		deliveries = append(deliveries, oneDeliveryDb.toDelivery())
+	}
+
+	return deliveries, totalRows, nil
+}
+
+// GetDueDeliveries returns up to limit pending deliveries whose next attempt time is
+// at or before nowUnix (oldest first)
+func (store *Storage) GetDueDeliveries(nowUnix int, limit int) (deliveries []notifications.Delivery, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		nd.id, nd.webhook_id, IFNULL(nw.name, ''), nd.event_id, nd.event_type, nd.payload, nd.status,
+		nd.attempts, nd.last_response_code, nd.last_error, nd.next_attempt_at, nd.created_at,
+		nd.updated_at
+	FROM
+		notification_deliveries nd
+		LEFT JOIN notification_webhooks nw on (nd.webhook_id = nw.id)
+	WHERE
+		nd.status = $1
+		AND
+		nd.next_attempt_at <= $2
+	ORDER BY
+		nd.next_attempt_at, nd.id
+	LIMIT
+		$3
+	`
+
+	rows, err := store.db.QueryContext(ctx, query,
+		notifications.DeliveryStatusPending,
+		nowUnix,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var oneDeliveryDb deliveryDb
+		err = rows.Scan(
+			&oneDeliveryDb.id,
+			&oneDeliveryDb.webhookId,
+			&oneDeliveryDb.webhookName,
+			&oneDeliveryDb.eventId,
+			&oneDeliveryDb.eventType,
+			&oneDeliveryDb.payload,
+			&oneDeliveryDb.status,
+			&oneDeliveryDb.attempts,
+			&oneDeliveryDb.lastResponseCode,
+			&oneDeliveryDb.lastError,
+			&oneDeliveryDb.nextAttemptAt,
+			&oneDeliveryDb.createdAt,
+			&oneDeliveryDb.updatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, oneDeliveryDb.toDelivery())
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/notifications"
+	"context"
+)
+
+// PostNewWebhook saves a new notification webhook to the db
+func (store *Storage) PostNewWebhook(payload notifications.NewWebhookPayload) (notifications.Webhook, error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO notification_webhooks (name, description, url, secret, events, enabled, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id
+	`
+
+	// insert and scan the new id
+	id := -1
+	err := store.db.QueryRowContext(ctx, query,
+		payload.Name,
+		payload.Description,
+		payload.URL,
+		payload.Secret,
+		makeJsonStringSlice(payload.Events),
+		payload.Enabled,
+		payload.CreatedAt,
+		payload.UpdatedAt,
+	).Scan(&id)
+
+	if err != nil {
+		return notifications.Webhook{}, err
+	}
+
+	// get new webhook to return
+	newWebhook, err := store.GetOneWebhookById(id)
+	if err != nil {
+		return notifications.Webhook{}, err
+	}
+
+	return newWebhook, nil
+}
+
+// PostNewDelivery saves a new notification delivery to the db
+func (store *Storage) PostNewDelivery(payload notifications.NewDeliveryPayload) (newId int, err error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO notification_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at,
+		created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id
+	`
+
+	// insert and scan the new id
+	err = store.db.QueryRowContext(ctx, query,
+		payload.WebhookID,
+		payload.EventID,
+		payload.EventType,
+		payload.Payload,
+		payload.Status,
+		payload.NextAttemptAt,
+		payload.CreatedAt,
+		payload.UpdatedAt,
+	).Scan(&newId)
+
+	if err != nil {
+		return -2, err
+	}
+
+	return newId, nil
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/app/notifications"
+	"context"
+)
+
+// PutWebhookUpdate updates an existing notification webhook in the db using any
+// non-null fields specified in the UpdateWebhookPayload.
+func (store *Storage) PutWebhookUpdate(payload notifications.UpdateWebhookPayload) (notifications.Webhook, error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// events are stored as json
+	var events *jsonStringSlice
+	if payload.Events != nil {
+		events = new(jsonStringSlice)
+		*events = makeJsonStringSlice(*payload.Events)
+	}
+
+	query := `
+	UPDATE
+		notification_webhooks
+	SET
+		name = case when $1 is null then name else $1 end,
+		description = case when $2 is null then description else $2 end,
+		url = case when $3 is null then url else $3 end,
+		secret = case when $4 is null then secret else $4 end,
+		events = case when $5 is null then events else $5 end,
+		enabled = case when $6 is null then enabled else $6 end,
+		updated_at = $7
+	WHERE
+		id = $8
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		payload.Name,
+		payload.Description,
+		payload.URL,
+		payload.Secret,
+		events,
+		payload.Enabled,
+		payload.UpdatedAt,
+		payload.ID,
+	)
+	if err != nil {
+		return notifications.Webhook{}, err
+	}
+
+	// get updated webhook to return
+	updatedWebhook, err := store.GetOneWebhookById(payload.ID)
+	if err != nil {
+		return notifications.Webhook{}, err
+	}
+
+	return updatedWebhook, nil
+}
+
+// PutDeliveryAttempt records the result of a delivery attempt in the db
+func (store *Storage) PutDeliveryAttempt(payload notifications.DeliveryAttemptPayload) error {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	UPDATE
+		notification_deliveries
+	SET
+		status = $1,
+		attempts = $2,
+		last_response_code = $3,
+		last_error = $4,
+		next_attempt_at = case when $5 = 0 then next_attempt_at else $5 end,
+		updated_at = $6
+	WHERE
+		id = $7
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		payload.Status,
+		payload.Attempts,
+		payload.LastResponseCode,
+		payload.LastError,
+		payload.NextAttemptAt,
+		payload.UpdatedAt,
+		payload.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -10,24 +10,25 @@ import (
 // orderDb is a single acme order, as database table fields
 // corresponds to orders.Order
 type orderDb struct {
-	id             int
-	certificate    certificateDb
-	location       string
-	status         string
-	knownRevoked   bool
-	err            sql.NullString // stored as json object
-	expires        sql.NullInt32
-	dnsIdentifiers jsonStringSlice // stored as json array
-	authorizations jsonStringSlice // stored as json array
-	finalize       string
-	finalizedKey   keyDb
-	certificateUrl sql.NullString
-	pem            sql.NullString
-	chainRootCN    sql.NullString
-	validFrom      sql.NullInt32
-	validTo        sql.NullInt32
-	createdAt      int
-	updatedAt      int
+	id              int
+	certificate     certificateDb
+	location        string
+	status          string
+	knownRevoked    bool
+	unknownToServer bool
+	err             sql.NullString // stored as json object
+	expires         sql.NullInt32
+	dnsIdentifiers  jsonStringSlice // stored as json array
+	authorizations  jsonStringSlice // stored as json array
+	finalize        string
+	finalizedKey    keyDb
+	certificateUrl  sql.NullString
+	pem             sql.NullString
+	chainRootCN     sql.NullString
+	validFrom       sql.NullInt32
+	validTo         sql.NullInt32
+	createdAt       int
+	updatedAt       int
 }
 
 func (order orderDb) toOrder() (orders.Order, error) {
@@ -51,23 +52,24 @@ func (order orderDb) toOrder() (orders.Order, error) {
 	}
 
 	return orders.Order{
-		ID:             order.id,
-		Certificate:    cert,
-		Location:       order.location,
-		Status:         order.status,
-		KnownRevoked:   order.knownRevoked,
-		Error:          acmeErr,
-		Expires:        nullInt32ToInt(order.expires),
-		DnsIdentifiers: order.dnsIdentifiers.toSlice(),
-		Authorizations: order.authorizations.toSlice(),
-		Finalize:       order.finalize,
-		FinalizedKey:   key,
-		CertificateUrl: nullStringToString(order.certificateUrl),
-		Pem:            nullStringToString(order.pem),
-		ValidFrom:      nullInt32UnixToTime(order.validFrom),
-		ValidTo:        nullInt32UnixToTime(order.validTo),
-		ChainRootCN:    nullStringToString(order.chainRootCN),
-		CreatedAt:      order.createdAt,
-		UpdatedAt:      order.updatedAt,
+		ID:              order.id,
+		Certificate:     cert,
+		Location:        order.location,
+		Status:          order.status,
+		KnownRevoked:    order.knownRevoked,
+		UnknownToServer: order.unknownToServer,
+		Error:           acmeErr,
+		Expires:         nullInt32ToInt(order.expires),
+		DnsIdentifiers:  order.dnsIdentifiers.toSlice(),
+		Authorizations:  order.authorizations.toSlice(),
+		Finalize:        order.finalize,
+		FinalizedKey:    key,
+		CertificateUrl:  nullStringToString(order.certificateUrl),
+		Pem:             nullStringToString(order.pem),
+		ValidFrom:       nullInt32UnixToTime(order.validFrom),
+		ValidTo:         nullInt32UnixToTime(order.validTo),
+		ChainRootCN:     nullStringToString(order.chainRootCN),
+		CreatedAt:       order.createdAt,
+		UpdatedAt:       order.updatedAt,
 	}, nil
 }
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/acme"
+	"certwarden-backend/pkg/domain/orders"
+	"context"
+	"encoding/json"
+)
+
+// GetOrderChains returns all of the stored certificate chains for the specified order,
+// ordered by chain index (the default chain is index 0)
+func (store *Storage) GetOrderChains(orderId int) ([]orders.OrderChain, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		chain_index, url, pem, root_cn, issuers, created_at
+	FROM
+		acme_order_chains
+	WHERE
+		order_id = $1
+	ORDER BY
+		chain_index ASC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chains := []orders.OrderChain{}
+	for rows.Next() {
+		var oneChain orders.OrderChain
+		var issuersJson string
+		err = rows.Scan(
+			&oneChain.Index,
+			&oneChain.URL,
+			&oneChain.Pem,
+			&oneChain.RootCN,
+			&issuersJson,
+			&oneChain.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		oneChain.Issuers = []acme.ChainIssuer{}
+		err = json.Unmarshal([]byte(issuersJson), &oneChain.Issuers)
+		if err != nil {
+			return nil, err
+		}
+
+		chains = append(chains, oneChain)
+	}
+
+	return chains, nil
+}
+
+// PutOrderChains replaces any stored certificate chains for the specified order with
+// the specified chains
+func (store *Storage) PutOrderChains(orderId int, chains []orders.OrderChain) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// transaction
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// remove old chains
+	query := `
+	DELETE FROM
+		acme_order_chains
+	WHERE
+		order_id = $1
+	`
+
+	_, err = tx.ExecContext(ctx, query, orderId)
+	if err != nil {
+		return err
+	}
+
+	// insert new chains
+	query = `
+	INSERT INTO acme_order_chains (order_id, chain_index, url, pem, root_cn, issuers, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for _, chain := range chains {
+		issuersJson, err := json.Marshal(chain.Issuers)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, query,
+			orderId,
+			chain.Index,
+			chain.URL,
+			chain.Pem,
+			chain.RootCN,
+			string(issuersJson),
+			chain.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/datatypes/order_events"
+	"certwarden-backend/pkg/domain/orders"
+	"context"
+	"database/sql"
+)
+
+// GetOrderEvents returns the event history of the specified order, oldest first
+func (store *Storage) GetOrderEvents(orderId int) ([]orders.OrderEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, order_id, event_type, identifier, detail, exit_code, created_at
+	FROM
+		acme_order_events
+	WHERE
+		order_id = $1
+	ORDER BY
+		created_at ASC, id ASC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []orders.OrderEvent{}
+	for rows.Next() {
+		var oneEvent orders.OrderEvent
+		var eventType string
+		var exitCode sql.NullInt32
+		err = rows.Scan(
+			&oneEvent.ID,
+			&oneEvent.OrderID,
+			&eventType,
+			&oneEvent.Identifier,
+			&oneEvent.Detail,
+			&exitCode,
+			&oneEvent.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		oneEvent.Type = order_events.Type(eventType)
+		if exitCode.Valid {
+			oneEvent.ExitCode = new(int)
+			*oneEvent.ExitCode = int(exitCode.Int32)
+		}
+
+		events = append(events, oneEvent)
+	}
+
+	return events, rows.Err()
+}
+
+// PostOrderEvent saves an event to an order's event history
+func (store *Storage) PostOrderEvent(event orders.OrderEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO acme_order_events (order_id, event_type, identifier, detail, exit_code, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		event.OrderID,
+		string(event.Type),
+		event.Identifier,
+		event.Detail,
+		event.ExitCode,
+		event.CreatedAt,
+	)
+
+	return err
+}
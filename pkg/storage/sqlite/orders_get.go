@@ -42,7 +42,7 @@ func (store *Storage) GetAllValidCurrentOrders(q pagination_sort.Query) (orders
 	query := fmt.Sprintf(`
 	SELECT
 		/* order */
-		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.error, ao.expires, ao.dns_identifiers, 
+		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.unknown_to_server, ao.error, ao.expires, ao.dns_identifiers, 
 		ao.authorizations, ao.finalize, ao.certificate_url, ao.valid_from, ao.valid_to, ao.chain_root_cn,
 		ao.created_at, ao.updated_at, 
 
@@ -50,7 +50,9 @@ func (store *Storage) GetAllValidCurrentOrders(q pagination_sort.Query) (orders
 		c.id, c.name, c.description, c.subject, c.subject_alts,
 		c.csr_org, c.csr_ou, c.csr_country, c.csr_state, c.csr_city, c.csr_extra_extensions, c.preferred_root_cn,
 		c.created_at, c.updated_at, c.api_key, c.api_key_new, c.api_key_via_url, c.post_processing_command, 
-		c.post_processing_environment, c.post_processing_client_key,
+		c.post_processing_environment, c.post_processing_client_key, c.profile, c.requested_validity_hours, c.post_processing_timeout,
+		c.post_processing_args, c.post_processing_working_dir, c.post_processing_write_files,
+		c.post_processing_clients, c.post_processing_client_ca_pem,
 		
 		/* cert's key */
 		ck.id, ck.name, ck.description, ck.algorithm, ck.pem, ck.api_key, ck.api_key_new,
@@ -128,6 +130,7 @@ func (store *Storage) GetAllValidCurrentOrders(q pagination_sort.Query) (orders
 			&oneOrder.location,
 			&oneOrder.status,
 			&oneOrder.knownRevoked,
+			&oneOrder.unknownToServer,
 			&oneOrder.err,
 			&oneOrder.expires,
 			&oneOrder.dnsIdentifiers,
@@ -160,6 +163,14 @@ func (store *Storage) GetAllValidCurrentOrders(q pagination_sort.Query) (orders
 			&oneOrder.certificate.postProcessingCommand,
 			&oneOrder.certificate.postProcessingEnvironment,
 			&oneOrder.certificate.postProcessingClientKeyB64,
+			&oneOrder.certificate.profile,
+			&oneOrder.certificate.requestedValidityHours,
+			&oneOrder.certificate.postProcessingTimeout,
+			&oneOrder.certificate.postProcessingArgs,
+			&oneOrder.certificate.postProcessingWorkingDir,
+			&oneOrder.certificate.postProcessingWriteFiles,
+			&oneOrder.certificate.postProcessingClients,
+			&oneOrder.certificate.postProcessingClientCAPem,
 
 			&oneOrder.certificate.certificateKeyDb.id,
 			&oneOrder.certificate.certificateKeyDb.name,
@@ -264,7 +275,7 @@ func (store *Storage) GetOrdersByCert(certId int, q pagination_sort.Query) (orde
 	query := fmt.Sprintf(`
 	SELECT
 		/* order */
-		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.error, ao.expires, ao.dns_identifiers, 
+		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.unknown_to_server, ao.error, ao.expires, ao.dns_identifiers, 
 		ao.authorizations, ao.finalize, ao.certificate_url, ao.pem, ao.valid_from, ao.valid_to, ao.chain_root_cn,
 		ao.created_at, ao.updated_at, 
 
@@ -273,7 +284,9 @@ func (store *Storage) GetOrdersByCert(certId int, q pagination_sort.Query) (orde
 		c.csr_org, c.csr_ou, c.csr_country, c.csr_state, c.csr_city, c.csr_extra_extensions, c.preferred_root_cn,
 		c.created_at, c.updated_at,
 		c.api_key, c.api_key_new, c.api_key_via_url, c.post_processing_command, c.post_processing_environment,
-		c.post_processing_client_key,
+		c.post_processing_client_key, c.profile, c.requested_validity_hours, c.post_processing_timeout,
+		c.post_processing_args, c.post_processing_working_dir, c.post_processing_write_files,
+		c.post_processing_clients, c.post_processing_client_ca_pem,
 		
 		/* cert's key */
 		ck.id, ck.name, ck.description, ck.algorithm, ck.pem, ck.api_key, ck.api_key_new, ck.api_key_disabled,
@@ -337,6 +350,7 @@ func (store *Storage) GetOrdersByCert(certId int, q pagination_sort.Query) (orde
 			&oneOrder.location,
 			&oneOrder.status,
 			&oneOrder.knownRevoked,
+			&oneOrder.unknownToServer,
 			&oneOrder.err,
 			&oneOrder.expires,
 			&oneOrder.dnsIdentifiers,
@@ -370,6 +384,14 @@ func (store *Storage) GetOrdersByCert(certId int, q pagination_sort.Query) (orde
 			&oneOrder.certificate.postProcessingCommand,
 			&oneOrder.certificate.postProcessingEnvironment,
 			&oneOrder.certificate.postProcessingClientKeyB64,
+			&oneOrder.certificate.profile,
+			&oneOrder.certificate.requestedValidityHours,
+			&oneOrder.certificate.postProcessingTimeout,
+			&oneOrder.certificate.postProcessingArgs,
+			&oneOrder.certificate.postProcessingWorkingDir,
+			&oneOrder.certificate.postProcessingWriteFiles,
+			&oneOrder.certificate.postProcessingClients,
+			&oneOrder.certificate.postProcessingClientCAPem,
 
 			&oneOrder.certificate.certificateKeyDb.id,
 			&oneOrder.certificate.certificateKeyDb.name,
@@ -545,7 +567,7 @@ func (store *Storage) GetOrders(orderIDs []int) (orders []orders.Order, err erro
 	query := fmt.Sprintf(`
 	SELECT
 		/* order */
-		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.error, ao.expires, ao.dns_identifiers, 
+		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.unknown_to_server, ao.error, ao.expires, ao.dns_identifiers, 
 		ao.authorizations, ao.finalize, ao.certificate_url, ao.pem, ao.valid_from, ao.valid_to, ao.chain_root_cn,
 		ao.created_at, ao.updated_at, 
 
@@ -554,7 +576,9 @@ func (store *Storage) GetOrders(orderIDs []int) (orders []orders.Order, err erro
 		c.csr_org, c.csr_ou, c.csr_country, c.csr_state, c.csr_city, c.csr_extra_extensions, c.preferred_root_cn,
 		c.created_at, c.updated_at,
 		c.api_key, c.api_key_new, c.api_key_via_url, c.post_processing_command, c.post_processing_environment,
-		c.post_processing_client_key,
+		c.post_processing_client_key, c.profile, c.requested_validity_hours, c.post_processing_timeout,
+		c.post_processing_args, c.post_processing_working_dir, c.post_processing_write_files,
+		c.post_processing_clients, c.post_processing_client_ca_pem,
 		
 		/* cert's key */
 		ck.id, ck.name, ck.description, ck.algorithm, ck.pem, ck.api_key, ak.api_key_new, ck.api_key_disabled,
@@ -604,6 +628,7 @@ func (store *Storage) GetOrders(orderIDs []int) (orders []orders.Order, err erro
 			&oneOrder.location,
 			&oneOrder.status,
 			&oneOrder.knownRevoked,
+			&oneOrder.unknownToServer,
 			&oneOrder.err,
 			&oneOrder.expires,
 			&oneOrder.dnsIdentifiers,
@@ -637,6 +662,14 @@ func (store *Storage) GetOrders(orderIDs []int) (orders []orders.Order, err erro
 			&oneOrder.certificate.postProcessingCommand,
 			&oneOrder.certificate.postProcessingEnvironment,
 			&oneOrder.certificate.postProcessingClientKeyB64,
+			&oneOrder.certificate.profile,
+			&oneOrder.certificate.requestedValidityHours,
+			&oneOrder.certificate.postProcessingTimeout,
+			&oneOrder.certificate.postProcessingArgs,
+			&oneOrder.certificate.postProcessingWorkingDir,
+			&oneOrder.certificate.postProcessingWriteFiles,
+			&oneOrder.certificate.postProcessingClients,
+			&oneOrder.certificate.postProcessingClientCAPem,
 
 			&oneOrder.certificate.certificateKeyDb.id,
 			&oneOrder.certificate.certificateKeyDb.name,
@@ -745,7 +778,7 @@ func (store *Storage) getCertNewestValidOrder(certId int, certName string) (orde
 	query := `
 	SELECT
 		/* order */
-		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.error, ao.expires, ao.dns_identifiers, 
+		ao.id, ao.acme_location, ao.status, ao.known_revoked, ao.unknown_to_server, ao.error, ao.expires, ao.dns_identifiers, 
 		ao.authorizations, ao.finalize, ao.certificate_url, ao.pem, ao.valid_from, ao.valid_to, ao.chain_root_cn,
 		ao.created_at, ao.updated_at, 
 
@@ -754,7 +787,9 @@ func (store *Storage) getCertNewestValidOrder(certId int, certName string) (orde
 		c.csr_org, c.csr_ou, c.csr_country, c.csr_state, c.csr_city, c.csr_extra_extensions, c.preferred_root_cn,
 		c.created_at, c.updated_at,
 		c.api_key, c.api_key_new, c.api_key_via_url, c.post_processing_command, c.post_processing_environment,
-		c.post_processing_client_key,
+		c.post_processing_client_key, c.profile, c.requested_validity_hours, c.post_processing_timeout,
+		c.post_processing_args, c.post_processing_working_dir, c.post_processing_write_files,
+		c.post_processing_clients, c.post_processing_client_ca_pem,
 		
 		/* cert's key */
 		ck.id, ck.name, ck.description, ck.algorithm, ck.pem, ck.api_key, ak.api_key_new, ck.api_key_disabled,
@@ -819,6 +854,7 @@ func (store *Storage) getCertNewestValidOrder(certId int, certName string) (orde
 		&oneOrder.location,
 		&oneOrder.status,
 		&oneOrder.knownRevoked,
+		&oneOrder.unknownToServer,
 		&oneOrder.err,
 		&oneOrder.expires,
 		&oneOrder.dnsIdentifiers,
@@ -852,6 +888,14 @@ func (store *Storage) getCertNewestValidOrder(certId int, certName string) (orde
 		&oneOrder.certificate.postProcessingCommand,
 		&oneOrder.certificate.postProcessingEnvironment,
 		&oneOrder.certificate.postProcessingClientKeyB64,
+		&oneOrder.certificate.profile,
+		&oneOrder.certificate.requestedValidityHours,
+		&oneOrder.certificate.postProcessingTimeout,
+		&oneOrder.certificate.postProcessingArgs,
+		&oneOrder.certificate.postProcessingWorkingDir,
+		&oneOrder.certificate.postProcessingWriteFiles,
+		&oneOrder.certificate.postProcessingClients,
+		&oneOrder.certificate.postProcessingClientCAPem,
 
 		&oneOrder.certificate.certificateKeyDb.id,
 		&oneOrder.certificate.certificateKeyDb.name,
@@ -922,3 +966,52 @@ func (store *Storage) getCertNewestValidOrder(certId int, certName string) (orde
 
 	return order, nil
 }
+
+// GetAccountOrderRefs returns a minimal reference to each of the orders in storage for
+// the specified account
+func (store *Storage) GetAccountOrderRefs(accountId int) (refs []orders.AccountOrderRef, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		ao.id, ao.certificate_id, c.name, ao.acme_location, ao.status, ao.known_revoked, ao.unknown_to_server,
+		ao.valid_to
+	FROM
+		acme_orders ao
+		LEFT JOIN certificates c on (ao.certificate_id = c.id)
+	WHERE
+		ao.acme_account_id = $1
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// read result
+	for rows.Next() {
+		var ref orders.AccountOrderRef
+		var validTo sql.NullInt32
+
+		err = rows.Scan(
+			&ref.ID,
+			&ref.CertificateID,
+			&ref.CertificateName,
+			&ref.Location,
+			&ref.Status,
+			&ref.KnownRevoked,
+			&ref.UnknownToServer,
+			&validTo,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ref.ValidTo = nullInt32UnixToTime(validTo)
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
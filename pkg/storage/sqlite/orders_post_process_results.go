@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"certwarden-backend/pkg/domain/orders"
+	"context"
+	"database/sql"
+)
+
+// GetPostProcessResults returns the post processing results of the specified order,
+// newest first
+func (store *Storage) GetPostProcessResults(orderId int) ([]orders.PostProcessResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	SELECT
+		id, order_id, method, deploy_target_id, deploy_target_name, client_url, attempt, success, started_at, ended_at, exit_code, http_status,
+		stdout, stderr, error
+	FROM
+		acme_order_post_process_results
+	WHERE
+		order_id = $1
+	ORDER BY
+		started_at DESC, id DESC
+	`
+
+	rows, err := store.db.QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []orders.PostProcessResult{}
+	for rows.Next() {
+		var oneResult orders.PostProcessResult
+		var method string
+		var deployTargetId, exitCode, httpStatus sql.NullInt32
+		err = rows.Scan(
+			&oneResult.ID,
+			&oneResult.OrderID,
+			&method,
+			&deployTargetId,
+			&oneResult.DeployTargetName,
+			&oneResult.ClientURL,
+			&oneResult.Attempt,
+			&oneResult.Success,
+			&oneResult.StartedAt,
+			&oneResult.EndedAt,
+			&exitCode,
+			&httpStatus,
+			&oneResult.Stdout,
+			&oneResult.Stderr,
+			&oneResult.Error,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		oneResult.Method = orders.PostProcessMethod(method)
+		if deployTargetId.Valid {
+			oneResult.DeployTargetID = new(int)
+			*oneResult.DeployTargetID = int(deployTargetId.Int32)
+		}
+		if exitCode.Valid {
+			oneResult.ExitCode = new(int)
+			*oneResult.ExitCode = int(exitCode.Int32)
+		}
+		if httpStatus.Valid {
+			oneResult.HttpStatus = new(int)
+			*oneResult.HttpStatus = int(httpStatus.Int32)
+		}
+
+		results = append(results, oneResult)
+	}
+
+	return results, rows.Err()
+}
+
+// PostPostProcessResult saves the result of a post processing run
+func (store *Storage) PostPostProcessResult(result orders.PostProcessResult) error {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	INSERT INTO acme_order_post_process_results (order_id, method, deploy_target_id, deploy_target_name,
+		client_url, attempt, success, started_at, ended_at, exit_code, http_status, stdout, stderr, error)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := store.db.ExecContext(ctx, query,
+		result.OrderID,
+		string(result.Method),
+		result.DeployTargetID,
+		result.DeployTargetName,
+		result.ClientURL,
+		result.Attempt,
+		result.Success,
+		result.StartedAt,
+		result.EndedAt,
+		result.ExitCode,
+		result.HttpStatus,
+		result.Stdout,
+		result.Stderr,
+		result.Error,
+	)
+
+	return err
+}
@@ -182,3 +182,32 @@ func (store *Storage) RevokeOrder(orderId int) (err error) {
 
 	return nil
 }
+
+// PutOrderUnknownToServer sets whether the order is flagged as no longer recognized by
+// the ACME server
+func (store *Storage) PutOrderUnknownToServer(orderId int, unknown bool) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+		UPDATE
+			acme_orders
+		SET
+			unknown_to_server = $1,
+			updated_at = $2
+		WHERE
+			id = $3
+		`
+
+	_, err = store.db.ExecContext(ctx, query,
+		unknown,
+		timeNow(),
+		orderId,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -19,7 +19,7 @@ import (
 // config for DB
 const dbTimeout = time.Duration(5 * time.Second)
 const DbFilename = "appdata.db"
-const DbCurrentUserVersion = 7
+const DbCurrentUserVersion = 24
 const dbFileMode = 0600
 
 var dbOptions = url.Values{
@@ -202,6 +202,142 @@ func OpenStorage(app App) (*Storage, error) {
 		}
 	}
 
+	// upgrade if schema 7
+	if fileUserVersion == 7 {
+		fileUserVersion, err = store.migrateV7toV8()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 8
+	if fileUserVersion == 8 {
+		fileUserVersion, err = store.migrateV8toV9()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 9
+	if fileUserVersion == 9 {
+		fileUserVersion, err = store.migrateV9toV10()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 10
+	if fileUserVersion == 10 {
+		fileUserVersion, err = store.migrateV10toV11()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 11
+	if fileUserVersion == 11 {
+		fileUserVersion, err = store.migrateV11toV12()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 12
+	if fileUserVersion == 12 {
+		fileUserVersion, err = store.migrateV12toV13()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 13
+	if fileUserVersion == 13 {
+		fileUserVersion, err = store.migrateV13toV14()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 14
+	if fileUserVersion == 14 {
+		fileUserVersion, err = store.migrateV14toV15()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 15
+	if fileUserVersion == 15 {
+		fileUserVersion, err = store.migrateV15toV16()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 16
+	if fileUserVersion == 16 {
+		fileUserVersion, err = store.migrateV16toV17()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 17
+	if fileUserVersion == 17 {
+		fileUserVersion, err = store.migrateV17toV18()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 18
+	if fileUserVersion == 18 {
+		fileUserVersion, err = store.migrateV18toV19()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 19
+	if fileUserVersion == 19 {
+		fileUserVersion, err = store.migrateV19toV20()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 20
+	if fileUserVersion == 20 {
+		fileUserVersion, err = store.migrateV20toV21()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 21
+	if fileUserVersion == 21 {
+		fileUserVersion, err = store.migrateV21toV22()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 22
+	if fileUserVersion == 22 {
+		fileUserVersion, err = store.migrateV22toV23()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// upgrade if schema 23
+	if fileUserVersion == 23 {
+		fileUserVersion, err = store.migrateV23toV24()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// fail if still not correct
 	if fileUserVersion != DbCurrentUserVersion {
 		return nil, fmt.Errorf("db schema user_version is %d (expected %d) and automatic migration failed", fileUserVersion, DbCurrentUserVersion)
@@ -242,7 +378,7 @@ func (store *Storage) populateNewDb() error {
 	}
 
 	// create tables
-	err = createDBTablesV7(tx)
+	err = createDBTablesV24(tx)
 	if err != nil {
 		return err
 	}
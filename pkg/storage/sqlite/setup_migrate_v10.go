@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v9 to v10:
+// - notification_webhooks:
+//     - New table for outbound webhook notification targets
+// - notification_deliveries:
+//     - New table for queued webhook deliveries and their delivery history
+
+// schemaChangesV10 makes the changes to go from schema v9 to v10
+func schemaChangesV10(tx *sql.Tx) error {
+	// notification_webhooks
+	query := `CREATE TABLE IF NOT EXISTS notification_webhooks (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		name text NOT NULL UNIQUE COLLATE NOCASE,
+		description text NOT NULL,
+		url text NOT NULL,
+		secret text NOT NULL,
+		events text NOT NULL DEFAULT "[]",
+		enabled integer NOT NULL DEFAULT 1 CHECK(enabled IN (0,1)),
+		created_at integer NOT NULL,
+		updated_at integer NOT NULL
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	// notification_deliveries
+	query = `CREATE TABLE IF NOT EXISTS notification_deliveries (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		webhook_id integer NOT NULL,
+		event_id text NOT NULL,
+		event_type text NOT NULL,
+		payload text NOT NULL,
+		status text NOT NULL,
+		attempts integer NOT NULL DEFAULT 0,
+		last_response_code integer NOT NULL DEFAULT 0,
+		last_error text NOT NULL DEFAULT "",
+		next_attempt_at integer NOT NULL,
+		created_at integer NOT NULL,
+		updated_at integer NOT NULL,
+		FOREIGN KEY (webhook_id)
+			REFERENCES notification_webhooks (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	query = `CREATE INDEX IF NOT EXISTS idx_notification_deliveries_status_next ON notification_deliveries (status, next_attempt_at)`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV10 creates a fresh set of tables in the db using schema version 10
+func createDBTablesV10(tx *sql.Tx) error {
+	err := createDBTablesV9(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV10(tx)
+}
+
+// migrateV9toV10 updates the storage db from user_version 9 to user_version 10, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV9toV10() (int, error) {
+	oldSchemaVer := 9
+	newSchemaVer := 10
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV10(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v10 to v11:
+// - certificates:
+//     - Add 'profile' field/column (ACME profile requested on newOrder, blank for
+//       the server's default)
+
+// schemaChangesV11 makes the changes to go from schema v10 to v11
+func schemaChangesV11(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE certificates ADD profile text NOT NULL DEFAULT "";
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV11 creates a fresh set of tables in the db using schema version 11
+func createDBTablesV11(tx *sql.Tx) error {
+	err := createDBTablesV10(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV11(tx)
+}
+
+// migrateV10toV11 updates the storage db from user_version 10 to user_version 11, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV10toV11() (int, error) {
+	oldSchemaVer := 10
+	newSchemaVer := 11
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV11(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
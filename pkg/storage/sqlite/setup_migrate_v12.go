@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v11 to v12:
+// - certificates:
+//     - Add 'requested_validity_hours' field/column (validity requested with
+//       notBefore/notAfter on newOrder, 0 for the CA's default)
+
+// schemaChangesV12 makes the changes to go from schema v11 to v12
+func schemaChangesV12(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE certificates ADD requested_validity_hours integer NOT NULL DEFAULT 0;
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV12 creates a fresh set of tables in the db using schema version 12
+func createDBTablesV12(tx *sql.Tx) error {
+	err := createDBTablesV11(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV12(tx)
+}
+
+// migrateV11toV12 updates the storage db from user_version 11 to user_version 12, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV11toV12() (int, error) {
+	oldSchemaVer := 11
+	newSchemaVer := 12
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV12(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v12 to v13:
+// - acme_authorizations:
+//     - New table to track ACME authorizations (from pre-authorization and orders) and
+//       their expiration per account and identifier
+
+// schemaChangesV13 makes the changes to go from schema v12 to v13
+func schemaChangesV13(tx *sql.Tx) error {
+	// acme_authorizations
+	query := `CREATE TABLE IF NOT EXISTS acme_authorizations (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		acme_account_id integer NOT NULL,
+		identifier_type text NOT NULL,
+		identifier_value text NOT NULL,
+		wildcard integer NOT NULL DEFAULT 0 CHECK(wildcard IN (0,1)),
+		url text NOT NULL UNIQUE,
+		status text NOT NULL,
+		expires integer NOT NULL DEFAULT 0,
+		created_at integer NOT NULL,
+		updated_at integer NOT NULL,
+		FOREIGN KEY (acme_account_id)
+			REFERENCES acme_accounts (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	query = `CREATE INDEX IF NOT EXISTS idx_acme_authorizations_account_identifier ON acme_authorizations (acme_account_id, identifier_value)`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV13 creates a fresh set of tables in the db using schema version 13
+func createDBTablesV13(tx *sql.Tx) error {
+	err := createDBTablesV12(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV13(tx)
+}
+
+// migrateV12toV13 updates the storage db from user_version 12 to user_version 13, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV12toV13() (int, error) {
+	oldSchemaVer := 12
+	newSchemaVer := 13
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV13(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
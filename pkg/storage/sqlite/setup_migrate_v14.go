@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v13 to v14:
+// - acme_order_chains:
+//     - New table to store all of the certificate chains the ACME server offered for
+//       an order (default and alternates)
+
+// schemaChangesV14 makes the changes to go from schema v13 to v14
+func schemaChangesV14(tx *sql.Tx) error {
+	// acme_order_chains
+	query := `CREATE TABLE IF NOT EXISTS acme_order_chains (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		order_id integer NOT NULL,
+		chain_index integer NOT NULL,
+		url text NOT NULL,
+		pem text NOT NULL,
+		root_cn text NOT NULL,
+		issuers text NOT NULL DEFAULT "[]",
+		created_at integer NOT NULL,
+		UNIQUE (order_id, chain_index),
+		FOREIGN KEY (order_id)
+			REFERENCES acme_orders (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV14 creates a fresh set of tables in the db using schema version 14
+func createDBTablesV14(tx *sql.Tx) error {
+	err := createDBTablesV13(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV14(tx)
+}
+
+// migrateV13toV14 updates the storage db from user_version 13 to user_version 14, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV13toV14() (int, error) {
+	oldSchemaVer := 13
+	newSchemaVer := 14
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV14(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v14 to v15:
+// - acme_servers:
+//     - Add http client settings fields/columns 'tls_root_cas_pem', 'tls_client_cert_pem',
+//       'tls_client_key_id', 'timeout_seconds', and 'proxy_url' (for CAs that require a
+//       private root, mutual TLS, or a proxy)
+
+// schemaChangesV15 makes the changes to go from schema v14 to v15
+func schemaChangesV15(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE acme_servers ADD tls_root_cas_pem text NOT NULL DEFAULT "";
+		ALTER TABLE acme_servers ADD tls_client_cert_pem text NOT NULL DEFAULT "";
+		ALTER TABLE acme_servers ADD tls_client_key_id integer DEFAULT NULL;
+		ALTER TABLE acme_servers ADD timeout_seconds integer NOT NULL DEFAULT 0;
+		ALTER TABLE acme_servers ADD proxy_url text NOT NULL DEFAULT "";
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV15 creates a fresh set of tables in the db using schema version 15
+func createDBTablesV15(tx *sql.Tx) error {
+	err := createDBTablesV14(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV15(tx)
+}
+
+// migrateV14toV15 updates the storage db from user_version 14 to user_version 15, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV14toV15() (int, error) {
+	oldSchemaVer := 14
+	newSchemaVer := 15
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV15(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v15 to v16:
+// - acme_accounts:
+//     - Add automatic key rotation policy fields/columns 'key_rotation_days' (0 is
+//       disabled), 'key_rotation_algorithm' (blank for the current key's algorithm),
+//       and 'key_rotation_grace_days' (how long to retain the old key)
+// - acme_account_key_rotations:
+//     - New table to record the history of account key rollovers
+
+// schemaChangesV16 makes the changes to go from schema v15 to v16
+func schemaChangesV16(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE acme_accounts ADD key_rotation_days integer NOT NULL DEFAULT 0;
+		ALTER TABLE acme_accounts ADD key_rotation_algorithm text NOT NULL DEFAULT "";
+		ALTER TABLE acme_accounts ADD key_rotation_grace_days integer NOT NULL DEFAULT 30;
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	// acme_account_key_rotations
+	query = `CREATE TABLE IF NOT EXISTS acme_account_key_rotations (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		acme_account_id integer NOT NULL,
+		old_private_key_id integer NOT NULL,
+		old_private_key_name text NOT NULL,
+		new_private_key_id integer,
+		new_private_key_name text NOT NULL DEFAULT "",
+		automatic integer NOT NULL DEFAULT 0 CHECK(automatic IN (0,1)),
+		success integer NOT NULL DEFAULT 0 CHECK(success IN (0,1)),
+		error text NOT NULL DEFAULT "",
+		old_key_deleted_at integer NOT NULL DEFAULT 0,
+		created_at integer NOT NULL,
+		FOREIGN KEY (acme_account_id)
+			REFERENCES acme_accounts (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV16 creates a fresh set of tables in the db using schema version 16
+func createDBTablesV16(tx *sql.Tx) error {
+	err := createDBTablesV15(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV16(tx)
+}
+
+// migrateV15toV16 updates the storage db from user_version 15 to user_version 16, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV15toV16() (int, error) {
+	oldSchemaVer := 15
+	newSchemaVer := 16
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV16(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v16 to v17:
+// - jobs:
+//     - New table to persist order fulfilling and post processing jobs (and their
+//       state) so that queued work survives an application restart
+
+// schemaChangesV17 makes the changes to go from schema v16 to v17
+func schemaChangesV17(tx *sql.Tx) error {
+	query := `CREATE TABLE IF NOT EXISTS jobs (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		kind text NOT NULL CHECK(kind IN ("fulfill","post_process")),
+		order_id integer NOT NULL,
+		high_priority integer NOT NULL DEFAULT 0 CHECK(high_priority IN (0,1)),
+		state text NOT NULL CHECK(state IN ("queued","running","done","failed")),
+		worker_id integer,
+		run_at integer,
+		error text NOT NULL DEFAULT "",
+		created_at integer NOT NULL,
+		updated_at integer NOT NULL,
+		FOREIGN KEY (order_id)
+			REFERENCES acme_orders (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV17 creates a fresh set of tables in the db using schema version 17
+func createDBTablesV17(tx *sql.Tx) error {
+	err := createDBTablesV16(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV17(tx)
+}
+
+// migrateV16toV17 updates the storage db from user_version 16 to user_version 17, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV16toV17() (int, error) {
+	oldSchemaVer := 16
+	newSchemaVer := 17
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV17(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
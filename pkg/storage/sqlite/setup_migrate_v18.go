@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v17 to v18:
+// - acme_order_events:
+//     - New table to record the history of steps taken for each order (e.g. challenge
+//       provisioned, finalized, post processed)
+
+// schemaChangesV18 makes the changes to go from schema v17 to v18
+func schemaChangesV18(tx *sql.Tx) error {
+	query := `CREATE TABLE IF NOT EXISTS acme_order_events (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		order_id integer NOT NULL,
+		event_type text NOT NULL,
+		identifier text NOT NULL DEFAULT "",
+		detail text NOT NULL DEFAULT "",
+		exit_code integer,
+		created_at integer NOT NULL,
+		FOREIGN KEY (order_id)
+			REFERENCES acme_orders (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV18 creates a fresh set of tables in the db using schema version 18
+func createDBTablesV18(tx *sql.Tx) error {
+	err := createDBTablesV17(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV18(tx)
+}
+
+// migrateV17toV18 updates the storage db from user_version 17 to user_version 18, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV17toV18() (int, error) {
+	oldSchemaVer := 17
+	newSchemaVer := 18
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV18(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
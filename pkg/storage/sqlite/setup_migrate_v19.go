@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v18 to v19:
+// - acme_servers:
+//     - Add 'max_concurrent_orders' to cap how many of the server's orders are fulfilled
+//       at the same time (0 is unlimited)
+
+// schemaChangesV19 makes the changes to go from schema v18 to v19
+func schemaChangesV19(tx *sql.Tx) error {
+	query := `
+		ALTER TABLE acme_servers ADD max_concurrent_orders integer NOT NULL DEFAULT 0;
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV19 creates a fresh set of tables in the db using schema version 19
+func createDBTablesV19(tx *sql.Tx) error {
+	err := createDBTablesV18(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV19(tx)
+}
+
+// migrateV18toV19 updates the storage db from user_version 18 to user_version 19, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV18toV19() (int, error) {
+	oldSchemaVer := 18
+	newSchemaVer := 19
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV19(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
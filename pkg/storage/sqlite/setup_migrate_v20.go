@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v19 to v20:
+// - certificates:
+//     - Add 'post_processing_timeout' (seconds) after which post processing is killed
+//       (0 is the default timeout)
+// - jobs:
+//     - Add 'attempt' to track retries of failed post processing
+// - acme_order_post_process_results:
+//     - New table to record the outcome of each post processing run (method, timing,
+//       exit code, output, client http status)
+
+// schemaChangesV20 makes the changes to go from schema v19 to v20
+func schemaChangesV20(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE certificates ADD post_processing_timeout integer NOT NULL DEFAULT 0;
+		ALTER TABLE jobs ADD attempt integer NOT NULL DEFAULT 0;
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	// acme_order_post_process_results
+	query = `CREATE TABLE IF NOT EXISTS acme_order_post_process_results (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		order_id integer NOT NULL,
+		method text NOT NULL CHECK(method IN ("client", "command")),
+		attempt integer NOT NULL DEFAULT 0,
+		success integer NOT NULL DEFAULT 0 CHECK(success IN (0,1)),
+		started_at integer NOT NULL,
+		ended_at integer NOT NULL,
+		exit_code integer,
+		http_status integer,
+		stdout text NOT NULL DEFAULT "",
+		stderr text NOT NULL DEFAULT "",
+		error text NOT NULL DEFAULT "",
+		FOREIGN KEY (order_id)
+			REFERENCES acme_orders (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV20 creates a fresh set of tables in the db using schema version 20
+func createDBTablesV20(tx *sql.Tx) error {
+	err := createDBTablesV19(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV20(tx)
+}
+
+// migrateV19toV20 updates the storage db from user_version 19 to user_version 20, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV19toV20() (int, error) {
+	oldSchemaVer := 19
+	newSchemaVer := 20
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV20(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
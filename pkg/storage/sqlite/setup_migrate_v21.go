@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v20 to v21:
+// - certificates:
+//     - Add 'post_processing_args' (json array of arguments for the post processing
+//       command)
+//     - Add 'post_processing_working_dir' (blank for the app's working directory)
+//     - Add 'post_processing_write_files' to write pem (and pkcs12) content to temporary
+//       files and pass their paths instead of the content
+
+// schemaChangesV21 makes the changes to go from schema v20 to v21
+func schemaChangesV21(tx *sql.Tx) error {
+	query := `
+		ALTER TABLE certificates ADD post_processing_args text NOT NULL DEFAULT "[]";
+		ALTER TABLE certificates ADD post_processing_working_dir text NOT NULL DEFAULT "";
+		ALTER TABLE certificates ADD post_processing_write_files integer NOT NULL DEFAULT 0 CHECK(post_processing_write_files IN (0,1));
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV21 creates a fresh set of tables in the db using schema version 21
+func createDBTablesV21(tx *sql.Tx) error {
+	err := createDBTablesV20(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV21(tx)
+}
+
+// migrateV20toV21 updates the storage db from user_version 20 to user_version 21, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV20toV21() (int, error) {
+	oldSchemaVer := 20
+	newSchemaVer := 21
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV21(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v21 to v22:
+// - deploy_targets:
+//     - New table for built-in post processing deploy targets (file, ssh, kubernetes)
+//       configured per certificate
+// - acme_order_post_process_results:
+//     - Allow 'deploy' method
+//     - Add 'deploy_target_id' and 'deploy_target_name' to identify which deploy target
+//       the result is for
+
+// schemaChangesV22 makes the changes to go from schema v21 to v22
+func schemaChangesV22(tx *sql.Tx) error {
+	// deploy_targets
+	query := `CREATE TABLE IF NOT EXISTS deploy_targets (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		certificate_id integer NOT NULL,
+		name text NOT NULL COLLATE NOCASE,
+		description text NOT NULL DEFAULT "",
+		type text NOT NULL CHECK(type IN ("file", "ssh", "kubernetes")),
+		config text NOT NULL DEFAULT "{}",
+		enabled integer NOT NULL DEFAULT 1 CHECK(enabled IN (0,1)),
+		created_at integer NOT NULL,
+		updated_at integer NOT NULL,
+		UNIQUE(certificate_id, name),
+		FOREIGN KEY (certificate_id)
+			REFERENCES certificates (id)
+				ON DELETE CASCADE
+				ON UPDATE NO ACTION
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	// acme_order_post_process_results (re-create to change method CHECK)
+	query = `
+		ALTER TABLE acme_order_post_process_results RENAME TO acme_order_post_process_results_old;
+
+		CREATE TABLE acme_order_post_process_results (
+			id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+			order_id integer NOT NULL,
+			method text NOT NULL CHECK(method IN ("client", "command", "deploy")),
+			deploy_target_id integer,
+			deploy_target_name text NOT NULL DEFAULT "",
+			attempt integer NOT NULL DEFAULT 0,
+			success integer NOT NULL DEFAULT 0 CHECK(success IN (0,1)),
+			started_at integer NOT NULL,
+			ended_at integer NOT NULL,
+			exit_code integer,
+			http_status integer,
+			stdout text NOT NULL DEFAULT "",
+			stderr text NOT NULL DEFAULT "",
+			error text NOT NULL DEFAULT "",
+			FOREIGN KEY (order_id)
+				REFERENCES acme_orders (id)
+					ON DELETE CASCADE
+					ON UPDATE NO ACTION,
+			FOREIGN KEY (deploy_target_id)
+				REFERENCES deploy_targets (id)
+					ON DELETE SET NULL
+					ON UPDATE NO ACTION
+		);
+
+		INSERT INTO acme_order_post_process_results (id, order_id, method, attempt, success, started_at,
+			ended_at, exit_code, http_status, stdout, stderr, error)
+		SELECT id, order_id, method, attempt, success, started_at, ended_at, exit_code, http_status,
+			stdout, stderr, error
+		FROM acme_order_post_process_results_old;
+
+		DROP TABLE acme_order_post_process_results_old;
+	`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV22 creates a fresh set of tables in the db using schema version 22
+func createDBTablesV22(tx *sql.Tx) error {
+	err := createDBTablesV21(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV22(tx)
+}
+
+// migrateV21toV22 updates the storage db from user_version 21 to user_version 22, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV21toV22() (int, error) {
+	oldSchemaVer := 21
+	newSchemaVer := 22
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV22(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
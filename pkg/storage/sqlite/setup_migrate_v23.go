@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v22 to v23:
+// - certificates:
+//     - Add 'post_processing_clients' (json array of client targets to push to instead
+//       of only the subject on the default port)
+//     - Add 'post_processing_client_ca_pem' (additional trusted ca(s) for the clients'
+//       tls)
+// - acme_order_post_process_results:
+//     - Add 'client_url' to identify which client the result is for
+
+// schemaChangesV23 makes the changes to go from schema v22 to v23
+func schemaChangesV23(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE certificates ADD post_processing_clients text NOT NULL DEFAULT "[]";
+		ALTER TABLE certificates ADD post_processing_client_ca_pem text NOT NULL DEFAULT "";
+		ALTER TABLE acme_order_post_process_results ADD client_url text NOT NULL DEFAULT "";
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV23 creates a fresh set of tables in the db using schema version 23
+func createDBTablesV23(tx *sql.Tx) error {
+	err := createDBTablesV22(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV23(tx)
+}
+
+// migrateV22toV23 updates the storage db from user_version 22 to user_version 23, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV22toV23() (int, error) {
+	oldSchemaVer := 22
+	newSchemaVer := 23
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV23(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
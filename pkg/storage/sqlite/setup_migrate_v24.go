@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v23 to v24:
+// - acme_orders:
+//     - Add 'unknown_to_server' to flag orders that the ACME server no longer
+//       recognizes (found during orders reconciliation)
+
+// schemaChangesV24 makes the changes to go from schema v23 to v24
+func schemaChangesV24(tx *sql.Tx) error {
+	// add column
+	query := `
+		ALTER TABLE acme_orders ADD unknown_to_server integer NOT NULL DEFAULT 0 CHECK(unknown_to_server IN (0, 1));
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV24 creates a fresh set of tables in the db using schema version 24
+func createDBTablesV24(tx *sql.Tx) error {
+	err := createDBTablesV23(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV24(tx)
+}
+
+// migrateV23toV24 updates the storage db from user_version 23 to user_version 24, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV23toV24() (int, error) {
+	oldSchemaVer := 23
+	newSchemaVer := 24
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV24(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
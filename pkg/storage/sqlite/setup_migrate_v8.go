@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v7 to v8:
+// - users:
+//     - Add 'totp_secret' field/column
+//     - Add 'totp_enabled' field/column
+//     - Add 'totp_recovery_codes' field/column
+
+// schemaChangesV8 makes the changes to go from schema v7 to v8
+func schemaChangesV8(tx *sql.Tx) error {
+	// add columns
+	query := `
+		ALTER TABLE users ADD totp_secret text NOT NULL DEFAULT "";
+	`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		ALTER TABLE users ADD totp_enabled integer NOT NULL DEFAULT 0 CHECK(totp_enabled IN (0,1));
+	`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		ALTER TABLE users ADD totp_recovery_codes text NOT NULL DEFAULT "[]";
+	`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV8 creates a fresh set of tables in the db using schema version 8
+func createDBTablesV8(tx *sql.Tx) error {
+	err := createDBTablesV7(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV8(tx)
+}
+
+// migrateV7toV8 updates the storage db from user_version 7 to user_version 8, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV7toV8() (int, error) {
+	oldSchemaVer := 7
+	newSchemaVer := 8
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV8(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
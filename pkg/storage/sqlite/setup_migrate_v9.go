@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CHANGES v8 to v9:
+// - audit_events:
+//     - New table to record administrative actions
+
+// schemaChangesV9 makes the changes to go from schema v8 to v9
+func schemaChangesV9(tx *sql.Tx) error {
+	// audit_events
+	query := `CREATE TABLE IF NOT EXISTS audit_events (
+		id integer PRIMARY KEY AUTOINCREMENT NOT NULL UNIQUE,
+		created_at integer NOT NULL,
+		actor text NOT NULL,
+		source_ip text NOT NULL,
+		action text NOT NULL,
+		object_type text NOT NULL,
+		object_id text NOT NULL,
+		success integer NOT NULL CHECK(success IN (0,1)),
+		message text NOT NULL,
+		changes text NOT NULL DEFAULT "{}"
+	)`
+
+	_, err := tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	query = `CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at)`
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createDBTablesV9 creates a fresh set of tables in the db using schema version 9
+func createDBTablesV9(tx *sql.Tx) error {
+	err := createDBTablesV8(tx)
+	if err != nil {
+		return err
+	}
+
+	return schemaChangesV9(tx)
+}
+
+// migrateV8toV9 updates the storage db from user_version 8 to user_version 9, if it cannot
+// do so, an error is returned and modification is aborted
+func (store *Storage) migrateV8toV9() (int, error) {
+	oldSchemaVer := 8
+	newSchemaVer := 9
+
+	store.logger.Infof("updating database user_version from %d to %d", oldSchemaVer, newSchemaVer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	// create sql transaction to roll back in the event an error occurs
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	// verify correct current ver
+	query := `PRAGMA user_version`
+	row := tx.QueryRowContext(ctx, query)
+	fileUserVersion := -1
+	err = row.Scan(
+		&fileUserVersion,
+	)
+	if err != nil {
+		return -1, err
+	}
+	if fileUserVersion != oldSchemaVer {
+		return -1, fmt.Errorf("cannot update db schema, current version %d (expected %d)", fileUserVersion, oldSchemaVer)
+	}
+
+	// schema changes
+	err = schemaChangesV9(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	// update user_version
+	query = fmt.Sprintf(`
+		PRAGMA user_version = %d
+	`, newSchemaVer)
+
+	_, err = tx.Exec(query)
+	if err != nil {
+		return -1, err
+	}
+
+	// no errors, commit transaction
+	err = tx.Commit()
+	if err != nil {
+		return -1, err
+	}
+
+	store.logger.Infof("database user_version successfully upgraded from %d to %d", oldSchemaVer, newSchemaVer)
+	return newSchemaVer, nil
+}
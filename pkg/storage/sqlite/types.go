@@ -81,3 +81,35 @@ func makeJsonCertExtensionSlice(extensionSlice []certificates.CertExtensionJSON)
 
 	return jsonCertExtensionSlice(jpes)
 }
+
+// jsonClientTargetSlice is a json formatted string that is a slice of ClientTarget
+type jsonClientTargetSlice string
+
+// transform JCTS into a slice of ClientTarget
+func (jcts jsonClientTargetSlice) toClientTargetSlice() ([]certificates.ClientTarget, error) {
+	targets := []certificates.ClientTarget{}
+	if jcts == "" {
+		return targets, nil
+	}
+
+	err := json.Unmarshal([]byte(jcts), &targets)
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// makeJsonClientTargetSlice creates a JCTS from a slice of ClientTarget
+func makeJsonClientTargetSlice(targets []certificates.ClientTarget) jsonClientTargetSlice {
+	if len(targets) == 0 {
+		return "[]"
+	}
+
+	jcts, err := json.Marshal(targets)
+	if err != nil {
+		return "[]"
+	}
+
+	return jsonClientTargetSlice(jcts)
+}
@@ -2,9 +2,12 @@ package sqlite
 
 // userDb represents how users are stored in the db
 type userDb struct {
-	id           int
-	username     string
-	passwordHash string
-	createdAt    int
-	updatedAt    int
+	id                int
+	username          string
+	passwordHash      string
+	totpSecret        string
+	totpEnabled       bool
+	totpRecoveryCodes jsonStringSlice
+	createdAt         int
+	updatedAt         int
 }
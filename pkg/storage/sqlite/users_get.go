@@ -11,11 +11,14 @@ import (
 // dbToUser converts the user db object to app object
 func (userDb *userDb) dbToUser() (user auth.User) {
 	return auth.User{
-		ID:           userDb.id,
-		Username:     userDb.username,
-		PasswordHash: userDb.passwordHash,
-		CreatedAt:    userDb.createdAt,
-		UpdatedAt:    userDb.updatedAt,
+		ID:                userDb.id,
+		Username:          userDb.username,
+		PasswordHash:      userDb.passwordHash,
+		TotpSecret:        userDb.totpSecret,
+		TotpEnabled:       userDb.totpEnabled,
+		TotpRecoveryCodes: userDb.totpRecoveryCodes.toSlice(),
+		CreatedAt:         userDb.createdAt,
+		UpdatedAt:         userDb.updatedAt,
 	}
 }
 
@@ -27,7 +30,8 @@ func (store Storage) GetOneUserByName(username string) (auth.User, error) {
 
 	query := `
 	SELECT
-		id, username, password_hash, created_at, updated_at
+		id, username, password_hash, totp_secret, totp_enabled, totp_recovery_codes,
+		created_at, updated_at
 	FROM
 		users
 	WHERE
@@ -41,6 +45,9 @@ func (store Storage) GetOneUserByName(username string) (auth.User, error) {
 		&user.id,
 		&user.username,
 		&user.passwordHash,
+		&user.totpSecret,
+		&user.totpEnabled,
+		&user.totpRecoveryCodes,
 		&user.createdAt,
 		&user.updatedAt,
 	)
@@ -34,3 +34,80 @@ func (store *Storage) UpdateUserPassword(username string, newPasswordHash string
 
 	return userId, nil
 }
+
+// UpdateUserTotp updates the specified user's TOTP secret, enabled state, and
+// the hashes of the user's recovery codes.
+func (store *Storage) UpdateUserTotp(username string, totpSecret string, totpEnabled bool, recoveryCodeHashes []string) (userId int, err error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	UPDATE
+		users
+	SET
+		totp_secret = $1,
+		totp_enabled = $2,
+		totp_recovery_codes = $3,
+		updated_at = $4
+	WHERE
+		username = $5
+	RETURNING
+		id
+	`
+
+	// update totp and return id
+	err = store.db.QueryRowContext(ctx, query,
+		totpSecret,
+		totpEnabled,
+		makeJsonStringSlice(recoveryCodeHashes),
+		timeNow(),
+		username,
+	).Scan(&userId)
+
+	if err != nil {
+		return -2, err
+	}
+
+	return userId, nil
+}
+
+// ReplaceUserTotpRecoveryCodes replaces the specified user's recovery code hashes,
+// but only if the currently stored hashes are still oldRecoveryCodeHashes. This
+// allows a recovery code to be consumed atomically (i.e. a concurrent request can't
+// also redeem the same code). If the stored hashes had already changed, replaced
+// is false.
+func (store *Storage) ReplaceUserTotpRecoveryCodes(username string, oldRecoveryCodeHashes []string, newRecoveryCodeHashes []string) (replaced bool, err error) {
+	// database action
+	ctx, cancel := context.WithTimeout(context.Background(), store.timeout)
+	defer cancel()
+
+	query := `
+	UPDATE
+		users
+	SET
+		totp_recovery_codes = $1,
+		updated_at = $2
+	WHERE
+		username = $3
+		AND
+		totp_recovery_codes = $4
+	`
+
+	result, err := store.db.ExecContext(ctx, query,
+		makeJsonStringSlice(newRecoveryCodeHashes),
+		timeNow(),
+		username,
+		makeJsonStringSlice(oldRecoveryCodeHashes),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}